@@ -0,0 +1,168 @@
+// Command mockserver serves an OpenAPI spec generated for an api-service
+// node, calling the node's downstream services before each response. It
+// is the entrypoint of the mock server image built from
+// docker/mock-server.Dockerfile. Requests and calls are traced and, when
+// OTEL_EXPORTER_OTLP_ENDPOINT is set, exported.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/mockserver"
+	"github.com/stwalsh4118/hephaestus/backend/internal/otlp"
+)
+
+const (
+	defaultAddr        = ":4010"
+	defaultMetricsAddr = ":9464"
+	defaultSpecPath    = "/tmp/spec.json"
+	shutdownTimeout    = 5 * time.Second
+	readTimeout        = 15 * time.Second
+	// writeTimeout leaves room for simulated timeouts, which hold a request
+	// for up to a minute.
+	writeTimeout = 90 * time.Second
+	idleTimeout  = 60 * time.Second
+
+	// reloadInterval is how often the spec is checked for changes, which
+	// the backend makes to adjust a running service.
+	reloadInterval = time.Second
+)
+
+func main() {
+	addr := flag.String("addr", defaultAddr, "address to listen on")
+	specPath := flag.String("spec", defaultSpecPath, "path of the OpenAPI spec to serve")
+	metricsAddr := flag.String("metrics-addr", defaultMetricsAddr, "address to serve Prometheus metrics on; empty disables them")
+	flag.Parse()
+
+	spec, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatalf("read spec: %v", err)
+	}
+	tracerCfg, err := tracerConfigFromEnv()
+	if err != nil {
+		log.Fatalf("tracing: %v", err)
+	}
+	tracer := mockserver.NewTracer(tracerCfg)
+	clients := mockserver.NewClients()
+	metrics := mockserver.NewMetrics()
+	mock, err := mockserver.New(spec, tracer.Caller(metrics.Caller(clients)))
+	if err != nil {
+		log.Fatalf("load spec %s: %v", *specPath, err)
+	}
+	defer clients.Close()
+
+	ctx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go mock.Watch(ctx, *specPath, reloadInterval)
+
+	exportCtx, stopExport := context.WithCancel(context.Background())
+	exported := make(chan struct{})
+	go func() {
+		tracer.Run(exportCtx)
+		close(exported)
+	}()
+
+	server := &http.Server{
+		Addr:         *addr,
+		Handler:      tracer.Instrument(mock, metrics.Instrument(mock)),
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  idleTimeout,
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		log.Printf("mock server listening on %s", server.Addr)
+		errs <- server.ListenAndServe()
+	}()
+
+	// Metrics are served on their own port so they never clash with a
+	// path of the spec.
+	var metricsServer *http.Server
+	if *metricsAddr != "" {
+		metricsServer = &http.Server{
+			Addr:         *metricsAddr,
+			Handler:      metrics.Handler(),
+			ReadTimeout:  readTimeout,
+			WriteTimeout: readTimeout,
+			IdleTimeout:  idleTimeout,
+		}
+		go func() {
+			log.Printf("metrics listening on %s", metricsServer.Addr)
+			errs <- metricsServer.ListenAndServe()
+		}()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case sig := <-quit:
+		log.Printf("received signal %s; shutting down", sig)
+	case err := <-errs:
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatalf("server error: %v", err)
+		}
+		return
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("graceful shutdown failed: %v", err)
+	}
+	if metricsServer != nil {
+		_ = metricsServer.Close()
+	}
+	// Send the spans of the requests just drained.
+	stopExport()
+	<-exported
+}
+
+// tracerConfigFromEnv reads the standard OpenTelemetry variables:
+// OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES
+// (key=value pairs separated by commas, values percent-encoded) and
+// OTEL_TRACES_SAMPLER_ARG (the ratio of new traces recorded, default 1).
+func tracerConfigFromEnv() (mockserver.TracerConfig, error) {
+	cfg := mockserver.TracerConfig{
+		Endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		Resource:    map[string]string{},
+		SampleRatio: 1,
+	}
+	for _, pair := range strings.Split(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			return cfg, fmt.Errorf("OTEL_RESOURCE_ATTRIBUTES: %q is not key=value", pair)
+		}
+		value, err := url.PathUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return cfg, fmt.Errorf("OTEL_RESOURCE_ATTRIBUTES: value of %q: %w", k, err)
+		}
+		cfg.Resource[strings.TrimSpace(k)] = value
+	}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		cfg.Resource[otlp.AttrServiceName] = name
+	}
+	if arg := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); arg != "" {
+		ratio, err := strconv.ParseFloat(arg, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return cfg, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG: %q is not a ratio between 0 and 1", arg)
+		}
+		cfg.SampleRatio = ratio
+	}
+	return cfg, nil
+}
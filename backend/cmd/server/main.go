@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -10,10 +11,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/distribution/reference"
+	"github.com/stwalsh4118/hephaestus/backend/internal/chaos"
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
 	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/export"
 	"github.com/stwalsh4118/hephaestus/backend/internal/handler"
+	"github.com/stwalsh4118/hephaestus/backend/internal/metrics"
 	"github.com/stwalsh4118/hephaestus/backend/internal/middleware"
 	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+	"github.com/stwalsh4118/hephaestus/backend/internal/tracing"
+	"github.com/stwalsh4118/hephaestus/backend/internal/traffic"
+	"github.com/stwalsh4118/hephaestus/backend/internal/tsdb"
 )
 
 const (
@@ -22,10 +31,31 @@ const (
 	readTimeout     = 15 * time.Second
 	writeTimeout    = 15 * time.Second
 	idleTimeout     = 60 * time.Second
+
+	// dockerPingTimeout bounds the startup check for a reachable daemon.
+	dockerPingTimeout = 3 * time.Second
+	// sweepTimeout bounds the startup sweep for orphaned resources.
+	sweepTimeout = 30 * time.Second
+)
+
+// Orchestrator selection and simulation settings.
+const (
+	orchestratorEnv   = "HEPH_ORCHESTRATOR" // auto (default) | docker | simulated
+	orchestratorAuto  = "auto"
+	simStartDelayEnv  = "HEPH_SIM_START_DELAY"
+	simHealthDelayEnv = "HEPH_SIM_HEALTH_DELAY"
+	simFailuresEnv    = "HEPH_SIM_FAILURES"
+	orphanPolicyEnv   = "HEPH_ORPHAN_POLICY"     // remove (default) | adopt
+	shutdownPolicyEnv = "HEPH_SHUTDOWN_POLICY"   // teardown (default) | detach
+	trafficGenEnv     = "HEPH_TRAFFIC_GENERATOR" // native (default) | k6
+	otlpAddrEnv       = "HEPH_OTLP_ADDR"         // OTLP receiver address (default :4318)
+	otlpEndpointEnv   = "HEPH_OTLP_ENDPOINT"     // receiver URL as seen from containers
+	mockImageEnv      = "HEPH_MOCK_IMAGE"        // image api-service nodes run
 )
 
 type healthResponse struct {
-	Status string `json:"status"`
+	Status       string `json:"status"`
+	Orchestrator string `json:"orchestrator"`
 }
 
 func main() {
@@ -34,37 +64,158 @@ func main() {
 		port = defaultPort
 	}
 
+	mockImage := os.Getenv(mockImageEnv)
+	if mockImage != "" {
+		if _, err := reference.ParseNormalizedNamed(mockImage); err != nil {
+			log.Fatalf("%s: %v", mockImageEnv, err)
+		}
+	}
+
 	store, err := storage.NewFileStore("")
 	if err != nil {
 		log.Fatalf("failed to initialize storage: %v", err)
 	}
 
-	// Initialize Docker orchestrator (non-fatal if Docker is unavailable).
-	// pollingCtx controls any background health polling; cancel it before teardown.
+	deploymentStore, err := storage.NewFileDeploymentStore("")
+	if err != nil {
+		log.Fatalf("failed to initialize deployment storage: %v", err)
+	}
+
+	profileStore, err := storage.NewFileProfileStore("")
+	if err != nil {
+		log.Fatalf("failed to initialize profile storage: %v", err)
+	}
+
+	// Use Docker when a daemon is reachable; otherwise fall back to the
+	// in-memory simulator. pollingCtx controls background health polling and
+	// metrics sampling; cancel it before teardown.
 	pollingCtx, cancelPolling := context.WithCancel(context.Background())
-	_ = pollingCtx // will be passed to StartHealthPolling when deploy flow is wired
-
-	dockerClient, dockerErr := docker.NewClient()
-	var orchestrator *docker.DockerOrchestrator
-	if dockerErr != nil {
-		log.Printf("docker client unavailable: %v (orchestration features disabled)", dockerErr)
-	} else {
-		orchestrator = docker.NewDockerOrchestrator(dockerClient)
-		log.Println("docker orchestrator initialized")
+
+	orchestrator, dockerClient, mode, err := newOrchestrator()
+	if err != nil {
+		log.Fatalf("failed to initialize orchestrator: %v", err)
+	}
+
+	orphanPolicy, err := deploy.ParseOrphanPolicy(os.Getenv(orphanPolicyEnv))
+	if err != nil {
+		log.Fatalf("%s: %v", orphanPolicyEnv, err)
+	}
+	shutdownPolicy, err := deploy.ParseShutdownPolicy(os.Getenv(shutdownPolicyEnv))
+	if err != nil {
+		log.Fatalf("%s: %v", shutdownPolicyEnv, err)
+	}
+	if shutdownPolicy == deploy.ShutdownDetach && mode == docker.ModeSimulated {
+		log.Printf("%s=%s has no effect in simulated mode: simulated containers do not outlive the process", shutdownPolicyEnv, shutdownPolicy)
+	}
+
+	wsHandler := handler.NewWebSocketHandler()
+	backendMetrics := metrics.New()
+	manager := deploy.NewManager(orchestrator, deploymentStore, mode, func(s deploy.Status) {
+		backendMetrics.ObserveDeployment(s)
+		wsHandler.Broadcast(handler.WSMessageDeploymentStatus, s)
+	})
+	manager.SetMockImage(mockImage)
+	orchestrator.Events().Subscribe(func(e docker.Event) {
+		backendMetrics.ObserveEvent(e)
+		wsHandler.Broadcast(handler.WSMessageOrchestratorEvent, e)
+	})
+	if d, ok := orchestrator.(*docker.DockerOrchestrator); ok {
+		d.OnAPIError(backendMetrics.ObserveDockerAPIError)
+	}
+	// Mock servers export their spans to the OTLP receiver below.
+	orchestrator.Events().OnPreCreate(tracing.ExportHook(envOr(otlpEndpointEnv, tracing.DefaultEndpoint)))
+	backendMetrics.CountContainers(orchestrator)
+	backendMetrics.CountWebSocketClients(wsHandler.Clients)
+
+	// Reattach to a deployment the previous run detached from, and clean up
+	// or take over whatever a run that was killed left behind, before
+	// polling or any deploy touches it.
+	sweepCtx, cancelSweep := context.WithTimeout(context.Background(), sweepTimeout)
+	if _, err := manager.Sweep(sweepCtx, orphanPolicy); err != nil {
+		log.Printf("orphan sweep failed: %v", err)
 	}
+	cancelSweep()
+
+	orchestrator.StartHealthPolling(pollingCtx, docker.DefaultHealthCheckInterval, manager.HandleHealth)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /health", healthHandler)
+	mux.HandleFunc("GET /health", healthHandler(mode))
+	mux.Handle("GET /metrics", backendMetrics.Handler())
 
 	diagramHandler := handler.NewDiagramHandler(store)
 	diagramHandler.RegisterRoutes(mux)
 
-	wsHandler := handler.NewWebSocketHandler()
+	exportHandler := handler.NewExportHandler(store, export.Options{MockImage: mockImage})
+	exportHandler.RegisterRoutes(mux)
+
+	costHandler := handler.NewCostHandler(store)
+	costHandler.RegisterRoutes(mux)
+
+	importHandler := handler.NewImportHandler(mockImage)
+	importHandler.RegisterRoutes(mux)
+
+	deployHandler := handler.NewDeployHandler(store, manager)
+	deployHandler.RegisterRoutes(mux)
+
+	planHandler := handler.NewPlanHandler(store, manager)
+	planHandler.RegisterRoutes(mux)
+
+	deploymentHandler := handler.NewDeploymentHandler(deploymentStore)
+	deploymentHandler.RegisterRoutes(mux)
+
+	systemHandler := handler.NewSystemHandler(manager)
+	systemHandler.RegisterRoutes(mux)
+
+	trafficGen, err := newTrafficGenerator(orchestrator)
+	if err != nil {
+		log.Fatalf("%s: %v", trafficGenEnv, err)
+	}
+	timeSeries := tsdb.NewStore()
+	sampler := tsdb.NewSampler(timeSeries, orchestrator, manager)
+	go sampler.Run(pollingCtx, tsdb.DefaultSampleInterval)
+
+	trafficController := traffic.NewController(trafficGen, func(r traffic.Run) {
+		sampler.ObserveTraffic(r)
+		wsHandler.Broadcast(handler.WSMessageTrafficStats, r)
+	})
+	trafficHandler := handler.NewTrafficHandler(store, manager, trafficController, traffic.NewProfiles(profileStore))
+	trafficHandler.RegisterRoutes(mux)
+
+	chaosController := chaos.NewController(orchestrator, manager, func(e chaos.Event) {
+		wsHandler.Broadcast(handler.WSMessageChaosEvent, e)
+	})
+	chaosHandler := handler.NewChaosHandler(chaosController)
+	chaosHandler.RegisterRoutes(mux)
+
+	prometheusHandler := handler.NewPrometheusHandler(manager)
+	prometheusHandler.RegisterRoutes(mux)
+
+	timeSeriesHandler := handler.NewTimeSeriesHandler(timeSeries, manager)
+	timeSeriesHandler.RegisterRoutes(mux)
+
+	traces := tracing.NewStore()
+	tracesHandler := handler.NewTracesHandler(traces, manager)
+	tracesHandler.RegisterRoutes(mux)
+
+	// New clients, including ones reconnecting after a restart, start from
+	// the current deployment status.
+	wsHandler.SendOnConnect(handler.WSMessageDeploymentStatus, func() any { return manager.Status() })
 	wsHandler.RegisterRoutes(mux)
 
 	server := &http.Server{
 		Addr:         ":" + port,
-		Handler:      middleware.CORS()(mux),
+		Handler:      middleware.Instrument(backendMetrics)(middleware.CORS()(mux)),
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  idleTimeout,
+	}
+
+	// OTLP has its own well-known port, which containers export spans to.
+	otlpMux := http.NewServeMux()
+	handler.NewOTLPHandler(traces, manager).RegisterRoutes(otlpMux)
+	otlpServer := &http.Server{
+		Addr:         envOr(otlpAddrEnv, tracing.DefaultReceiverAddr),
+		Handler:      otlpMux,
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
@@ -75,6 +226,13 @@ func main() {
 		log.Printf("server listening on %s", server.Addr)
 		errs <- server.ListenAndServe()
 	}()
+	// Without the receiver only tracing is lost, so the server keeps going.
+	go func() {
+		log.Printf("OTLP receiver listening on %s", otlpServer.Addr)
+		if err := otlpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("OTLP receiver error: %v", err)
+		}
+	}()
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -95,35 +253,147 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("graceful shutdown failed: %v", err)
 	}
+	_ = otlpServer.Close()
 
 	// Cancel health polling before teardown to stop background goroutines.
 	cancelPolling()
 
-	// Teardown Docker resources after HTTP server has drained.
-	if orchestrator != nil {
-		log.Println("tearing down docker resources...")
-		if err := orchestrator.TeardownAll(ctx); err != nil {
-			log.Printf("docker teardown errors: %v", err)
-		} else {
-			log.Println("docker teardown complete")
+	// Revert running chaos experiments so no container is left paused or
+	// disconnected, even when detaching.
+	if err := chaosController.Shutdown(ctx); err != nil {
+		log.Printf("chaos shutdown errors: %v", err)
+	}
+
+	// Stop traffic so a k6 container is removed even when detaching.
+	if _, err := trafficController.Stop(); err == nil {
+		log.Println("traffic stopped")
+	}
+
+	// Tear down or detach from the deployment after HTTP server has drained.
+	if shutdownPolicy == deploy.ShutdownTeardown {
+		log.Printf("tearing down %s resources...", mode)
+	}
+	if err := manager.Shutdown(ctx, shutdownPolicy); err != nil {
+		log.Printf("%s errors: %v", shutdownPolicy, err)
+	} else if shutdownPolicy == deploy.ShutdownTeardown {
+		log.Println("teardown complete")
+	}
+	if dockerClient != nil {
+		if err := dockerClient.Close(); err != nil {
+			log.Printf("failed to close docker client: %v", err)
 		}
-		if dockerClient != nil {
-			if err := dockerClient.Close(); err != nil {
-				log.Printf("failed to close docker client: %v", err)
+	}
+
+	log.Println("server stopped")
+}
+
+// newOrchestrator selects the orchestrator from HEPH_ORCHESTRATOR. In auto
+// mode (the default) it uses Docker when the daemon answers a ping and falls
+// back to the simulator otherwise. The Docker client is nil when simulating.
+func newOrchestrator() (docker.Orchestrator, *docker.Client, string, error) {
+	requested := os.Getenv(orchestratorEnv)
+	switch requested {
+	case "", orchestratorAuto, docker.ModeDocker:
+	case docker.ModeSimulated:
+		sim, err := newSimulatedOrchestrator()
+		if err != nil {
+			return nil, nil, "", err
+		}
+		log.Printf("SIMULATED orchestrator selected via %s; no real containers will run", orchestratorEnv)
+		return sim, nil, docker.ModeSimulated, nil
+	default:
+		return nil, nil, "", fmt.Errorf("%s=%q: must be %s, %s or %s", orchestratorEnv, requested, orchestratorAuto, docker.ModeDocker, docker.ModeSimulated)
+	}
+
+	client, err := connectDocker()
+	if err == nil {
+		log.Println("docker orchestrator initialized")
+		return docker.NewDockerOrchestrator(client), client, docker.ModeDocker, nil
+	}
+	if requested == docker.ModeDocker {
+		return nil, nil, "", err
+	}
+
+	sim, simErr := newSimulatedOrchestrator()
+	if simErr != nil {
+		return nil, nil, "", simErr
+	}
+	log.Printf("docker unavailable: %v; falling back to SIMULATED orchestrator (no real containers will run)", err)
+	return sim, nil, docker.ModeSimulated, nil
+}
+
+// connectDocker creates a Docker client and verifies the daemon answers.
+func connectDocker() (*docker.Client, error) {
+	client, err := docker.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dockerPingTimeout)
+	defer cancel()
+	if err := client.Ping(ctx); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// newTrafficGenerator selects the traffic generator from
+// HEPH_TRAFFIC_GENERATOR. k6 runs in a container through orch.
+func newTrafficGenerator(orch docker.Orchestrator) (traffic.Generator, error) {
+	switch name := os.Getenv(trafficGenEnv); name {
+	case "", "native":
+		return traffic.NewNativeGenerator(), nil
+	case "k6":
+		return traffic.NewK6Generator(orch), nil
+	default:
+		return nil, fmt.Errorf("unknown traffic generator %q: must be native or k6", name)
+	}
+}
+
+// newSimulatedOrchestrator builds a simulator from the HEPH_SIM_* variables.
+func newSimulatedOrchestrator() (*docker.SimulatedOrchestrator, error) {
+	cfg := docker.DefaultSimulationConfig()
+
+	for env, dst := range map[string]*time.Duration{
+		simStartDelayEnv:  &cfg.StartDelay,
+		simHealthDelayEnv: &cfg.HealthDelay,
+	} {
+		if v := os.Getenv(env); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("%s=%q: must be a non-negative duration such as 500ms", env, v)
 			}
+			*dst = d
 		}
 	}
 
-	log.Println("server stopped")
+	failures, err := docker.ParseSimFailures(os.Getenv(simFailuresEnv))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", simFailuresEnv, err)
+	}
+	cfg.Failures = failures
+
+	return docker.NewSimulatedOrchestrator(cfg), nil
 }
 
-func healthHandler(w http.ResponseWriter, _ *http.Request) {
-	response := healthResponse{Status: "ok"}
+func healthHandler(mode string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		response := healthResponse{Status: "ok", Orchestrator: mode}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("failed to write health response: %v", err)
+		}
+	}
+}
 
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("failed to write health response: %v", err)
+// envOr returns the environment variable key, or fallback when unset.
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
+	return fallback
 }
@@ -0,0 +1,218 @@
+// Package chaos disrupts nodes of the running deployment: it kills, pauses,
+// restarts and disconnects their containers, either immediately or as
+// scheduled experiments that revert themselves after a set duration.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Action is a disruption applied to a node's container.
+type Action string
+
+const (
+	ActionKill       Action = "kill"
+	ActionPause      Action = "pause"
+	ActionUnpause    Action = "unpause"
+	ActionRestart    Action = "restart"
+	ActionDisconnect Action = "disconnect"
+	ActionReconnect  Action = "reconnect"
+)
+
+// reverts maps the actions an experiment can run to the action that undoes
+// them. A killed container is brought back by restarting it.
+var reverts = map[Action]Action{
+	ActionKill:       ActionRestart,
+	ActionPause:      ActionUnpause,
+	ActionDisconnect: ActionReconnect,
+}
+
+// validActions is the set of recognised actions.
+var validActions = map[Action]bool{
+	ActionKill:       true,
+	ActionPause:      true,
+	ActionUnpause:    true,
+	ActionRestart:    true,
+	ActionDisconnect: true,
+	ActionReconnect:  true,
+}
+
+// DefaultSignal is sent by a kill that names no signal.
+const DefaultSignal = "SIGKILL"
+
+// Experiment limits.
+const (
+	MaxExperimentDuration = time.Hour
+	// MaxExperimentDelay bounds how far ahead an experiment can start.
+	MaxExperimentDelay = 24 * time.Hour
+)
+
+// signalPattern matches signal names such as SIGTERM and numbers such as 9.
+var signalPattern = regexp.MustCompile(`^(SIG[A-Z0-9+-]+|[0-9]{1,2})$`)
+
+var (
+	// ErrInvalid is wrapped by errors for requests that can never succeed.
+	ErrInvalid = errors.New("invalid chaos request")
+	// ErrNotDeployed is returned when no deployment is running.
+	ErrNotDeployed = errors.New("no deployment is running")
+	// ErrUnknownNode is returned for a node without a container in the
+	// running deployment.
+	ErrUnknownNode = errors.New("node is not deployed")
+	// ErrExperimentNotFound is returned for an unknown experiment ID.
+	ErrExperimentNotFound = errors.New("experiment not found")
+	// ErrExperimentFinished is returned when cancelling an experiment that
+	// has already ended.
+	ErrExperimentFinished = errors.New("experiment has already finished")
+)
+
+// Request applies one action to one node.
+type Request struct {
+	NodeID string `json:"nodeId"`
+	Action Action `json:"action"`
+	// Signal is sent by kill; it defaults to DefaultSignal.
+	Signal string `json:"signal,omitempty"`
+}
+
+// Validate checks the request and fills in the default signal.
+func (r *Request) Validate() error {
+	if r.NodeID == "" {
+		return fmt.Errorf("%w: nodeId is required", ErrInvalid)
+	}
+	if !validActions[r.Action] {
+		return fmt.Errorf("%w: action %q must be kill, pause, unpause, restart, disconnect or reconnect", ErrInvalid, r.Action)
+	}
+	if r.Signal != "" && r.Action != ActionKill {
+		return fmt.Errorf("%w: signal only applies to kill", ErrInvalid)
+	}
+	if r.Action == ActionKill {
+		if r.Signal == "" {
+			r.Signal = DefaultSignal
+		}
+		if !signalPattern.MatchString(r.Signal) {
+			return fmt.Errorf("%w: signal %q must be a name such as SIGTERM or a number", ErrInvalid, r.Signal)
+		}
+	}
+	return nil
+}
+
+// ExperimentSpec schedules an action and its automatic revert.
+type ExperimentSpec struct {
+	Request
+	// StartAt is when the action runs; zero means now.
+	StartAt time.Time `json:"startAt"`
+	// DurationSeconds is how long the action lasts before it is reverted.
+	DurationSeconds int `json:"durationSeconds"`
+}
+
+// Validate checks the spec against now and fills in defaults.
+func (s *ExperimentSpec) Validate(now time.Time) error {
+	if err := s.Request.Validate(); err != nil {
+		return err
+	}
+	if _, ok := reverts[s.Action]; !ok {
+		return fmt.Errorf("%w: experiments support kill, pause and disconnect, not %q", ErrInvalid, s.Action)
+	}
+	if s.DurationSeconds < 1 || time.Duration(s.DurationSeconds)*time.Second > MaxExperimentDuration {
+		return fmt.Errorf("%w: durationSeconds must be between 1 and %d", ErrInvalid, int(MaxExperimentDuration/time.Second))
+	}
+	if s.StartAt.IsZero() || s.StartAt.Before(now) {
+		s.StartAt = now
+	}
+	if s.StartAt.Sub(now) > MaxExperimentDelay {
+		return fmt.Errorf("%w: startAt must be within %v", ErrInvalid, MaxExperimentDelay)
+	}
+	return nil
+}
+
+// duration returns the spec's duration.
+func (s ExperimentSpec) duration() time.Duration {
+	return time.Duration(s.DurationSeconds) * time.Second
+}
+
+// ExperimentState is the lifecycle state of an experiment.
+type ExperimentState string
+
+const (
+	ExperimentScheduled ExperimentState = "scheduled"
+	ExperimentRunning   ExperimentState = "running"
+	ExperimentCompleted ExperimentState = "completed"
+	ExperimentCancelled ExperimentState = "cancelled"
+	ExperimentFailed    ExperimentState = "failed"
+)
+
+// Experiment is a snapshot of a scheduled experiment.
+type Experiment struct {
+	ID string `json:"id"`
+	ExperimentSpec
+	State ExperimentState `json:"state"`
+	// ContainerID is the container the action was applied to, and the one
+	// the revert targets.
+	ContainerID string     `json:"containerId,omitempty"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	EndedAt     *time.Time `json:"endedAt,omitempty"`
+	Error       string     `json:"error,omitempty"`
+
+	// name is the container name, reported with the revert.
+	name string
+}
+
+// finished reports whether the experiment has ended.
+func (e *Experiment) finished() bool {
+	switch e.State {
+	case ExperimentCompleted, ExperimentCancelled, ExperimentFailed:
+		return true
+	}
+	return false
+}
+
+// clone returns a copy of e safe to hand to other goroutines.
+func (e *Experiment) clone() Experiment {
+	cp := *e
+	if e.StartedAt != nil {
+		t := *e.StartedAt
+		cp.StartedAt = &t
+	}
+	if e.EndedAt != nil {
+		t := *e.EndedAt
+		cp.EndedAt = &t
+	}
+	return cp
+}
+
+// Result records one action applied to a container.
+type Result struct {
+	Request
+	At          time.Time `json:"at"`
+	ContainerID string    `json:"containerId,omitempty"`
+	// Name is the node's container name.
+	Name string `json:"name,omitempty"`
+	// ExperimentID is set for actions run by an experiment.
+	ExperimentID string `json:"experimentId,omitempty"`
+	// Revert is set for the action that ends an experiment.
+	Revert bool   `json:"revert,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// EventType identifies a chaos event.
+type EventType string
+
+const (
+	// EventAction is published for every action run, successful or not.
+	EventAction EventType = "chaos.action"
+	// EventExperiment is published whenever an experiment changes state.
+	EventExperiment EventType = "chaos.experiment"
+)
+
+// Event is published by the Controller. Only the field relevant to Type is
+// set.
+type Event struct {
+	Type       EventType   `json:"type"`
+	Action     *Result     `json:"action,omitempty"`
+	Experiment *Experiment `json:"experiment,omitempty"`
+}
+
+// Notifier receives chaos events.
+type Notifier func(Event)
@@ -0,0 +1,68 @@
+package chaos
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantErr bool
+	}{
+		{"pause", Request{NodeID: "api", Action: ActionPause}, false},
+		{"kill with signal name", Request{NodeID: "api", Action: ActionKill, Signal: "SIGTERM"}, false},
+		{"kill with signal number", Request{NodeID: "api", Action: ActionKill, Signal: "15"}, false},
+		{"missing node", Request{Action: ActionPause}, true},
+		{"unknown action", Request{NodeID: "api", Action: "explode"}, true},
+		{"signal on pause", Request{NodeID: "api", Action: ActionPause, Signal: "SIGTERM"}, true},
+		{"bad signal", Request{NodeID: "api", Action: ActionKill, Signal: "TERM; rm"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) {
+				t.Errorf("expected ErrInvalid, got %v", err)
+			}
+		})
+	}
+
+	kill := Request{NodeID: "api", Action: ActionKill}
+	if err := kill.Validate(); err != nil || kill.Signal != DefaultSignal {
+		t.Errorf("expected the default signal, got %q (%v)", kill.Signal, err)
+	}
+}
+
+func TestExperimentSpec_Validate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		spec    ExperimentSpec
+		wantErr bool
+	}{
+		{"pause now", ExperimentSpec{Request: Request{NodeID: "api", Action: ActionPause}, DurationSeconds: 30}, false},
+		{"disconnect later", ExperimentSpec{Request: Request{NodeID: "api", Action: ActionDisconnect}, StartAt: now.Add(time.Hour), DurationSeconds: 30}, false},
+		{"restart cannot be reverted", ExperimentSpec{Request: Request{NodeID: "api", Action: ActionRestart}, DurationSeconds: 30}, true},
+		{"no duration", ExperimentSpec{Request: Request{NodeID: "api", Action: ActionKill}}, true},
+		{"too long", ExperimentSpec{Request: Request{NodeID: "api", Action: ActionKill}, DurationSeconds: 3601}, true},
+		{"too far ahead", ExperimentSpec{Request: Request{NodeID: "api", Action: ActionKill}, StartAt: now.Add(25 * time.Hour), DurationSeconds: 30}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate(now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	past := ExperimentSpec{Request: Request{NodeID: "api", Action: ActionPause}, StartAt: now.Add(-time.Hour), DurationSeconds: 30}
+	if err := past.Validate(now); err != nil || !past.StartAt.Equal(now) {
+		t.Errorf("expected a past start to run now, got %v (%v)", past.StartAt, err)
+	}
+}
@@ -0,0 +1,380 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+)
+
+// actionTimeout bounds an action run by an experiment. A restart stops the
+// container gracefully first, which can take docker.StopTimeout.
+const actionTimeout = 30 * time.Second
+
+// maxFinishedExperiments is how many ended experiments are kept for status
+// queries; older ones are dropped.
+const maxFinishedExperiments = 100
+
+// Deployment reports the running deployment. *deploy.Manager satisfies it.
+type Deployment interface {
+	Status() deploy.Status
+}
+
+// timer is a pending call scheduled by afterFunc.
+type timer interface {
+	Stop() bool
+}
+
+// target is the container an action applies to.
+type target struct {
+	containerID string
+	name        string
+}
+
+// Controller applies chaos actions to the nodes of the running deployment
+// and runs scheduled experiments. Every action it runs is published as an
+// EventAction and every experiment transition as an EventExperiment.
+type Controller struct {
+	orch       docker.Orchestrator
+	deployment Deployment
+	notify     Notifier
+	now        func() time.Time
+	afterFunc  func(time.Duration, func()) timer
+
+	opMu sync.Mutex // serialises experiment starts, reverts and cancels
+
+	mu sync.Mutex // guards experiments and timers
+	// experiments are kept in the order they were scheduled.
+	experiments []*Experiment
+	// timers hold the pending start or revert of each active experiment.
+	timers map[string]timer
+}
+
+// NewController creates a Controller acting on orch for the nodes of
+// deployment. notify may be nil.
+func NewController(orch docker.Orchestrator, deployment Deployment, notify Notifier) *Controller {
+	if notify == nil {
+		notify = func(Event) {}
+	}
+	return &Controller{
+		orch:       orch,
+		deployment: deployment,
+		notify:     notify,
+		now:        time.Now,
+		afterFunc:  func(d time.Duration, f func()) timer { return time.AfterFunc(d, f) },
+		timers:     make(map[string]timer),
+	}
+}
+
+// Do applies req to its node immediately. The returned Result is also
+// published; when the orchestrator rejects the action, it carries the error
+// that is returned too.
+func (c *Controller) Do(ctx context.Context, req Request) (Result, error) {
+	if err := req.Validate(); err != nil {
+		return Result{}, err
+	}
+	t, err := c.resolve(req.NodeID)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.apply(ctx, req, t, "", false)
+}
+
+// Schedule registers an experiment that applies spec's action at its start
+// time and reverts it once its duration has passed. The node must be
+// deployed when the experiment is scheduled; it is looked up again when the
+// experiment starts, and the experiment fails if it is gone by then.
+func (c *Controller) Schedule(spec ExperimentSpec) (Experiment, error) {
+	now := c.now().UTC()
+	if err := spec.Validate(now); err != nil {
+		return Experiment{}, err
+	}
+	if _, err := c.resolve(spec.NodeID); err != nil {
+		return Experiment{}, err
+	}
+
+	e := &Experiment{ID: uuid.New().String(), ExperimentSpec: spec, State: ExperimentScheduled}
+	c.mu.Lock()
+	c.experiments = append(c.experiments, e)
+	c.pruneLocked()
+	c.timers[e.ID] = c.afterFunc(spec.StartAt.Sub(now), func() { c.start(e.ID) })
+	snapshot := e.clone()
+	c.mu.Unlock()
+
+	c.notify(Event{Type: EventExperiment, Experiment: &snapshot})
+	return snapshot, nil
+}
+
+// Experiments returns every active experiment and the most recent finished
+// ones, in the order they were scheduled.
+func (c *Controller) Experiments() []Experiment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]Experiment, len(c.experiments))
+	for i, e := range c.experiments {
+		out[i] = e.clone()
+	}
+	return out
+}
+
+// Experiment returns the experiment with the given ID.
+func (c *Controller) Experiment(id string) (Experiment, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.findLocked(id)
+	if e == nil {
+		return Experiment{}, ErrExperimentNotFound
+	}
+	return e.clone(), nil
+}
+
+// Cancel ends an experiment early. A scheduled experiment never runs; a
+// running one is reverted now.
+func (c *Controller) Cancel(ctx context.Context, id string) (Experiment, error) {
+	c.opMu.Lock()
+	defer c.opMu.Unlock()
+
+	c.mu.Lock()
+	e := c.findLocked(id)
+	c.mu.Unlock()
+	if e == nil {
+		return Experiment{}, ErrExperimentNotFound
+	}
+	if err := c.cancelLocked(ctx, e); err != nil {
+		return Experiment{}, err
+	}
+	return c.Experiment(id)
+}
+
+// Shutdown cancels every active experiment, reverting the running ones, so
+// no container is left paused, killed or disconnected.
+func (c *Controller) Shutdown(ctx context.Context) error {
+	c.opMu.Lock()
+	defer c.opMu.Unlock()
+
+	c.mu.Lock()
+	active := make([]*Experiment, 0, len(c.timers))
+	for _, e := range c.experiments {
+		if !e.finished() {
+			active = append(active, e)
+		}
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	for _, e := range active {
+		if err := c.cancelLocked(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("cancel experiment %s: %w", e.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cancelLocked ends e as cancelled. Caller must hold c.opMu.
+func (c *Controller) cancelLocked(ctx context.Context, e *Experiment) error {
+	c.mu.Lock()
+	state := e.State
+	c.stopTimerLocked(e.ID)
+	c.mu.Unlock()
+
+	switch state {
+	case ExperimentScheduled:
+		now := c.now().UTC()
+		c.update(e, func(e *Experiment) {
+			e.State = ExperimentCancelled
+			e.EndedAt = &now
+		})
+		return nil
+	case ExperimentRunning:
+		c.revertLocked(ctx, e, ExperimentCancelled)
+		return nil
+	default:
+		return ErrExperimentFinished
+	}
+}
+
+// start runs a scheduled experiment's action and schedules its revert.
+func (c *Controller) start(id string) {
+	c.opMu.Lock()
+	defer c.opMu.Unlock()
+
+	c.mu.Lock()
+	e := c.findLocked(id)
+	if e == nil || e.State != ExperimentScheduled {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.timers, id)
+	req := e.Request
+	c.mu.Unlock()
+
+	t, err := c.resolve(req.NodeID)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		_, err = c.apply(ctx, req, t, id, false)
+		cancel()
+	}
+
+	now := c.now().UTC()
+	c.update(e, func(e *Experiment) {
+		if err != nil {
+			e.State = ExperimentFailed
+			e.Error = err.Error()
+			e.EndedAt = &now
+			return
+		}
+		e.State = ExperimentRunning
+		e.StartedAt = &now
+		e.ContainerID = t.containerID
+		e.name = t.name
+		c.timers[id] = c.afterFunc(e.duration(), func() { c.finish(id) })
+	})
+}
+
+// finish reverts a running experiment whose duration has passed.
+func (c *Controller) finish(id string) {
+	c.opMu.Lock()
+	defer c.opMu.Unlock()
+
+	c.mu.Lock()
+	e := c.findLocked(id)
+	if e == nil || e.State != ExperimentRunning {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.timers, id)
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+	c.revertLocked(ctx, e, ExperimentCompleted)
+}
+
+// revertLocked undoes a running experiment's action on the container it was
+// applied to and ends it in state, or as failed if the revert fails. Caller
+// must hold c.opMu.
+func (c *Controller) revertLocked(ctx context.Context, e *Experiment, state ExperimentState) {
+	c.mu.Lock()
+	req := Request{NodeID: e.NodeID, Action: reverts[e.Action]}
+	t := target{containerID: e.ContainerID, name: e.name}
+	c.mu.Unlock()
+
+	_, err := c.apply(ctx, req, t, e.ID, true)
+	now := c.now().UTC()
+	c.update(e, func(e *Experiment) {
+		e.EndedAt = &now
+		if err != nil {
+			e.State = ExperimentFailed
+			e.Error = "revert: " + err.Error()
+			return
+		}
+		e.State = state
+	})
+}
+
+// resolve returns the container of a node in the running deployment.
+func (c *Controller) resolve(nodeID string) (target, error) {
+	status := c.deployment.Status()
+	if status.State != deploy.StateDeployed {
+		return target{}, ErrNotDeployed
+	}
+	for _, n := range status.Nodes {
+		if n.NodeID == nodeID && n.ContainerID != "" {
+			return target{containerID: n.ContainerID, name: n.Name}, nil
+		}
+	}
+	return target{}, fmt.Errorf("%w: %q", ErrUnknownNode, nodeID)
+}
+
+// apply runs req against t's container and publishes the result.
+func (c *Controller) apply(ctx context.Context, req Request, t target, experimentID string, revert bool) (Result, error) {
+	var err error
+	switch req.Action {
+	case ActionKill:
+		err = c.orch.KillContainer(ctx, t.containerID, req.Signal)
+	case ActionPause:
+		err = c.orch.PauseContainer(ctx, t.containerID)
+	case ActionUnpause:
+		err = c.orch.UnpauseContainer(ctx, t.containerID)
+	case ActionRestart:
+		err = c.orch.RestartContainer(ctx, t.containerID)
+	case ActionDisconnect:
+		err = c.orch.DisconnectContainer(ctx, t.containerID)
+	case ActionReconnect:
+		err = c.orch.ConnectContainer(ctx, t.containerID)
+	default:
+		err = fmt.Errorf("%w: unknown action %q", ErrInvalid, req.Action)
+	}
+
+	res := Result{
+		Request:      req,
+		At:           c.now().UTC(),
+		ContainerID:  t.containerID,
+		Name:         t.name,
+		ExperimentID: experimentID,
+		Revert:       revert,
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	c.notify(Event{Type: EventAction, Action: &res})
+	return res, err
+}
+
+// update applies fn to e under lock, then notifies with a snapshot.
+func (c *Controller) update(e *Experiment, fn func(*Experiment)) {
+	c.mu.Lock()
+	fn(e)
+	snapshot := e.clone()
+	c.mu.Unlock()
+
+	c.notify(Event{Type: EventExperiment, Experiment: &snapshot})
+}
+
+// findLocked returns the experiment with the given ID, or nil. Caller must
+// hold c.mu.
+func (c *Controller) findLocked(id string) *Experiment {
+	for _, e := range c.experiments {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// stopTimerLocked stops the pending start or revert of an experiment.
+// Caller must hold c.mu.
+func (c *Controller) stopTimerLocked(id string) {
+	if t, ok := c.timers[id]; ok {
+		t.Stop()
+		delete(c.timers, id)
+	}
+}
+
+// pruneLocked drops the oldest finished experiments beyond
+// maxFinishedExperiments. Caller must hold c.mu.
+func (c *Controller) pruneLocked() {
+	finished := 0
+	for _, e := range c.experiments {
+		if e.finished() {
+			finished++
+		}
+	}
+	kept := c.experiments[:0]
+	for _, e := range c.experiments {
+		if e.finished() && finished > maxFinishedExperiments {
+			finished--
+			continue
+		}
+		kept = append(kept, e)
+	}
+	c.experiments = kept
+}
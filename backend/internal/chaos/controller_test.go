@@ -0,0 +1,355 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+)
+
+// fakeDeployment reports a fixed status.
+type fakeDeployment struct {
+	status deploy.Status
+}
+
+func (d *fakeDeployment) Status() deploy.Status { return d.status }
+
+// fakeTimer is a call scheduled on fakeTimers.
+type fakeTimer struct {
+	d       time.Duration
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	was := !t.stopped
+	t.stopped = true
+	return was
+}
+
+// fakeTimers records scheduled calls so tests run them explicitly.
+type fakeTimers struct {
+	mu      sync.Mutex
+	pending []*fakeTimer
+}
+
+func (ft *fakeTimers) afterFunc(d time.Duration, f func()) timer {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	t := &fakeTimer{d: d, f: f}
+	ft.pending = append(ft.pending, t)
+	return t
+}
+
+// fire runs the oldest pending call that was not stopped and returns its
+// delay.
+func (ft *fakeTimers) fire(t *testing.T) time.Duration {
+	t.Helper()
+	ft.mu.Lock()
+	var next *fakeTimer
+	for len(ft.pending) > 0 && next == nil {
+		if !ft.pending[0].stopped {
+			next = ft.pending[0]
+		}
+		ft.pending = ft.pending[1:]
+	}
+	ft.mu.Unlock()
+	if next == nil {
+		t.Fatal("no pending timer")
+	}
+	next.f()
+	return next.d
+}
+
+// newTestController returns a Controller for a deployment whose node "api"
+// runs in a simulator, the simulator with the container's ID, the events
+// published so far and the controller's timers.
+func newTestController(t *testing.T) (*Controller, *docker.SimulatedOrchestrator, string, *[]Event, *fakeTimers) {
+	t.Helper()
+	ctx := context.Background()
+	sim := docker.NewSimulatedOrchestrator(docker.SimulationConfig{})
+	_ = sim.CreateNetwork(ctx)
+	id, err := sim.CreateContainer(ctx, docker.ContainerConfig{Name: "api"})
+	if err != nil {
+		t.Fatalf("CreateContainer: %v", err)
+	}
+	if err := sim.StartContainer(ctx, id); err != nil {
+		t.Fatalf("StartContainer: %v", err)
+	}
+
+	dep := &fakeDeployment{status: deploy.Status{
+		State: deploy.StateDeployed,
+		Nodes: []deploy.NodeStatus{
+			{NodeID: "api", Name: "api", ContainerID: id},
+			{NodeID: "pending", Name: "pending", Status: deploy.StatusPending},
+		},
+	}}
+	var events []Event
+	c := NewController(sim, dep, func(e Event) { events = append(events, e) })
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	timers := &fakeTimers{}
+	c.afterFunc = timers.afterFunc
+	return c, sim, id, &events, timers
+}
+
+func containerStatus(t *testing.T, sim *docker.SimulatedOrchestrator, id string) docker.ContainerStatus {
+	t.Helper()
+	st, err := sim.HealthCheck(context.Background(), id)
+	if err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+	return st
+}
+
+func TestController_Do(t *testing.T) {
+	c, sim, id, events, _ := newTestController(t)
+	ctx := context.Background()
+
+	res, err := c.Do(ctx, Request{NodeID: "api", Action: ActionPause})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if res.ContainerID != id || res.Name != "api" || res.Error != "" {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if got := containerStatus(t, sim, id); got != docker.StatusPaused {
+		t.Errorf("expected paused, got %q", got)
+	}
+
+	_, err = c.Do(ctx, Request{NodeID: "api", Action: ActionPause})
+	if !errdefs.IsConflict(err) {
+		t.Errorf("expected a conflict pausing twice, got %v", err)
+	}
+
+	res, err = c.Do(ctx, Request{NodeID: "api", Action: ActionKill})
+	if err != nil {
+		t.Fatalf("Do kill: %v", err)
+	}
+	if res.Signal != DefaultSignal {
+		t.Errorf("expected the default signal, got %q", res.Signal)
+	}
+
+	if len(*events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", *events)
+	}
+	for _, e := range *events {
+		if e.Type != EventAction || e.Action == nil {
+			t.Errorf("expected an action event, got %+v", e)
+		}
+	}
+	if (*events)[1].Action.Error == "" {
+		t.Error("expected the failed action to be published with its error")
+	}
+}
+
+func TestController_DoRejectsBadTargets(t *testing.T) {
+	c, _, _, events, _ := newTestController(t)
+	ctx := context.Background()
+
+	if _, err := c.Do(ctx, Request{NodeID: "api", Action: "explode"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid, got %v", err)
+	}
+	if _, err := c.Do(ctx, Request{NodeID: "pending", Action: ActionPause}); !errors.Is(err, ErrUnknownNode) {
+		t.Errorf("expected ErrUnknownNode for a node without a container, got %v", err)
+	}
+	c.deployment = &fakeDeployment{status: deploy.Status{State: deploy.StateIdle}}
+	if _, err := c.Do(ctx, Request{NodeID: "api", Action: ActionPause}); !errors.Is(err, ErrNotDeployed) {
+		t.Errorf("expected ErrNotDeployed, got %v", err)
+	}
+	if len(*events) != 0 {
+		t.Errorf("expected no events for rejected requests, got %+v", *events)
+	}
+}
+
+func TestController_ExperimentRunsAndReverts(t *testing.T) {
+	c, sim, id, events, timers := newTestController(t)
+
+	start := c.now().Add(time.Minute)
+	e, err := c.Schedule(ExperimentSpec{
+		Request:         Request{NodeID: "api", Action: ActionDisconnect},
+		StartAt:         start,
+		DurationSeconds: 30,
+	})
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if e.State != ExperimentScheduled || !e.StartAt.Equal(start) {
+		t.Errorf("unexpected experiment %+v", e)
+	}
+
+	if d := timers.fire(t); d != time.Minute {
+		t.Errorf("expected the start after 1m, got %v", d)
+	}
+	if got, _ := c.Experiment(e.ID); got.State != ExperimentRunning || got.ContainerID != id {
+		t.Errorf("expected a running experiment on %s, got %+v", id, got)
+	}
+	if err := sim.DisconnectContainer(context.Background(), id); !errdefs.IsConflict(err) {
+		t.Errorf("expected the container to be disconnected, got %v", err)
+	}
+
+	if d := timers.fire(t); d != 30*time.Second {
+		t.Errorf("expected the revert after 30s, got %v", d)
+	}
+	got, _ := c.Experiment(e.ID)
+	if got.State != ExperimentCompleted || got.EndedAt == nil {
+		t.Errorf("expected a completed experiment, got %+v", got)
+	}
+	if err := sim.ConnectContainer(context.Background(), id); !errdefs.IsConflict(err) {
+		t.Errorf("expected the container to be reconnected, got %v", err)
+	}
+
+	var actions []Action
+	var states []ExperimentState
+	for _, ev := range *events {
+		switch ev.Type {
+		case EventAction:
+			actions = append(actions, ev.Action.Action)
+			if ev.Action.ExperimentID != e.ID {
+				t.Errorf("expected the action to name experiment %s, got %+v", e.ID, ev.Action)
+			}
+		case EventExperiment:
+			states = append(states, ev.Experiment.State)
+		}
+	}
+	wantActions := []Action{ActionDisconnect, ActionReconnect}
+	wantStates := []ExperimentState{ExperimentScheduled, ExperimentRunning, ExperimentCompleted}
+	if len(actions) != len(wantActions) || actions[0] != wantActions[0] || actions[1] != wantActions[1] {
+		t.Errorf("expected actions %v, got %v", wantActions, actions)
+	}
+	if len(states) != len(wantStates) {
+		t.Fatalf("expected states %v, got %v", wantStates, states)
+	}
+	for i := range wantStates {
+		if states[i] != wantStates[i] {
+			t.Errorf("state %d: expected %q, got %q", i, wantStates[i], states[i])
+		}
+	}
+}
+
+func TestController_CancelExperiments(t *testing.T) {
+	c, sim, id, _, timers := newTestController(t)
+	ctx := context.Background()
+
+	scheduled, _ := c.Schedule(ExperimentSpec{
+		Request:         Request{NodeID: "api", Action: ActionKill},
+		StartAt:         c.now().Add(time.Hour),
+		DurationSeconds: 10,
+	})
+	got, err := c.Cancel(ctx, scheduled.ID)
+	if err != nil {
+		t.Fatalf("Cancel scheduled: %v", err)
+	}
+	if got.State != ExperimentCancelled {
+		t.Errorf("expected cancelled, got %q", got.State)
+	}
+	if _, err := c.Cancel(ctx, scheduled.ID); !errors.Is(err, ErrExperimentFinished) {
+		t.Errorf("expected ErrExperimentFinished, got %v", err)
+	}
+
+	running, _ := c.Schedule(ExperimentSpec{Request: Request{NodeID: "api", Action: ActionPause}, DurationSeconds: 10})
+	timers.fire(t)
+	if st := containerStatus(t, sim, id); st != docker.StatusPaused {
+		t.Fatalf("expected paused, got %q", st)
+	}
+	got, err = c.Cancel(ctx, running.ID)
+	if err != nil {
+		t.Fatalf("Cancel running: %v", err)
+	}
+	if got.State != ExperimentCancelled {
+		t.Errorf("expected cancelled, got %q", got.State)
+	}
+	if st := containerStatus(t, sim, id); st != docker.StatusRunning {
+		t.Errorf("expected the pause to be reverted, got %q", st)
+	}
+
+	if _, err := c.Cancel(ctx, "missing"); !errors.Is(err, ErrExperimentNotFound) {
+		t.Errorf("expected ErrExperimentNotFound, got %v", err)
+	}
+	if n := len(c.Experiments()); n != 2 {
+		t.Errorf("expected 2 experiments, got %d", n)
+	}
+}
+
+func TestController_ExperimentFailures(t *testing.T) {
+	c, sim, id, _, timers := newTestController(t)
+
+	gone, _ := c.Schedule(ExperimentSpec{Request: Request{NodeID: "api", Action: ActionPause}, DurationSeconds: 10})
+	c.deployment = &fakeDeployment{status: deploy.Status{State: deploy.StateIdle}}
+	timers.fire(t)
+	if got, _ := c.Experiment(gone.ID); got.State != ExperimentFailed || got.Error != ErrNotDeployed.Error() {
+		t.Errorf("expected a failed experiment after teardown, got %+v", got)
+	}
+
+	c.deployment = &fakeDeployment{status: deploy.Status{
+		State: deploy.StateDeployed,
+		Nodes: []deploy.NodeStatus{{NodeID: "api", Name: "api", ContainerID: id}},
+	}}
+	removed, _ := c.Schedule(ExperimentSpec{Request: Request{NodeID: "api", Action: ActionPause}, DurationSeconds: 10})
+	timers.fire(t)
+	_ = sim.RemoveContainer(context.Background(), id)
+	timers.fire(t)
+	got, _ := c.Experiment(removed.ID)
+	if got.State != ExperimentFailed || got.Error == "" {
+		t.Errorf("expected a failed revert, got %+v", got)
+	}
+}
+
+func TestController_ShutdownRevertsRunningExperiments(t *testing.T) {
+	c, sim, id, _, timers := newTestController(t)
+
+	running, _ := c.Schedule(ExperimentSpec{Request: Request{NodeID: "api", Action: ActionKill, Signal: "SIGTERM"}, DurationSeconds: 60})
+	timers.fire(t)
+	scheduled, _ := c.Schedule(ExperimentSpec{
+		Request:         Request{NodeID: "api", Action: ActionPause},
+		StartAt:         c.now().Add(time.Minute),
+		DurationSeconds: 60,
+	})
+
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	for _, id := range []string{running.ID, scheduled.ID} {
+		if got, _ := c.Experiment(id); got.State != ExperimentCancelled {
+			t.Errorf("experiment %s: expected cancelled, got %q", id, got.State)
+		}
+	}
+	if st := containerStatus(t, sim, id); st != docker.StatusRunning {
+		t.Errorf("expected the killed container to be restarted, got %q", st)
+	}
+}
+
+func TestController_PrunesFinishedExperiments(t *testing.T) {
+	c, _, _, _, _ := newTestController(t)
+	ctx := context.Background()
+
+	var first string
+	for i := range maxFinishedExperiments + 2 {
+		e, err := c.Schedule(ExperimentSpec{
+			Request:         Request{NodeID: "api", Action: ActionPause},
+			StartAt:         c.now().Add(time.Hour),
+			DurationSeconds: 1,
+		})
+		if err != nil {
+			t.Fatalf("Schedule: %v", err)
+		}
+		if i == 0 {
+			first = e.ID
+		}
+		if _, err := c.Cancel(ctx, e.ID); err != nil {
+			t.Fatalf("Cancel: %v", err)
+		}
+	}
+	if n := len(c.Experiments()); n > maxFinishedExperiments+1 {
+		t.Errorf("expected at most %d experiments, got %d", maxFinishedExperiments+1, n)
+	}
+	if _, err := c.Experiment(first); !errors.Is(err, ErrExperimentNotFound) {
+		t.Errorf("expected the oldest experiment to be dropped, got %v", err)
+	}
+}
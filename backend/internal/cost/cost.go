@@ -0,0 +1,292 @@
+// Package cost estimates what running a diagram on a public cloud would
+// cost, from price tables bundled with the backend. Each node is mapped by
+// type onto a managed service or virtual machines, sized from its
+// resources, and priced per month.
+package cost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// HoursPerMonth is the length of a priced month.
+const HoursPerMonth = 730
+
+// Currency is the currency of every price.
+const Currency = "USD"
+
+// Provider names a cloud provider.
+type Provider string
+
+const (
+	ProviderAWS   Provider = "aws"
+	ProviderGCP   Provider = "gcp"
+	ProviderAzure Provider = "azure"
+)
+
+// ErrInvalid is returned for an unknown provider or region.
+var ErrInvalid = errors.New("invalid cost estimate")
+
+// nodeDefaults are what a node of a type is priced as, and sized for when
+// its resources leave a value unset.
+type nodeDefaults struct {
+	category  category
+	cpus      float64
+	memoryMB  int
+	storageGB int
+}
+
+// typeDefaults maps every service type onto its defaults.
+var typeDefaults = map[string]nodeDefaults{
+	model.ServiceTypeAPIService:      {categoryCompute, 0.5, 512, 0},
+	model.ServiceTypeNginx:           {categoryCompute, 0.25, 256, 0},
+	model.ServiceTypeCustomContainer: {categoryCompute, 0.5, 512, 0},
+	model.ServiceTypePostgreSQL:      {categoryDatabase, 1, 1024, 20},
+	model.ServiceTypeRedis:           {categoryCache, 0.5, 512, 0},
+	model.ServiceTypeRabbitMQ:        {categoryBroker, 1, 1024, 10},
+}
+
+// NodeCost is the monthly cost of one node.
+type NodeCost struct {
+	NodeID string `json:"nodeId"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	// Service is the cloud service the node runs on.
+	Service       string `json:"service"`
+	InstanceClass string `json:"instanceClass"`
+	// Instances is the node's replicas times the instances each needs.
+	Instances int `json:"instances"`
+	// VCPUs and MemoryGiB describe one instance. VCPUs is 0 for classes
+	// sized by memory alone.
+	VCPUs     float64 `json:"vcpus,omitempty"`
+	MemoryGiB float64 `json:"memoryGiB"`
+	// StorageGB is the persistent storage of all replicas.
+	StorageGB int `json:"storageGb,omitempty"`
+	// HourlyPrice is the price of one instance in the region.
+	HourlyPrice    float64 `json:"hourlyPrice"`
+	ComputeMonthly float64 `json:"computeMonthly"`
+	StorageMonthly float64 `json:"storageMonthly"`
+	Monthly        float64 `json:"monthly"`
+	// Assumptions explain the defaults and substitutions used for the node.
+	Assumptions []string `json:"assumptions,omitempty"`
+}
+
+// Estimate is the monthly cost of a diagram on one provider and region.
+type Estimate struct {
+	DiagramID     string     `json:"diagramId"`
+	Provider      Provider   `json:"provider"`
+	Region        string     `json:"region"`
+	Currency      string     `json:"currency"`
+	PricesAsOf    string     `json:"pricesAsOf"`
+	HoursPerMonth int        `json:"hoursPerMonth"`
+	Nodes         []NodeCost `json:"nodes"`
+	TotalMonthly  float64    `json:"totalMonthly"`
+	// Assumptions apply to the whole estimate.
+	Assumptions []string `json:"assumptions"`
+}
+
+// EstimateDiagram prices d on provider in region; an empty region means
+// the provider's base region. Amounts are rounded to cents, and the total
+// is the sum of the rounded node amounts.
+func EstimateDiagram(d model.Diagram, provider Provider, region string) (Estimate, error) {
+	table, ok := priceTables[provider]
+	if !ok {
+		return Estimate{}, fmt.Errorf("%w: unknown provider %q; use one of %s", ErrInvalid, provider, strings.Join(providers(), ", "))
+	}
+	if region == "" {
+		region = table.baseRegion
+	}
+	factor, ok := table.regions[region]
+	if !ok {
+		return Estimate{}, fmt.Errorf("%w: unknown %s region %q; use one of %s", ErrInvalid, provider, region, strings.Join(regions(table), ", "))
+	}
+
+	est := Estimate{
+		DiagramID:     d.ID,
+		Provider:      provider,
+		Region:        region,
+		Currency:      Currency,
+		PricesAsOf:    PricesAsOf,
+		HoursPerMonth: HoursPerMonth,
+		Nodes:         make([]NodeCost, 0, len(d.Nodes)),
+		Assumptions:   assumptions(table, region, factor),
+	}
+	for _, n := range d.Nodes {
+		nc := estimateNode(n, table, factor)
+		est.Nodes = append(est.Nodes, nc)
+		est.TotalMonthly += nc.Monthly
+	}
+	est.TotalMonthly = round(est.TotalMonthly, 2)
+	return est, nil
+}
+
+// estimateNode prices one node with the prices of table scaled by factor.
+func estimateNode(n model.DiagramNode, table priceTable, factor float64) NodeCost {
+	def, ok := typeDefaults[n.Type]
+	if !ok {
+		def = typeDefaults[model.ServiceTypeCustomContainer]
+	}
+	o := table.offerings[def.category]
+	nc := NodeCost{NodeID: n.ID, Name: n.Name, Type: n.Type, Service: o.service}
+	if o.note != "" {
+		nc.Assumptions = append(nc.Assumptions, o.note)
+	}
+
+	var r model.Resources
+	if n.Resources != nil {
+		r = *n.Resources
+	}
+	replicas := max(r.Replicas, 1)
+	cpus := r.CPUs
+	if cpus == 0 {
+		cpus = def.cpus
+		nc.Assumptions = append(nc.Assumptions, fmt.Sprintf("no cpus set; sized for %g vCPU, the %s default", cpus, n.Type))
+	}
+	memoryMB := r.MemoryMB
+	if memoryMB == 0 {
+		if mb, raw, ok := redisMaxMemory(n); ok {
+			memoryMB = mb
+			nc.Assumptions = append(nc.Assumptions, fmt.Sprintf("no memoryMb set; sized for the configured maxmemory of %s", raw))
+		} else {
+			memoryMB = def.memoryMB
+			nc.Assumptions = append(nc.Assumptions, fmt.Sprintf("no memoryMb set; sized for %d MiB, the %s default", memoryMB, n.Type))
+		}
+	}
+	storageGB := r.StorageGB
+	if storageGB == 0 && def.storageGB > 0 {
+		storageGB = def.storageGB
+		nc.Assumptions = append(nc.Assumptions, fmt.Sprintf("no storageGb set; priced %d GiB, the %s default", storageGB, n.Type))
+	}
+	if storageGB > 0 && o.storageGBMonth == 0 {
+		nc.Assumptions = append(nc.Assumptions, fmt.Sprintf("storageGb not priced; %s has no separate storage", o.service))
+		storageGB = 0
+	}
+
+	class, count := fit(o.classes, cpus, float64(memoryMB)/1024)
+	if count > 1 {
+		nc.Assumptions = append(nc.Assumptions, fmt.Sprintf("no single class has %g vCPU and %d MiB; priced %d × %s per replica", cpus, memoryMB, count, class.name))
+	}
+
+	nc.InstanceClass = class.name
+	nc.Instances = replicas * count
+	nc.VCPUs = class.vcpus
+	nc.MemoryGiB = class.memoryGiB
+	nc.StorageGB = storageGB * replicas
+	nc.HourlyPrice = round(class.hourly*factor, 4)
+	nc.ComputeMonthly = round(class.hourly*factor*HoursPerMonth*float64(nc.Instances), 2)
+	nc.StorageMonthly = round(o.storageGBMonth*factor*float64(nc.StorageGB), 2)
+	nc.Monthly = round(nc.ComputeMonthly+nc.StorageMonthly, 2)
+	return nc
+}
+
+// fit returns the cheapest class with at least cpus and memGiB, or, when
+// none is large enough, the largest class and how many of it are needed.
+func fit(classes []instanceClass, cpus, memGiB float64) (instanceClass, int) {
+	best := -1
+	for i, c := range classes {
+		if (c.vcpus == 0 || c.vcpus >= cpus) && c.memoryGiB >= memGiB && (best < 0 || c.hourly < classes[best].hourly) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		return classes[best], 1
+	}
+	largest := classes[len(classes)-1]
+	n := math.Ceil(memGiB / largest.memoryGiB)
+	if largest.vcpus > 0 {
+		n = max(n, math.Ceil(cpus/largest.vcpus))
+	}
+	return largest, int(n)
+}
+
+// redisMaxMemory returns the maxmemory of a redis node in MiB, along with
+// the configured value, if one is set.
+func redisMaxMemory(n model.DiagramNode) (int, string, bool) {
+	if n.Type != model.ServiceTypeRedis || len(n.Config) == 0 {
+		return 0, "", false
+	}
+	var cfg model.RedisConfig
+	if err := json.Unmarshal(n.Config, &cfg); err != nil {
+		return 0, "", false
+	}
+	bytes, ok := parseRedisMemory(cfg.MaxMemory)
+	if !ok || bytes == 0 {
+		return 0, "", false
+	}
+	return int(math.Ceil(float64(bytes) / (1 << 20))), cfg.MaxMemory, true
+}
+
+// redisUnits are the memory units Redis accepts, longest suffix first.
+var redisUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10},
+	{"g", 1e9}, {"m", 1e6}, {"k", 1e3}, {"b", 1},
+}
+
+// parseRedisMemory parses a Redis memory size such as 256mb into bytes.
+func parseRedisMemory(v string) (int64, bool) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	unit := int64(1)
+	for _, u := range redisUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v, unit = strings.TrimSuffix(v, u.suffix), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n * unit, true
+}
+
+// assumptions returns the assumptions of every estimate on table.
+func assumptions(table priceTable, region string, factor float64) []string {
+	a := []string{
+		fmt.Sprintf("Approximate on-demand %s list prices in %s as of %s, bundled offline; actual prices change and vary by account and commitment.", table.name, Currency, PricesAsOf),
+		fmt.Sprintf("A month is %d hours of continuous running.", HoursPerMonth),
+	}
+	if factor != 1 {
+		a = append(a, fmt.Sprintf("%s prices are %s prices scaled by %g.", region, table.baseRegion, factor))
+	}
+	return append(a,
+		"Each node runs on the cheapest instance class with at least its cpus and memoryMb; unset values use per-type defaults, listed on the node.",
+		"Each replica is a separate instance with its own storage; managed services run in a single zone without standbys.",
+		"Data transfer, load balancers, IP addresses, backups, support plans and monitoring services are not included.",
+	)
+}
+
+// providers returns the supported providers, sorted.
+func providers() []string {
+	names := make([]string, 0, len(priceTables))
+	for p := range priceTables {
+		names = append(names, string(p))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// regions returns the regions of table, sorted.
+func regions(table priceTable) []string {
+	names := make([]string, 0, len(table.regions))
+	for r := range table.regions {
+		names = append(names, r)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// round rounds v to places decimal places.
+func round(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,162 @@
+package cost
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+func TestPriceTables(t *testing.T) {
+	for p, table := range priceTables {
+		if table.regions[table.baseRegion] != 1 {
+			t.Errorf("%s: base region %q must have factor 1", p, table.baseRegion)
+		}
+		for _, c := range []category{categoryCompute, categoryDatabase, categoryCache, categoryBroker} {
+			o, ok := table.offerings[c]
+			if !ok || len(o.classes) == 0 {
+				t.Errorf("%s: no %s offering", p, c)
+				continue
+			}
+			for i := 1; i < len(o.classes); i++ {
+				prev, cur := o.classes[i-1], o.classes[i]
+				if cur.memoryGiB < prev.memoryGiB || cur.vcpus < prev.vcpus {
+					t.Errorf("%s %s: %s is smaller than %s", p, c, cur.name, prev.name)
+				}
+			}
+		}
+	}
+}
+
+func nodeByID(t *testing.T, est Estimate, id string) NodeCost {
+	t.Helper()
+	for _, n := range est.Nodes {
+		if n.NodeID == id {
+			return n
+		}
+	}
+	t.Fatalf("no cost for node %q in %+v", id, est.Nodes)
+	return NodeCost{}
+}
+
+func costDiagram() model.Diagram {
+	return model.Diagram{
+		ID: "d1",
+		Nodes: []model.DiagramNode{
+			{ID: "api", Type: model.ServiceTypeAPIService, Name: "API"},
+			{ID: "db", Type: model.ServiceTypePostgreSQL, Name: "DB"},
+			{ID: "cache", Type: model.ServiceTypeRedis, Name: "Cache", Config: json.RawMessage(`{"type":"redis","maxMemory":"2gb"}`)},
+			{ID: "mq", Type: model.ServiceTypeRabbitMQ, Name: "MQ"},
+		},
+	}
+}
+
+func TestEstimateDiagram_Defaults(t *testing.T) {
+	est, err := EstimateDiagram(costDiagram(), ProviderAWS, "")
+	if err != nil {
+		t.Fatalf("EstimateDiagram: %v", err)
+	}
+	if est.Region != "us-east-1" || est.Currency != "USD" || est.HoursPerMonth != 730 || len(est.Assumptions) == 0 {
+		t.Errorf("unexpected estimate %+v", est)
+	}
+
+	api := nodeByID(t, est, "api")
+	if api.InstanceClass != "t3.nano" || api.Instances != 1 || api.ComputeMonthly != 3.8 || api.StorageMonthly != 0 {
+		t.Errorf("unexpected api cost %+v", api)
+	}
+	db := nodeByID(t, est, "db")
+	// db.t3.micro: 0.018 × 730 = 13.14, plus 20 GiB × 0.115 = 2.30.
+	if db.InstanceClass != "db.t3.micro" || db.StorageGB != 20 || db.ComputeMonthly != 13.14 || db.StorageMonthly != 2.3 || db.Monthly != 15.44 {
+		t.Errorf("unexpected db cost %+v", db)
+	}
+	cache := nodeByID(t, est, "cache")
+	if cache.InstanceClass != "cache.t3.medium" || !strings.Contains(strings.Join(cache.Assumptions, ";"), "maxmemory of 2gb") {
+		t.Errorf("expected the cache sized by its maxmemory, got %+v", cache)
+	}
+
+	var total float64
+	for _, n := range est.Nodes {
+		total += n.Monthly
+	}
+	if est.TotalMonthly != round(total, 2) {
+		t.Errorf("total %v is not the sum of nodes %v", est.TotalMonthly, total)
+	}
+}
+
+func TestEstimateDiagram_Resources(t *testing.T) {
+	d := costDiagram()
+	d.Nodes[0].Resources = &model.Resources{Replicas: 3, CPUs: 4, MemoryMB: 8192}
+	d.Nodes[1].Resources = &model.Resources{CPUs: 2, MemoryMB: 4096, StorageGB: 100}
+	d.Nodes[2].Resources = &model.Resources{StorageGB: 10}
+	d.Nodes[3].Resources = &model.Resources{CPUs: 64, MemoryMB: 1024}
+
+	est, err := EstimateDiagram(d, ProviderAWS, "eu-west-1")
+	if err != nil {
+		t.Fatalf("EstimateDiagram: %v", err)
+	}
+	api := nodeByID(t, est, "api")
+	if api.InstanceClass != "t3.xlarge" || api.Instances != 3 || len(api.Assumptions) != 0 {
+		t.Errorf("unexpected api cost %+v", api)
+	}
+	// t3.xlarge: 0.1664 × 1.08 × 730 × 3.
+	if api.HourlyPrice != 0.1797 || api.ComputeMonthly != 393.57 {
+		t.Errorf("expected regional prices, got %+v", api)
+	}
+	db := nodeByID(t, est, "db")
+	if db.InstanceClass != "db.t3.medium" || db.StorageGB != 100 || db.StorageMonthly != 12.42 {
+		t.Errorf("unexpected db cost %+v", db)
+	}
+	if cache := nodeByID(t, est, "cache"); cache.StorageGB != 0 || cache.StorageMonthly != 0 {
+		t.Errorf("expected cache storage to be left unpriced, got %+v", cache)
+	}
+	mq := nodeByID(t, est, "mq")
+	if mq.InstanceClass != "mq.m5.4xlarge" || mq.Instances != 4 {
+		t.Errorf("expected 4 of the largest broker class, got %+v", mq)
+	}
+	if !strings.Contains(strings.Join(est.Assumptions, ";"), "scaled by 1.08") {
+		t.Errorf("expected the region factor in the assumptions, got %v", est.Assumptions)
+	}
+}
+
+func TestEstimateDiagram_Providers(t *testing.T) {
+	for _, p := range []Provider{ProviderGCP, ProviderAzure} {
+		est, err := EstimateDiagram(costDiagram(), p, "")
+		if err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		mq := nodeByID(t, est, "mq")
+		if !strings.Contains(mq.Service, "self-managed") || len(mq.Assumptions) == 0 || !strings.HasPrefix(mq.Assumptions[0], "no managed RabbitMQ") {
+			t.Errorf("%s: expected a self-managed broker, got %+v", p, mq)
+		}
+		if cache := nodeByID(t, est, "cache"); cache.VCPUs != 0 || cache.MemoryGiB < 2 {
+			t.Errorf("%s: expected a memory-sized cache of at least 2 GiB, got %+v", p, cache)
+		}
+		if est.TotalMonthly <= 0 {
+			t.Errorf("%s: expected a positive total, got %v", p, est.TotalMonthly)
+		}
+	}
+}
+
+func TestEstimateDiagram_Invalid(t *testing.T) {
+	if _, err := EstimateDiagram(costDiagram(), "oracle", ""); !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "aws, azure, gcp") {
+		t.Errorf("unknown provider: got %v", err)
+	}
+	if _, err := EstimateDiagram(costDiagram(), ProviderGCP, "us-east-1"); !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "us-central1") {
+		t.Errorf("unknown region: got %v", err)
+	}
+}
+
+func TestParseRedisMemory(t *testing.T) {
+	for in, want := range map[string]int64{"256mb": 256 << 20, "1GB": 1 << 30, "100m": 1e8, "512": 512, "4kb": 4096} {
+		if got, ok := parseRedisMemory(in); !ok || got != want {
+			t.Errorf("parseRedisMemory(%q): got %d, %v; want %d", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "lots", "-1mb", "1tb"} {
+		if _, ok := parseRedisMemory(in); ok {
+			t.Errorf("parseRedisMemory(%q): expected failure", in)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package cost
+
+// PricesAsOf is when the bundled price tables were taken.
+const PricesAsOf = "2026-01"
+
+// category is the kind of managed offering a node is priced as.
+type category string
+
+const (
+	categoryCompute  category = "compute"
+	categoryDatabase category = "database"
+	categoryCache    category = "cache"
+	categoryBroker   category = "broker"
+)
+
+// instanceClass is a priced instance size. Classes sized by memory alone,
+// such as most managed caches, have no vCPU count.
+type instanceClass struct {
+	name      string
+	vcpus     float64
+	memoryGiB float64
+	// hourly is the on-demand price in USD in the provider's base region.
+	hourly float64
+}
+
+// offering is the service a category of node runs on.
+type offering struct {
+	service string
+	// classes are ordered by size, smallest first.
+	classes []instanceClass
+	// storageGBMonth is the price of a GiB of persistent storage per month
+	// in the base region; 0 when the offering has no separate storage.
+	storageGBMonth float64
+	// note is an assumption every node priced on the offering carries.
+	note string
+}
+
+// priceTable holds the prices of one provider.
+type priceTable struct {
+	name string
+	// baseRegion is the region the prices were taken in.
+	baseRegion string
+	// regions maps every supported region to its price relative to
+	// baseRegion.
+	regions   map[string]float64
+	offerings map[category]offering
+}
+
+// Compute classes shared by self-managed services.
+var (
+	awsEC2 = offering{
+		service: "Amazon EC2",
+		classes: []instanceClass{
+			{"t3.nano", 2, 0.5, 0.0052},
+			{"t3.micro", 2, 1, 0.0104},
+			{"t3.small", 2, 2, 0.0208},
+			{"t3.medium", 2, 4, 0.0416},
+			{"t3.large", 2, 8, 0.0832},
+			{"t3.xlarge", 4, 16, 0.1664},
+			{"t3.2xlarge", 8, 32, 0.3328},
+			{"m5.4xlarge", 16, 64, 0.768},
+			{"m5.8xlarge", 32, 128, 1.536},
+		},
+		storageGBMonth: 0.08,
+	}
+	gcpCompute = offering{
+		service: "Compute Engine",
+		classes: []instanceClass{
+			{"e2-micro", 2, 1, 0.0084},
+			{"e2-small", 2, 2, 0.0168},
+			{"e2-medium", 2, 4, 0.0335},
+			{"e2-standard-2", 2, 8, 0.067},
+			{"e2-standard-4", 4, 16, 0.134},
+			{"e2-standard-8", 8, 32, 0.268},
+			{"e2-standard-16", 16, 64, 0.536},
+			{"e2-standard-32", 32, 128, 1.072},
+		},
+		storageGBMonth: 0.10,
+	}
+	azureVMs = offering{
+		service: "Azure Virtual Machines",
+		classes: []instanceClass{
+			{"B1ls", 1, 0.5, 0.0052},
+			{"B1s", 1, 1, 0.0104},
+			{"B1ms", 1, 2, 0.0207},
+			{"B2s", 2, 4, 0.0416},
+			{"B2ms", 2, 8, 0.0832},
+			{"B4ms", 4, 16, 0.166},
+			{"B8ms", 8, 32, 0.333},
+			{"D16s_v5", 16, 64, 0.768},
+			{"D32s_v5", 32, 128, 1.536},
+		},
+		storageGBMonth: 0.075,
+	}
+)
+
+// selfManaged returns compute offered for a service the provider has no
+// managed offering of.
+func selfManaged(o offering, what string) offering {
+	o.service += " (self-managed " + what + ")"
+	o.note = "no managed " + what + " offering; priced as a virtual machine running it"
+	return o
+}
+
+// priceTables are approximate on-demand list prices for Linux instances
+// and single-zone managed services.
+var priceTables = map[Provider]priceTable{
+	ProviderAWS: {
+		name:       "Amazon Web Services",
+		baseRegion: "us-east-1",
+		regions: map[string]float64{
+			"us-east-1":      1,
+			"us-east-2":      1,
+			"us-west-2":      1,
+			"us-west-1":      1.12,
+			"ca-central-1":   1.08,
+			"eu-west-1":      1.08,
+			"eu-west-2":      1.12,
+			"eu-central-1":   1.15,
+			"ap-south-1":     1.05,
+			"ap-southeast-1": 1.2,
+			"ap-northeast-1": 1.22,
+			"ap-southeast-2": 1.2,
+			"sa-east-1":      1.5,
+		},
+		offerings: map[category]offering{
+			categoryCompute: awsEC2,
+			categoryDatabase: {
+				service: "Amazon RDS for PostgreSQL",
+				classes: []instanceClass{
+					{"db.t3.micro", 2, 1, 0.018},
+					{"db.t3.small", 2, 2, 0.036},
+					{"db.t3.medium", 2, 4, 0.072},
+					{"db.t3.large", 2, 8, 0.145},
+					{"db.m5.xlarge", 4, 16, 0.356},
+					{"db.m5.2xlarge", 8, 32, 0.712},
+					{"db.m5.4xlarge", 16, 64, 1.424},
+					{"db.m5.8xlarge", 32, 128, 2.848},
+				},
+				storageGBMonth: 0.115,
+			},
+			categoryCache: {
+				service: "Amazon ElastiCache for Redis",
+				classes: []instanceClass{
+					{"cache.t3.micro", 2, 0.5, 0.017},
+					{"cache.t3.small", 2, 1.37, 0.034},
+					{"cache.t3.medium", 2, 3.09, 0.068},
+					{"cache.m5.large", 2, 6.38, 0.156},
+					{"cache.m5.xlarge", 4, 12.93, 0.311},
+					{"cache.m5.2xlarge", 8, 26.04, 0.622},
+					{"cache.m5.4xlarge", 16, 52.26, 1.244},
+				},
+			},
+			categoryBroker: {
+				service: "Amazon MQ for RabbitMQ",
+				classes: []instanceClass{
+					{"mq.t3.micro", 2, 1, 0.027},
+					{"mq.m5.large", 2, 8, 0.288},
+					{"mq.m5.xlarge", 4, 16, 0.576},
+					{"mq.m5.2xlarge", 8, 32, 1.152},
+					{"mq.m5.4xlarge", 16, 64, 2.304},
+				},
+				storageGBMonth: 0.10,
+			},
+		},
+	},
+	ProviderGCP: {
+		name:       "Google Cloud",
+		baseRegion: "us-central1",
+		regions: map[string]float64{
+			"us-central1":             1,
+			"us-east1":                1,
+			"us-west1":                1,
+			"us-east4":                1.13,
+			"northamerica-northeast1": 1.1,
+			"europe-west1":            1.1,
+			"europe-west2":            1.17,
+			"europe-west3":            1.2,
+			"asia-south1":             1.2,
+			"asia-east1":              1.16,
+			"asia-northeast1":         1.28,
+			"australia-southeast1":    1.42,
+			"southamerica-east1":      1.59,
+		},
+		offerings: map[category]offering{
+			categoryCompute: gcpCompute,
+			categoryDatabase: {
+				service: "Cloud SQL for PostgreSQL",
+				classes: []instanceClass{
+					{"db-f1-micro", 1, 0.6, 0.0105},
+					{"db-g1-small", 1, 1.7, 0.035},
+					{"db-custom-1-3840", 1, 3.75, 0.0676},
+					{"db-custom-2-7680", 2, 7.5, 0.1352},
+					{"db-custom-4-15360", 4, 15, 0.2704},
+					{"db-custom-8-30720", 8, 30, 0.5408},
+					{"db-custom-16-61440", 16, 60, 1.0816},
+					{"db-custom-32-122880", 32, 120, 2.1632},
+				},
+				storageGBMonth: 0.17,
+			},
+			categoryCache: {
+				service: "Memorystore for Redis (Basic)",
+				classes: []instanceClass{
+					{"basic-1gb", 0, 1, 0.049},
+					{"basic-2gb", 0, 2, 0.098},
+					{"basic-4gb", 0, 4, 0.196},
+					{"basic-5gb", 0, 5, 0.135},
+					{"basic-10gb", 0, 10, 0.27},
+					{"basic-16gb", 0, 16, 0.368},
+					{"basic-35gb", 0, 35, 0.805},
+					{"basic-100gb", 0, 100, 1.6},
+				},
+			},
+			categoryBroker: selfManaged(gcpCompute, "RabbitMQ"),
+		},
+	},
+	ProviderAzure: {
+		name:       "Microsoft Azure",
+		baseRegion: "eastus",
+		regions: map[string]float64{
+			"eastus":        1,
+			"eastus2":       1,
+			"westus2":       1,
+			"centralus":     1.05,
+			"canadacentral": 1.08,
+			"northeurope":   1.05,
+			"westeurope":    1.12,
+			"uksouth":       1.1,
+			"centralindia":  1.05,
+			"southeastasia": 1.15,
+			"japaneast":     1.25,
+			"australiaeast": 1.3,
+			"brazilsouth":   1.55,
+		},
+		offerings: map[category]offering{
+			categoryCompute: azureVMs,
+			categoryDatabase: {
+				service: "Azure Database for PostgreSQL flexible server",
+				classes: []instanceClass{
+					{"B1ms", 1, 2, 0.026},
+					{"B2s", 2, 4, 0.052},
+					{"D2ds_v4", 2, 8, 0.178},
+					{"D4ds_v4", 4, 16, 0.356},
+					{"D8ds_v4", 8, 32, 0.712},
+					{"D16ds_v4", 16, 64, 1.424},
+					{"D32ds_v4", 32, 128, 2.848},
+				},
+				storageGBMonth: 0.115,
+			},
+			categoryCache: {
+				service: "Azure Cache for Redis (Basic)",
+				classes: []instanceClass{
+					{"C0", 0, 0.25, 0.022},
+					{"C1", 0, 1, 0.055},
+					{"C2", 0, 2.5, 0.09},
+					{"C3", 0, 6, 0.18},
+					{"C4", 0, 13, 0.4},
+					{"C5", 0, 26, 0.8},
+					{"C6", 0, 53, 1.6},
+				},
+			},
+			categoryBroker: selfManaged(azureVMs, "RabbitMQ"),
+		},
+	},
+}
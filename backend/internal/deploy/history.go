@@ -0,0 +1,132 @@
+package deploy
+
+import (
+	"log"
+	"maps"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// History failures are logged rather than returned: a deployment must not
+// fail, or be left half-rolled-back, because its record could not be saved.
+
+// recordStart creates the history record of a deploy that is starting and
+// returns its ID, or "" when there is no store or the record could not be
+// created. Caller must hold opMu.
+func (m *Manager) recordStart(d model.Diagram, nodes []templates.TranslatedNode, startedAt time.Time) string {
+	m.record = nil
+	if m.history == nil {
+		return ""
+	}
+
+	containers := make([]model.DeployedContainer, len(nodes))
+	for i, n := range nodes {
+		containers[i] = model.DeployedContainer{
+			NodeID: n.Node.ID,
+			Ports:  maps.Clone(n.Config.Ports),
+			Config: n.Config,
+		}
+	}
+	record, err := m.history.Create(&model.Deployment{
+		DiagramID:       d.ID,
+		DiagramName:     d.Name,
+		DiagramRevision: d.Revision,
+		Mode:            m.mode,
+		Status:          model.DeploymentDeploying,
+		StartedAt:       startedAt,
+		Containers:      containers,
+	})
+	if err != nil {
+		log.Printf("failed to record deployment of diagram %q: %v", d.ID, err)
+		return ""
+	}
+	m.record = record
+	return record.ID
+}
+
+// recordContainer notes the container created for the node at index.
+// Caller must hold opMu.
+func (m *Manager) recordContainer(index int, containerID string) {
+	if m.record != nil {
+		m.record.Containers[index].ContainerID = containerID
+	}
+}
+
+// recordDeployed marks the current record deployed. Caller must hold opMu.
+func (m *Manager) recordDeployed() {
+	if m.record == nil {
+		return
+	}
+	now := time.Now().UTC()
+	m.record.Status = model.DeploymentDeployed
+	m.record.DeployedAt = &now
+	m.saveRecord()
+}
+
+// recordReconfigured notes that the current record now runs d, which
+// differs from the deployed diagram only in generated files. Caller must
+// hold opMu.
+func (m *Manager) recordReconfigured(d model.Diagram) {
+	if m.record == nil {
+		return
+	}
+	m.record.DiagramName = d.Name
+	m.record.DiagramRevision = d.Revision
+	m.saveRecord()
+}
+
+// recordFailed marks the current record failed with err, attributing it to
+// the node at index when index is non-negative. Caller must hold opMu.
+func (m *Manager) recordFailed(index int, err error) {
+	if m.record == nil {
+		return
+	}
+	now := time.Now().UTC()
+	m.record.Status = model.DeploymentFailed
+	m.record.Error = err.Error()
+	m.record.EndedAt = &now
+	if index >= 0 {
+		m.record.Containers[index].Error = err.Error()
+	}
+	m.saveRecord()
+}
+
+// recordTornDown ends the current record, if it is still live, and forgets
+// it. Caller must hold opMu.
+func (m *Manager) recordTornDown() {
+	if m.record == nil {
+		return
+	}
+	if m.record.EndedAt == nil {
+		now := time.Now().UTC()
+		m.record.Status = model.DeploymentTornDown
+		m.record.EndedAt = &now
+		m.saveRecord()
+	}
+	m.record = nil
+}
+
+// recordRestored makes record, a deployment that was torn down and then
+// recreated with the given containers, the current record again. Caller
+// must hold opMu.
+func (m *Manager) recordRestored(record *model.Deployment, containerIDs map[string]string) {
+	m.record = record
+	if record == nil {
+		return
+	}
+	record.Status = model.DeploymentDeployed
+	record.EndedAt = nil
+	for i, c := range record.Containers {
+		record.Containers[i].ContainerID = containerIDs[c.NodeID]
+	}
+	m.saveRecord()
+}
+
+// saveRecord writes the current record to the store.
+func (m *Manager) saveRecord() {
+	if _, err := m.history.Update(m.record.ID, m.record); err != nil {
+		log.Printf("failed to update deployment record %q: %v", m.record.ID, err)
+	}
+}
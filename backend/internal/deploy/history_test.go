@@ -0,0 +1,126 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+func newHistoryManager(t *testing.T, cfg docker.SimulationConfig) (*Manager, *storage.FileDeploymentStore) {
+	t.Helper()
+	store, err := storage.NewFileDeploymentStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileDeploymentStore: %v", err)
+	}
+	return NewManager(docker.NewSimulatedOrchestrator(cfg), store, docker.ModeSimulated, nil), store
+}
+
+func TestHistory_RecordsLifecycle(t *testing.T) {
+	m, store := newHistoryManager(t, docker.SimulationConfig{})
+	ctx := context.Background()
+
+	d := testDiagram()
+	d.Revision = 4
+	status, err := m.Deploy(ctx, d)
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	if status.DeploymentID == "" {
+		t.Fatal("expected status to carry the deployment ID")
+	}
+
+	record, err := store.Get(status.DeploymentID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if record.Status != model.DeploymentDeployed || record.DeployedAt == nil || record.EndedAt != nil {
+		t.Errorf("unexpected deployed record %+v", record)
+	}
+	if record.DiagramID != "d1" || record.DiagramRevision != 4 || record.Mode != docker.ModeSimulated {
+		t.Errorf("unexpected diagram fields %+v", record)
+	}
+	if len(record.Containers) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(record.Containers))
+	}
+	for i, c := range record.Containers {
+		if c.NodeID != status.Nodes[i].NodeID || c.ContainerID != status.Nodes[i].ContainerID || len(c.Ports) == 0 || c.Config.Image == "" {
+			t.Errorf("unexpected container %+v", c)
+		}
+	}
+
+	if _, err := m.Teardown(ctx); err != nil {
+		t.Fatalf("Teardown: %v", err)
+	}
+	record, _ = store.Get(status.DeploymentID)
+	if record.Status != model.DeploymentTornDown || record.EndedAt == nil {
+		t.Errorf("expected torn-down record, got %+v", record)
+	}
+}
+
+func TestHistory_RedeployEndsPreviousRecord(t *testing.T) {
+	m, store := newHistoryManager(t, docker.SimulationConfig{})
+	ctx := context.Background()
+
+	first, err := m.Deploy(ctx, testDiagram())
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	second, err := m.Deploy(ctx, testDiagram())
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(list))
+	}
+	byID := map[string]model.Deployment{}
+	for _, r := range list {
+		byID[r.ID] = r
+	}
+	if byID[first.DeploymentID].Status != model.DeploymentTornDown {
+		t.Errorf("expected first deployment torn down, got %q", byID[first.DeploymentID].Status)
+	}
+	if byID[second.DeploymentID].Status != model.DeploymentDeployed {
+		t.Errorf("expected second deployment deployed, got %q", byID[second.DeploymentID].Status)
+	}
+}
+
+func TestHistory_RecordsFailure(t *testing.T) {
+	m, store := newHistoryManager(t, docker.SimulationConfig{
+		Failures: map[string]docker.SimFailure{"api": docker.SimFailStart},
+	})
+	ctx := context.Background()
+
+	status, err := m.Deploy(ctx, testDiagram())
+	if err == nil {
+		t.Fatal("expected deploy to fail")
+	}
+
+	record, err := store.Get(status.DeploymentID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if record.Status != model.DeploymentFailed || record.Error == "" || record.EndedAt == nil || record.DeployedAt != nil {
+		t.Errorf("unexpected failed record %+v", record)
+	}
+	api := record.Containers[1]
+	if api.ContainerID == "" || api.Error == "" {
+		t.Errorf("expected api container to keep its ID and error, got %+v", api)
+	}
+
+	// Tearing down after a failure must not overwrite the failed record.
+	if _, err := m.Teardown(ctx); err != nil {
+		t.Fatalf("Teardown: %v", err)
+	}
+	record, _ = store.Get(status.DeploymentID)
+	if record.Status != model.DeploymentFailed {
+		t.Errorf("expected record to stay failed, got %q", record.Status)
+	}
+}
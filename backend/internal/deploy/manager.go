@@ -0,0 +1,356 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+// ErrTranslation is wrapped by Deploy errors caused by a diagram that cannot
+// be translated into containers (unsupported types, cycles, bad config).
+var ErrTranslation = errors.New("translate diagram")
+
+// State is the lifecycle state of the current deployment.
+type State string
+
+const (
+	StateIdle      State = "idle"
+	StateDeploying State = "deploying"
+	StateDeployed  State = "deployed"
+	StateFailed    State = "failed"
+)
+
+// Node statuses reported by the manager in addition to container statuses.
+const (
+	// StatusPending is reported for nodes whose container has not been created yet.
+	StatusPending docker.ContainerStatus = "pending"
+	// StatusRolledBack is reported for nodes whose container was removed by
+	// the rollback of a failed deploy.
+	StatusRolledBack docker.ContainerStatus = "rolled-back"
+)
+
+// NodeStatus is the deployed state of a single diagram node.
+type NodeStatus struct {
+	NodeID      string                 `json:"nodeId"`
+	Name        string                 `json:"name"`
+	ContainerID string                 `json:"containerId,omitempty"`
+	Ports       map[string]string      `json:"ports,omitempty"` // host port → container port
+	Status      docker.ContainerStatus `json:"status"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+// Status is a snapshot of the current deployment.
+type Status struct {
+	// Mode is docker.ModeDocker or docker.ModeSimulated.
+	Mode      string `json:"mode"`
+	State     State  `json:"state"`
+	DiagramID string `json:"diagramId,omitempty"`
+	// DeploymentID identifies the history record of this deployment.
+	DeploymentID string     `json:"deploymentId,omitempty"`
+	StartedAt    *time.Time `json:"startedAt,omitempty"`
+	// Error and Rollback describe the last failed deploy. When it restored
+	// the deployment it tried to replace, State is deployed again and the
+	// rest of the status describes the restored deployment.
+	Error    string          `json:"error,omitempty"`
+	Nodes    []NodeStatus    `json:"nodes"`
+	Rollback *RollbackReport `json:"rollback,omitempty"`
+}
+
+// Notifier receives a status snapshot whenever the deployment changes.
+type Notifier func(Status)
+
+// Manager deploys diagrams onto an orchestrator and tracks the single active
+// deployment. Deploy and Teardown are serialised; Status may be called at
+// any time.
+type Manager struct {
+	orch    docker.Orchestrator
+	history storage.DeploymentStore
+	mode    string
+	notify  Notifier
+	// mockImage is the image api-service nodes run; empty means
+	// templates.ImageAPIService. Set once, before the first operation.
+	mockImage string
+
+	opMu sync.Mutex // serialises Deploy, Teardown and Sweep
+	// record is the history record of the current deployment. Guarded by opMu.
+	record *model.Deployment
+
+	mu     sync.Mutex // guards status, deployed, diagram and sweep
+	status Status
+	// deployed holds the translated nodes of the current deployment, in
+	// startup order, so plans can be diffed against it.
+	deployed []templates.TranslatedNode
+	// diagram is the diagram the current deployment was created or last
+	// reconfigured from. It is nil for a deployment adopted from a
+	// previous run, whose diagram is not recorded.
+	diagram *model.Diagram
+	// sweep is the report of the last orphan sweep.
+	sweep *SweepReport
+}
+
+// NewManager creates a Manager. Every deployment is recorded in history;
+// mode is reported in every Status. history and notify may be nil.
+func NewManager(orch docker.Orchestrator, history storage.DeploymentStore, mode string, notify Notifier) *Manager {
+	if notify == nil {
+		notify = func(Status) {}
+	}
+	return &Manager{
+		orch:    orch,
+		history: history,
+		mode:    mode,
+		notify:  notify,
+		status:  Status{Mode: mode, State: StateIdle, Nodes: []NodeStatus{}},
+	}
+}
+
+// SetMockImage makes api-service nodes run image instead of
+// templates.ImageAPIService. It must be called before the manager plans or
+// deploys anything.
+func (m *Manager) SetMockImage(image string) {
+	m.mockImage = image
+}
+
+// Deploy replaces the current deployment with the given diagram. Once the
+// diagram has been translated, the previous deployment is torn down and the
+// new one is created as a transaction: generated files are written, the
+// shared network is created if missing, and containers are created and
+// started in dependency order. If any step fails, everything the deploy
+// created is undone in reverse order, files it overwrote are restored, a
+// pre-existing network is left alone, and the previous deployment is
+// recreated from its recorded configuration and becomes current again. The
+// returned *DeployError wraps the original error and carries the rollback
+// report, which is also recorded in Status.
+func (m *Manager) Deploy(ctx context.Context, d model.Diagram) (Status, error) {
+	m.opMu.Lock()
+	defer m.opMu.Unlock()
+
+	nodes, err := m.translateNodes(d)
+	if err != nil {
+		return m.Status(), fmt.Errorf("%w: %v", ErrTranslation, err)
+	}
+
+	tx := &transaction{previous: m.previousLocked()}
+	if err := m.teardownLocked(ctx); err != nil {
+		return m.Status(), fmt.Errorf("tear down previous deployment: %w", err)
+	}
+	m.journalRestore(tx)
+
+	now := time.Now().UTC()
+	pending := make([]NodeStatus, len(nodes))
+	for i, n := range nodes {
+		pending[i] = NodeStatus{NodeID: n.Node.ID, Name: n.Config.Name, Ports: n.Config.Ports, Status: StatusPending}
+	}
+	deploymentID := m.recordStart(d, nodes, now)
+	m.mu.Lock()
+	m.deployed = nodes
+	m.diagram = &d
+	m.mu.Unlock()
+	m.update(func(s *Status) {
+		*s = Status{Mode: m.mode, State: StateDeploying, DiagramID: d.ID, DeploymentID: deploymentID, StartedAt: &now, Nodes: pending}
+	})
+
+	for i, n := range nodes {
+		for _, a := range n.Artifacts {
+			if err := tx.writeFile(n.Node.ID, a.HostPath, a.Content); err != nil {
+				return m.rollback(ctx, tx, i, fmt.Errorf("write generated file for node %q: %w", n.Node.ID, err))
+			}
+		}
+	}
+
+	exists, err := m.orch.NetworkExists(ctx)
+	if err != nil {
+		return m.rollback(ctx, tx, -1, fmt.Errorf("check network: %w", err))
+	}
+	if err := m.orch.CreateNetwork(ctx); err != nil {
+		return m.rollback(ctx, tx, -1, fmt.Errorf("create network: %w", err))
+	}
+	if !exists {
+		tx.record(RollbackStep{Action: RollbackRemoveNetwork, Target: docker.NetworkName}, m.orch.RemoveNetwork)
+	}
+
+	for i, n := range nodes {
+		id, err := m.orch.CreateContainer(ctx, labelled(n, deploymentID))
+		if err != nil {
+			return m.rollback(ctx, tx, i, fmt.Errorf("create container for node %q: %w", n.Node.ID, err))
+		}
+		tx.record(RollbackStep{Action: RollbackRemoveContainer, Target: n.Config.Name, NodeID: n.Node.ID}, func(ctx context.Context) error {
+			return m.orch.RemoveContainer(ctx, id)
+		})
+		m.recordContainer(i, id)
+		m.update(func(s *Status) {
+			s.Nodes[i].ContainerID = id
+			s.Nodes[i].Status = docker.StatusCreated
+		})
+
+		if err := m.orch.StartContainer(ctx, id); err != nil {
+			return m.rollback(ctx, tx, i, fmt.Errorf("start container for node %q: %w", n.Node.ID, err))
+		}
+		tx.record(RollbackStep{Action: RollbackStopContainer, Target: n.Config.Name, NodeID: n.Node.ID}, func(ctx context.Context) error {
+			return m.orch.StopContainer(ctx, id)
+		})
+		m.update(func(s *Status) { s.Nodes[i].Status = docker.StatusRunning })
+	}
+
+	m.recordDeployed()
+	m.update(func(s *Status) { s.State = StateDeployed })
+	return m.Status(), nil
+}
+
+// labelled returns the config of n with the labels that let a later run
+// trace its container back to the node and deployment. The stored config
+// stays label-free so plans compare only what the diagram controls.
+func labelled(n templates.TranslatedNode, deploymentID string) docker.ContainerConfig {
+	cfg := n.Config
+	cfg.Labels = make(map[string]string, len(n.Config.Labels)+3)
+	maps.Copy(cfg.Labels, n.Config.Labels)
+	cfg.Labels[docker.LabelNodeID] = n.Node.ID
+	cfg.Labels[docker.LabelServiceType] = string(n.Node.Type)
+	if deploymentID != "" {
+		cfg.Labels[docker.LabelDeploymentID] = deploymentID
+	}
+	return cfg
+}
+
+// Teardown removes every container and the shared network and resets the
+// deployment to idle.
+func (m *Manager) Teardown(ctx context.Context) (Status, error) {
+	m.opMu.Lock()
+	defer m.opMu.Unlock()
+
+	if err := m.teardownLocked(ctx); err != nil {
+		return m.Status(), err
+	}
+	return m.Status(), nil
+}
+
+// teardownLocked tears everything down. Caller must hold opMu.
+func (m *Manager) teardownLocked(ctx context.Context) error {
+	if err := m.orch.TeardownAll(ctx); err != nil {
+		return fmt.Errorf("teardown: %w", err)
+	}
+	m.recordTornDown()
+	m.mu.Lock()
+	m.deployed = nil
+	m.diagram = nil
+	m.mu.Unlock()
+	m.update(func(s *Status) {
+		*s = Status{Mode: m.mode, State: StateIdle, Nodes: []NodeStatus{}}
+	})
+	return nil
+}
+
+// Diagram returns the diagram the running deployment was created or last
+// reconfigured from. It reports false when nothing is deployed, or when the
+// deployment was adopted from a previous run, which does not record it.
+func (m *Manager) Diagram() (model.Diagram, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.status.State != StateDeployed || m.diagram == nil {
+		return model.Diagram{}, false
+	}
+	return *m.diagram, true
+}
+
+// Status returns a snapshot of the current deployment.
+func (m *Manager) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status.clone()
+}
+
+// HandleHealth applies container status transitions from health polling.
+// It matches docker.HealthTransitionCallback and notifies only when a node's
+// status changed.
+func (m *Manager) HandleHealth(transitions []docker.HealthTransition) {
+	statuses := make(map[string]docker.ContainerStatus, len(transitions))
+	for _, t := range transitions {
+		statuses[t.ContainerID] = t.To
+	}
+
+	m.mu.Lock()
+	changed := false
+	for i, n := range m.status.Nodes {
+		if st, ok := statuses[n.ContainerID]; ok && n.ContainerID != "" && st != n.Status {
+			m.status.Nodes[i].Status = st
+			changed = true
+		}
+	}
+	snapshot := m.status.clone()
+	m.mu.Unlock()
+
+	if changed {
+		m.notify(snapshot)
+	}
+}
+
+// rollback undoes tx after err, records err on the node at index (or on the
+// deployment when index is negative), marks the deployment failed and
+// returns a *DeployError. Nodes whose container was removed lose their
+// container ID and are reported as rolled back. When tx also restored the
+// previous deployment in full, that deployment becomes current again.
+func (m *Manager) rollback(ctx context.Context, tx *transaction, index int, err error) (Status, error) {
+	report := tx.rollback(ctx)
+	m.recordFailed(index, err)
+
+	removed := make(map[string]bool)
+	for _, step := range report.Steps {
+		if step.Action == RollbackRemoveContainer && step.Error == "" {
+			removed[step.NodeID] = true
+		}
+	}
+
+	m.update(func(s *Status) {
+		s.State = StateFailed
+		s.Error = err.Error()
+		s.Rollback = &report
+		for i := range s.Nodes {
+			if removed[s.Nodes[i].NodeID] {
+				s.Nodes[i].ContainerID = ""
+				s.Nodes[i].Status = StatusRolledBack
+			}
+		}
+		if index >= 0 {
+			s.Nodes[index].Status = docker.StatusError
+			s.Nodes[index].Error = err.Error()
+		}
+	})
+	if tx.previous != nil && report.Complete {
+		m.reinstate(tx.previous, err, report)
+	}
+	return m.Status(), &DeployError{Err: err, Rollback: report}
+}
+
+// update applies fn to the status under lock, then notifies with a snapshot.
+func (m *Manager) update(fn func(*Status)) {
+	m.mu.Lock()
+	fn(&m.status)
+	snapshot := m.status.clone()
+	m.mu.Unlock()
+
+	m.notify(snapshot)
+}
+
+// clone returns a deep copy of s safe to hand to other goroutines.
+func (s Status) clone() Status {
+	c := s
+	c.Nodes = make([]NodeStatus, len(s.Nodes))
+	copy(c.Nodes, s.Nodes)
+	if s.StartedAt != nil {
+		t := *s.StartedAt
+		c.StartedAt = &t
+	}
+	if s.Rollback != nil {
+		r := *s.Rollback
+		r.Steps = append([]RollbackStep(nil), s.Rollback.Steps...)
+		c.Rollback = &r
+	}
+	return c
+}
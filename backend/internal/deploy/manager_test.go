@@ -0,0 +1,363 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+// recorder collects notified statuses.
+type recorder struct {
+	mu       sync.Mutex
+	statuses []Status
+}
+
+func (r *recorder) notify(s Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, s)
+}
+
+func (r *recorder) states() []State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var states []State
+	for _, s := range r.statuses {
+		if len(states) == 0 || states[len(states)-1] != s.State {
+			states = append(states, s.State)
+		}
+	}
+	return states
+}
+
+func testDiagram() model.Diagram {
+	return model.Diagram{
+		ID:   "d1",
+		Name: "Deploy Test",
+		Nodes: []model.DiagramNode{
+			{ID: "api", Type: model.ServiceTypeCustomContainer, Name: "API", Position: &model.Position{}, Config: []byte(`{"type":"custom-container","image":"example/api:1","ports":[8080]}`)},
+			{ID: "db", Type: model.ServiceTypePostgreSQL, Name: "DB", Position: &model.Position{}},
+		},
+		Edges: []model.DiagramEdge{{ID: "e1", Source: "api", Target: "db"}},
+	}
+}
+
+func newTestManager(cfg docker.SimulationConfig) (*Manager, *docker.SimulatedOrchestrator, *recorder) {
+	sim := docker.NewSimulatedOrchestrator(cfg)
+	rec := &recorder{}
+	return NewManager(sim, nil, docker.ModeSimulated, rec.notify), sim, rec
+}
+
+func TestManager_Deploy(t *testing.T) {
+	m, sim, rec := newTestManager(docker.SimulationConfig{})
+
+	status, err := m.Deploy(context.Background(), testDiagram())
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+
+	if status.State != StateDeployed || status.Mode != docker.ModeSimulated || status.DiagramID != "d1" {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if len(status.Nodes) != 2 || status.Nodes[0].NodeID != "db" || status.Nodes[1].NodeID != "api" {
+		t.Fatalf("expected nodes in dependency order [db api], got %+v", status.Nodes)
+	}
+	for _, n := range status.Nodes {
+		if n.ContainerID == "" || n.Status != docker.StatusRunning || len(n.Ports) == 0 {
+			t.Errorf("unexpected node status %+v", n)
+		}
+	}
+
+	infos, _ := sim.ListContainers(context.Background())
+	if len(infos) != 2 {
+		t.Errorf("expected 2 containers, got %d", len(infos))
+	}
+	for _, info := range infos {
+		if info.Labels[docker.LabelNodeID] == "" || info.Labels[docker.LabelServiceType] == "" {
+			t.Errorf("expected node and service type labels, got %v", info.Labels)
+		}
+	}
+
+	want := []State{StateIdle, StateDeploying, StateDeployed}
+	if got := rec.states(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("expected state transitions %v, got %v", want, got)
+	}
+}
+
+func TestManager_DeployFailureRollsBack(t *testing.T) {
+	m, sim, _ := newTestManager(docker.SimulationConfig{
+		Failures: map[string]docker.SimFailure{"api": docker.SimFailStart},
+	})
+	ctx := context.Background()
+
+	status, err := m.Deploy(ctx, testDiagram())
+	if !errors.Is(err, docker.ErrSimulatedFailure) {
+		t.Fatalf("expected simulated failure, got %v", err)
+	}
+	var deployErr *DeployError
+	if !errors.As(err, &deployErr) {
+		t.Fatalf("expected *DeployError, got %T", err)
+	}
+	if status.State != StateFailed || status.Error == "" {
+		t.Errorf("expected failed deployment, got %+v", status)
+	}
+	if api := status.Nodes[1]; api.Status != docker.StatusError || api.Error == "" || api.ContainerID != "" {
+		t.Errorf("expected api node to carry the error, got %+v", api)
+	}
+	if db := status.Nodes[0]; db.Status != StatusRolledBack || db.ContainerID != "" {
+		t.Errorf("expected db node to be rolled back, got %+v", db)
+	}
+
+	want := []RollbackStep{
+		{Action: RollbackRemoveContainer, Target: "api", NodeID: "api"},
+		{Action: RollbackStopContainer, Target: "db", NodeID: "db"},
+		{Action: RollbackRemoveContainer, Target: "db", NodeID: "db"},
+		{Action: RollbackRemoveNetwork, Target: docker.NetworkName},
+	}
+	report := deployErr.Rollback
+	if !report.Complete || len(report.Steps) != len(want) {
+		t.Fatalf("expected complete rollback %v, got %+v", want, report)
+	}
+	for i := range want {
+		if report.Steps[i] != want[i] {
+			t.Errorf("step %d: expected %+v, got %+v", i, want[i], report.Steps[i])
+		}
+	}
+	if status.Rollback == nil || len(status.Rollback.Steps) != len(want) {
+		t.Errorf("expected rollback report in status, got %+v", status.Rollback)
+	}
+
+	infos, _ := sim.ListContainers(ctx)
+	if len(infos) != 0 {
+		t.Errorf("expected no containers after rollback, got %d", len(infos))
+	}
+	if exists, _ := sim.NetworkExists(ctx); exists {
+		t.Error("expected network created by the deploy to be removed")
+	}
+}
+
+// persistentNetwork is an orchestrator whose network survives teardown, like
+// a Docker network this process did not create.
+type persistentNetwork struct {
+	*docker.SimulatedOrchestrator
+}
+
+func (o persistentNetwork) TeardownAll(ctx context.Context) error {
+	if err := o.SimulatedOrchestrator.TeardownAll(ctx); err != nil {
+		return err
+	}
+	return o.CreateNetwork(ctx)
+}
+
+func TestManager_RollbackKeepsExistingNetwork(t *testing.T) {
+	sim := docker.NewSimulatedOrchestrator(docker.SimulationConfig{
+		Failures: map[string]docker.SimFailure{"db": docker.SimFailCreate},
+	})
+	m := NewManager(persistentNetwork{sim}, nil, docker.ModeSimulated, nil)
+	ctx := context.Background()
+
+	_, err := m.Deploy(ctx, testDiagram())
+	var deployErr *DeployError
+	if !errors.As(err, &deployErr) {
+		t.Fatalf("expected *DeployError, got %v", err)
+	}
+	if len(deployErr.Rollback.Steps) != 0 {
+		t.Errorf("expected nothing to roll back, got %+v", deployErr.Rollback.Steps)
+	}
+	if exists, _ := sim.NetworkExists(ctx); !exists {
+		t.Error("expected pre-existing network to be left alone")
+	}
+}
+
+func TestManager_RollbackRestoresFiles(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	d := model.Diagram{
+		ID:   "d2",
+		Name: "Specs",
+		Nodes: []model.DiagramNode{
+			{ID: "old", Type: model.ServiceTypeAPIService, Name: "Old Spec", Position: &model.Position{}},
+			{ID: "new", Type: model.ServiceTypeAPIService, Name: "New Spec", Position: &model.Position{}},
+			{ID: "boom", Type: model.ServiceTypeCustomContainer, Name: "Boom", Position: &model.Position{}, Config: []byte(`{"type":"custom-container","image":"example/boom:1"}`)},
+		},
+		Edges: []model.DiagramEdge{
+			{ID: "e1", Source: "boom", Target: "old"},
+			{ID: "e2", Source: "boom", Target: "new"},
+		},
+	}
+
+	oldPath := filepath.Join(templates.DefaultSpecDir(), "old-spec.json")
+	newPath := filepath.Join(templates.DefaultSpecDir(), "new-spec.json")
+	if err := os.MkdirAll(templates.DefaultSpecDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(oldPath, []byte("previous"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, _, _ := newTestManager(docker.SimulationConfig{
+		Failures: map[string]docker.SimFailure{"boom": docker.SimFailStart},
+	})
+	_, err := m.Deploy(context.Background(), d)
+	var deployErr *DeployError
+	if !errors.As(err, &deployErr) {
+		t.Fatalf("expected *DeployError, got %v", err)
+	}
+	if !deployErr.Rollback.Complete {
+		t.Errorf("expected complete rollback, got %+v", deployErr.Rollback)
+	}
+
+	if got, err := os.ReadFile(oldPath); err != nil || string(got) != "previous" {
+		t.Errorf("expected existing file to be restored, got %q (%v)", got, err)
+	}
+	if _, err := os.Stat(newPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected generated file to be removed, got %v", err)
+	}
+
+	steps := deployErr.Rollback.Steps
+	last := steps[len(steps)-2:]
+	if last[0].Action != RollbackRestoreFile || last[0].Target != oldPath ||
+		last[1].Action != RollbackRemoveFile || last[1].Target != newPath {
+		t.Errorf("expected file steps last in reverse write order, got %+v", last)
+	}
+}
+
+func TestManager_DeployTranslationError(t *testing.T) {
+	m, _, _ := newTestManager(docker.SimulationConfig{})
+
+	d := testDiagram()
+	d.Edges = append(d.Edges, model.DiagramEdge{ID: "e2", Source: "db", Target: "api"})
+	if _, err := m.Deploy(context.Background(), d); !errors.Is(err, ErrTranslation) {
+		t.Errorf("expected ErrTranslation, got %v", err)
+	}
+	if got := m.Status().State; got != StateIdle {
+		t.Errorf("expected state to stay idle, got %q", got)
+	}
+}
+
+func TestManager_Redeploy(t *testing.T) {
+	m, sim, _ := newTestManager(docker.SimulationConfig{})
+	ctx := context.Background()
+
+	if _, err := m.Deploy(ctx, testDiagram()); err != nil {
+		t.Fatalf("first Deploy: %v", err)
+	}
+	if _, err := m.Deploy(ctx, testDiagram()); err != nil {
+		t.Fatalf("second Deploy: %v", err)
+	}
+	infos, _ := sim.ListContainers(ctx)
+	if len(infos) != 2 {
+		t.Errorf("expected previous deployment to be replaced, got %d containers", len(infos))
+	}
+}
+
+func TestManager_FailedRedeployRestoresPrevious(t *testing.T) {
+	history, err := storage.NewFileDeploymentStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileDeploymentStore: %v", err)
+	}
+	sim := docker.NewSimulatedOrchestrator(docker.SimulationConfig{
+		Failures: map[string]docker.SimFailure{"boom": docker.SimFailStart},
+	})
+	m := NewManager(sim, history, docker.ModeSimulated, nil)
+	ctx := context.Background()
+
+	first, err := m.Deploy(ctx, testDiagram())
+	if err != nil {
+		t.Fatalf("first Deploy: %v", err)
+	}
+	d := testDiagram()
+	d.ID = "d2"
+	d.Nodes = append(d.Nodes, model.DiagramNode{ID: "boom", Type: model.ServiceTypeCustomContainer, Name: "Boom", Position: &model.Position{}, Config: []byte(`{"type":"custom-container","image":"example/boom:1"}`)})
+	_, err = m.Deploy(ctx, d)
+	var deployErr *DeployError
+	if !errors.As(err, &deployErr) {
+		t.Fatalf("expected *DeployError, got %v", err)
+	}
+
+	report := deployErr.Rollback
+	n := len(report.Steps)
+	if !report.Complete || n < 3 ||
+		report.Steps[n-3].Action != RollbackRestoreNetwork ||
+		report.Steps[n-2] != (RollbackStep{Action: RollbackRestoreContainer, Target: "db", NodeID: "db"}) ||
+		report.Steps[n-1] != (RollbackStep{Action: RollbackRestoreContainer, Target: "api", NodeID: "api"}) {
+		t.Errorf("expected the previous deployment restored last in startup order, got %+v", report)
+	}
+
+	status := m.Status()
+	if status.State != StateDeployed || status.DiagramID != "d1" || status.DeploymentID != first.DeploymentID || status.Error == "" || status.Rollback == nil {
+		t.Errorf("expected the previous deployment to be current with the failure reported, got %+v", status)
+	}
+	infos, _ := sim.ListContainers(ctx)
+	if len(infos) != 2 {
+		t.Fatalf("expected the 2 previous containers, got %+v", infos)
+	}
+	running := map[string]bool{}
+	for _, info := range infos {
+		running[info.ID] = info.Status != docker.StatusStopped && info.Labels[docker.LabelDeploymentID] == first.DeploymentID
+	}
+	for _, node := range status.Nodes {
+		if !running[node.ContainerID] {
+			t.Errorf("expected node %q to run a restored container, got %+v", node.NodeID, node)
+		}
+	}
+
+	record, err := history.Get(first.DeploymentID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if record.Status != model.DeploymentDeployed || record.EndedAt != nil || record.Containers[0].ContainerID != status.Nodes[0].ContainerID {
+		t.Errorf("expected the previous record to be live again, got %+v", record)
+	}
+}
+
+func TestManager_Teardown(t *testing.T) {
+	m, sim, _ := newTestManager(docker.SimulationConfig{})
+	ctx := context.Background()
+	if _, err := m.Deploy(ctx, testDiagram()); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+
+	status, err := m.Teardown(ctx)
+	if err != nil {
+		t.Fatalf("Teardown: %v", err)
+	}
+	if status.State != StateIdle || len(status.Nodes) != 0 {
+		t.Errorf("expected idle status, got %+v", status)
+	}
+	infos, _ := sim.ListContainers(ctx)
+	if len(infos) != 0 {
+		t.Errorf("expected no containers, got %d", len(infos))
+	}
+}
+
+func TestManager_HandleHealthNotifiesOnChange(t *testing.T) {
+	m, _, rec := newTestManager(docker.SimulationConfig{})
+	status, err := m.Deploy(context.Background(), testDiagram())
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	dbID := status.Nodes[0].ContainerID
+	before := len(rec.statuses)
+
+	m.HandleHealth([]docker.HealthTransition{{ContainerID: dbID, From: docker.StatusCreated, To: docker.StatusRunning}})
+	if len(rec.statuses) != before {
+		t.Error("expected no notification when nothing changed")
+	}
+
+	m.HandleHealth([]docker.HealthTransition{{ContainerID: dbID, From: docker.StatusRunning, To: docker.StatusHealthy}})
+	if len(rec.statuses) != before+1 {
+		t.Fatal("expected one notification for a status change")
+	}
+	if got := m.Status().Nodes[0].Status; got != docker.StatusHealthy {
+		t.Errorf("expected db healthy, got %q", got)
+	}
+}
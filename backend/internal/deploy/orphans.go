@@ -0,0 +1,399 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// OrphanPolicy selects what the startup sweep does with managed resources
+// left behind by an earlier run that did not shut down cleanly.
+type OrphanPolicy string
+
+const (
+	// OrphanRemove removes orphaned containers, the network and generated
+	// files so the next deploy starts clean. It is the default.
+	OrphanRemove OrphanPolicy = "remove"
+	// OrphanAdopt takes orphaned resources over and restores the deployment
+	// they belong to, as if the backend had never stopped.
+	OrphanAdopt OrphanPolicy = "adopt"
+)
+
+// ParseOrphanPolicy parses a policy name. The empty string selects OrphanRemove.
+func ParseOrphanPolicy(s string) (OrphanPolicy, error) {
+	switch OrphanPolicy(s) {
+	case "", OrphanRemove:
+		return OrphanRemove, nil
+	case OrphanAdopt:
+		return OrphanAdopt, nil
+	}
+	return "", fmt.Errorf("unknown orphan policy %q: must be %s or %s", s, OrphanRemove, OrphanAdopt)
+}
+
+// Orphaned resource kinds and the actions the sweep takes on them.
+const (
+	OrphanKindContainer = "container"
+	OrphanKindNetwork   = "network"
+	OrphanKindFile      = "file"
+
+	OrphanActionRemoved = "removed"
+	OrphanActionAdopted = "adopted"
+	OrphanActionKept    = "kept"
+	// OrphanActionReattached is taken on the containers of a deployment the
+	// previous run detached from, whatever the policy.
+	OrphanActionReattached = "reattached"
+)
+
+// Errors recorded on history records that were still live when the backend
+// stopped.
+const (
+	orphanedRecordError = "left behind by an unclean shutdown"
+	detachedRecordError = "detached deployment was no longer running"
+)
+
+// OrphanResource is a managed resource found by the startup sweep.
+type OrphanResource struct {
+	Kind string `json:"kind"`
+	// Name is the container or network name, or the file path.
+	Name string `json:"name"`
+	ID   string `json:"id,omitempty"`
+	// NodeID and DeploymentID come from the container's labels.
+	NodeID       string                 `json:"nodeId,omitempty"`
+	DeploymentID string                 `json:"deploymentId,omitempty"`
+	Status       docker.ContainerStatus `json:"status,omitempty"`
+	Action       string                 `json:"action"`
+	// Error is set when the action failed.
+	Error string `json:"error,omitempty"`
+}
+
+// SweepReport is the outcome of a startup sweep.
+type SweepReport struct {
+	Policy    OrphanPolicy     `json:"policy"`
+	RanAt     time.Time        `json:"ranAt"`
+	Resources []OrphanResource `json:"resources"`
+	// ClosedDeployments lists history records that were still live and have
+	// been ended.
+	ClosedDeployments []string `json:"closedDeployments"`
+	// AdoptedDeploymentID is the deployment restored by OrphanAdopt, if any.
+	AdoptedDeploymentID string `json:"adoptedDeploymentId,omitempty"`
+	// ReattachedDeploymentID is the detached deployment restored, if any.
+	ReattachedDeploymentID string `json:"reattachedDeploymentId,omitempty"`
+}
+
+// Sweep finds managed resources left behind by an earlier run — containers
+// with the heph- prefix and LabelManaged, the shared network and generated
+// spec files — and removes or adopts them according to policy. Containers
+// from versions that predate LabelManaged carry only the prefix, so they are
+// no longer swept and must be removed by hand. A deployment the earlier run
+// detached from (see Shutdown) is not an orphan: its containers, the network
+// and the files are always kept and the deployment is reattached. History
+// records that were never ended are closed, except the one that is restored:
+// the detached one, or under OrphanAdopt the most recent deployed record
+// whose containers carry its deployment ID. Sweep is meant to run once at
+// startup, before any deploy; the report is kept for LastSweep. Failures on
+// individual resources are recorded in the report, not returned.
+func (m *Manager) Sweep(ctx context.Context, policy OrphanPolicy) (SweepReport, error) {
+	m.opMu.Lock()
+	defer m.opMu.Unlock()
+
+	report := SweepReport{Policy: policy, RanAt: time.Now().UTC(), Resources: []OrphanResource{}, ClosedDeployments: []string{}}
+
+	listed, err := m.orch.ListContainers(ctx)
+	if err != nil {
+		return report, fmt.Errorf("list containers: %w", err)
+	}
+	// Never touch a container Hephaestus did not create.
+	var containers []docker.ContainerInfo
+	for _, c := range listed {
+		if c.Managed() {
+			containers = append(containers, c)
+		}
+	}
+	networkExists, err := m.orch.NetworkExists(ctx)
+	if err != nil {
+		return report, fmt.Errorf("check network: %w", err)
+	}
+	files, err := specFiles()
+	if err != nil {
+		return report, err
+	}
+	stale := m.staleRecords()
+
+	reattach := detachedRecord(stale, containers)
+	restore := reattach
+	if restore == nil && policy == OrphanAdopt {
+		restore = adoptableRecord(stale, containers)
+	}
+	// The network and files are shared, so they stay whenever anything is
+	// kept running.
+	keep := reattach != nil || policy == OrphanAdopt
+
+	for _, c := range containers {
+		r := OrphanResource{
+			Kind:         OrphanKindContainer,
+			Name:         c.Name,
+			ID:           c.ID,
+			NodeID:       c.Labels[docker.LabelNodeID],
+			DeploymentID: c.Labels[docker.LabelDeploymentID],
+			Status:       c.Status,
+		}
+		switch {
+		case reattach != nil && r.DeploymentID == reattach.ID:
+			r.Action = OrphanActionReattached
+			err = m.orch.AdoptContainer(ctx, c.ID)
+		case policy == OrphanAdopt:
+			r.Action = OrphanActionAdopted
+			err = m.orch.AdoptContainer(ctx, c.ID)
+		default:
+			r.Action = OrphanActionRemoved
+			err = m.orch.RemoveContainer(ctx, c.ID)
+		}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		report.Resources = append(report.Resources, r)
+	}
+
+	if networkExists {
+		r := OrphanResource{Kind: OrphanKindNetwork, Name: docker.NetworkName, Action: OrphanActionAdopted}
+		// CreateNetwork adopts the existing network, which RemoveNetwork
+		// needs in order to find it.
+		err = m.orch.CreateNetwork(ctx)
+		if err == nil && !keep {
+			r.Action = OrphanActionRemoved
+			err = m.orch.RemoveNetwork(ctx)
+		}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		report.Resources = append(report.Resources, r)
+	}
+
+	for _, path := range files {
+		r := OrphanResource{Kind: OrphanKindFile, Name: path, Action: OrphanActionKept}
+		if !keep {
+			r.Action = OrphanActionRemoved
+			if err := os.RemoveAll(path); err != nil {
+				r.Error = err.Error()
+			}
+		}
+		report.Resources = append(report.Resources, r)
+	}
+
+	for i := range stale {
+		if restore != nil && stale[i].ID == restore.ID {
+			continue
+		}
+		m.closeStaleRecord(&stale[i])
+		report.ClosedDeployments = append(report.ClosedDeployments, stale[i].ID)
+	}
+	switch {
+	case reattach != nil:
+		reattach.DetachedAt = nil
+		m.restoreLocked(reattach, containers)
+		m.saveRecord()
+		report.ReattachedDeploymentID = reattach.ID
+	case restore != nil:
+		m.restoreLocked(restore, containers)
+		report.AdoptedDeploymentID = restore.ID
+	}
+
+	m.mu.Lock()
+	m.sweep = &report
+	m.mu.Unlock()
+
+	log.Print(report.summary())
+	return report, nil
+}
+
+// LastSweep returns the report of the most recent sweep. ok is false if no
+// sweep has run.
+func (m *Manager) LastSweep() (report SweepReport, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sweep == nil {
+		return SweepReport{}, false
+	}
+	return *m.sweep, true
+}
+
+// summary renders the report as a single log line.
+func (r SweepReport) summary() string {
+	counts := map[string]int{}
+	failed := 0
+	for _, res := range r.Resources {
+		counts[res.Kind]++
+		if res.Error != "" {
+			failed++
+		}
+	}
+	s := fmt.Sprintf("orphan sweep (%s): %d container(s), %d network(s), %d file(s), %d failed; closed %d stale deployment record(s)",
+		r.Policy, counts[OrphanKindContainer], counts[OrphanKindNetwork], counts[OrphanKindFile], failed, len(r.ClosedDeployments))
+	if r.AdoptedDeploymentID != "" {
+		s += "; adopted deployment " + r.AdoptedDeploymentID
+	}
+	if r.ReattachedDeploymentID != "" {
+		s += "; reattached to deployment " + r.ReattachedDeploymentID
+	}
+	return s
+}
+
+// specFiles lists the entries of the shared spec directory.
+func specFiles() ([]string, error) {
+	dir := templates.DefaultSpecDir()
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read spec directory %q: %w", dir, err)
+	}
+	paths := make([]string, len(entries))
+	for i, e := range entries {
+		paths[i] = filepath.Join(dir, e.Name())
+	}
+	return paths, nil
+}
+
+// staleRecords returns the history records that were never ended, most
+// recent first.
+func (m *Manager) staleRecords() []model.Deployment {
+	if m.history == nil {
+		return nil
+	}
+	records, err := m.history.List()
+	if err != nil {
+		log.Printf("failed to list deployment records: %v", err)
+		return nil
+	}
+	var stale []model.Deployment
+	for _, d := range records {
+		if d.EndedAt == nil {
+			stale = append(stale, d)
+		}
+	}
+	return stale
+}
+
+// detachedRecord returns the most recent record the previous run detached
+// from, if any of containers belong to it.
+func detachedRecord(stale []model.Deployment, containers []docker.ContainerInfo) *model.Deployment {
+	for i, d := range stale {
+		if d.DetachedAt == nil {
+			continue
+		}
+		for _, c := range containers {
+			if c.Labels[docker.LabelDeploymentID] == d.ID {
+				return &stale[i]
+			}
+		}
+	}
+	return nil
+}
+
+// adoptableRecord returns the most recent deployed record that at least one
+// of containers belongs to.
+func adoptableRecord(stale []model.Deployment, containers []docker.ContainerInfo) *model.Deployment {
+	for i, d := range stale {
+		if d.Status != model.DeploymentDeployed {
+			continue
+		}
+		for _, c := range containers {
+			if c.Labels[docker.LabelDeploymentID] == d.ID {
+				return &stale[i]
+			}
+		}
+	}
+	return nil
+}
+
+// closeStaleRecord ends a record that was still live when the backend
+// stopped: an interrupted deploy failed, a finished one was torn down.
+func (m *Manager) closeStaleRecord(d *model.Deployment) {
+	now := time.Now().UTC()
+	if d.Status == model.DeploymentDeploying {
+		d.Status = model.DeploymentFailed
+	} else {
+		d.Status = model.DeploymentTornDown
+	}
+	d.Error = orphanedRecordError
+	if d.DetachedAt != nil {
+		d.Error = detachedRecordError
+	}
+	d.EndedAt = &now
+	if _, err := m.history.Update(d.ID, d); err != nil {
+		log.Printf("failed to update deployment record %q: %v", d.ID, err)
+	}
+}
+
+// restoreLocked makes record the current deployment. Node statuses come from
+// the adopted containers; the deployed nodes are rebuilt from the recorded
+// configs and the generated files still on disk, so plans diff against what
+// is actually running. Caller must hold opMu.
+func (m *Manager) restoreLocked(record *model.Deployment, containers []docker.ContainerInfo) {
+	byID := make(map[string]docker.ContainerInfo, len(containers))
+	for _, c := range containers {
+		byID[c.ID] = c
+	}
+
+	nodes := make([]NodeStatus, len(record.Containers))
+	deployed := make([]templates.TranslatedNode, len(record.Containers))
+	for i, c := range record.Containers {
+		nodes[i] = NodeStatus{NodeID: c.NodeID, Name: c.Config.Name, Ports: c.Ports}
+		if info, ok := byID[c.ContainerID]; ok && c.ContainerID != "" {
+			nodes[i].ContainerID = c.ContainerID
+			nodes[i].Status = info.Status
+		} else {
+			nodes[i].Status = docker.StatusError
+			nodes[i].Error = "container not found"
+		}
+		deployed[i] = templates.TranslatedNode{
+			Node:      model.DiagramNode{ID: c.NodeID},
+			Config:    c.Config,
+			Artifacts: readArtifacts(c.Config.Volumes),
+		}
+	}
+
+	m.record = record
+	startedAt := record.StartedAt
+	m.mu.Lock()
+	m.deployed = deployed
+	m.diagram = nil
+	m.mu.Unlock()
+	m.update(func(s *Status) {
+		*s = Status{Mode: m.mode, State: StateDeployed, DiagramID: record.DiagramID, DeploymentID: record.ID, StartedAt: &startedAt, Nodes: nodes}
+	})
+}
+
+// readArtifacts reads back the generated files mounted through volumes, in
+// container-path order. Volumes outside the spec directory are not
+// generated and are skipped, as are files that no longer exist.
+func readArtifacts(volumes map[string]string) []templates.Artifact {
+	dir := templates.DefaultSpecDir() + string(filepath.Separator)
+	var artifacts []templates.Artifact
+	for host, ctr := range volumes {
+		if !strings.HasPrefix(host, dir) {
+			continue
+		}
+		content, err := os.ReadFile(host)
+		if err != nil {
+			continue
+		}
+		artifacts = append(artifacts, templates.Artifact{HostPath: host, ContainerPath: ctr, Content: content})
+	}
+	slices.SortFunc(artifacts, func(a, b templates.Artifact) int {
+		return strings.Compare(a.ContainerPath, b.ContainerPath)
+	})
+	return artifacts
+}
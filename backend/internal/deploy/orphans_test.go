@@ -0,0 +1,233 @@
+package deploy
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+// orphanDiagram is testDiagram plus an API service, so that a deploy leaves
+// a generated spec file behind.
+func orphanDiagram() model.Diagram {
+	d := testDiagram()
+	d.Nodes = append(d.Nodes, model.DiagramNode{ID: "spec", Type: model.ServiceTypeAPIService, Name: "Spec", Position: &model.Position{}})
+	return d
+}
+
+// crashedDeployment deploys orphanDiagram and returns the orchestrator and
+// history it left behind, as if the backend had been killed afterwards.
+func crashedDeployment(t *testing.T) (*docker.SimulatedOrchestrator, *storage.FileDeploymentStore, Status) {
+	t.Helper()
+	t.Setenv("TMPDIR", t.TempDir())
+
+	store, err := storage.NewFileDeploymentStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileDeploymentStore: %v", err)
+	}
+	sim := docker.NewSimulatedOrchestrator(docker.SimulationConfig{})
+	status, err := NewManager(sim, store, docker.ModeSimulated, nil).Deploy(context.Background(), orphanDiagram())
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	return sim, store, status
+}
+
+func TestParseOrphanPolicy(t *testing.T) {
+	for in, want := range map[string]OrphanPolicy{"": OrphanRemove, "remove": OrphanRemove, "adopt": OrphanAdopt} {
+		got, err := ParseOrphanPolicy(in)
+		if err != nil || got != want {
+			t.Errorf("ParseOrphanPolicy(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseOrphanPolicy("keep"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestSweep_RemovesOrphans(t *testing.T) {
+	sim, store, crashed := crashedDeployment(t)
+	ctx := context.Background()
+
+	m := NewManager(sim, store, docker.ModeSimulated, nil)
+	if _, ok := m.LastSweep(); ok {
+		t.Fatal("expected no sweep report before the first sweep")
+	}
+	report, err := m.Sweep(ctx, OrphanRemove)
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+
+	kinds := map[string]int{}
+	for _, r := range report.Resources {
+		kinds[r.Kind]++
+		if r.Action != OrphanActionRemoved || r.Error != "" {
+			t.Errorf("expected %s %q removed, got %+v", r.Kind, r.Name, r)
+		}
+		if r.Kind == OrphanKindContainer && (r.NodeID == "" || r.DeploymentID != crashed.DeploymentID) {
+			t.Errorf("expected labels on container %+v", r)
+		}
+	}
+	if kinds[OrphanKindContainer] != 3 || kinds[OrphanKindNetwork] != 1 || kinds[OrphanKindFile] != 1 {
+		t.Errorf("unexpected resources %+v", report.Resources)
+	}
+
+	if infos, _ := sim.ListContainers(ctx); len(infos) != 0 {
+		t.Errorf("expected no containers, got %d", len(infos))
+	}
+	if exists, _ := sim.NetworkExists(ctx); exists {
+		t.Error("expected network to be removed")
+	}
+	if entries, _ := os.ReadDir(templates.DefaultSpecDir()); len(entries) != 0 {
+		t.Errorf("expected spec directory to be empty, got %d entries", len(entries))
+	}
+
+	if len(report.ClosedDeployments) != 1 || report.ClosedDeployments[0] != crashed.DeploymentID {
+		t.Errorf("expected closed deployment %q, got %v", crashed.DeploymentID, report.ClosedDeployments)
+	}
+	record, _ := store.Get(crashed.DeploymentID)
+	if record.Status != model.DeploymentTornDown || record.EndedAt == nil || record.Error == "" {
+		t.Errorf("expected closed record, got %+v", record)
+	}
+
+	if status := m.Status(); status.State != StateIdle {
+		t.Errorf("expected idle state, got %q", status.State)
+	}
+	if last, ok := m.LastSweep(); !ok || len(last.Resources) != len(report.Resources) {
+		t.Errorf("expected LastSweep to return the report, got %+v", last)
+	}
+}
+
+// foreignContainers is an orchestrator that also lists containers
+// Hephaestus did not create, as Docker's substring name filter would, and
+// records which containers are removed or adopted.
+type foreignContainers struct {
+	*docker.SimulatedOrchestrator
+	foreign []docker.ContainerInfo
+	touched *[]string
+}
+
+func (o foreignContainers) ListContainers(ctx context.Context) ([]docker.ContainerInfo, error) {
+	infos, err := o.SimulatedOrchestrator.ListContainers(ctx)
+	return append(infos, o.foreign...), err
+}
+
+func (o foreignContainers) RemoveContainer(ctx context.Context, id string) error {
+	*o.touched = append(*o.touched, id)
+	return o.SimulatedOrchestrator.RemoveContainer(ctx, id)
+}
+
+func (o foreignContainers) AdoptContainer(ctx context.Context, id string) error {
+	*o.touched = append(*o.touched, id)
+	return o.SimulatedOrchestrator.AdoptContainer(ctx, id)
+}
+
+func TestSweep_LeavesUnmanagedContainers(t *testing.T) {
+	sim, store, _ := crashedDeployment(t)
+	var touched []string
+	orch := foreignContainers{SimulatedOrchestrator: sim, touched: &touched, foreign: []docker.ContainerInfo{
+		{ID: "foreign-1", Name: "foo-heph-bar", Status: docker.StatusRunning},
+		{ID: "foreign-2", Name: "foo-heph-baz", Status: docker.StatusRunning, Labels: map[string]string{docker.LabelManaged: "true"}},
+		{ID: "foreign-3", Name: "heph-lookalike", Status: docker.StatusRunning},
+	}}
+
+	for _, policy := range []OrphanPolicy{OrphanAdopt, OrphanRemove} {
+		report, err := NewManager(orch, store, docker.ModeSimulated, nil).Sweep(context.Background(), policy)
+		if err != nil {
+			t.Fatalf("Sweep(%s): %v", policy, err)
+		}
+		for _, r := range report.Resources {
+			if strings.HasPrefix(r.ID, "foreign-") {
+				t.Errorf("%s: expected %q to be left out of the report, got %+v", policy, r.Name, r)
+			}
+		}
+	}
+	for _, id := range touched {
+		if strings.HasPrefix(id, "foreign-") {
+			t.Errorf("expected unmanaged container %s to survive the sweep", id)
+		}
+	}
+	if len(touched) == 0 {
+		t.Error("expected the managed containers to be swept")
+	}
+}
+
+func TestSweep_AdoptsOrphans(t *testing.T) {
+	sim, store, crashed := crashedDeployment(t)
+	ctx := context.Background()
+
+	m := NewManager(sim, store, docker.ModeSimulated, nil)
+	report, err := m.Sweep(ctx, OrphanAdopt)
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if report.AdoptedDeploymentID != crashed.DeploymentID {
+		t.Errorf("expected adopted deployment %q, got %q", crashed.DeploymentID, report.AdoptedDeploymentID)
+	}
+	if len(report.ClosedDeployments) != 0 {
+		t.Errorf("expected no closed deployments, got %v", report.ClosedDeployments)
+	}
+	for _, r := range report.Resources {
+		want := OrphanActionAdopted
+		if r.Kind == OrphanKindFile {
+			want = OrphanActionKept
+		}
+		if r.Action != want || r.Error != "" {
+			t.Errorf("expected %s %q %s, got %+v", r.Kind, r.Name, want, r)
+		}
+	}
+
+	status := m.Status()
+	if status.State != StateDeployed || status.DeploymentID != crashed.DeploymentID || status.DiagramID != "d1" {
+		t.Errorf("unexpected restored status %+v", status)
+	}
+	for i, n := range status.Nodes {
+		if n.NodeID != crashed.Nodes[i].NodeID || n.ContainerID != crashed.Nodes[i].ContainerID {
+			t.Errorf("node %d: got %+v, want %+v", i, n, crashed.Nodes[i])
+		}
+	}
+
+	plan, err := m.Plan(orphanDiagram())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if plan.DeployedDiagramID != "d1" {
+		t.Errorf("expected the adopted diagram to be deployed, got %q", plan.DeployedDiagramID)
+	}
+	for _, c := range plan.Changes {
+		if c.Action != ActionRecreate {
+			t.Errorf("expected no changes against the adopted deployment, got %+v", c)
+		}
+	}
+
+	record, _ := store.Get(crashed.DeploymentID)
+	if record.Status != model.DeploymentDeployed || record.EndedAt != nil {
+		t.Errorf("expected adopted record to stay live, got %+v", record)
+	}
+
+	if _, err := m.Teardown(ctx); err != nil {
+		t.Fatalf("Teardown: %v", err)
+	}
+	record, _ = store.Get(crashed.DeploymentID)
+	if record.Status != model.DeploymentTornDown || record.EndedAt == nil {
+		t.Errorf("expected torn-down record after teardown, got %+v", record)
+	}
+}
+
+func TestSweep_NothingToDo(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	m, _, _ := newTestManager(docker.SimulationConfig{})
+
+	report, err := m.Sweep(context.Background(), OrphanRemove)
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if len(report.Resources) != 0 || len(report.ClosedDeployments) != 0 {
+		t.Errorf("expected an empty report, got %+v", report)
+	}
+}
@@ -0,0 +1,250 @@
+package deploy
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// Action is the change a plan makes to a node of the running deployment.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	// ActionRecreate is a node whose container settings are unchanged.
+	// Deploy still replaces its container, so anything written inside it,
+	// such as database data, is lost.
+	ActionRecreate Action = "recreate"
+	ActionDelete   Action = "delete"
+)
+
+// Plan describes what deploying a diagram would do, without touching the
+// orchestrator.
+type Plan struct {
+	DiagramID string `json:"diagramId"`
+	// Levels groups node IDs by startup level: every node in a level depends
+	// only on nodes in earlier levels.
+	Levels [][]string `json:"levels"`
+	Nodes  []PlanNode `json:"nodes"`
+	// DeployedDiagramID is the diagram currently deployed, if any. Changes
+	// are computed against it; with nothing deployed every node is created.
+	DeployedDiagramID string       `json:"deployedDiagramId,omitempty"`
+	Changes           []PlanChange `json:"changes"`
+}
+
+// PlanNode is the container that would be created for a diagram node.
+type PlanNode struct {
+	NodeID string `json:"nodeId"`
+	Type   string `json:"type"`
+	// Name is the node's display name and Hostname the unique DNS name
+	// derived from it, used as container name and hostname.
+	Name      string                 `json:"name"`
+	Hostname  string                 `json:"hostname"`
+	Level     int                    `json:"level"`
+	DependsOn []string               `json:"dependsOn,omitempty"`
+	Config    docker.ContainerConfig `json:"config"`
+	// InjectedEnv is the subset of Config.Env added for outgoing edges.
+	InjectedEnv map[string]string `json:"injectedEnv,omitempty"`
+	Files       []PlanFile        `json:"files,omitempty"`
+}
+
+// PlanFile is a generated file that would be mounted into a container.
+type PlanFile struct {
+	HostPath      string `json:"hostPath"`
+	ContainerPath string `json:"containerPath"`
+	Content       string `json:"content"`
+}
+
+// PlanChange is a single entry of the diff against the running deployment.
+// Fields lists what differs for updates. Deploy replaces every container,
+// so each node the deployment keeps is either an update or a recreate.
+type PlanChange struct {
+	Action Action   `json:"action"`
+	NodeID string   `json:"nodeId"`
+	Name   string   `json:"name"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// Plan translates d exactly as Deploy would and diffs the result against the
+// running deployment. Generated files are rendered into a scratch directory
+// so that planning never rewrites files mounted by live containers; reported
+// host paths are the ones Deploy would use.
+func (m *Manager) Plan(d model.Diagram) (Plan, error) {
+	nodes, err := m.translateNodes(d)
+	if err != nil {
+		return Plan{}, fmt.Errorf("%w: %v", ErrTranslation, err)
+	}
+
+	levels, levelOf := startupLevels(nodes)
+	plan := Plan{DiagramID: d.ID, Levels: levels, Nodes: make([]PlanNode, len(nodes))}
+	for i, n := range nodes {
+		files := make([]PlanFile, len(n.Artifacts))
+		for j, a := range n.Artifacts {
+			files[j] = PlanFile{HostPath: a.HostPath, ContainerPath: a.ContainerPath, Content: string(a.Content)}
+		}
+		plan.Nodes[i] = PlanNode{
+			NodeID:      n.Node.ID,
+			Type:        n.Node.Type,
+			Name:        n.Node.Name,
+			Hostname:    n.Config.Hostname,
+			Level:       levelOf[n.Node.ID],
+			DependsOn:   n.DependsOn,
+			Config:      n.Config,
+			InjectedEnv: n.InjectedEnv,
+			Files:       files,
+		}
+	}
+
+	m.mu.Lock()
+	deployed := m.liveNodesLocked()
+	if len(deployed) > 0 {
+		plan.DeployedDiagramID = m.status.DiagramID
+	}
+	m.mu.Unlock()
+
+	plan.Changes = diffNodes(deployed, nodes)
+	return plan, nil
+}
+
+// liveNodesLocked returns the deployed nodes that have a container. Caller
+// must hold mu.
+func (m *Manager) liveNodesLocked() []templates.TranslatedNode {
+	created := make(map[string]bool, len(m.status.Nodes))
+	for _, n := range m.status.Nodes {
+		if n.ContainerID != "" {
+			created[n.NodeID] = true
+		}
+	}
+	var live []templates.TranslatedNode
+	for _, n := range m.deployed {
+		if created[n.Node.ID] {
+			live = append(live, n)
+		}
+	}
+	return live
+}
+
+// translateNodes translates d into a scratch spec directory and rewrites the
+// generated host paths to the shared spec directory. Nothing is written
+// there: Deploy writes the artifacts itself so it can roll them back, and
+// Plan never writes them at all.
+func (m *Manager) translateNodes(d model.Diagram) ([]templates.TranslatedNode, error) {
+	dir, err := os.MkdirTemp("", "heph-plan-*")
+	if err != nil {
+		return nil, fmt.Errorf("create plan spec directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	nodes, err := templates.NewTranslatorWithSpecDir(dir).WithMockImage(m.mockImage).TranslateNodes(d)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := templates.DefaultSpecDir()
+	rebase := func(p string) string {
+		if rel, err := filepath.Rel(dir, p); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join(shared, rel)
+		}
+		return p
+	}
+	for i := range nodes {
+		if len(nodes[i].Config.Volumes) > 0 {
+			volumes := make(map[string]string, len(nodes[i].Config.Volumes))
+			for host, ctr := range nodes[i].Config.Volumes {
+				volumes[rebase(host)] = ctr
+			}
+			nodes[i].Config.Volumes = volumes
+		}
+		for j := range nodes[i].Artifacts {
+			nodes[i].Artifacts[j].HostPath = rebase(nodes[i].Artifacts[j].HostPath)
+		}
+	}
+	return nodes, nil
+}
+
+// startupLevels assigns each node the length of its longest dependency chain
+// and groups node IDs by that level. nodes must be in dependency order.
+func startupLevels(nodes []templates.TranslatedNode) ([][]string, map[string]int) {
+	levelOf := make(map[string]int, len(nodes))
+	levels := [][]string{}
+	for _, n := range nodes {
+		level := 0
+		for _, dep := range n.DependsOn {
+			level = max(level, levelOf[dep]+1)
+		}
+		levelOf[n.Node.ID] = level
+		for len(levels) <= level {
+			levels = append(levels, []string{})
+		}
+		levels[level] = append(levels[level], n.Node.ID)
+	}
+	return levels, levelOf
+}
+
+// diffNodes compares the deployed nodes with the planned ones by node ID.
+// Creates, updates and recreates follow planned startup order; deletes
+// follow, in reverse deployed order.
+func diffNodes(deployed, planned []templates.TranslatedNode) []PlanChange {
+	current := make(map[string]templates.TranslatedNode, len(deployed))
+	for _, n := range deployed {
+		current[n.Node.ID] = n
+	}
+
+	changes := []PlanChange{}
+	keep := make(map[string]bool, len(planned))
+	for _, n := range planned {
+		keep[n.Node.ID] = true
+		old, ok := current[n.Node.ID]
+		if !ok {
+			changes = append(changes, PlanChange{Action: ActionCreate, NodeID: n.Node.ID, Name: n.Config.Name})
+			continue
+		}
+		if fields := changedFields(old, n); len(fields) > 0 {
+			changes = append(changes, PlanChange{Action: ActionUpdate, NodeID: n.Node.ID, Name: n.Config.Name, Fields: fields})
+			continue
+		}
+		changes = append(changes, PlanChange{Action: ActionRecreate, NodeID: n.Node.ID, Name: n.Config.Name})
+	}
+	for i := len(deployed) - 1; i >= 0; i-- {
+		if n := deployed[i]; !keep[n.Node.ID] {
+			changes = append(changes, PlanChange{Action: ActionDelete, NodeID: n.Node.ID, Name: n.Config.Name})
+		}
+	}
+	return changes
+}
+
+// changedFields lists the container settings that differ between two
+// translations of the same node, using the ContainerConfig JSON names.
+func changedFields(old, updated templates.TranslatedNode) []string {
+	a, b := old.Config, updated.Config
+	var fields []string
+	check := func(name string, equal bool) {
+		if !equal {
+			fields = append(fields, name)
+		}
+	}
+	check("image", a.Image == b.Image)
+	check("name", a.Name == b.Name)
+	check("cmd", slices.Equal(a.Cmd, b.Cmd))
+	check("entrypoint", slices.Equal(a.Entrypoint, b.Entrypoint))
+	check("env", maps.Equal(a.Env, b.Env))
+	check("ports", maps.Equal(a.Ports, b.Ports))
+	check("volumes", maps.Equal(a.Volumes, b.Volumes))
+	check("hostname", a.Hostname == b.Hostname)
+	check("networkName", a.NetworkName == b.NetworkName)
+	check("healthcheck", reflect.DeepEqual(a.Healthcheck, b.Healthcheck))
+	check("files", slices.EqualFunc(old.Artifacts, updated.Artifacts, func(x, y templates.Artifact) bool {
+		return x.ContainerPath == y.ContainerPath && bytes.Equal(x.Content, y.Content)
+	}))
+	return fields
+}
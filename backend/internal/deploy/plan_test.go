@@ -0,0 +1,150 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+func TestPlan_NothingDeployed(t *testing.T) {
+	m, sim, _ := newTestManager(docker.SimulationConfig{})
+
+	d := testDiagram()
+	d.Nodes = append(d.Nodes, model.DiagramNode{ID: "spec", Type: model.ServiceTypeAPIService, Name: "Spec", Position: &model.Position{}})
+	d.Edges = append(d.Edges, model.DiagramEdge{ID: "e2", Source: "spec", Target: "api"})
+
+	plan, err := m.Plan(d)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	wantLevels := [][]string{{"db"}, {"api"}, {"spec"}}
+	if len(plan.Levels) != len(wantLevels) {
+		t.Fatalf("expected levels %v, got %v", wantLevels, plan.Levels)
+	}
+	for i := range wantLevels {
+		if !slices.Equal(plan.Levels[i], wantLevels[i]) {
+			t.Errorf("level %d: expected %v, got %v", i, wantLevels[i], plan.Levels[i])
+		}
+	}
+
+	api := plan.Nodes[1]
+	if api.NodeID != "api" || api.Level != 1 || len(api.Config.Ports) != 1 {
+		t.Errorf("unexpected api node %+v", api)
+	}
+	if api.Name != "API" || api.Hostname != "api" {
+		t.Errorf("expected name API mapped to hostname api, got %q → %q", api.Name, api.Hostname)
+	}
+	if api.InjectedEnv["DB_HOST"] != "db" || api.Config.Env["DB_HOST"] != "db" {
+		t.Errorf("expected DB_HOST injected, got %v", api.InjectedEnv)
+	}
+
+	spec := plan.Nodes[2]
+	if len(spec.Files) != 1 || !strings.Contains(spec.Files[0].Content, "openapi") {
+		t.Fatalf("expected generated OpenAPI spec, got %+v", spec.Files)
+	}
+	if !strings.HasPrefix(spec.Files[0].HostPath, templates.DefaultSpecDir()) {
+		t.Errorf("expected host path under the shared spec dir, got %q", spec.Files[0].HostPath)
+	}
+	if spec.Config.Volumes[spec.Files[0].HostPath] == "" {
+		t.Errorf("expected volume keyed by the reported host path, got %v", spec.Config.Volumes)
+	}
+
+	if plan.DeployedDiagramID != "" {
+		t.Errorf("expected no deployed diagram, got %q", plan.DeployedDiagramID)
+	}
+	if len(plan.Changes) != 3 {
+		t.Fatalf("expected 3 changes, got %+v", plan.Changes)
+	}
+	for _, c := range plan.Changes {
+		if c.Action != ActionCreate {
+			t.Errorf("expected create for %q, got %q", c.NodeID, c.Action)
+		}
+	}
+
+	infos, _ := sim.ListContainers(context.Background())
+	if len(infos) != 0 {
+		t.Errorf("plan must not create containers, got %d", len(infos))
+	}
+}
+
+func TestPlan_DiffAgainstDeployment(t *testing.T) {
+	m, _, _ := newTestManager(docker.SimulationConfig{})
+	if _, err := m.Deploy(context.Background(), testDiagram()); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+
+	plan, err := m.Plan(testDiagram())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if plan.DeployedDiagramID != "d1" {
+		t.Errorf("expected deployed diagram d1, got %q", plan.DeployedDiagramID)
+	}
+	if len(plan.Changes) != 2 || plan.Changes[0].Action != ActionRecreate || plan.Changes[1].Action != ActionRecreate {
+		t.Errorf("expected every node of an identical diagram to be recreated, got %+v", plan.Changes)
+	}
+
+	d := testDiagram()
+	d.Nodes[0].Config = []byte(`{"type":"custom-container","image":"example/api:2","ports":[8080]}`)
+	d.Nodes[1] = model.DiagramNode{ID: "cache", Type: model.ServiceTypeRedis, Name: "Cache", Position: &model.Position{}}
+	d.Edges = []model.DiagramEdge{{ID: "e1", Source: "api", Target: "cache"}}
+
+	plan, err = m.Plan(d)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	actions := map[string]PlanChange{}
+	for _, c := range plan.Changes {
+		actions[c.NodeID] = c
+	}
+	if actions["cache"].Action != ActionCreate {
+		t.Errorf("expected cache to be created, got %+v", actions["cache"])
+	}
+	if actions["db"].Action != ActionDelete {
+		t.Errorf("expected db to be deleted, got %+v", actions["db"])
+	}
+	api := actions["api"]
+	if api.Action != ActionUpdate || !slices.Contains(api.Fields, "image") || !slices.Contains(api.Fields, "env") {
+		t.Errorf("expected api update of image and env, got %+v", api)
+	}
+	if last := plan.Changes[len(plan.Changes)-1]; last.Action != ActionDelete {
+		t.Errorf("expected deletes last, got %+v", plan.Changes)
+	}
+}
+
+func TestPlan_AfterRolledBackDeploy(t *testing.T) {
+	m, _, _ := newTestManager(docker.SimulationConfig{
+		Failures: map[string]docker.SimFailure{"api": docker.SimFailCreate},
+	})
+	if _, err := m.Deploy(context.Background(), testDiagram()); err == nil {
+		t.Fatal("expected deploy to fail")
+	}
+
+	plan, err := m.Plan(testDiagram())
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if plan.DeployedDiagramID != "" {
+		t.Errorf("expected nothing deployed after rollback, got %q", plan.DeployedDiagramID)
+	}
+	if len(plan.Changes) != 2 || plan.Changes[0].Action != ActionCreate || plan.Changes[1].Action != ActionCreate {
+		t.Errorf("expected every node to be created, got %+v", plan.Changes)
+	}
+}
+
+func TestPlan_TranslationError(t *testing.T) {
+	m, _, _ := newTestManager(docker.SimulationConfig{})
+	d := testDiagram()
+	d.Edges = append(d.Edges, model.DiagramEdge{ID: "e2", Source: "db", Target: "api"})
+	if _, err := m.Plan(d); !errors.Is(err, ErrTranslation) {
+		t.Errorf("expected ErrTranslation, got %v", err)
+	}
+}
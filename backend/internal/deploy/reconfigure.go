@@ -0,0 +1,98 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// ErrNotDeployed is returned by Reconfigure when no deployment is running.
+var ErrNotDeployed = errors.New("no deployment is running")
+
+// ErrRedeployRequired is wrapped by Reconfigure errors for changes that
+// need containers to be recreated.
+var ErrRedeployRequired = errors.New("change requires a redeploy")
+
+// Reconfiguration reports what Reconfigure changed.
+type Reconfiguration struct {
+	// Updated lists the IDs of the nodes whose generated files were
+	// rewritten, in startup order.
+	Updated []string `json:"updated"`
+	Status  Status   `json:"status"`
+}
+
+// Reconfigure applies d to the running deployment of the same diagram
+// without recreating any container, when the only differences are in
+// generated files, such as the spec carrying an api-service's simulated
+// latency and errors. The files are rewritten in place, so their bind
+// mounts see the new content; the mock server reloads its spec when it
+// changes. Any other difference returns ErrRedeployRequired and nothing is
+// written. If a write fails, the files already rewritten are restored and
+// the returned *DeployError carries the rollback report.
+func (m *Manager) Reconfigure(d model.Diagram) (Reconfiguration, error) {
+	m.opMu.Lock()
+	defer m.opMu.Unlock()
+
+	nodes, err := m.translateNodes(d)
+	if err != nil {
+		return Reconfiguration{}, fmt.Errorf("%w: %v", ErrTranslation, err)
+	}
+
+	m.mu.Lock()
+	state, diagramID := m.status.State, m.status.DiagramID
+	deployed := m.liveNodesLocked()
+	m.mu.Unlock()
+	if state != StateDeployed {
+		return Reconfiguration{}, ErrNotDeployed
+	}
+	if diagramID != d.ID {
+		return Reconfiguration{}, fmt.Errorf("%w: diagram %q is deployed, not %q", ErrRedeployRequired, diagramID, d.ID)
+	}
+
+	updated := []string{}
+	for _, c := range diffNodes(deployed, nodes) {
+		if c.Action == ActionRecreate {
+			continue
+		}
+		if c.Action != ActionUpdate || !slices.Equal(c.Fields, []string{"files"}) {
+			return Reconfiguration{}, fmt.Errorf("%w: %s", ErrRedeployRequired, describeChange(c))
+		}
+		updated = append(updated, c.NodeID)
+	}
+
+	byID := make(map[string]templates.TranslatedNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.Node.ID] = n
+	}
+	tx := &transaction{}
+	for _, id := range updated {
+		for _, a := range byID[id].Artifacts {
+			if err := tx.writeFile(id, a.HostPath, a.Content); err != nil {
+				report := tx.rollback(context.Background())
+				return Reconfiguration{}, &DeployError{Err: fmt.Errorf("write generated file for node %q: %w", id, err), Rollback: report}
+			}
+		}
+	}
+
+	m.mu.Lock()
+	m.deployed = nodes
+	m.diagram = &d
+	m.mu.Unlock()
+	m.recordReconfigured(d)
+	return Reconfiguration{Updated: updated, Status: m.Status()}, nil
+}
+
+// describeChange renders a plan change for an error message, for example
+// "update api (env, files)".
+func describeChange(c PlanChange) string {
+	s := string(c.Action) + " " + c.NodeID
+	if len(c.Fields) > 0 {
+		s += " (" + strings.Join(c.Fields, ", ") + ")"
+	}
+	return s
+}
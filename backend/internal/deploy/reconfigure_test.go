@@ -0,0 +1,164 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// faultDiagram is a diagram with an api-service whose endpoint fails at
+// the given rate.
+func faultDiagram(rate string) model.Diagram {
+	d := testDiagram()
+	d.Nodes = append(d.Nodes, model.DiagramNode{
+		ID:       "orders",
+		Type:     model.ServiceTypeAPIService,
+		Name:     "Orders",
+		Position: &model.Position{},
+		Config:   []byte(`{"type":"api-service","endpoints":[{"method":"GET","path":"/orders","errors":{"rate":` + rate + `}}]}`),
+	})
+	return d
+}
+
+func TestManager_ReconfigureRewritesSpecs(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	m, sim, _ := newTestManager(docker.SimulationConfig{})
+	if _, err := m.Deploy(context.Background(), faultDiagram("0.1")); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	specPath := filepath.Join(templates.DefaultSpecDir(), "orders.json")
+	before, err := os.Stat(specPath)
+	if err != nil {
+		t.Fatalf("stat spec: %v", err)
+	}
+	containers, _ := sim.ListContainers(context.Background())
+
+	result, err := m.Reconfigure(faultDiagram("0.5"))
+	if err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	if len(result.Updated) != 1 || result.Updated[0] != "orders" {
+		t.Errorf("expected orders to be updated, got %v", result.Updated)
+	}
+	spec, _ := os.ReadFile(specPath)
+	if !strings.Contains(string(spec), `"rate": 0.5`) {
+		t.Errorf("expected the spec to carry the new error rate, got %s", spec)
+	}
+	if after, _ := os.Stat(specPath); !os.SameFile(before, after) {
+		t.Error("expected the spec to be rewritten in place so its bind mount sees it")
+	}
+	if after, _ := sim.ListContainers(context.Background()); len(after) != len(containers) || after[0].ID != containers[0].ID {
+		t.Errorf("expected containers to be kept, had %+v, now %+v", containers, after)
+	}
+
+	plan, err := m.Plan(faultDiagram("0.5"))
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	for _, c := range plan.Changes {
+		if c.Action != ActionRecreate {
+			t.Errorf("expected the reconfigured diagram to match the deployment, got %+v", c)
+		}
+	}
+
+	result, err = m.Reconfigure(faultDiagram("0.5"))
+	if err != nil || len(result.Updated) != 0 {
+		t.Errorf("expected nothing to update, got %v (%v)", result.Updated, err)
+	}
+}
+
+func TestManager_ReconfigureRestoresFilesWhenAWriteFails(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	m, _, _ := newTestManager(docker.SimulationConfig{})
+	d := faultDiagram("0.1")
+	d.Nodes = append(d.Nodes, model.DiagramNode{
+		ID:       "payments",
+		Type:     model.ServiceTypeAPIService,
+		Name:     "Payments",
+		Position: &model.Position{},
+		Config:   []byte(`{"type":"api-service","endpoints":[{"method":"GET","path":"/pay","errors":{"rate":0.1}}]}`),
+	})
+	if _, err := m.Deploy(context.Background(), d); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+
+	// Break whichever spec is rewritten last, so the first is rolled back.
+	var order []string
+	for _, n := range m.Status().Nodes {
+		if n.NodeID == "orders" || n.NodeID == "payments" {
+			order = append(order, n.Name)
+		}
+	}
+	first := filepath.Join(templates.DefaultSpecDir(), order[0]+".json")
+	last := filepath.Join(templates.DefaultSpecDir(), order[1]+".json")
+	spec, _ := os.ReadFile(first)
+	if err := os.Remove(last); err != nil {
+		t.Fatalf("remove spec: %v", err)
+	}
+	if err := os.Mkdir(last, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	changed := faultDiagram("0.5")
+	changed.Nodes = append(changed.Nodes, d.Nodes[len(d.Nodes)-1])
+	changed.Nodes[len(changed.Nodes)-1].Config = []byte(`{"type":"api-service","endpoints":[{"method":"GET","path":"/pay","errors":{"rate":0.5}}]}`)
+	_, err := m.Reconfigure(changed)
+	var deployErr *DeployError
+	if !errors.As(err, &deployErr) {
+		t.Fatalf("expected a DeployError, got %v", err)
+	}
+	if !deployErr.Rollback.Complete || len(deployErr.Rollback.Steps) != 1 || deployErr.Rollback.Steps[0].Action != RollbackRestoreFile {
+		t.Errorf("expected one completed restore-file step, got %+v", deployErr.Rollback)
+	}
+	if after, _ := os.ReadFile(first); string(after) != string(spec) {
+		t.Error("expected the rewritten spec to be restored")
+	}
+	plan, err := m.Plan(d)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	for _, c := range plan.Changes {
+		if c.Action != ActionRecreate {
+			t.Errorf("expected the deployment to keep its previous configuration, got %+v", c)
+		}
+	}
+}
+
+func TestManager_ReconfigureRejectsStructuralChanges(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	m, _, _ := newTestManager(docker.SimulationConfig{})
+	if _, err := m.Reconfigure(faultDiagram("0.1")); !errors.Is(err, ErrNotDeployed) {
+		t.Errorf("expected ErrNotDeployed, got %v", err)
+	}
+	if _, err := m.Deploy(context.Background(), faultDiagram("0.1")); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	specPath := filepath.Join(templates.DefaultSpecDir(), "orders.json")
+	spec, _ := os.ReadFile(specPath)
+
+	changed := faultDiagram("0.5")
+	changed.Nodes[0].Config = []byte(`{"type":"custom-container","image":"example/api:2","ports":[8080]}`)
+	other := faultDiagram("0.5")
+	other.ID = "d2"
+	for name, d := range map[string]model.Diagram{"image change": changed, "other diagram": other} {
+		if _, err := m.Reconfigure(d); !errors.Is(err, ErrRedeployRequired) {
+			t.Errorf("%s: expected ErrRedeployRequired, got %v", name, err)
+		}
+	}
+	if after, _ := os.ReadFile(specPath); string(after) != string(spec) {
+		t.Error("expected no file to be written when a redeploy is required")
+	}
+
+	bad := faultDiagram("0.1")
+	bad.Nodes[0].Type = "unknown"
+	if _, err := m.Reconfigure(bad); !errors.Is(err, ErrTranslation) {
+		t.Errorf("expected ErrTranslation, got %v", err)
+	}
+}
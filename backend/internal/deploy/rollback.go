@@ -0,0 +1,196 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// Rollback step actions, in the vocabulary of the resource being undone.
+const (
+	RollbackStopContainer   = "stop-container"
+	RollbackRemoveContainer = "remove-container"
+	RollbackRemoveNetwork   = "remove-network"
+	RollbackRemoveFile      = "remove-file"
+	RollbackRestoreFile     = "restore-file"
+	// RollbackRestoreNetwork and RollbackRestoreContainer recreate the
+	// deployment a failed deploy replaced.
+	RollbackRestoreNetwork   = "restore-network"
+	RollbackRestoreContainer = "restore-container"
+)
+
+// RollbackStep is one undo action performed after a failed deploy.
+type RollbackStep struct {
+	Action string `json:"action"`
+	// Target is the container name, network name or file path undone or
+	// restored.
+	Target string `json:"target"`
+	NodeID string `json:"nodeId,omitempty"`
+	// Error is set when the undo action itself failed.
+	Error string `json:"error,omitempty"`
+}
+
+// RollbackReport lists the undo actions of a failed deploy in the order they
+// ran, which is the reverse of the order the resources were created.
+type RollbackReport struct {
+	Steps []RollbackStep `json:"steps"`
+	// Complete is false if any step failed and resources may remain.
+	Complete bool `json:"complete"`
+}
+
+// DeployError is returned by Deploy when a step fails after the deployment
+// started, and by Reconfigure when rewriting a file fails. It wraps the
+// original error and reports what was rolled back.
+type DeployError struct {
+	Err      error
+	Rollback RollbackReport
+}
+
+func (e *DeployError) Error() string { return e.Err.Error() }
+
+func (e *DeployError) Unwrap() error { return e.Err }
+
+// transaction journals the resources a deploy creates so they can be undone
+// in reverse order.
+type transaction struct {
+	undos []undoAction
+	// previous is the deployment the transaction replaced, if any; its
+	// restore is journalled first, so it is undone last.
+	previous *previousDeployment
+}
+
+type undoAction struct {
+	step RollbackStep
+	run  func(ctx context.Context) error
+}
+
+// record journals an undo action for a resource that was just created.
+func (tx *transaction) record(step RollbackStep, run func(ctx context.Context) error) {
+	tx.undos = append(tx.undos, undoAction{step: step, run: run})
+}
+
+// rollback runs every undo action in reverse order. It keeps going past
+// failures so as much as possible is cleaned up, and runs even if ctx was
+// cancelled.
+func (tx *transaction) rollback(ctx context.Context) RollbackReport {
+	ctx = context.WithoutCancel(ctx)
+	report := RollbackReport{Steps: make([]RollbackStep, 0, len(tx.undos)), Complete: true}
+	for i := len(tx.undos) - 1; i >= 0; i-- {
+		u := tx.undos[i]
+		step := u.step
+		if err := u.run(ctx); err != nil {
+			step.Error = err.Error()
+			report.Complete = false
+		}
+		report.Steps = append(report.Steps, step)
+	}
+	return report
+}
+
+// writeFile writes content to path as part of tx. A file that did not exist
+// is removed on rollback; an existing file has its previous content restored.
+func (tx *transaction) writeFile(nodeID, path string, content []byte) error {
+	previous, err := os.ReadFile(path)
+	existed := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read %q: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create directory for %q: %w", path, err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("write %q: %w", path, err)
+	}
+
+	if existed {
+		tx.record(RollbackStep{Action: RollbackRestoreFile, Target: path, NodeID: nodeID}, func(context.Context) error {
+			return os.WriteFile(path, previous, 0o644)
+		})
+		return nil
+	}
+	tx.record(RollbackStep{Action: RollbackRemoveFile, Target: path, NodeID: nodeID}, func(context.Context) error {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+	return nil
+}
+
+// previousDeployment is a running deployment a deploy replaces, kept so a
+// failed deploy can put it back.
+type previousDeployment struct {
+	nodes   []templates.TranslatedNode
+	diagram *model.Diagram
+	status  Status
+	record  *model.Deployment
+	// containerIDs maps node IDs to the containers recreated on restore.
+	containerIDs map[string]string
+}
+
+// previousLocked returns the running deployment, or nil when nothing is
+// deployed. Caller must hold opMu.
+func (m *Manager) previousLocked() *previousDeployment {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.status.State != StateDeployed {
+		return nil
+	}
+	return &previousDeployment{
+		nodes:        m.liveNodesLocked(),
+		diagram:      m.diagram,
+		status:       m.status.clone(),
+		record:       m.record,
+		containerIDs: make(map[string]string, len(m.deployed)),
+	}
+}
+
+// journalRestore records the undo actions that recreate tx.previous after
+// it has been torn down: the network first, then its containers in startup
+// order, labelled with the previous deployment's ID. Caller must hold opMu.
+func (m *Manager) journalRestore(tx *transaction) {
+	prev := tx.previous
+	if prev == nil {
+		return
+	}
+	for i := len(prev.nodes) - 1; i >= 0; i-- {
+		n := prev.nodes[i]
+		tx.record(RollbackStep{Action: RollbackRestoreContainer, Target: n.Config.Name, NodeID: n.Node.ID}, func(ctx context.Context) error {
+			id, err := m.orch.CreateContainer(ctx, labelled(n, prev.status.DeploymentID))
+			if err != nil {
+				return err
+			}
+			prev.containerIDs[n.Node.ID] = id
+			return m.orch.StartContainer(ctx, id)
+		})
+	}
+	tx.record(RollbackStep{Action: RollbackRestoreNetwork, Target: docker.NetworkName}, m.orch.CreateNetwork)
+}
+
+// reinstate makes a restored deployment current again. Its status keeps
+// the error and rollback report of the deploy that failed to replace it.
+// Caller must hold opMu.
+func (m *Manager) reinstate(prev *previousDeployment, err error, report RollbackReport) {
+	m.recordRestored(prev.record, prev.containerIDs)
+	m.mu.Lock()
+	m.deployed = prev.nodes
+	m.diagram = prev.diagram
+	m.mu.Unlock()
+	m.update(func(s *Status) {
+		*s = prev.status
+		s.Error = err.Error()
+		s.Rollback = &report
+		for i := range s.Nodes {
+			s.Nodes[i].ContainerID = prev.containerIDs[s.Nodes[i].NodeID]
+			s.Nodes[i].Status = docker.StatusRunning
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// ShutdownPolicy selects what happens to the deployment when the backend
+// stops.
+type ShutdownPolicy string
+
+const (
+	// ShutdownTeardown removes every container and the shared network. It is
+	// the default.
+	ShutdownTeardown ShutdownPolicy = "teardown"
+	// ShutdownDetach leaves the deployment running. The next start
+	// reattaches to it instead of treating it as orphaned.
+	ShutdownDetach ShutdownPolicy = "detach"
+)
+
+// ParseShutdownPolicy parses a policy name. The empty string selects
+// ShutdownTeardown.
+func ParseShutdownPolicy(s string) (ShutdownPolicy, error) {
+	switch ShutdownPolicy(s) {
+	case "", ShutdownTeardown:
+		return ShutdownTeardown, nil
+	case ShutdownDetach:
+		return ShutdownDetach, nil
+	}
+	return "", fmt.Errorf("unknown shutdown policy %q: must be %s or %s", s, ShutdownTeardown, ShutdownDetach)
+}
+
+// Shutdown applies policy to the current deployment as the backend stops.
+// It waits for a deploy in progress to finish. With ShutdownDetach the
+// containers, network and generated files are left alone and the history
+// record is marked detached, so that the next Sweep reattaches to it.
+func (m *Manager) Shutdown(ctx context.Context, policy ShutdownPolicy) error {
+	if policy != ShutdownDetach {
+		_, err := m.Teardown(ctx)
+		return err
+	}
+
+	m.opMu.Lock()
+	defer m.opMu.Unlock()
+
+	if m.record == nil || m.record.Status != model.DeploymentDeployed || m.record.EndedAt != nil {
+		log.Printf("detaching with no running deployment to reattach to")
+		return nil
+	}
+	now := time.Now().UTC()
+	m.record.DetachedAt = &now
+	m.saveRecord()
+	log.Printf("detached from deployment %s; its containers keep running", m.record.ID)
+	return nil
+}
@@ -0,0 +1,122 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+func TestParseShutdownPolicy(t *testing.T) {
+	for in, want := range map[string]ShutdownPolicy{"": ShutdownTeardown, "teardown": ShutdownTeardown, "detach": ShutdownDetach} {
+		got, err := ParseShutdownPolicy(in)
+		if err != nil || got != want {
+			t.Errorf("ParseShutdownPolicy(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseShutdownPolicy("pause"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestShutdown_Teardown(t *testing.T) {
+	m, store := newHistoryManager(t, docker.SimulationConfig{})
+	ctx := context.Background()
+	status, err := m.Deploy(ctx, testDiagram())
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+
+	if err := m.Shutdown(ctx, ShutdownTeardown); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if infos, _ := m.orch.ListContainers(ctx); len(infos) != 0 {
+		t.Errorf("expected no containers, got %d", len(infos))
+	}
+	record, _ := store.Get(status.DeploymentID)
+	if record.Status != model.DeploymentTornDown || record.DetachedAt != nil {
+		t.Errorf("expected torn-down record, got %+v", record)
+	}
+}
+
+func TestShutdown_DetachThenReattach(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	m, store := newHistoryManager(t, docker.SimulationConfig{})
+	ctx := context.Background()
+	deployed, err := m.Deploy(ctx, orphanDiagram())
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+
+	if err := m.Shutdown(ctx, ShutdownDetach); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	record, _ := store.Get(deployed.DeploymentID)
+	if record.Status != model.DeploymentDeployed || record.DetachedAt == nil || record.EndedAt != nil {
+		t.Fatalf("expected detached record, got %+v", record)
+	}
+	if infos, _ := m.orch.ListContainers(ctx); len(infos) != 3 {
+		t.Fatalf("expected containers to keep running, got %d", len(infos))
+	}
+
+	// The remove policy applies to orphans only; the detached deployment
+	// is reattached.
+	next := NewManager(m.orch, store, docker.ModeSimulated, nil)
+	report, err := next.Sweep(ctx, OrphanRemove)
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if report.ReattachedDeploymentID != deployed.DeploymentID || len(report.ClosedDeployments) != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	for _, r := range report.Resources {
+		if r.Action == OrphanActionRemoved || r.Error != "" {
+			t.Errorf("expected %s %q to be kept, got %+v", r.Kind, r.Name, r)
+		}
+	}
+
+	status := next.Status()
+	if status.State != StateDeployed || status.DeploymentID != deployed.DeploymentID || len(status.Nodes) != len(deployed.Nodes) {
+		t.Errorf("unexpected reattached status %+v", status)
+	}
+	for i, n := range status.Nodes {
+		if n.ContainerID != deployed.Nodes[i].ContainerID {
+			t.Errorf("node %q: expected container %q, got %q", n.NodeID, deployed.Nodes[i].ContainerID, n.ContainerID)
+		}
+	}
+	record, _ = store.Get(deployed.DeploymentID)
+	if record.DetachedAt != nil || record.EndedAt != nil {
+		t.Errorf("expected live record after reattaching, got %+v", record)
+	}
+}
+
+func TestShutdown_DetachedDeploymentGone(t *testing.T) {
+	m, store := newHistoryManager(t, docker.SimulationConfig{})
+	ctx := context.Background()
+	deployed, err := m.Deploy(ctx, testDiagram())
+	if err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	if err := m.Shutdown(ctx, ShutdownDetach); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	// A fresh orchestrator has none of the detached containers, as after a
+	// restart in simulated mode.
+	next := NewManager(docker.NewSimulatedOrchestrator(docker.SimulationConfig{}), store, docker.ModeSimulated, nil)
+	report, err := next.Sweep(ctx, OrphanRemove)
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if report.ReattachedDeploymentID != "" || len(report.ClosedDeployments) != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	record, _ := store.Get(deployed.DeploymentID)
+	if record.Status != model.DeploymentTornDown || record.EndedAt == nil || record.Error != detachedRecordError {
+		t.Errorf("expected closed record, got %+v", record)
+	}
+	if status := next.Status(); status.State != StateIdle {
+		t.Errorf("expected idle state, got %q", status.State)
+	}
+}
@@ -16,44 +16,98 @@ import (
 // simpler interface.
 type sdkClientAdapter struct {
 	cli *client.Client
+	// onError, when set, is called for every failed call.
+	onError APIErrorHook
+}
+
+// APIErrorHook is called with the SDK method name, such as
+// "ContainerCreate", and the error of every Docker API call that fails.
+type APIErrorHook func(operation string, err error)
+
+// observe reports err to the error hook and returns it.
+func (a *sdkClientAdapter) observe(operation string, err error) error {
+	if err != nil && a.onError != nil {
+		a.onError(operation, err)
+	}
+	return err
 }
 
 func (a *sdkClientAdapter) NetworkCreate(ctx context.Context, name string, options network.CreateOptions) (network.CreateResponse, error) {
-	return a.cli.NetworkCreate(ctx, name, options)
+	resp, err := a.cli.NetworkCreate(ctx, name, options)
+	return resp, a.observe("NetworkCreate", err)
 }
 
 func (a *sdkClientAdapter) NetworkList(ctx context.Context, options network.ListOptions) ([]network.Summary, error) {
-	return a.cli.NetworkList(ctx, options)
+	resp, err := a.cli.NetworkList(ctx, options)
+	return resp, a.observe("NetworkList", err)
 }
 
 func (a *sdkClientAdapter) NetworkRemove(ctx context.Context, networkID string) error {
-	return a.cli.NetworkRemove(ctx, networkID)
+	return a.observe("NetworkRemove", a.cli.NetworkRemove(ctx, networkID))
+}
+
+func (a *sdkClientAdapter) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	return a.observe("NetworkConnect", a.cli.NetworkConnect(ctx, networkID, containerID, config))
+}
+
+func (a *sdkClientAdapter) NetworkDisconnect(ctx context.Context, networkID, containerID string, force bool) error {
+	return a.observe("NetworkDisconnect", a.cli.NetworkDisconnect(ctx, networkID, containerID, force))
 }
 
 func (a *sdkClientAdapter) ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
-	return a.cli.ImagePull(ctx, refStr, options)
+	resp, err := a.cli.ImagePull(ctx, refStr, options)
+	return resp, a.observe("ImagePull", err)
+}
+
+func (a *sdkClientAdapter) ImageInspect(ctx context.Context, imageID string) (image.InspectResponse, error) {
+	resp, err := a.cli.ImageInspect(ctx, imageID)
+	return resp, a.observe("ImageInspect", err)
 }
 
 func (a *sdkClientAdapter) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.CreateResponse, error) {
-	return a.cli.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, containerName)
+	resp, err := a.cli.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, containerName)
+	return resp, a.observe("ContainerCreate", err)
 }
 
 func (a *sdkClientAdapter) ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error {
-	return a.cli.ContainerStart(ctx, containerID, options)
+	return a.observe("ContainerStart", a.cli.ContainerStart(ctx, containerID, options))
 }
 
 func (a *sdkClientAdapter) ContainerStop(ctx context.Context, containerID string, options container.StopOptions) error {
-	return a.cli.ContainerStop(ctx, containerID, options)
+	return a.observe("ContainerStop", a.cli.ContainerStop(ctx, containerID, options))
 }
 
 func (a *sdkClientAdapter) ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error {
-	return a.cli.ContainerRemove(ctx, containerID, options)
+	return a.observe("ContainerRemove", a.cli.ContainerRemove(ctx, containerID, options))
+}
+
+func (a *sdkClientAdapter) ContainerKill(ctx context.Context, containerID, signal string) error {
+	return a.observe("ContainerKill", a.cli.ContainerKill(ctx, containerID, signal))
+}
+
+func (a *sdkClientAdapter) ContainerPause(ctx context.Context, containerID string) error {
+	return a.observe("ContainerPause", a.cli.ContainerPause(ctx, containerID))
+}
+
+func (a *sdkClientAdapter) ContainerUnpause(ctx context.Context, containerID string) error {
+	return a.observe("ContainerUnpause", a.cli.ContainerUnpause(ctx, containerID))
+}
+
+func (a *sdkClientAdapter) ContainerRestart(ctx context.Context, containerID string, options container.StopOptions) error {
+	return a.observe("ContainerRestart", a.cli.ContainerRestart(ctx, containerID, options))
 }
 
 func (a *sdkClientAdapter) ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
-	return a.cli.ContainerList(ctx, options)
+	resp, err := a.cli.ContainerList(ctx, options)
+	return resp, a.observe("ContainerList", err)
+}
+
+func (a *sdkClientAdapter) ContainerStats(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error) {
+	resp, err := a.cli.ContainerStats(ctx, containerID, stream)
+	return resp, a.observe("ContainerStats", err)
 }
 
 func (a *sdkClientAdapter) ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
-	return a.cli.ContainerInspect(ctx, containerID)
+	resp, err := a.cli.ContainerInspect(ctx, containerID)
+	return resp, a.observe("ContainerInspect", err)
 }
@@ -2,9 +2,12 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -37,15 +40,23 @@ type dockerAPIClient interface {
 	NetworkCreate(ctx context.Context, name string, options network.CreateOptions) (network.CreateResponse, error)
 	NetworkList(ctx context.Context, options network.ListOptions) ([]network.Summary, error)
 	NetworkRemove(ctx context.Context, networkID string) error
+	NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error
+	NetworkDisconnect(ctx context.Context, networkID, containerID string, force bool) error
 
 	// Container operations
 	ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
+	ImageInspect(ctx context.Context, imageID string) (image.InspectResponse, error)
 	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.CreateResponse, error)
 	ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error
 	ContainerStop(ctx context.Context, containerID string, options container.StopOptions) error
 	ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error
+	ContainerKill(ctx context.Context, containerID, signal string) error
+	ContainerPause(ctx context.Context, containerID string) error
+	ContainerUnpause(ctx context.Context, containerID string) error
+	ContainerRestart(ctx context.Context, containerID string, options container.StopOptions) error
 	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
 	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ContainerStats(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error)
 }
 
 // DockerOrchestrator manages Docker containers and networks via the Docker SDK.
@@ -54,13 +65,18 @@ type DockerOrchestrator struct {
 	mu                sync.Mutex
 	networkID         string
 	managedContainers map[string]string // container ID → name
+	// endpoints holds the shared-network settings of disconnected
+	// containers, keyed by container ID, for ConnectContainer to restore.
+	endpoints map[string]*network.EndpointSettings
+	events    *EventBus
 }
 
 // NewDockerOrchestrator creates an orchestrator using the provided Docker client.
 func NewDockerOrchestrator(c *Client) *DockerOrchestrator {
 	return &DockerOrchestrator{
-		api:               &sdkClientAdapter{c.cli},
+		api:               &sdkClientAdapter{cli: c.cli},
 		managedContainers: make(map[string]string),
+		events:            NewEventBus(),
 	}
 }
 
@@ -69,6 +85,17 @@ func newOrchestratorWithAPI(api dockerAPIClient) *DockerOrchestrator {
 	return &DockerOrchestrator{
 		api:               api,
 		managedContainers: make(map[string]string),
+		events:            NewEventBus(),
+	}
+}
+
+// OnAPIError registers hook to be called for every Docker API call that
+// fails, including failures the orchestrator handles itself, such as
+// removing a network that is already gone. It replaces any earlier hook and
+// must be called before the orchestrator is used.
+func (o *DockerOrchestrator) OnAPIError(hook APIErrorHook) {
+	if a, ok := o.api.(*sdkClientAdapter); ok {
+		a.onError = hook
 	}
 }
 
@@ -94,15 +121,34 @@ func (o *DockerOrchestrator) CreateNetwork(ctx context.Context) error {
 
 	resp, err := o.api.NetworkCreate(ctx, NetworkName, network.CreateOptions{
 		Driver: "bridge",
+		Labels: map[string]string{LabelManaged: "true"},
 	})
 	if err != nil {
 		return fmt.Errorf("create network %q: %w", NetworkName, err)
 	}
 
 	o.networkID = resp.ID
+	o.events.Publish(Event{Type: EventNetworkCreated, Name: NetworkName})
 	return nil
 }
 
+// NetworkExists reports whether the shared Docker bridge network exists,
+// whether or not this orchestrator created it.
+func (o *DockerOrchestrator) NetworkExists(ctx context.Context) (bool, error) {
+	existing, err := o.api.NetworkList(ctx, network.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("name", NetworkName)),
+	})
+	if err != nil {
+		return false, fmt.Errorf("list networks: %w", err)
+	}
+	for _, n := range existing {
+		if n.Name == NetworkName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // RemoveNetwork removes the shared Docker bridge network. Returns nil if the
 // network does not exist.
 func (o *DockerOrchestrator) RemoveNetwork(ctx context.Context) error {
@@ -122,22 +168,22 @@ func (o *DockerOrchestrator) RemoveNetwork(ctx context.Context) error {
 	}
 
 	o.networkID = ""
+	o.events.Publish(Event{Type: EventNetworkRemoved, Name: NetworkName})
 	return nil
 }
 
-// CreateContainer pulls the image (if needed), creates a container with the
-// given configuration, and connects it to the shared network.
+// CreateContainer runs the pre-create hooks, pulls the image (if needed),
+// creates a container with the resulting configuration, and connects it to
+// the shared network.
 func (o *DockerOrchestrator) CreateContainer(ctx context.Context, cfg ContainerConfig) (string, error) {
-	// Pull image — drain the reader to complete the pull.
-	reader, err := o.api.ImagePull(ctx, cfg.Image, image.PullOptions{})
+	cfg, err := o.events.runPreCreate(ctx, cfg)
 	if err != nil {
-		return "", fmt.Errorf("pull image %q: %w", cfg.Image, err)
+		return "", fmt.Errorf("create container %q: %w", ContainerNamePrefix+cfg.Name, err)
 	}
-	if _, err := io.Copy(io.Discard, reader); err != nil {
-		_ = reader.Close()
-		return "", fmt.Errorf("read image pull response for %q: %w", cfg.Image, err)
+
+	if err := o.pullImage(ctx, cfg.Image); err != nil {
+		return "", err
 	}
-	_ = reader.Close()
 
 	prefixedName := ContainerNamePrefix + cfg.Name
 
@@ -162,6 +208,12 @@ func (o *DockerOrchestrator) CreateContainer(ctx context.Context, cfg ContainerC
 		binds = append(binds, hostPath+":"+containerPath)
 	}
 
+	labels := make(map[string]string, len(cfg.Labels)+1)
+	for k, v := range cfg.Labels {
+		labels[k] = v
+	}
+	labels[LabelManaged] = "true"
+
 	// Determine hostname.
 	hostname := cfg.Hostname
 	if hostname == "" {
@@ -182,13 +234,17 @@ func (o *DockerOrchestrator) CreateContainer(ctx context.Context, cfg ContainerC
 		&container.Config{
 			Image:        cfg.Image,
 			Cmd:          cfg.Cmd,
+			Entrypoint:   cfg.Entrypoint,
 			Env:          env,
 			ExposedPorts: exposedPorts,
 			Hostname:     hostname,
+			Healthcheck:  toHealthConfig(cfg.Healthcheck),
+			Labels:       labels,
 		},
 		&container.HostConfig{
 			PortBindings: portBindings,
 			Binds:        binds,
+			ExtraHosts:   cfg.ExtraHosts,
 		},
 		networkCfg,
 		prefixedName,
@@ -201,14 +257,52 @@ func (o *DockerOrchestrator) CreateContainer(ctx context.Context, cfg ContainerC
 	o.managedContainers[resp.ID] = prefixedName
 	o.mu.Unlock()
 
+	o.events.created(ctx, resp.ID, prefixedName, cfg)
 	return resp.ID, nil
 }
 
+// pullImage pulls ref and drains the response to complete the pull. When
+// the pull fails but the image exists locally, such as an image built on
+// this host and never pushed, the local copy is used.
+func (o *DockerOrchestrator) pullImage(ctx context.Context, ref string) error {
+	start := time.Now()
+	reader, err := o.api.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		if _, inspectErr := o.api.ImageInspect(ctx, ref); inspectErr == nil {
+			return nil
+		}
+		return fmt.Errorf("pull image %q: %w", ref, err)
+	}
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		_ = reader.Close()
+		return fmt.Errorf("read image pull response for %q: %w", ref, err)
+	}
+	_ = reader.Close()
+	o.events.Publish(Event{Type: EventImagePulled, Image: ref, Duration: time.Since(start)})
+	return nil
+}
+
+// toHealthConfig converts a HealthcheckConfig into the Docker SDK form.
+// A nil config leaves the image's own HEALTHCHECK in effect.
+func toHealthConfig(hc *HealthcheckConfig) *container.HealthConfig {
+	if hc == nil {
+		return nil
+	}
+	return &container.HealthConfig{
+		Test:        hc.Test,
+		Interval:    hc.Interval,
+		Timeout:     hc.Timeout,
+		Retries:     hc.Retries,
+		StartPeriod: hc.StartPeriod,
+	}
+}
+
 // StartContainer starts a previously created container.
 func (o *DockerOrchestrator) StartContainer(ctx context.Context, containerID string) error {
 	if err := o.api.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
 		return fmt.Errorf("start container %q: %w", containerID, err)
 	}
+	o.events.Publish(Event{Type: EventContainerStarted, ContainerID: containerID, Name: o.containerName(containerID)})
 	return nil
 }
 
@@ -220,6 +314,7 @@ func (o *DockerOrchestrator) StopContainer(ctx context.Context, containerID stri
 	}); err != nil {
 		return fmt.Errorf("stop container %q: %w", containerID, err)
 	}
+	o.events.Publish(Event{Type: EventContainerStopped, ContainerID: containerID, Name: o.containerName(containerID)})
 	return nil
 }
 
@@ -232,17 +327,124 @@ func (o *DockerOrchestrator) RemoveContainer(ctx context.Context, containerID st
 	}
 
 	o.mu.Lock()
+	name := o.managedContainers[containerID]
 	delete(o.managedContainers, containerID)
 	o.mu.Unlock()
 
+	o.events.Publish(Event{Type: EventContainerRemoved, ContainerID: containerID, Name: name})
+	return nil
+}
+
+// KillContainer sends signal to a running container.
+func (o *DockerOrchestrator) KillContainer(ctx context.Context, containerID, signal string) error {
+	if err := o.api.ContainerKill(ctx, containerID, signal); err != nil {
+		return fmt.Errorf("kill container %q: %w", containerID, err)
+	}
+	o.events.Publish(Event{Type: EventContainerKilled, ContainerID: containerID, Name: o.containerName(containerID), Signal: signal})
+	return nil
+}
+
+// PauseContainer freezes a running container.
+func (o *DockerOrchestrator) PauseContainer(ctx context.Context, containerID string) error {
+	if err := o.api.ContainerPause(ctx, containerID); err != nil {
+		return fmt.Errorf("pause container %q: %w", containerID, err)
+	}
+	o.events.Publish(Event{Type: EventContainerPaused, ContainerID: containerID, Name: o.containerName(containerID)})
+	return nil
+}
+
+// UnpauseContainer resumes a paused container.
+func (o *DockerOrchestrator) UnpauseContainer(ctx context.Context, containerID string) error {
+	if err := o.api.ContainerUnpause(ctx, containerID); err != nil {
+		return fmt.Errorf("unpause container %q: %w", containerID, err)
+	}
+	o.events.Publish(Event{Type: EventContainerUnpaused, ContainerID: containerID, Name: o.containerName(containerID)})
+	return nil
+}
+
+// RestartContainer restarts a container, stopping it gracefully first.
+func (o *DockerOrchestrator) RestartContainer(ctx context.Context, containerID string) error {
+	timeout := StopTimeout
+	if err := o.api.ContainerRestart(ctx, containerID, container.StopOptions{Timeout: &timeout}); err != nil {
+		return fmt.Errorf("restart container %q: %w", containerID, err)
+	}
+	o.events.Publish(Event{Type: EventContainerRestarted, ContainerID: containerID, Name: o.containerName(containerID)})
+	return nil
+}
+
+// DisconnectContainer force-disconnects a container from the shared
+// network, so it can no longer reach or be reached by other containers.
+// Its endpoint settings on the network, such as aliases and a static
+// address, are kept for ConnectContainer.
+func (o *DockerOrchestrator) DisconnectContainer(ctx context.Context, containerID string) error {
+	resp, err := o.api.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("inspect container %q: %w", containerID, err)
+	}
+	var settings *network.EndpointSettings
+	if resp.NetworkSettings != nil {
+		if ep := resp.NetworkSettings.Networks[NetworkName]; ep != nil {
+			settings = reusableEndpoint(ep)
+		}
+	}
+
+	if err := o.api.NetworkDisconnect(ctx, NetworkName, containerID, true); err != nil {
+		return fmt.Errorf("disconnect container %q from %q: %w", containerID, NetworkName, err)
+	}
+	if settings != nil {
+		o.mu.Lock()
+		if o.endpoints == nil {
+			o.endpoints = make(map[string]*network.EndpointSettings)
+		}
+		o.endpoints[containerID] = settings
+		o.mu.Unlock()
+	}
+	o.events.Publish(Event{Type: EventContainerDisconnected, ContainerID: containerID, Name: o.containerName(containerID)})
 	return nil
 }
 
-// ListContainers returns info for all containers with the managed prefix.
+// ConnectContainer reconnects a container to the shared network with the
+// endpoint settings DisconnectContainer saved, or the defaults it was
+// created with when there are none.
+func (o *DockerOrchestrator) ConnectContainer(ctx context.Context, containerID string) error {
+	o.mu.Lock()
+	settings := o.endpoints[containerID]
+	o.mu.Unlock()
+	if settings == nil {
+		settings = &network.EndpointSettings{}
+	}
+
+	if err := o.api.NetworkConnect(ctx, NetworkName, containerID, settings); err != nil {
+		return fmt.Errorf("connect container %q to %q: %w", containerID, NetworkName, err)
+	}
+	o.mu.Lock()
+	delete(o.endpoints, containerID)
+	o.mu.Unlock()
+	o.events.Publish(Event{Type: EventContainerConnected, ContainerID: containerID, Name: o.containerName(containerID)})
+	return nil
+}
+
+// reusableEndpoint keeps the configurable parts of ep, dropping what the
+// daemon assigns per connection, such as the endpoint ID and MAC address.
+func reusableEndpoint(ep *network.EndpointSettings) *network.EndpointSettings {
+	return &network.EndpointSettings{
+		IPAMConfig: ep.IPAMConfig,
+		Links:      ep.Links,
+		Aliases:    ep.Aliases,
+		DriverOpts: ep.DriverOpts,
+	}
+}
+
+// ListContainers returns info for all containers carrying LabelManaged
+// whose name starts with the managed prefix. The daemon's name filter
+// matches substrings, so the prefix is checked here.
 func (o *DockerOrchestrator) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
 	containers, err := o.api.ContainerList(ctx, container.ListOptions{
-		All:     true,
-		Filters: filters.NewArgs(filters.Arg("name", ContainerNamePrefix)),
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", LabelManaged+"=true"),
+			filters.Arg("name", ContainerNamePrefix),
+		),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("list containers: %w", err)
@@ -254,11 +456,26 @@ func (o *DockerOrchestrator) ListContainers(ctx context.Context) ([]ContainerInf
 		if len(c.Names) > 0 {
 			name = strings.TrimPrefix(c.Names[0], "/")
 		}
+		if !strings.HasPrefix(name, ContainerNamePrefix) {
+			continue
+		}
+		var ports map[string]string
+		for _, p := range c.Ports {
+			if p.PublicPort == 0 {
+				continue
+			}
+			if ports == nil {
+				ports = map[string]string{}
+			}
+			ports[strconv.Itoa(int(p.PublicPort))] = strconv.Itoa(int(p.PrivatePort))
+		}
 		infos = append(infos, ContainerInfo{
 			ID:     c.ID,
 			Name:   name,
 			Image:  c.Image,
 			Status: mapContainerState(c.State),
+			Ports:  ports,
+			Labels: c.Labels,
 		})
 	}
 	return infos, nil
@@ -276,10 +493,71 @@ func (o *DockerOrchestrator) InspectContainer(ctx context.Context, containerID s
 		Name:   strings.TrimPrefix(resp.Name, "/"),
 		Image:  resp.Config.Image,
 		Status: mapInspectState(resp.State),
+		Labels: resp.Config.Labels,
 	}
 	return info, nil
 }
 
+// ContainerStats takes a single stats sample from the daemon. The daemon
+// waits for a second reading so CPU usage can be computed. Memory excludes
+// the page cache, as docker stats reports it.
+func (o *DockerOrchestrator) ContainerStats(ctx context.Context, containerID string) (ContainerStats, error) {
+	resp, err := o.api.ContainerStats(ctx, containerID, false)
+	if err != nil {
+		return ContainerStats{}, fmt.Errorf("stats container %q: %w", containerID, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var s container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return ContainerStats{}, fmt.Errorf("decode stats of container %q: %w", containerID, err)
+	}
+	return mapStats(s), nil
+}
+
+// mapStats converts a daemon stats sample.
+func mapStats(s container.StatsResponse) ContainerStats {
+	stats := ContainerStats{MemoryLimitBytes: s.MemoryStats.Limit}
+
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	cpus := float64(s.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		stats.CPUPercent = cpuDelta / systemDelta * cpus * 100
+	}
+
+	// cgroup v2 reports inactive_file, v1 total_inactive_file.
+	cache := s.MemoryStats.Stats["inactive_file"]
+	if v, ok := s.MemoryStats.Stats["total_inactive_file"]; ok {
+		cache = v
+	}
+	if s.MemoryStats.Usage > cache {
+		stats.MemoryBytes = s.MemoryStats.Usage - cache
+	}
+
+	for _, n := range s.Networks {
+		stats.NetworkRxBytes += n.RxBytes
+		stats.NetworkTxBytes += n.TxBytes
+	}
+	return stats
+}
+
+// AdoptContainer adds an existing container to the managed set.
+func (o *DockerOrchestrator) AdoptContainer(ctx context.Context, containerID string) error {
+	resp, err := o.api.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("adopt container %q: %w", containerID, err)
+	}
+
+	o.mu.Lock()
+	o.managedContainers[resp.ID] = strings.TrimPrefix(resp.Name, "/")
+	o.mu.Unlock()
+	return nil
+}
+
 // mapContainerState maps Docker's short state string to ContainerStatus.
 func mapContainerState(state string) ContainerStatus {
 	switch state {
@@ -287,6 +565,8 @@ func mapContainerState(state string) ContainerStatus {
 		return StatusCreated
 	case "running":
 		return StatusRunning
+	case "paused":
+		return StatusPaused
 	case "exited", "dead":
 		return StatusStopped
 	default:
@@ -313,6 +593,8 @@ func mapInspectState(state *container.State) ContainerStatus {
 			}
 		}
 		return StatusRunning
+	case "paused":
+		return StatusPaused
 	case "exited", "dead":
 		return StatusStopped
 	default:
@@ -338,64 +620,68 @@ func (o *DockerOrchestrator) HealthCheck(ctx context.Context, containerID string
 }
 
 // StartHealthPolling runs a background goroutine that polls all managed
-// containers at the given interval and calls the callback with their statuses.
-// It stops when the context is cancelled.
-func (o *DockerOrchestrator) StartHealthPolling(ctx context.Context, interval time.Duration, callback HealthStatusCallback) {
-	ticker := time.NewTicker(interval)
-	go func() {
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				o.mu.Lock()
-				ids := make([]string, 0, len(o.managedContainers))
-				for id := range o.managedContainers {
-					ids = append(ids, id)
-				}
-				o.mu.Unlock()
-
-				statuses := make(map[string]ContainerStatus, len(ids))
-				for _, id := range ids {
-					status, _ := o.HealthCheck(ctx, id)
-					statuses[id] = status
-				}
-
-				if len(statuses) > 0 {
-					callback(statuses)
-				}
-			}
-		}
-	}()
+// containers at the given interval and reports status transitions to the
+// callback, publishing a container.health event for each. It stops when the
+// context is cancelled.
+func (o *DockerOrchestrator) StartHealthPolling(ctx context.Context, interval time.Duration, callback HealthTransitionCallback) {
+	pollHealth(ctx, interval, o.managedIDs, o.HealthCheck, func(transitions []HealthTransition) {
+		o.events.publishHealth(transitions, o.containerName)
+		callback(transitions)
+	})
 }
 
-// TeardownAll stops and removes all managed containers, then removes the
-// shared network. It continues even if individual operations fail, collecting
-// all errors. It is idempotent — safe to call multiple times.
-func (o *DockerOrchestrator) TeardownAll(ctx context.Context) error {
+// Events returns the orchestrator's event bus.
+func (o *DockerOrchestrator) Events() *EventBus {
+	return o.events
+}
+
+// containerName returns the name of a managed container, or "" if it is
+// not managed.
+func (o *DockerOrchestrator) containerName(id string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.managedContainers[id]
+}
+
+// managedIDs returns the IDs of all managed containers.
+func (o *DockerOrchestrator) managedIDs() []string {
 	o.mu.Lock()
+	defer o.mu.Unlock()
+
 	ids := make([]string, 0, len(o.managedContainers))
 	for id := range o.managedContainers {
 		ids = append(ids, id)
 	}
+	return ids
+}
+
+// TeardownAll stops and removes all managed containers, then removes the
+// shared network. It continues even if individual operations fail, collecting
+// all errors. It is idempotent — safe to call multiple times.
+func (o *DockerOrchestrator) TeardownAll(ctx context.Context) error {
+	o.mu.Lock()
+	names := maps.Clone(o.managedContainers)
 	o.mu.Unlock()
 
 	var errs []error
 
 	// Stop and remove each managed container.
-	for _, id := range ids {
+	for id, name := range names {
 		timeout := StopTimeout
 		if err := o.api.ContainerStop(ctx, id, container.StopOptions{Timeout: &timeout}); err != nil {
 			if !errdefs.IsNotFound(err) {
 				errs = append(errs, fmt.Errorf("stop container %q: %w", id, err))
 			}
+		} else {
+			o.events.Publish(Event{Type: EventContainerStopped, ContainerID: id, Name: name})
 		}
 
 		if err := o.api.ContainerRemove(ctx, id, container.RemoveOptions{Force: true}); err != nil {
 			if !errdefs.IsNotFound(err) {
 				errs = append(errs, fmt.Errorf("remove container %q: %w", id, err))
 			}
+		} else {
+			o.events.Publish(Event{Type: EventContainerRemoved, ContainerID: id, Name: name})
 		}
 	}
 
@@ -414,6 +700,8 @@ func (o *DockerOrchestrator) TeardownAll(ctx context.Context) error {
 			if !errdefs.IsNotFound(err) {
 				errs = append(errs, fmt.Errorf("remove network: %w", err))
 			}
+		} else {
+			o.events.Publish(Event{Type: EventNetworkRemoved, Name: NetworkName})
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -16,16 +17,24 @@ import (
 
 // mockDockerAPI is a test double for the Docker SDK client.
 type mockDockerAPI struct {
-	networkCreateFn    func(ctx context.Context, name string, options network.CreateOptions) (network.CreateResponse, error)
-	networkListFn      func(ctx context.Context, options network.ListOptions) ([]network.Summary, error)
-	networkRemoveFn    func(ctx context.Context, networkID string) error
-	imagePullFn        func(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
-	containerCreateFn  func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.CreateResponse, error)
-	containerStartFn   func(ctx context.Context, containerID string, options container.StartOptions) error
-	containerStopFn    func(ctx context.Context, containerID string, options container.StopOptions) error
-	containerRemoveFn  func(ctx context.Context, containerID string, options container.RemoveOptions) error
-	containerListFn    func(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
-	containerInspectFn func(ctx context.Context, containerID string) (container.InspectResponse, error)
+	networkCreateFn     func(ctx context.Context, name string, options network.CreateOptions) (network.CreateResponse, error)
+	networkListFn       func(ctx context.Context, options network.ListOptions) ([]network.Summary, error)
+	networkRemoveFn     func(ctx context.Context, networkID string) error
+	networkConnectFn    func(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error
+	networkDisconnectFn func(ctx context.Context, networkID, containerID string, force bool) error
+	imagePullFn         func(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
+	imageInspectFn      func(ctx context.Context, imageID string) (image.InspectResponse, error)
+	containerCreateFn   func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.CreateResponse, error)
+	containerStartFn    func(ctx context.Context, containerID string, options container.StartOptions) error
+	containerStopFn     func(ctx context.Context, containerID string, options container.StopOptions) error
+	containerRemoveFn   func(ctx context.Context, containerID string, options container.RemoveOptions) error
+	containerKillFn     func(ctx context.Context, containerID, signal string) error
+	containerPauseFn    func(ctx context.Context, containerID string) error
+	containerUnpauseFn  func(ctx context.Context, containerID string) error
+	containerRestartFn  func(ctx context.Context, containerID string, options container.StopOptions) error
+	containerListFn     func(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
+	containerInspectFn  func(ctx context.Context, containerID string) (container.InspectResponse, error)
+	containerStatsFn    func(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error)
 }
 
 func (m *mockDockerAPI) NetworkCreate(ctx context.Context, name string, options network.CreateOptions) (network.CreateResponse, error) {
@@ -56,6 +65,13 @@ func (m *mockDockerAPI) ImagePull(ctx context.Context, refStr string, options im
 	return io.NopCloser(bytes.NewReader(nil)), nil
 }
 
+func (m *mockDockerAPI) ImageInspect(ctx context.Context, imageID string) (image.InspectResponse, error) {
+	if m.imageInspectFn != nil {
+		return m.imageInspectFn(ctx, imageID)
+	}
+	return image.InspectResponse{}, errors.New("no such image")
+}
+
 func (m *mockDockerAPI) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.CreateResponse, error) {
 	if m.containerCreateFn != nil {
 		return m.containerCreateFn(ctx, config, hostConfig, networkingConfig, containerName)
@@ -98,6 +114,55 @@ func (m *mockDockerAPI) ContainerInspect(ctx context.Context, containerID string
 	return container.InspectResponse{}, nil
 }
 
+func (m *mockDockerAPI) ContainerStats(ctx context.Context, containerID string, stream bool) (container.StatsResponseReader, error) {
+	if m.containerStatsFn != nil {
+		return m.containerStatsFn(ctx, containerID, stream)
+	}
+	return container.StatsResponseReader{Body: io.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func (m *mockDockerAPI) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	if m.networkConnectFn != nil {
+		return m.networkConnectFn(ctx, networkID, containerID, config)
+	}
+	return nil
+}
+
+func (m *mockDockerAPI) NetworkDisconnect(ctx context.Context, networkID, containerID string, force bool) error {
+	if m.networkDisconnectFn != nil {
+		return m.networkDisconnectFn(ctx, networkID, containerID, force)
+	}
+	return nil
+}
+
+func (m *mockDockerAPI) ContainerKill(ctx context.Context, containerID, signal string) error {
+	if m.containerKillFn != nil {
+		return m.containerKillFn(ctx, containerID, signal)
+	}
+	return nil
+}
+
+func (m *mockDockerAPI) ContainerPause(ctx context.Context, containerID string) error {
+	if m.containerPauseFn != nil {
+		return m.containerPauseFn(ctx, containerID)
+	}
+	return nil
+}
+
+func (m *mockDockerAPI) ContainerUnpause(ctx context.Context, containerID string) error {
+	if m.containerUnpauseFn != nil {
+		return m.containerUnpauseFn(ctx, containerID)
+	}
+	return nil
+}
+
+func (m *mockDockerAPI) ContainerRestart(ctx context.Context, containerID string, options container.StopOptions) error {
+	if m.containerRestartFn != nil {
+		return m.containerRestartFn(ctx, containerID, options)
+	}
+	return nil
+}
+
 // --- Network Tests (from task 6-3) ---
 
 func TestCreateNetwork_CreatesNewBridgeNetwork(t *testing.T) {
@@ -154,6 +219,33 @@ func TestCreateNetwork_IsIdempotent(t *testing.T) {
 	}
 }
 
+func TestNetworkExists(t *testing.T) {
+	var networks []network.Summary
+	mock := &mockDockerAPI{
+		networkListFn: func(_ context.Context, _ network.ListOptions) ([]network.Summary, error) {
+			return networks, nil
+		},
+	}
+	o := newOrchestratorWithAPI(mock)
+
+	exists, err := o.NetworkExists(context.Background())
+	if err != nil {
+		t.Fatalf("NetworkExists() returned error: %v", err)
+	}
+	if exists {
+		t.Error("expected network to be absent")
+	}
+
+	networks = []network.Summary{{ID: "other", Name: NetworkName + "-other"}, {ID: "net-1", Name: NetworkName}}
+	exists, err = o.NetworkExists(context.Background())
+	if err != nil {
+		t.Fatalf("NetworkExists() returned error: %v", err)
+	}
+	if !exists {
+		t.Error("expected network to exist")
+	}
+}
+
 func TestRemoveNetwork_RemovesSuccessfully(t *testing.T) {
 	var removedID string
 	mock := &mockDockerAPI{
@@ -347,6 +439,42 @@ func TestCreateContainer_NilCmdUsesImageDefault(t *testing.T) {
 	}
 }
 
+func TestCreateContainer_PassesEntrypointAndHealthcheck(t *testing.T) {
+	var createdConfig *container.Config
+
+	mock := &mockDockerAPI{
+		containerCreateFn: func(_ context.Context, config *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig, _ string) (container.CreateResponse, error) {
+			createdConfig = config
+			return container.CreateResponse{ID: "ctr-hc"}, nil
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	_, err := o.CreateContainer(context.Background(), ContainerConfig{
+		Image:      "ghcr.io/acme/app:1.0",
+		Name:       "app",
+		Entrypoint: []string{"/bin/sh", "-c"},
+		Healthcheck: &HealthcheckConfig{
+			Test:     []string{"CMD", "true"},
+			Interval: 2 * time.Second,
+			Retries:  4,
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateContainer() returned error: %v", err)
+	}
+
+	if len(createdConfig.Entrypoint) != 2 || createdConfig.Entrypoint[0] != "/bin/sh" {
+		t.Errorf("unexpected Entrypoint: %v", createdConfig.Entrypoint)
+	}
+	if createdConfig.Healthcheck == nil {
+		t.Fatal("expected Healthcheck to be set")
+	}
+	if createdConfig.Healthcheck.Interval != 2*time.Second || createdConfig.Healthcheck.Retries != 4 {
+		t.Errorf("unexpected Healthcheck: %+v", createdConfig.Healthcheck)
+	}
+}
+
 func TestCreateContainer_AppliesNamePrefix(t *testing.T) {
 	var createdName string
 	mock := &mockDockerAPI{
@@ -369,6 +497,159 @@ func TestCreateContainer_AppliesNamePrefix(t *testing.T) {
 	}
 }
 
+func TestCreateContainer_SetsLabels(t *testing.T) {
+	var createdLabels map[string]string
+	mock := &mockDockerAPI{
+		containerCreateFn: func(_ context.Context, config *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig, _ string) (container.CreateResponse, error) {
+			createdLabels = config.Labels
+			return container.CreateResponse{ID: "ctr-456"}, nil
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	_, err := o.CreateContainer(context.Background(), ContainerConfig{
+		Image:  "alpine:latest",
+		Name:   "myservice",
+		Labels: map[string]string{LabelNodeID: "node-1"},
+	})
+	if err != nil {
+		t.Fatalf("CreateContainer() returned error: %v", err)
+	}
+	if createdLabels[LabelManaged] != "true" {
+		t.Errorf("expected label %s=true, got %q", LabelManaged, createdLabels[LabelManaged])
+	}
+	if createdLabels[LabelNodeID] != "node-1" {
+		t.Errorf("expected label %s=node-1, got %q", LabelNodeID, createdLabels[LabelNodeID])
+	}
+}
+
+func TestCreateContainer_PassesExtraHosts(t *testing.T) {
+	var hostConfig *container.HostConfig
+	mock := &mockDockerAPI{
+		containerCreateFn: func(_ context.Context, _ *container.Config, hc *container.HostConfig, _ *network.NetworkingConfig, _ string) (container.CreateResponse, error) {
+			hostConfig = hc
+			return container.CreateResponse{ID: "ctr-456"}, nil
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	_, err := o.CreateContainer(context.Background(), ContainerConfig{
+		Image:      "alpine:latest",
+		Name:       "myservice",
+		ExtraHosts: []string{"host.docker.internal:host-gateway"},
+	})
+	if err != nil {
+		t.Fatalf("CreateContainer() returned error: %v", err)
+	}
+	if len(hostConfig.ExtraHosts) != 1 || hostConfig.ExtraHosts[0] != "host.docker.internal:host-gateway" {
+		t.Errorf("unexpected ExtraHosts: %v", hostConfig.ExtraHosts)
+	}
+}
+
+func TestCreateContainer_PreCreateHookVetoSkipsDaemon(t *testing.T) {
+	pulled := false
+	mock := &mockDockerAPI{
+		imagePullFn: func(_ context.Context, _ string, _ image.PullOptions) (io.ReadCloser, error) {
+			pulled = true
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	errDenied := errors.New("denied")
+	o.Events().OnPreCreate(func(context.Context, *ContainerConfig) error { return errDenied })
+
+	if _, err := o.CreateContainer(context.Background(), ContainerConfig{Image: "alpine:latest", Name: "myservice"}); !errors.Is(err, errDenied) {
+		t.Fatalf("expected veto error, got %v", err)
+	}
+	if pulled {
+		t.Error("expected no image pull after a veto")
+	}
+}
+
+func TestCreateContainer_RunsHooksAndPublishesEvents(t *testing.T) {
+	var createdEnv []string
+	mock := &mockDockerAPI{
+		containerCreateFn: func(_ context.Context, config *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig, _ string) (container.CreateResponse, error) {
+			createdEnv = config.Env
+			return container.CreateResponse{ID: "ctr-456"}, nil
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	o.Events().OnPreCreate(func(_ context.Context, cfg *ContainerConfig) error {
+		cfg.Env = map[string]string{"INJECTED": "yes"}
+		return nil
+	})
+	var postID string
+	var postEnv map[string]string
+	o.Events().OnPostCreate(func(_ context.Context, id string, cfg ContainerConfig) {
+		postID, postEnv = id, cfg.Env
+	})
+	var events []Event
+	o.Events().Subscribe(func(e Event) { events = append(events, e) })
+
+	ctx := context.Background()
+	id, err := o.CreateContainer(ctx, ContainerConfig{Image: "alpine:latest", Name: "myservice"})
+	if err != nil {
+		t.Fatalf("CreateContainer() returned error: %v", err)
+	}
+	if err := o.StartContainer(ctx, id); err != nil {
+		t.Fatalf("StartContainer() returned error: %v", err)
+	}
+	if err := o.StopContainer(ctx, id); err != nil {
+		t.Fatalf("StopContainer() returned error: %v", err)
+	}
+	if err := o.RemoveContainer(ctx, id); err != nil {
+		t.Fatalf("RemoveContainer() returned error: %v", err)
+	}
+
+	if len(createdEnv) != 1 || createdEnv[0] != "INJECTED=yes" {
+		t.Errorf("expected decorated env, got %v", createdEnv)
+	}
+	if postID != "ctr-456" || postEnv["INJECTED"] != "yes" {
+		t.Errorf("expected post-create hook to see the decorated config, got %q %v", postID, postEnv)
+	}
+
+	want := []EventType{EventImagePulled, EventContainerCreated, EventContainerStarted, EventContainerStopped, EventContainerRemoved}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i, e := range events {
+		if e.Type != want[i] {
+			t.Errorf("event %d: expected %q, got %q", i, want[i], e.Type)
+		}
+		if i > 0 && (e.ContainerID != "ctr-456" || e.Name != "heph-myservice") {
+			t.Errorf("event %d: expected container ctr-456 heph-myservice, got %+v", i, e)
+		}
+	}
+	if events[0].Duration <= 0 {
+		t.Errorf("expected image.pulled to carry the pull duration, got %v", events[0].Duration)
+	}
+}
+
+func TestSDKClientAdapter_ReportsErrors(t *testing.T) {
+	var ops []string
+	a := &sdkClientAdapter{onError: func(operation string, _ error) { ops = append(ops, operation) }}
+
+	if err := a.observe("ContainerStart", nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	failure := errors.New("boom")
+	if err := a.observe("ContainerStop", failure); err != failure {
+		t.Errorf("expected the error to be returned unchanged, got %v", err)
+	}
+	if len(ops) != 1 || ops[0] != "ContainerStop" {
+		t.Errorf("expected one ContainerStop error, got %v", ops)
+	}
+
+	o := &DockerOrchestrator{api: &sdkClientAdapter{}}
+	o.OnAPIError(func(string, error) {})
+	if o.api.(*sdkClientAdapter).onError == nil {
+		t.Error("expected OnAPIError to install the hook on the adapter")
+	}
+}
+
 func TestStartContainer_CallsDockerAPI(t *testing.T) {
 	var startedID string
 	mock := &mockDockerAPI{
@@ -410,6 +691,142 @@ func TestStopContainer_UsesGracefulTimeout(t *testing.T) {
 	}
 }
 
+func TestChaosOperations_CallDaemonAndPublishEvents(t *testing.T) {
+	var calls []string
+	record := func(call string) error {
+		calls = append(calls, call)
+		return nil
+	}
+	mock := &mockDockerAPI{
+		containerKillFn: func(_ context.Context, containerID, signal string) error {
+			return record("kill " + containerID + " " + signal)
+		},
+		containerPauseFn:   func(_ context.Context, containerID string) error { return record("pause " + containerID) },
+		containerUnpauseFn: func(_ context.Context, containerID string) error { return record("unpause " + containerID) },
+		containerRestartFn: func(_ context.Context, containerID string, options container.StopOptions) error {
+			if options.Timeout == nil || *options.Timeout != StopTimeout {
+				t.Errorf("expected restart timeout %d, got %v", StopTimeout, options.Timeout)
+			}
+			return record("restart " + containerID)
+		},
+		networkDisconnectFn: func(_ context.Context, networkID, containerID string, force bool) error {
+			if !force {
+				t.Error("expected a forced disconnect")
+			}
+			return record("disconnect " + containerID + " " + networkID)
+		},
+		networkConnectFn: func(_ context.Context, networkID, containerID string, _ *network.EndpointSettings) error {
+			return record("connect " + containerID + " " + networkID)
+		},
+	}
+	o := newOrchestratorWithAPI(mock)
+	o.managedContainers["ctr-1"] = "heph-api"
+	var events []Event
+	o.Events().Subscribe(func(e Event) { events = append(events, e) })
+
+	ctx := context.Background()
+	steps := []func() error{
+		func() error { return o.KillContainer(ctx, "ctr-1", "SIGTERM") },
+		func() error { return o.PauseContainer(ctx, "ctr-1") },
+		func() error { return o.UnpauseContainer(ctx, "ctr-1") },
+		func() error { return o.RestartContainer(ctx, "ctr-1") },
+		func() error { return o.DisconnectContainer(ctx, "ctr-1") },
+		func() error { return o.ConnectContainer(ctx, "ctr-1") },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+
+	wantCalls := []string{
+		"kill ctr-1 SIGTERM", "pause ctr-1", "unpause ctr-1", "restart ctr-1",
+		"disconnect ctr-1 " + NetworkName, "connect ctr-1 " + NetworkName,
+	}
+	wantEvents := []EventType{
+		EventContainerKilled, EventContainerPaused, EventContainerUnpaused,
+		EventContainerRestarted, EventContainerDisconnected, EventContainerConnected,
+	}
+	if len(calls) != len(wantCalls) || len(events) != len(wantEvents) {
+		t.Fatalf("expected calls %v and events %v, got %v and %+v", wantCalls, wantEvents, calls, events)
+	}
+	for i := range wantCalls {
+		if calls[i] != wantCalls[i] {
+			t.Errorf("call %d: expected %q, got %q", i, wantCalls[i], calls[i])
+		}
+		if events[i].Type != wantEvents[i] || events[i].Name != "heph-api" {
+			t.Errorf("event %d: expected %q for heph-api, got %+v", i, wantEvents[i], events[i])
+		}
+	}
+	if events[0].Signal != "SIGTERM" {
+		t.Errorf("expected the kill event to carry the signal, got %q", events[0].Signal)
+	}
+}
+
+func TestConnectContainer_RestoresEndpointSettings(t *testing.T) {
+	var connected []*network.EndpointSettings
+	mock := &mockDockerAPI{
+		containerInspectFn: func(_ context.Context, _ string) (container.InspectResponse, error) {
+			return container.InspectResponse{
+				NetworkSettings: &container.NetworkSettings{
+					Networks: map[string]*network.EndpointSettings{
+						NetworkName: {Aliases: []string{"api", "api.local"}, EndpointID: "ep-1", MacAddress: "02:42:ac:11:00:02"},
+					},
+				},
+			}, nil
+		},
+		networkConnectFn: func(_ context.Context, _, _ string, config *network.EndpointSettings) error {
+			connected = append(connected, config)
+			return nil
+		},
+	}
+	o := newOrchestratorWithAPI(mock)
+
+	ctx := context.Background()
+	if err := o.DisconnectContainer(ctx, "ctr-1"); err != nil {
+		t.Fatalf("disconnect: %v", err)
+	}
+	if err := o.ConnectContainer(ctx, "ctr-1"); err != nil {
+		t.Fatalf("first connect: %v", err)
+	}
+	if err := o.ConnectContainer(ctx, "ctr-1"); err != nil {
+		t.Fatalf("second connect: %v", err)
+	}
+
+	if len(connected) != 2 {
+		t.Fatalf("expected 2 connects, got %d", len(connected))
+	}
+	first := connected[0]
+	if len(first.Aliases) != 2 || first.Aliases[0] != "api" || first.Aliases[1] != "api.local" {
+		t.Errorf("expected the saved aliases, got %v", first.Aliases)
+	}
+	if first.EndpointID != "" || first.MacAddress != "" {
+		t.Errorf("expected daemon-assigned fields to be dropped, got %+v", first)
+	}
+	if len(connected[1].Aliases) != 0 {
+		t.Errorf("expected saved settings to be used once, got %v", connected[1].Aliases)
+	}
+}
+
+func TestChaosOperations_WrapErrorsWithoutEvents(t *testing.T) {
+	mock := &mockDockerAPI{
+		containerPauseFn: func(_ context.Context, _ string) error {
+			return errors.New("container is not running")
+		},
+	}
+	o := newOrchestratorWithAPI(mock)
+	published := false
+	o.Events().Subscribe(func(Event) { published = true })
+
+	err := o.PauseContainer(context.Background(), "ctr-1")
+	if err == nil || err.Error() != `pause container "ctr-1": container is not running` {
+		t.Errorf("unexpected error %v", err)
+	}
+	if published {
+		t.Error("expected no event for a failed pause")
+	}
+}
+
 func TestRemoveContainer_ForcesRemoval(t *testing.T) {
 	var removedID string
 	var wasForced bool
@@ -468,6 +885,60 @@ func TestListContainers_MapsToContainerInfo(t *testing.T) {
 	}
 }
 
+func TestListContainers_OnlyManaged(t *testing.T) {
+	var opts container.ListOptions
+	mock := &mockDockerAPI{
+		containerListFn: func(_ context.Context, o container.ListOptions) ([]container.Summary, error) {
+			opts = o
+			// The daemon's name filter matches substrings.
+			return []container.Summary{
+				{ID: "ctr-1", Names: []string{"/heph-web"}, State: "running"},
+				{ID: "ctr-2", Names: []string{"/foo-heph-bar"}, State: "running"},
+			}, nil
+		},
+	}
+
+	infos, err := newOrchestratorWithAPI(mock).ListContainers(context.Background())
+	if err != nil {
+		t.Fatalf("ListContainers: %v", err)
+	}
+	if !opts.Filters.ExactMatch("label", LabelManaged+"=true") {
+		t.Errorf("expected a %s=true label filter, got %v", LabelManaged, opts.Filters)
+	}
+	if len(infos) != 1 || infos[0].Name != "heph-web" {
+		t.Errorf("expected only heph-web, got %+v", infos)
+	}
+}
+
+func TestListContainers_MapsPortsAndLabels(t *testing.T) {
+	mock := &mockDockerAPI{
+		containerListFn: func(_ context.Context, _ container.ListOptions) ([]container.Summary, error) {
+			return []container.Summary{{
+				ID:     "ctr-1",
+				Names:  []string{"/heph-web"},
+				State:  "running",
+				Ports:  []container.Port{{PrivatePort: 80, PublicPort: 8081}, {PrivatePort: 443}},
+				Labels: map[string]string{LabelManaged: "true", LabelNodeID: "web"},
+			}}, nil
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	infos, err := o.ListContainers(context.Background())
+	if err != nil {
+		t.Fatalf("ListContainers() returned error: %v", err)
+	}
+	if len(infos) != 1 {
+		t.Fatalf("expected 1 container, got %d", len(infos))
+	}
+	if len(infos[0].Ports) != 1 || infos[0].Ports["8081"] != "80" {
+		t.Errorf("expected ports {8081: 80}, got %v", infos[0].Ports)
+	}
+	if infos[0].Labels[LabelNodeID] != "web" {
+		t.Errorf("expected label %s=web, got %q", LabelNodeID, infos[0].Labels[LabelNodeID])
+	}
+}
+
 func TestInspectContainer_MapsStateCorrectly(t *testing.T) {
 	mock := &mockDockerAPI{
 		containerInspectFn: func(_ context.Context, _ string) (container.InspectResponse, error) {
@@ -500,6 +971,85 @@ func TestInspectContainer_MapsStateCorrectly(t *testing.T) {
 	}
 }
 
+func TestAdoptContainer_TracksExistingContainer(t *testing.T) {
+	mock := &mockDockerAPI{
+		containerInspectFn: func(_ context.Context, _ string) (container.InspectResponse, error) {
+			return container.InspectResponse{
+				ContainerJSONBase: &container.ContainerJSONBase{ID: "ctr-1", Name: "/heph-web"},
+			}, nil
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	if err := o.AdoptContainer(context.Background(), "ctr-1"); err != nil {
+		t.Fatalf("AdoptContainer() returned error: %v", err)
+	}
+	if name := o.managedContainers["ctr-1"]; name != "heph-web" {
+		t.Errorf("expected managed container 'heph-web', got %q", name)
+	}
+}
+
+func TestContainerStats_MapsDaemonSample(t *testing.T) {
+	body := `{
+		"cpu_stats": {"cpu_usage": {"total_usage": 3000}, "system_cpu_usage": 20000, "online_cpus": 2},
+		"precpu_stats": {"cpu_usage": {"total_usage": 1000}, "system_cpu_usage": 10000},
+		"memory_stats": {"usage": 1000, "limit": 4000, "stats": {"inactive_file": 200}},
+		"networks": {"eth0": {"rx_bytes": 10, "tx_bytes": 20}, "eth1": {"rx_bytes": 1, "tx_bytes": 2}}
+	}`
+	var gotStream bool
+	mock := &mockDockerAPI{
+		containerStatsFn: func(_ context.Context, _ string, stream bool) (container.StatsResponseReader, error) {
+			gotStream = stream
+			return container.StatsResponseReader{Body: io.NopCloser(strings.NewReader(body))}, nil
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	stats, err := o.ContainerStats(context.Background(), "ctr-1")
+	if err != nil {
+		t.Fatalf("ContainerStats() returned error: %v", err)
+	}
+	if gotStream {
+		t.Error("expected a single sample, not a stream")
+	}
+	want := ContainerStats{CPUPercent: 40, MemoryBytes: 800, MemoryLimitBytes: 4000, NetworkRxBytes: 11, NetworkTxBytes: 22}
+	if stats != want {
+		t.Errorf("expected %+v, got %+v", want, stats)
+	}
+}
+
+func TestContainerStats_WrapsAPIError(t *testing.T) {
+	mock := &mockDockerAPI{
+		containerStatsFn: func(_ context.Context, _ string, _ bool) (container.StatsResponseReader, error) {
+			return container.StatsResponseReader{}, notFoundError("container not found")
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	_, err := o.ContainerStats(context.Background(), "ctr-gone")
+	var nf notFoundError
+	if !errors.As(err, &nf) || !strings.Contains(err.Error(), "ctr-gone") {
+		t.Errorf("expected the wrapped daemon error, got %v", err)
+	}
+}
+
+func TestAdoptContainer_WrapsNotFound(t *testing.T) {
+	mock := &mockDockerAPI{
+		containerInspectFn: func(_ context.Context, _ string) (container.InspectResponse, error) {
+			return container.InspectResponse{}, notFoundError("container not found")
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	err := o.AdoptContainer(context.Background(), "ctr-gone")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if len(o.managedContainers) != 0 {
+		t.Errorf("expected no managed containers, got %d", len(o.managedContainers))
+	}
+}
+
 func TestMapContainerState_AllStates(t *testing.T) {
 	tests := []struct {
 		state    string
@@ -509,7 +1059,8 @@ func TestMapContainerState_AllStates(t *testing.T) {
 		{"running", StatusRunning},
 		{"exited", StatusStopped},
 		{"dead", StatusStopped},
-		{"paused", StatusError},
+		{"paused", StatusPaused},
+		{"restarting", StatusError},
 		{"unknown", StatusError},
 	}
 
@@ -553,6 +1104,11 @@ func TestMapInspectState_WithHealthCheck(t *testing.T) {
 			},
 			expected: StatusUnhealthy,
 		},
+		{
+			name:     "paused",
+			state:    &container.State{Status: "paused", Health: &container.Health{Status: "healthy"}},
+			expected: StatusPaused,
+		},
 		{
 			name:     "exited",
 			state:    &container.State{Status: "exited"},
@@ -584,6 +1140,31 @@ func TestCreateContainer_WrapsErrorOnPullFailure(t *testing.T) {
 	}
 }
 
+func TestCreateContainer_UsesLocalImageWhenPullFails(t *testing.T) {
+	var inspected string
+	mock := &mockDockerAPI{
+		imagePullFn: func(_ context.Context, _ string, _ image.PullOptions) (io.ReadCloser, error) {
+			return nil, errors.New("pull access denied")
+		},
+		imageInspectFn: func(_ context.Context, imageID string) (image.InspectResponse, error) {
+			inspected = imageID
+			return image.InspectResponse{ID: "sha256:abc"}, nil
+		},
+		containerCreateFn: func(_ context.Context, _ *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig, _ string) (container.CreateResponse, error) {
+			return container.CreateResponse{ID: "ctr-local"}, nil
+		},
+	}
+
+	o := newOrchestratorWithAPI(mock)
+	id, err := o.CreateContainer(context.Background(), ContainerConfig{Image: "hephaestus/mock-server:latest", Name: "api"})
+	if err != nil {
+		t.Fatalf("expected local image to be used, got %v", err)
+	}
+	if id != "ctr-local" || inspected != "hephaestus/mock-server:latest" {
+		t.Errorf("expected ctr-local from the inspected local image, got %q (inspected %q)", id, inspected)
+	}
+}
+
 // --- Health Check Tests (task 6-5) ---
 
 func TestHealthCheck_ReturnsRunningStatus(t *testing.T) {
@@ -689,14 +1270,14 @@ func TestStartHealthPolling_CallsCallbackAndStopsOnCancel(t *testing.T) {
 
 	var mu sync.Mutex
 	callbackCount := 0
-	var lastStatuses map[string]ContainerStatus
+	var lastTransitions []HealthTransition
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	o.StartHealthPolling(ctx, 50*time.Millisecond, func(statuses map[string]ContainerStatus) {
+	o.StartHealthPolling(ctx, 50*time.Millisecond, func(transitions []HealthTransition) {
 		mu.Lock()
 		callbackCount++
-		lastStatuses = statuses
+		lastTransitions = transitions
 		mu.Unlock()
 	})
 
@@ -709,11 +1290,13 @@ func TestStartHealthPolling_CallsCallbackAndStopsOnCancel(t *testing.T) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	if callbackCount < 1 {
-		t.Errorf("expected at least 1 callback, got %d", callbackCount)
+	// The status never changes after the first poll, so only that one is
+	// reported.
+	if callbackCount != 1 {
+		t.Errorf("expected 1 callback, got %d", callbackCount)
 	}
-	if lastStatuses["ctr-1"] != StatusRunning {
-		t.Errorf("expected status %q for ctr-1, got %q", StatusRunning, lastStatuses["ctr-1"])
+	if len(lastTransitions) != 1 || lastTransitions[0].ContainerID != "ctr-1" || lastTransitions[0].To != StatusRunning {
+		t.Errorf("expected ctr-1 to be reported %q, got %+v", StatusRunning, lastTransitions)
 	}
 }
 
@@ -726,7 +1309,7 @@ func TestStartHealthPolling_NoCallbackWhenNoContainers(t *testing.T) {
 	callbackCount := 0
 	ctx, cancel := context.WithCancel(context.Background())
 
-	o.StartHealthPolling(ctx, 50*time.Millisecond, func(_ map[string]ContainerStatus) {
+	o.StartHealthPolling(ctx, 50*time.Millisecond, func(_ []HealthTransition) {
 		callbackCount++
 	})
 
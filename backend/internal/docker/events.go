@@ -0,0 +1,208 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EventType identifies an orchestrator lifecycle event.
+type EventType string
+
+const (
+	EventNetworkCreated   EventType = "network.created"
+	EventNetworkRemoved   EventType = "network.removed"
+	EventImagePulled      EventType = "image.pulled"
+	EventContainerCreated EventType = "container.created"
+	EventContainerStarted EventType = "container.started"
+	EventContainerStopped EventType = "container.stopped"
+	EventContainerRemoved EventType = "container.removed"
+	EventHealthChanged    EventType = "container.health"
+
+	// Chaos events, published by the actions that disrupt a running
+	// container.
+	EventContainerKilled       EventType = "container.killed"
+	EventContainerPaused       EventType = "container.paused"
+	EventContainerUnpaused     EventType = "container.unpaused"
+	EventContainerRestarted    EventType = "container.restarted"
+	EventContainerDisconnected EventType = "container.disconnected"
+	EventContainerConnected    EventType = "container.connected"
+)
+
+// Event is published on an orchestrator's EventBus after a lifecycle step
+// succeeds. Only the fields relevant to Type are set.
+type Event struct {
+	Type EventType `json:"type"`
+	At   time.Time `json:"at"`
+	// ContainerID is set for container and health events.
+	ContainerID string `json:"containerId,omitempty"`
+	// Name is the prefixed container name, or the network name for network
+	// events.
+	Name string `json:"name,omitempty"`
+	// Image is set for image.pulled and container.created.
+	Image string `json:"image,omitempty"`
+	// Labels are the container's labels, set for container.created.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Health is set for container.health.
+	Health *HealthTransition `json:"health,omitempty"`
+	// Signal is set for container.killed.
+	Signal string `json:"signal,omitempty"`
+	// Duration is how long the pull took, set for image.pulled.
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// EventHandler receives published events. Handlers run synchronously on the
+// goroutine that performed the operation, so they must not block or call
+// back into the orchestrator.
+type EventHandler func(Event)
+
+// PreCreateHook runs before a container is created. It may change cfg, for
+// example to add env vars or labels, or return an error to veto the
+// creation; CreateContainer then fails without touching the daemon.
+type PreCreateHook func(ctx context.Context, cfg *ContainerConfig) error
+
+// PostCreateHook runs after a container is created, with its ID and the
+// config it was created from, including changes made by pre-create hooks.
+type PostCreateHook func(ctx context.Context, containerID string, cfg ContainerConfig)
+
+// subscription is one registered EventHandler with its type filter.
+type subscription struct {
+	id      int
+	handler EventHandler
+	types   map[EventType]bool // nil matches every type
+}
+
+// EventBus fans orchestrator events out to subscribers and holds the
+// container create hooks. The zero value is not usable; use NewEventBus.
+type EventBus struct {
+	mu         sync.RWMutex
+	nextID     int
+	subs       []subscription
+	preCreate  []PreCreateHook
+	postCreate []PostCreateHook
+	now        func() time.Time
+}
+
+// NewEventBus creates an empty EventBus.
+func NewEventBus() *EventBus {
+	return &EventBus{now: time.Now}
+}
+
+// Subscribe registers handler for events of the given types, or of every
+// type when none are given. Handlers are called in subscription order. The
+// returned function unsubscribes; it is safe to call more than once.
+func (b *EventBus) Subscribe(handler EventHandler, types ...EventType) (unsubscribe func()) {
+	var filter map[EventType]bool
+	if len(types) > 0 {
+		filter = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			filter[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscription{id: id, handler: handler, types: filter})
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.subs = slices.DeleteFunc(b.subs, func(s subscription) bool { return s.id == id })
+	}
+}
+
+// Publish delivers e to every matching subscriber. A zero At is set to the
+// current time.
+func (b *EventBus) Publish(e Event) {
+	if e.At.IsZero() {
+		e.At = b.now().UTC()
+	}
+
+	b.mu.RLock()
+	subs := slices.Clone(b.subs)
+	b.mu.RUnlock()
+
+	for _, s := range subs {
+		if s.types == nil || s.types[e.Type] {
+			s.handler(e)
+		}
+	}
+}
+
+// OnPreCreate registers a hook run before every container is created.
+// Hooks run in registration order, each seeing the previous hook's changes.
+func (b *EventBus) OnPreCreate(hook PreCreateHook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.preCreate = append(b.preCreate, hook)
+}
+
+// OnPostCreate registers a hook run after every container is created.
+func (b *EventBus) OnPostCreate(hook PostCreateHook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.postCreate = append(b.postCreate, hook)
+}
+
+// runPreCreate returns a copy of cfg after every pre-create hook has run
+// on it. The caller's maps and slices are never modified. The first hook
+// error stops the chain and is returned.
+func (b *EventBus) runPreCreate(ctx context.Context, cfg ContainerConfig) (ContainerConfig, error) {
+	b.mu.RLock()
+	hooks := slices.Clone(b.preCreate)
+	b.mu.RUnlock()
+
+	if len(hooks) == 0 {
+		return cfg, nil
+	}
+	cfg = cloneConfig(cfg)
+	for _, hook := range hooks {
+		if err := hook(ctx, &cfg); err != nil {
+			return cfg, fmt.Errorf("pre-create hook: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// created runs the post-create hooks for a new container and publishes
+// container.created.
+func (b *EventBus) created(ctx context.Context, id, name string, cfg ContainerConfig) {
+	b.mu.RLock()
+	hooks := slices.Clone(b.postCreate)
+	b.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(ctx, id, cfg)
+	}
+	b.Publish(Event{Type: EventContainerCreated, ContainerID: id, Name: name, Image: cfg.Image, Labels: maps.Clone(cfg.Labels)})
+}
+
+// publishHealth publishes a container.health event per transition. name
+// resolves a container ID to its name.
+func (b *EventBus) publishHealth(transitions []HealthTransition, name func(id string) string) {
+	for _, t := range transitions {
+		b.Publish(Event{Type: EventHealthChanged, At: t.At, ContainerID: t.ContainerID, Name: name(t.ContainerID), Health: &t})
+	}
+}
+
+// cloneConfig returns a deep copy of cfg so hooks can change it freely.
+func cloneConfig(cfg ContainerConfig) ContainerConfig {
+	cfg.Cmd = slices.Clone(cfg.Cmd)
+	cfg.Entrypoint = slices.Clone(cfg.Entrypoint)
+	cfg.Env = maps.Clone(cfg.Env)
+	cfg.Ports = maps.Clone(cfg.Ports)
+	cfg.Volumes = maps.Clone(cfg.Volumes)
+	cfg.Labels = maps.Clone(cfg.Labels)
+	cfg.ExtraHosts = slices.Clone(cfg.ExtraHosts)
+	if cfg.Healthcheck != nil {
+		hc := *cfg.Healthcheck
+		hc.Test = slices.Clone(hc.Test)
+		cfg.Healthcheck = &hc
+	}
+	return cfg
+}
@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestEventBus_SubscribeFiltersAndUnsubscribes(t *testing.T) {
+	b := NewEventBus()
+	var all, started []EventType
+	b.Subscribe(func(e Event) { all = append(all, e.Type) })
+	unsubscribe := b.Subscribe(func(e Event) { started = append(started, e.Type) }, EventContainerStarted)
+
+	b.Publish(Event{Type: EventContainerCreated})
+	b.Publish(Event{Type: EventContainerStarted})
+	unsubscribe()
+	unsubscribe()
+	b.Publish(Event{Type: EventContainerStarted})
+
+	if want := []EventType{EventContainerCreated, EventContainerStarted, EventContainerStarted}; !slices.Equal(all, want) {
+		t.Errorf("all: got %v, want %v", all, want)
+	}
+	if want := []EventType{EventContainerStarted}; !slices.Equal(started, want) {
+		t.Errorf("filtered: got %v, want %v", started, want)
+	}
+}
+
+func TestEventBus_PublishSetsTime(t *testing.T) {
+	b := NewEventBus()
+	var got Event
+	b.Subscribe(func(e Event) { got = e })
+
+	b.Publish(Event{Type: EventNetworkCreated})
+	if got.At.IsZero() {
+		t.Error("expected At to be set")
+	}
+}
+
+func TestEventBus_PreCreateHooksDecorateACopy(t *testing.T) {
+	b := NewEventBus()
+	b.OnPreCreate(func(_ context.Context, cfg *ContainerConfig) error {
+		cfg.Env["TRACE"] = "1"
+		return nil
+	})
+	b.OnPreCreate(func(_ context.Context, cfg *ContainerConfig) error {
+		if cfg.Env["TRACE"] != "1" {
+			t.Error("expected the second hook to see the first hook's change")
+		}
+		cfg.Labels = map[string]string{"team": "core"}
+		return nil
+	})
+
+	in := ContainerConfig{Name: "api", Env: map[string]string{"PORT": "8080"}}
+	out, err := b.runPreCreate(context.Background(), in)
+	if err != nil {
+		t.Fatalf("runPreCreate: %v", err)
+	}
+	if out.Env["TRACE"] != "1" || out.Env["PORT"] != "8080" || out.Labels["team"] != "core" {
+		t.Errorf("unexpected decorated config %+v", out)
+	}
+	if _, ok := in.Env["TRACE"]; ok {
+		t.Error("expected the caller's env to be left alone")
+	}
+}
+
+func TestEventBus_PreCreateHookVetoStopsTheChain(t *testing.T) {
+	b := NewEventBus()
+	errDenied := errors.New("image not allowed")
+	b.OnPreCreate(func(context.Context, *ContainerConfig) error { return errDenied })
+	b.OnPreCreate(func(context.Context, *ContainerConfig) error {
+		t.Error("expected later hooks not to run after a veto")
+		return nil
+	})
+
+	if _, err := b.runPreCreate(context.Background(), ContainerConfig{Name: "api"}); !errors.Is(err, errDenied) {
+		t.Errorf("expected veto error, got %v", err)
+	}
+}
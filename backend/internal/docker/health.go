@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// healthPoller checks containers through a bounded worker pool and keeps
+// the last known status of each so that only transitions are reported.
+type healthPoller struct {
+	ids     func() []string
+	check   func(context.Context, string) (ContainerStatus, error)
+	workers int
+	timeout time.Duration
+	now     func() time.Time
+
+	// last is only touched by the polling goroutine.
+	last map[string]ContainerStatus
+}
+
+// pollHealth starts a goroutine that, on every tick, checks each container
+// returned by ids and passes the transitions to callback. Ticks with no
+// transitions are skipped. It stops when ctx is cancelled.
+func pollHealth(ctx context.Context, interval time.Duration, ids func() []string, check func(context.Context, string) (ContainerStatus, error), callback HealthTransitionCallback) {
+	p := &healthPoller{
+		ids:     ids,
+		check:   check,
+		workers: HealthCheckConcurrency,
+		timeout: HealthCheckTimeout,
+		now:     time.Now,
+		last:    make(map[string]ContainerStatus),
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if transitions := p.poll(ctx); len(transitions) > 0 {
+					callback(transitions)
+				}
+			}
+		}
+	}()
+}
+
+// poll checks every current container and returns the ones whose status
+// differs from the previous poll, ordered by container ID. A check that
+// fails or times out leaves the last known status in place. Containers that
+// are no longer managed are forgotten.
+func (p *healthPoller) poll(ctx context.Context) []HealthTransition {
+	current := p.ids()
+	statuses := make([]ContainerStatus, len(current))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(p.workers, 1))
+	for i, id := range current {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			checkCtx, cancel := context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+			if status, err := p.check(checkCtx, id); err == nil {
+				statuses[i] = status
+			}
+		}()
+	}
+	wg.Wait()
+
+	at := p.now().UTC()
+	seen := make(map[string]bool, len(current))
+	var transitions []HealthTransition
+	for i, id := range current {
+		seen[id] = true
+		status := statuses[i]
+		if status == "" || status == p.last[id] {
+			continue
+		}
+		transitions = append(transitions, HealthTransition{ContainerID: id, From: p.last[id], To: status, At: at})
+		p.last[id] = status
+	}
+	for id := range p.last {
+		if !seen[id] {
+			delete(p.last, id)
+		}
+	}
+
+	sort.Slice(transitions, func(i, j int) bool {
+		return transitions[i].ContainerID < transitions[j].ContainerID
+	})
+	return transitions
+}
@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestPoller(ids []string, check func(context.Context, string) (ContainerStatus, error)) *healthPoller {
+	return &healthPoller{
+		ids:     func() []string { return ids },
+		check:   check,
+		workers: HealthCheckConcurrency,
+		timeout: HealthCheckTimeout,
+		now:     func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) },
+		last:    make(map[string]ContainerStatus),
+	}
+}
+
+func TestHealthPoller_ReportsOnlyTransitions(t *testing.T) {
+	var mu sync.Mutex
+	statuses := map[string]ContainerStatus{"b": StatusRunning, "a": StatusRunning}
+	p := newTestPoller([]string{"b", "a"}, func(_ context.Context, id string) (ContainerStatus, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return statuses[id], nil
+	})
+	ctx := context.Background()
+
+	first := p.poll(ctx)
+	if len(first) != 2 || first[0].ContainerID != "a" || first[1].ContainerID != "b" {
+		t.Fatalf("expected first observations ordered by ID, got %+v", first)
+	}
+	if first[0].From != "" || first[0].To != StatusRunning || first[0].At.IsZero() {
+		t.Errorf("unexpected first transition %+v", first[0])
+	}
+
+	if again := p.poll(ctx); len(again) != 0 {
+		t.Errorf("expected no transitions when nothing changed, got %+v", again)
+	}
+
+	mu.Lock()
+	statuses["b"] = StatusHealthy
+	mu.Unlock()
+	changed := p.poll(ctx)
+	if len(changed) != 1 || changed[0].ContainerID != "b" || changed[0].From != StatusRunning || changed[0].To != StatusHealthy {
+		t.Errorf("expected b running → healthy, got %+v", changed)
+	}
+}
+
+func TestHealthPoller_FailedCheckKeepsLastStatus(t *testing.T) {
+	fail := false
+	p := newTestPoller([]string{"a"}, func(context.Context, string) (ContainerStatus, error) {
+		if fail {
+			return "", errors.New("daemon unavailable")
+		}
+		return StatusRunning, nil
+	})
+	ctx := context.Background()
+
+	p.poll(ctx)
+	fail = true
+	if tr := p.poll(ctx); len(tr) != 0 {
+		t.Errorf("expected no transition for a failed check, got %+v", tr)
+	}
+	if p.last["a"] != StatusRunning {
+		t.Errorf("expected last status to stay %q, got %q", StatusRunning, p.last["a"])
+	}
+}
+
+func TestHealthPoller_ForgetsRemovedContainers(t *testing.T) {
+	ids := []string{"a"}
+	p := newTestPoller(nil, func(context.Context, string) (ContainerStatus, error) { return StatusRunning, nil })
+	p.ids = func() []string { return ids }
+	ctx := context.Background()
+
+	p.poll(ctx)
+	ids = nil
+	p.poll(ctx)
+	if len(p.last) != 0 {
+		t.Fatalf("expected removed container to be forgotten, got %v", p.last)
+	}
+
+	// A container that comes back is observed afresh.
+	ids = []string{"a"}
+	if tr := p.poll(ctx); len(tr) != 1 || tr[0].From != "" {
+		t.Errorf("expected a first observation, got %+v", tr)
+	}
+}
+
+func TestHealthPoller_BoundsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	ids := make([]string, 20)
+	for i := range ids {
+		ids[i] = string(rune('a' + i))
+	}
+	p := newTestPoller(ids, func(context.Context, string) (ContainerStatus, error) {
+		n := inFlight.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inFlight.Add(-1)
+		return StatusRunning, nil
+	})
+	p.workers = 4
+
+	if tr := p.poll(context.Background()); len(tr) != len(ids) {
+		t.Fatalf("expected %d transitions, got %d", len(ids), len(tr))
+	}
+	if got := peak.Load(); got > 4 || got < 2 {
+		t.Errorf("expected between 2 and 4 concurrent checks, got %d", got)
+	}
+}
+
+func TestHealthPoller_TimesOutSlowChecks(t *testing.T) {
+	p := newTestPoller([]string{"slow", "fast"}, func(ctx context.Context, id string) (ContainerStatus, error) {
+		if id == "slow" {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return StatusRunning, nil
+	})
+	p.timeout = 20 * time.Millisecond
+
+	start := time.Now()
+	tr := p.poll(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the slow check to be abandoned, poll took %v", elapsed)
+	}
+	if len(tr) != 1 || tr[0].ContainerID != "fast" {
+		t.Errorf("expected only fast to be reported, got %+v", tr)
+	}
+}
@@ -1,8 +1,13 @@
 package docker
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
-// Orchestrator defines the contract for Docker container lifecycle management.
+// Orchestrator defines the contract for container lifecycle management.
+// DockerOrchestrator drives a real Docker daemon; SimulatedOrchestrator
+// simulates one in memory.
 type Orchestrator interface {
 	// CreateContainer creates a new container from the given config and returns its ID.
 	CreateContainer(ctx context.Context, config ContainerConfig) (string, error)
@@ -16,21 +21,62 @@ type Orchestrator interface {
 	// RemoveContainer removes a container, force-removing if still running.
 	RemoveContainer(ctx context.Context, containerID string) error
 
+	// KillContainer sends signal, such as "SIGKILL" or "SIGTERM", to a
+	// running container.
+	KillContainer(ctx context.Context, containerID, signal string) error
+
+	// PauseContainer freezes every process in a running container.
+	PauseContainer(ctx context.Context, containerID string) error
+
+	// UnpauseContainer resumes a paused container.
+	UnpauseContainer(ctx context.Context, containerID string) error
+
+	// RestartContainer stops a container, if running, and starts it again.
+	RestartContainer(ctx context.Context, containerID string) error
+
+	// DisconnectContainer detaches a container from the shared network.
+	DisconnectContainer(ctx context.Context, containerID string) error
+
+	// ConnectContainer reattaches a disconnected container to the shared
+	// network.
+	ConnectContainer(ctx context.Context, containerID string) error
+
 	// ListContainers returns info for all containers managed by this orchestrator.
 	ListContainers(ctx context.Context) ([]ContainerInfo, error)
 
+	// AdoptContainer starts managing an existing container, such as one left
+	// behind by an earlier run, so that health polling and TeardownAll cover it.
+	AdoptContainer(ctx context.Context, containerID string) error
+
 	// InspectContainer returns detailed info for a single container.
 	InspectContainer(ctx context.Context, containerID string) (*ContainerInfo, error)
 
-	// CreateNetwork creates the shared Docker bridge network.
+	// ContainerStats samples a container's resource usage. Containers that
+	// are not running report zero usage.
+	ContainerStats(ctx context.Context, containerID string) (ContainerStats, error)
+
+	// CreateNetwork creates the shared Docker bridge network, or adopts it if
+	// it already exists.
 	CreateNetwork(ctx context.Context) error
 
 	// RemoveNetwork removes the shared Docker bridge network.
 	RemoveNetwork(ctx context.Context) error
 
+	// NetworkExists reports whether the shared Docker bridge network exists.
+	NetworkExists(ctx context.Context) (bool, error)
+
 	// HealthCheck inspects a container and returns its current status.
 	HealthCheck(ctx context.Context, containerID string) (ContainerStatus, error)
 
+	// StartHealthPolling polls every managed container at the given interval
+	// in a background goroutine and reports status transitions to callback
+	// until ctx is cancelled.
+	StartHealthPolling(ctx context.Context, interval time.Duration, callback HealthTransitionCallback)
+
 	// TeardownAll stops and removes all managed containers and the shared network.
 	TeardownAll(ctx context.Context) error
+
+	// Events returns the bus on which lifecycle events are published and
+	// container create hooks are registered.
+	Events() *EventBus
 }
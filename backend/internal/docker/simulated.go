@@ -0,0 +1,670 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/errdefs"
+)
+
+// Orchestrator modes, reported to clients so a simulated backend is never
+// mistaken for a real one.
+const (
+	ModeDocker    = "docker"
+	ModeSimulated = "simulated"
+)
+
+// Default lifecycle timing for SimulatedOrchestrator.
+const (
+	DefaultSimStartDelay  = 500 * time.Millisecond
+	DefaultSimHealthDelay = 2 * time.Second
+)
+
+// simIDPrefix marks container IDs issued by the simulator.
+const simIDPrefix = "sim-"
+
+// Compile-time assertion that SimulatedOrchestrator implements Orchestrator.
+var _ Orchestrator = (*SimulatedOrchestrator)(nil)
+
+// ErrSimulatedFailure is wrapped by every error caused by an injected failure.
+var ErrSimulatedFailure = errors.New("simulated failure")
+
+// SimFailure is a fault the simulated orchestrator injects into a container.
+type SimFailure string
+
+const (
+	// SimFailCreate makes CreateContainer fail.
+	SimFailCreate SimFailure = "create"
+	// SimFailStart makes StartContainer fail.
+	SimFailStart SimFailure = "start"
+	// SimFailCrash lets the container start, then reports it stopped once
+	// the start delay has elapsed.
+	SimFailCrash SimFailure = "crash"
+	// SimFailUnhealthy makes a container with a healthcheck report
+	// unhealthy instead of healthy.
+	SimFailUnhealthy SimFailure = "unhealthy"
+)
+
+// validSimFailures is the set of recognised SimFailure values.
+var validSimFailures = map[SimFailure]bool{
+	SimFailCreate:    true,
+	SimFailStart:     true,
+	SimFailCrash:     true,
+	SimFailUnhealthy: true,
+}
+
+// SimulationConfig controls simulated container lifecycles.
+type SimulationConfig struct {
+	// StartDelay is how long a started container reports "created" before
+	// it is running.
+	StartDelay time.Duration
+	// HealthDelay is how long a running container with a healthcheck
+	// reports "running" before it turns healthy (or unhealthy).
+	HealthDelay time.Duration
+	// Failures maps container names (without ContainerNamePrefix) to the
+	// fault injected into them.
+	Failures map[string]SimFailure
+}
+
+// DefaultSimulationConfig returns the default lifecycle timing with no
+// injected failures.
+func DefaultSimulationConfig() SimulationConfig {
+	return SimulationConfig{
+		StartDelay:  DefaultSimStartDelay,
+		HealthDelay: DefaultSimHealthDelay,
+	}
+}
+
+// ParseSimFailures parses a failure spec of the form
+// "name=failure,name=failure", e.g. "user-api=crash,cache=unhealthy".
+func ParseSimFailures(spec string) (map[string]SimFailure, error) {
+	failures := make(map[string]SimFailure)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, mode, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid failure %q: expected name=failure", entry)
+		}
+		f := SimFailure(mode)
+		if !validSimFailures[f] {
+			return nil, fmt.Errorf("invalid failure %q for %q: must be create, start, crash or unhealthy", mode, name)
+		}
+		failures[name] = f
+	}
+	return failures, nil
+}
+
+// simContainer is the simulator's record of one container.
+type simContainer struct {
+	id        string
+	name      string // prefixed
+	config    ContainerConfig
+	startedAt time.Time // zero until started
+	stopped   bool
+	paused    bool
+	// disconnected is set while the container is off the shared network.
+	disconnected bool
+	failure      SimFailure
+}
+
+// SimulatedOrchestrator implements Orchestrator entirely in memory. It lets
+// the deploy, status and WebSocket flows run on machines without a Docker
+// daemon. Containers move through created → running → healthy on a timer,
+// and faults can be injected per container name.
+type SimulatedOrchestrator struct {
+	mu         sync.Mutex
+	cfg        SimulationConfig
+	now        func() time.Time
+	network    bool
+	nextID     int
+	containers map[string]*simContainer // container ID → container
+	events     *EventBus
+}
+
+// NewSimulatedOrchestrator creates a simulator with the given configuration.
+func NewSimulatedOrchestrator(cfg SimulationConfig) *SimulatedOrchestrator {
+	failures := make(map[string]SimFailure, len(cfg.Failures))
+	for name, f := range cfg.Failures {
+		failures[name] = f
+	}
+	cfg.Failures = failures
+
+	return &SimulatedOrchestrator{
+		cfg:        cfg,
+		now:        time.Now,
+		containers: make(map[string]*simContainer),
+		events:     NewEventBus(),
+	}
+}
+
+// InjectFailure sets the fault for the named container (without
+// ContainerNamePrefix). An empty failure clears it. It applies to
+// containers created afterwards.
+func (o *SimulatedOrchestrator) InjectFailure(name string, f SimFailure) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if f == "" {
+		delete(o.cfg.Failures, name)
+		return
+	}
+	o.cfg.Failures[name] = f
+}
+
+// CreateNetwork marks the shared network as present. It is idempotent.
+func (o *SimulatedOrchestrator) CreateNetwork(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("create network %q: %w", NetworkName, err)
+	}
+	o.mu.Lock()
+	existed := o.network
+	o.network = true
+	o.mu.Unlock()
+
+	if !existed {
+		o.events.Publish(Event{Type: EventNetworkCreated, Name: NetworkName})
+	}
+	return nil
+}
+
+// RemoveNetwork marks the shared network as absent. Returns nil if the
+// network does not exist.
+func (o *SimulatedOrchestrator) RemoveNetwork(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("remove network %q: %w", NetworkName, err)
+	}
+	o.mu.Lock()
+	existed := o.network
+	o.network = false
+	o.mu.Unlock()
+
+	if existed {
+		o.events.Publish(Event{Type: EventNetworkRemoved, Name: NetworkName})
+	}
+	return nil
+}
+
+// NetworkExists reports whether the shared network is present.
+func (o *SimulatedOrchestrator) NetworkExists(ctx context.Context) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("list networks: %w", err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.network, nil
+}
+
+// CreateContainer runs the pre-create hooks and records a new container.
+// Like the Docker daemon, it fails if the shared network is missing or the
+// name is already in use. No image is pulled, so image.pulled is never
+// published.
+func (o *SimulatedOrchestrator) CreateContainer(ctx context.Context, cfg ContainerConfig) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("create container %q: %w", ContainerNamePrefix+cfg.Name, err)
+	}
+	cfg, err := o.events.runPreCreate(ctx, cfg)
+	if err != nil {
+		return "", fmt.Errorf("create container %q: %w", ContainerNamePrefix+cfg.Name, err)
+	}
+
+	id, err := o.createLocked(cfg)
+	if err != nil {
+		return "", err
+	}
+	o.events.created(ctx, id, ContainerNamePrefix+cfg.Name, cfg)
+	return id, nil
+}
+
+// createLocked records a container for cfg under o.mu.
+func (o *SimulatedOrchestrator) createLocked(cfg ContainerConfig) (string, error) {
+	prefixedName := ContainerNamePrefix + cfg.Name
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	failure := o.cfg.Failures[cfg.Name]
+	if failure == SimFailCreate {
+		return "", fmt.Errorf("create container %q: %w", prefixedName, ErrSimulatedFailure)
+	}
+	if !o.network {
+		return "", fmt.Errorf("create container %q: network %q: %w", prefixedName, NetworkName, errdefs.ErrNotFound)
+	}
+	for _, c := range o.containers {
+		if c.name == prefixedName {
+			return "", fmt.Errorf("create container %q: name already in use by %s: %w", prefixedName, c.id, errdefs.ErrConflict)
+		}
+	}
+
+	o.nextID++
+	id := fmt.Sprintf("%s%012d", simIDPrefix, o.nextID)
+	o.containers[id] = &simContainer{
+		id:      id,
+		name:    prefixedName,
+		config:  cfg,
+		failure: failure,
+	}
+	return id, nil
+}
+
+// StartContainer starts a previously created container. The container
+// reports "created" until the configured start delay has elapsed.
+func (o *SimulatedOrchestrator) StartContainer(ctx context.Context, containerID string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("start container %q: %w", containerID, err)
+	}
+	o.mu.Lock()
+	c, ok := o.containers[containerID]
+	if !ok {
+		o.mu.Unlock()
+		return fmt.Errorf("start container %q: %w", containerID, errdefs.ErrNotFound)
+	}
+	if c.failure == SimFailStart {
+		o.mu.Unlock()
+		return fmt.Errorf("start container %q: %w", containerID, ErrSimulatedFailure)
+	}
+	c.startedAt = o.now()
+	c.stopped, c.paused = false, false
+	o.mu.Unlock()
+
+	o.events.Publish(Event{Type: EventContainerStarted, ContainerID: containerID, Name: c.name})
+	return nil
+}
+
+// StopContainer stops a container.
+func (o *SimulatedOrchestrator) StopContainer(ctx context.Context, containerID string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("stop container %q: %w", containerID, err)
+	}
+	o.mu.Lock()
+	c, ok := o.containers[containerID]
+	if !ok {
+		o.mu.Unlock()
+		return fmt.Errorf("stop container %q: %w", containerID, errdefs.ErrNotFound)
+	}
+	c.stopped, c.paused = true, false
+	o.mu.Unlock()
+
+	o.events.Publish(Event{Type: EventContainerStopped, ContainerID: containerID, Name: c.name})
+	return nil
+}
+
+// RemoveContainer removes a container, whether or not it is running.
+func (o *SimulatedOrchestrator) RemoveContainer(ctx context.Context, containerID string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("remove container %q: %w", containerID, err)
+	}
+	o.mu.Lock()
+	c, ok := o.containers[containerID]
+	if !ok {
+		o.mu.Unlock()
+		return fmt.Errorf("remove container %q: %w", containerID, errdefs.ErrNotFound)
+	}
+	delete(o.containers, containerID)
+	o.mu.Unlock()
+
+	o.events.Publish(Event{Type: EventContainerRemoved, ContainerID: containerID, Name: c.name})
+	return nil
+}
+
+// KillContainer stops a running container. Every signal is treated as
+// fatal.
+func (o *SimulatedOrchestrator) KillContainer(ctx context.Context, containerID, signal string) error {
+	c, err := o.chaosLocked(ctx, "kill", containerID, func(c *simContainer) error {
+		if c.stopped || c.startedAt.IsZero() {
+			return fmt.Errorf("container is not running: %w", errdefs.ErrConflict)
+		}
+		c.stopped, c.paused = true, false
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	o.events.Publish(Event{Type: EventContainerKilled, ContainerID: containerID, Name: c.name, Signal: signal})
+	return nil
+}
+
+// PauseContainer marks a running container paused until it is unpaused.
+func (o *SimulatedOrchestrator) PauseContainer(ctx context.Context, containerID string) error {
+	c, err := o.chaosLocked(ctx, "pause", containerID, func(c *simContainer) error {
+		switch {
+		case c.stopped || c.startedAt.IsZero():
+			return fmt.Errorf("container is not running: %w", errdefs.ErrConflict)
+		case c.paused:
+			return fmt.Errorf("container is already paused: %w", errdefs.ErrConflict)
+		}
+		c.paused = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	o.events.Publish(Event{Type: EventContainerPaused, ContainerID: containerID, Name: c.name})
+	return nil
+}
+
+// UnpauseContainer resumes a paused container.
+func (o *SimulatedOrchestrator) UnpauseContainer(ctx context.Context, containerID string) error {
+	c, err := o.chaosLocked(ctx, "unpause", containerID, func(c *simContainer) error {
+		if !c.paused {
+			return fmt.Errorf("container is not paused: %w", errdefs.ErrConflict)
+		}
+		c.paused = false
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	o.events.Publish(Event{Type: EventContainerUnpaused, ContainerID: containerID, Name: c.name})
+	return nil
+}
+
+// RestartContainer starts a container afresh, running or not. It goes
+// through the start delay again.
+func (o *SimulatedOrchestrator) RestartContainer(ctx context.Context, containerID string) error {
+	c, err := o.chaosLocked(ctx, "restart", containerID, func(c *simContainer) error {
+		c.startedAt = o.now()
+		c.stopped, c.paused = false, false
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	o.events.Publish(Event{Type: EventContainerRestarted, ContainerID: containerID, Name: c.name})
+	return nil
+}
+
+// DisconnectContainer marks a container disconnected from the shared
+// network. Its status is unaffected.
+func (o *SimulatedOrchestrator) DisconnectContainer(ctx context.Context, containerID string) error {
+	c, err := o.chaosLocked(ctx, "disconnect", containerID, func(c *simContainer) error {
+		if c.disconnected {
+			return fmt.Errorf("container is not connected to %q: %w", NetworkName, errdefs.ErrConflict)
+		}
+		c.disconnected = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	o.events.Publish(Event{Type: EventContainerDisconnected, ContainerID: containerID, Name: c.name})
+	return nil
+}
+
+// ConnectContainer reconnects a disconnected container to the shared
+// network.
+func (o *SimulatedOrchestrator) ConnectContainer(ctx context.Context, containerID string) error {
+	c, err := o.chaosLocked(ctx, "connect", containerID, func(c *simContainer) error {
+		if !c.disconnected {
+			return fmt.Errorf("container is already connected to %q: %w", NetworkName, errdefs.ErrConflict)
+		}
+		c.disconnected = false
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	o.events.Publish(Event{Type: EventContainerConnected, ContainerID: containerID, Name: c.name})
+	return nil
+}
+
+// chaosLocked applies fn to a container under o.mu, wrapping errors with
+// the operation name like the Docker daemon's errors are wrapped.
+func (o *SimulatedOrchestrator) chaosLocked(ctx context.Context, op, containerID string, fn func(c *simContainer) error) (*simContainer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s container %q: %w", op, containerID, err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	c, ok := o.containers[containerID]
+	if !ok {
+		return nil, fmt.Errorf("%s container %q: %w", op, containerID, errdefs.ErrNotFound)
+	}
+	if err := fn(c); err != nil {
+		return nil, fmt.Errorf("%s container %q: %w", op, containerID, err)
+	}
+	return c, nil
+}
+
+// ListContainers returns all simulated containers ordered by name.
+func (o *SimulatedOrchestrator) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	infos := make([]ContainerInfo, 0, len(o.containers))
+	for _, c := range o.containers {
+		infos = append(infos, o.infoLocked(c, now))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// Simulated resource usage. Each container gets a fixed share of these,
+// derived from its name, so samples are stable but differ by container.
+const (
+	simMemoryLimit    = 2 << 30 // bytes
+	simBaseMemory     = 48 << 20
+	simMemorySpread   = 64 << 20
+	simBaseCPUPercent = 2.0
+	simCPUSpread      = 8
+	simCPUSwing       = 3.0
+	simRxBytesPerSec  = 1024
+	simRxSpreadPerSec = 1024
+	simCPUPeriod      = 10.0 // seconds
+)
+
+// ContainerStats reports synthetic usage for running containers: steady
+// memory, CPU oscillating around a per-container level and network
+// counters growing with uptime. Other containers report zero usage.
+func (o *SimulatedOrchestrator) ContainerStats(ctx context.Context, containerID string) (ContainerStats, error) {
+	if err := ctx.Err(); err != nil {
+		return ContainerStats{}, fmt.Errorf("stats container %q: %w", containerID, err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	c, ok := o.containers[containerID]
+	if !ok {
+		return ContainerStats{}, fmt.Errorf("stats container %q: %w", containerID, errdefs.ErrNotFound)
+	}
+	now := o.now()
+	stats := ContainerStats{MemoryLimitBytes: simMemoryLimit}
+	switch o.statusLocked(c, now) {
+	case StatusRunning, StatusHealthy, StatusUnhealthy, StatusPaused:
+	default:
+		return stats, nil
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(c.name))
+	seed := h.Sum32()
+	uptime := now.Sub(c.startedAt).Seconds()
+
+	stats.MemoryBytes = simBaseMemory + uint64(seed%simMemorySpread)
+	rx := uint64(uptime * float64(simRxBytesPerSec+seed%simRxSpreadPerSec))
+	stats.NetworkRxBytes, stats.NetworkTxBytes = rx, rx/2
+	if !c.paused {
+		phase := uptime/simCPUPeriod + float64(seed%360)
+		stats.CPUPercent = simBaseCPUPercent + float64(seed%simCPUSpread) + simCPUSwing*math.Sin(phase)
+		stats.CPUPercent = max(stats.CPUPercent, 0)
+	}
+	return stats, nil
+}
+
+// AdoptContainer checks that the container exists. Every simulated container
+// is already managed.
+func (o *SimulatedOrchestrator) AdoptContainer(ctx context.Context, containerID string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("adopt container %q: %w", containerID, err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.containers[containerID]; !ok {
+		return fmt.Errorf("adopt container %q: %w", containerID, errdefs.ErrNotFound)
+	}
+	return nil
+}
+
+// InspectContainer returns the current state of a single container.
+func (o *SimulatedOrchestrator) InspectContainer(ctx context.Context, containerID string) (*ContainerInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("inspect container %q: %w", containerID, err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	c, ok := o.containers[containerID]
+	if !ok {
+		return nil, fmt.Errorf("inspect container %q: %w", containerID, errdefs.ErrNotFound)
+	}
+	info := o.infoLocked(c, o.now())
+	return &info, nil
+}
+
+// HealthCheck returns a container's current status. A missing container
+// reports StatusError, matching DockerOrchestrator.
+func (o *SimulatedOrchestrator) HealthCheck(ctx context.Context, containerID string) (ContainerStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("health check container %q: %w", containerID, err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	c, ok := o.containers[containerID]
+	if !ok {
+		return StatusError, nil
+	}
+	return o.statusLocked(c, o.now()), nil
+}
+
+// StartHealthPolling polls all simulated containers at the given interval
+// and reports status transitions to the callback, publishing a
+// container.health event for each, until ctx is cancelled.
+func (o *SimulatedOrchestrator) StartHealthPolling(ctx context.Context, interval time.Duration, callback HealthTransitionCallback) {
+	pollHealth(ctx, interval, o.containerIDs, o.HealthCheck, func(transitions []HealthTransition) {
+		o.events.publishHealth(transitions, o.containerName)
+		callback(transitions)
+	})
+}
+
+// TeardownAll removes every container and the shared network. It is
+// idempotent.
+func (o *SimulatedOrchestrator) TeardownAll(_ context.Context) error {
+	o.mu.Lock()
+	removed := make([]*simContainer, 0, len(o.containers))
+	for _, c := range o.containers {
+		removed = append(removed, c)
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].id < removed[j].id })
+	network := o.network
+	o.containers = make(map[string]*simContainer)
+	o.network = false
+	o.mu.Unlock()
+
+	for _, c := range removed {
+		if !c.stopped {
+			o.events.Publish(Event{Type: EventContainerStopped, ContainerID: c.id, Name: c.name})
+		}
+		o.events.Publish(Event{Type: EventContainerRemoved, ContainerID: c.id, Name: c.name})
+	}
+	if network {
+		o.events.Publish(Event{Type: EventNetworkRemoved, Name: NetworkName})
+	}
+	return nil
+}
+
+// Events returns the simulator's event bus.
+func (o *SimulatedOrchestrator) Events() *EventBus {
+	return o.events
+}
+
+// containerName returns the name of a simulated container, or "" if it
+// does not exist.
+func (o *SimulatedOrchestrator) containerName(id string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if c, ok := o.containers[id]; ok {
+		return c.name
+	}
+	return ""
+}
+
+// containerIDs returns the IDs of all simulated containers.
+func (o *SimulatedOrchestrator) containerIDs() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	ids := make([]string, 0, len(o.containers))
+	for id := range o.containers {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// infoLocked builds the ContainerInfo for c. Caller must hold o.mu.
+func (o *SimulatedOrchestrator) infoLocked(c *simContainer, now time.Time) ContainerInfo {
+	var ports map[string]string
+	if len(c.config.Ports) > 0 {
+		ports = make(map[string]string, len(c.config.Ports))
+		for host, ctr := range c.config.Ports {
+			ports[host] = ctr
+		}
+	}
+	labels := make(map[string]string, len(c.config.Labels)+1)
+	for k, v := range c.config.Labels {
+		labels[k] = v
+	}
+	labels[LabelManaged] = "true"
+	return ContainerInfo{
+		ID:     c.id,
+		Name:   c.name,
+		Image:  c.config.Image,
+		Status: o.statusLocked(c, now),
+		Ports:  ports,
+		Labels: labels,
+	}
+}
+
+// statusLocked derives c's status from the time since it was started.
+// Caller must hold o.mu.
+func (o *SimulatedOrchestrator) statusLocked(c *simContainer, now time.Time) ContainerStatus {
+	switch {
+	case c.stopped:
+		return StatusStopped
+	case c.startedAt.IsZero():
+		return StatusCreated
+	case c.paused:
+		return StatusPaused
+	}
+
+	elapsed := now.Sub(c.startedAt)
+	switch {
+	case elapsed < o.cfg.StartDelay:
+		return StatusCreated
+	case c.failure == SimFailCrash:
+		return StatusStopped
+	case c.config.Healthcheck == nil, elapsed < o.cfg.StartDelay+o.cfg.HealthDelay:
+		return StatusRunning
+	case c.failure == SimFailUnhealthy:
+		return StatusUnhealthy
+	default:
+		return StatusHealthy
+	}
+}
@@ -0,0 +1,390 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/containerd/errdefs"
+)
+
+// newTestSimulator returns a simulator with a controllable clock.
+func newTestSimulator(cfg SimulationConfig) (*SimulatedOrchestrator, *time.Time) {
+	o := NewSimulatedOrchestrator(cfg)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	o.now = func() time.Time { return now }
+	return o, &now
+}
+
+func TestSimulated_Lifecycle(t *testing.T) {
+	o, now := newTestSimulator(SimulationConfig{StartDelay: time.Second, HealthDelay: 2 * time.Second})
+	ctx := context.Background()
+
+	if err := o.CreateNetwork(ctx); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	id, err := o.CreateContainer(ctx, ContainerConfig{
+		Name:        "db",
+		Image:       "postgres:16",
+		Ports:       map[string]string{"10000": "5432"},
+		Healthcheck: &HealthcheckConfig{Test: []string{"CMD", "true"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateContainer: %v", err)
+	}
+
+	steps := []struct {
+		advance time.Duration
+		want    ContainerStatus
+	}{
+		{0, StatusCreated},
+		{500 * time.Millisecond, StatusCreated},
+		{time.Second, StatusRunning},
+		{2 * time.Second, StatusHealthy},
+	}
+
+	if err := o.StartContainer(ctx, id); err != nil {
+		t.Fatalf("StartContainer: %v", err)
+	}
+	for _, s := range steps {
+		*now = now.Add(s.advance)
+		got, err := o.HealthCheck(ctx, id)
+		if err != nil {
+			t.Fatalf("HealthCheck: %v", err)
+		}
+		if got != s.want {
+			t.Errorf("after +%v: expected %q, got %q", s.advance, s.want, got)
+		}
+	}
+
+	infos, err := o.ListContainers(ctx)
+	if err != nil {
+		t.Fatalf("ListContainers: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name != "heph-db" || infos[0].Ports["10000"] != "5432" {
+		t.Errorf("unexpected containers %+v", infos)
+	}
+
+	if err := o.StopContainer(ctx, id); err != nil {
+		t.Fatalf("StopContainer: %v", err)
+	}
+	if got, _ := o.HealthCheck(ctx, id); got != StatusStopped {
+		t.Errorf("expected stopped, got %q", got)
+	}
+	if err := o.RemoveContainer(ctx, id); err != nil {
+		t.Fatalf("RemoveContainer: %v", err)
+	}
+	if got, _ := o.HealthCheck(ctx, id); got != StatusError {
+		t.Errorf("expected error status for removed container, got %q", got)
+	}
+}
+
+func TestSimulated_NoHealthcheckStaysRunning(t *testing.T) {
+	o, now := newTestSimulator(SimulationConfig{StartDelay: time.Second, HealthDelay: time.Second})
+	ctx := context.Background()
+	_ = o.CreateNetwork(ctx)
+	id, _ := o.CreateContainer(ctx, ContainerConfig{Name: "api", Image: "x"})
+	_ = o.StartContainer(ctx, id)
+
+	*now = now.Add(time.Hour)
+	if got, _ := o.HealthCheck(ctx, id); got != StatusRunning {
+		t.Errorf("expected running, got %q", got)
+	}
+}
+
+func TestSimulated_InjectedFailures(t *testing.T) {
+	o, now := newTestSimulator(SimulationConfig{
+		StartDelay: time.Second,
+		Failures: map[string]SimFailure{
+			"bad-create": SimFailCreate,
+			"bad-start":  SimFailStart,
+			"crashy":     SimFailCrash,
+			"sick":       SimFailUnhealthy,
+		},
+	})
+	ctx := context.Background()
+	_ = o.CreateNetwork(ctx)
+
+	if _, err := o.CreateContainer(ctx, ContainerConfig{Name: "bad-create"}); !errors.Is(err, ErrSimulatedFailure) {
+		t.Errorf("expected simulated create failure, got %v", err)
+	}
+
+	id, err := o.CreateContainer(ctx, ContainerConfig{Name: "bad-start"})
+	if err != nil {
+		t.Fatalf("CreateContainer: %v", err)
+	}
+	if err := o.StartContainer(ctx, id); !errors.Is(err, ErrSimulatedFailure) {
+		t.Errorf("expected simulated start failure, got %v", err)
+	}
+
+	crashy, _ := o.CreateContainer(ctx, ContainerConfig{Name: "crashy"})
+	sick, _ := o.CreateContainer(ctx, ContainerConfig{Name: "sick", Healthcheck: &HealthcheckConfig{Test: []string{"CMD", "true"}}})
+	_ = o.StartContainer(ctx, crashy)
+	_ = o.StartContainer(ctx, sick)
+
+	*now = now.Add(time.Minute)
+	if got, _ := o.HealthCheck(ctx, crashy); got != StatusStopped {
+		t.Errorf("expected crashed container to be stopped, got %q", got)
+	}
+	if got, _ := o.HealthCheck(ctx, sick); got != StatusUnhealthy {
+		t.Errorf("expected unhealthy, got %q", got)
+	}
+
+	o.InjectFailure("bad-create", "")
+	if _, err := o.CreateContainer(ctx, ContainerConfig{Name: "bad-create"}); err != nil {
+		t.Errorf("expected cleared failure, got %v", err)
+	}
+}
+
+func TestSimulated_DaemonLikeErrors(t *testing.T) {
+	o, _ := newTestSimulator(DefaultSimulationConfig())
+	ctx := context.Background()
+
+	if _, err := o.CreateContainer(ctx, ContainerConfig{Name: "a"}); !errdefs.IsNotFound(err) {
+		t.Errorf("expected not-found error without network, got %v", err)
+	}
+
+	_ = o.CreateNetwork(ctx)
+	if _, err := o.CreateContainer(ctx, ContainerConfig{Name: "a"}); err != nil {
+		t.Fatalf("CreateContainer: %v", err)
+	}
+	if _, err := o.CreateContainer(ctx, ContainerConfig{Name: "a"}); !errdefs.IsConflict(err) {
+		t.Errorf("expected name conflict, got %v", err)
+	}
+	if err := o.StartContainer(ctx, "missing"); !errdefs.IsNotFound(err) {
+		t.Errorf("expected not-found error, got %v", err)
+	}
+}
+
+func TestSimulated_ChaosOperations(t *testing.T) {
+	o, now := newTestSimulator(SimulationConfig{StartDelay: time.Second})
+	ctx := context.Background()
+	_ = o.CreateNetwork(ctx)
+	id, _ := o.CreateContainer(ctx, ContainerConfig{Name: "api"})
+	var events []EventType
+	o.Events().Subscribe(func(e Event) { events = append(events, e.Type) })
+
+	if err := o.PauseContainer(ctx, id); !errdefs.IsConflict(err) {
+		t.Errorf("expected pausing a created container to conflict, got %v", err)
+	}
+	_ = o.StartContainer(ctx, id)
+	*now = now.Add(time.Second)
+
+	status := func() ContainerStatus {
+		st, _ := o.HealthCheck(ctx, id)
+		return st
+	}
+	steps := []struct {
+		name string
+		do   func() error
+		want ContainerStatus
+	}{
+		{"pause", func() error { return o.PauseContainer(ctx, id) }, StatusPaused},
+		{"unpause", func() error { return o.UnpauseContainer(ctx, id) }, StatusRunning},
+		{"disconnect", func() error { return o.DisconnectContainer(ctx, id) }, StatusRunning},
+		{"connect", func() error { return o.ConnectContainer(ctx, id) }, StatusRunning},
+		{"kill", func() error { return o.KillContainer(ctx, id, "SIGKILL") }, StatusStopped},
+		{"restart", func() error { return o.RestartContainer(ctx, id) }, StatusCreated},
+	}
+	for _, s := range steps {
+		if err := s.do(); err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		if got := status(); got != s.want {
+			t.Errorf("after %s: expected %q, got %q", s.name, s.want, got)
+		}
+	}
+
+	if err := o.UnpauseContainer(ctx, id); !errdefs.IsConflict(err) {
+		t.Errorf("expected unpausing a running container to conflict, got %v", err)
+	}
+	if err := o.ConnectContainer(ctx, id); !errdefs.IsConflict(err) {
+		t.Errorf("expected connecting a connected container to conflict, got %v", err)
+	}
+	if err := o.KillContainer(ctx, "missing", "SIGKILL"); !errdefs.IsNotFound(err) {
+		t.Errorf("expected not-found error, got %v", err)
+	}
+
+	want := []EventType{
+		EventContainerStarted,
+		EventContainerPaused, EventContainerUnpaused,
+		EventContainerDisconnected, EventContainerConnected,
+		EventContainerKilled, EventContainerRestarted,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d: expected %q, got %q", i, want[i], events[i])
+		}
+	}
+}
+
+func TestSimulated_TeardownAll(t *testing.T) {
+	o, _ := newTestSimulator(DefaultSimulationConfig())
+	ctx := context.Background()
+	_ = o.CreateNetwork(ctx)
+	_, _ = o.CreateContainer(ctx, ContainerConfig{Name: "a"})
+	_, _ = o.CreateContainer(ctx, ContainerConfig{Name: "b"})
+
+	if err := o.TeardownAll(ctx); err != nil {
+		t.Fatalf("TeardownAll: %v", err)
+	}
+	infos, _ := o.ListContainers(ctx)
+	if len(infos) != 0 {
+		t.Errorf("expected no containers after teardown, got %d", len(infos))
+	}
+	if _, err := o.CreateContainer(ctx, ContainerConfig{Name: "a"}); err == nil {
+		t.Error("expected network to be removed by teardown")
+	}
+}
+
+func TestSimulated_PublishesEvents(t *testing.T) {
+	o, _ := newTestSimulator(DefaultSimulationConfig())
+	ctx := context.Background()
+	var events []EventType
+	o.Events().Subscribe(func(e Event) { events = append(events, e.Type) })
+	o.Events().OnPreCreate(func(_ context.Context, cfg *ContainerConfig) error {
+		if cfg.Name == "blocked" {
+			return errors.New("vetoed")
+		}
+		return nil
+	})
+
+	_ = o.CreateNetwork(ctx)
+	_ = o.CreateNetwork(ctx)
+	if _, err := o.CreateContainer(ctx, ContainerConfig{Name: "blocked"}); err == nil {
+		t.Error("expected the pre-create hook to veto the container")
+	}
+	a, _ := o.CreateContainer(ctx, ContainerConfig{Name: "a"})
+	_ = o.StartContainer(ctx, a)
+	_, _ = o.CreateContainer(ctx, ContainerConfig{Name: "b"})
+	if err := o.TeardownAll(ctx); err != nil {
+		t.Fatalf("TeardownAll: %v", err)
+	}
+
+	want := []EventType{
+		EventNetworkCreated,
+		EventContainerCreated, EventContainerStarted, EventContainerCreated,
+		EventContainerStopped, EventContainerRemoved, EventContainerStopped, EventContainerRemoved,
+		EventNetworkRemoved,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d: expected %q, got %q", i, want[i], events[i])
+		}
+	}
+}
+
+func TestSimulated_HealthPolling(t *testing.T) {
+	o := NewSimulatedOrchestrator(SimulationConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_ = o.CreateNetwork(ctx)
+	id, _ := o.CreateContainer(ctx, ContainerConfig{Name: "a"})
+	_ = o.StartContainer(ctx, id)
+
+	events := make(chan Event, 1)
+	o.Events().Subscribe(func(e Event) {
+		select {
+		case events <- e:
+		default:
+		}
+	}, EventHealthChanged)
+	got := make(chan []HealthTransition, 1)
+	o.StartHealthPolling(ctx, 10*time.Millisecond, func(tr []HealthTransition) {
+		select {
+		case got <- tr:
+		default:
+		}
+	})
+
+	select {
+	case tr := <-got:
+		if len(tr) != 1 || tr[0].ContainerID != id || tr[0].From != "" || tr[0].To != StatusRunning {
+			t.Errorf("expected first observation of %s as running, got %+v", id, tr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for health poll")
+	}
+	select {
+	case e := <-events:
+		if e.ContainerID != id || e.Name != "heph-a" || e.Health == nil || e.Health.To != StatusRunning {
+			t.Errorf("unexpected health event %+v", e)
+		}
+	default:
+		t.Error("expected a health event to be published before the callback")
+	}
+}
+
+func TestSimulated_ContainerStats(t *testing.T) {
+	o, now := newTestSimulator(SimulationConfig{})
+	ctx := context.Background()
+	if err := o.CreateNetwork(ctx); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+
+	api, _ := o.CreateContainer(ctx, ContainerConfig{Name: "api", Image: "x"})
+	db, _ := o.CreateContainer(ctx, ContainerConfig{Name: "db", Image: "x"})
+	if stats, err := o.ContainerStats(ctx, api); err != nil || stats.MemoryBytes != 0 || stats.CPUPercent != 0 {
+		t.Errorf("expected no usage before start, got %+v (%v)", stats, err)
+	}
+	for _, id := range []string{api, db} {
+		if err := o.StartContainer(ctx, id); err != nil {
+			t.Fatalf("StartContainer: %v", err)
+		}
+	}
+
+	*now = now.Add(10 * time.Second)
+	first, err := o.ContainerStats(ctx, api)
+	if err != nil {
+		t.Fatalf("ContainerStats: %v", err)
+	}
+	if first.MemoryBytes == 0 || first.MemoryLimitBytes == 0 || first.NetworkRxBytes == 0 {
+		t.Errorf("expected usage while running, got %+v", first)
+	}
+	if again, _ := o.ContainerStats(ctx, api); again != first {
+		t.Errorf("expected the same sample at the same time, got %+v and %+v", first, again)
+	}
+	if other, _ := o.ContainerStats(ctx, db); other.MemoryBytes == first.MemoryBytes {
+		t.Errorf("expected containers to differ, both use %d bytes", first.MemoryBytes)
+	}
+
+	*now = now.Add(10 * time.Second)
+	later, _ := o.ContainerStats(ctx, api)
+	if later.NetworkRxBytes <= first.NetworkRxBytes || later.NetworkTxBytes <= first.NetworkTxBytes {
+		t.Errorf("expected network counters to grow, got %+v then %+v", first, later)
+	}
+
+	if err := o.PauseContainer(ctx, api); err != nil {
+		t.Fatalf("PauseContainer: %v", err)
+	}
+	if paused, _ := o.ContainerStats(ctx, api); paused.CPUPercent != 0 || paused.MemoryBytes == 0 {
+		t.Errorf("expected a paused container to hold memory without CPU, got %+v", paused)
+	}
+
+	if _, err := o.ContainerStats(ctx, "missing"); !errdefs.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestParseSimFailures(t *testing.T) {
+	got, err := ParseSimFailures(" api=crash, cache=unhealthy ,")
+	if err != nil {
+		t.Fatalf("ParseSimFailures: %v", err)
+	}
+	if len(got) != 2 || got["api"] != SimFailCrash || got["cache"] != SimFailUnhealthy {
+		t.Errorf("unexpected failures %v", got)
+	}
+
+	for _, bad := range []string{"api", "=crash", "api=explode"} {
+		if _, err := ParseSimFailures(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
@@ -11,55 +11,80 @@ import (
 	"github.com/stwalsh4118/hephaestus/backend/internal/openapi"
 )
 
-// Prism container configuration constants.
+// Mock server container configuration constants.
 const (
 	// specDir is the host-side directory where generated OpenAPI specs are written.
 	specDir = "heph-specs"
-	// containerSpecPath is the path inside the Prism container where the spec is mounted.
+	// containerSpecPath is the path inside the mock server container where the spec is mounted.
 	containerSpecPath = "/tmp/spec.json"
 )
 
-// newPrismCmd returns the command passed to the Prism container to serve a mounted spec.
-func newPrismCmd() []string {
-	return []string{"mock", "-h", "0.0.0.0", containerSpecPath}
+// newMockCmd returns the command passed to the mock server container to
+// serve a mounted spec.
+func newMockCmd() []string {
+	return []string{"-addr", ":" + PortAPIService, "-spec", containerSpecPath}
 }
 
-// APIServiceTemplate builds a ContainerConfig for API Service (Prism) nodes.
-type APIServiceTemplate struct{}
+// APIServiceTemplate builds a ContainerConfig for API Service nodes, which
+// run the hephaestus mock server.
+type APIServiceTemplate struct {
+	// SpecDir is the host directory generated specs are written to. When
+	// empty, specs go to the shared heph-specs directory under os.TempDir.
+	SpecDir string
+	// Image is the mock server image; empty means ImageAPIService.
+	Image string
+}
 
 // Build creates a docker.ContainerConfig for an API service node.
 // It parses endpoint config, generates an OpenAPI spec, writes it to disk,
-// and mounts it into the Prism container.
-func (t *APIServiceTemplate) Build(node model.DiagramNode, hostPort string, _ ...string) (docker.ContainerConfig, error) {
-	hostname := sanitizeName(node.Name)
-
-	endpoints, err := parseEndpoints(node)
+// and mounts it into the mock server container. Downstream calls are added
+// to the spec later, once the node's edge targets are known. The mock
+// server's metrics port is published on hostPorts[0], if given, so the
+// backend can read its counters.
+func (t *APIServiceTemplate) Build(node model.DiagramNode, hostname, hostPort string, hostPorts ...string) (docker.ContainerConfig, error) {
+	hostSpecPath, err := t.writeSpec(node, hostname, nil)
 	if err != nil {
 		return docker.ContainerConfig{}, err
 	}
 
-	specBytes, err := openapi.GenerateSpec(endpoints, node.Name)
-	if err != nil {
-		return docker.ContainerConfig{}, fmt.Errorf("generate openapi spec for node %q: %w", node.ID, err)
-	}
-
-	hostSpecPath, err := writeSpecFile(hostname, specBytes)
-	if err != nil {
-		return docker.ContainerConfig{}, fmt.Errorf("write spec file for node %q: %w", node.ID, err)
+	ports := map[string]string{hostPort: PortAPIService}
+	if len(hostPorts) > 0 {
+		ports[hostPorts[0]] = PortAPIServiceMetrics
 	}
 
 	return docker.ContainerConfig{
-		Image:       ImageAPIService,
+		Image:       t.image(),
 		Name:        hostname,
-		Cmd:         newPrismCmd(),
+		Cmd:         newMockCmd(),
 		Env:         map[string]string{},
-		Ports:       map[string]string{hostPort: PortAPIService},
+		Ports:       ports,
 		Volumes:     map[string]string{hostSpecPath: containerSpecPath},
 		Hostname:    hostname,
 		NetworkName: docker.NetworkName,
 	}, nil
 }
 
+// writeSpec generates the node's OpenAPI spec, with downstream[i] as the
+// calls of endpoint i, and writes it to the spec directory. Returns the
+// host path of the written file.
+func (t *APIServiceTemplate) writeSpec(node model.DiagramNode, hostname string, downstream [][]openapi.Downstream) (string, error) {
+	endpoints, err := parseEndpoints(node)
+	if err != nil {
+		return "", err
+	}
+
+	specBytes, err := openapi.GenerateMockSpec(endpoints, node.Name, downstream)
+	if err != nil {
+		return "", fmt.Errorf("generate openapi spec for node %q: %w", node.ID, err)
+	}
+
+	hostSpecPath, err := writeSpecFile(t.specDir(), hostname, specBytes)
+	if err != nil {
+		return "", fmt.Errorf("write spec file for node %q: %w", node.ID, err)
+	}
+	return hostSpecPath, nil
+}
+
 // parseEndpoints extracts the endpoint definitions from a node's config JSON.
 // Returns nil endpoints if the config is empty. Returns an error if the config
 // is present but cannot be parsed.
@@ -76,10 +101,30 @@ func parseEndpoints(node model.DiagramNode) ([]model.Endpoint, error) {
 	return cfg.Endpoints, nil
 }
 
-// writeSpecFile writes the OpenAPI spec bytes to a temp file on the host.
+// image returns the mock server image containers are created from.
+func (t *APIServiceTemplate) image() string {
+	if t.Image != "" {
+		return t.Image
+	}
+	return ImageAPIService
+}
+
+// specDir returns the directory generated specs are written to.
+func (t *APIServiceTemplate) specDir() string {
+	if t.SpecDir != "" {
+		return t.SpecDir
+	}
+	return DefaultSpecDir()
+}
+
+// DefaultSpecDir returns the shared host directory for generated OpenAPI specs.
+func DefaultSpecDir() string {
+	return filepath.Join(os.TempDir(), specDir)
+}
+
+// writeSpecFile writes the OpenAPI spec bytes into dir on the host.
 // Returns the absolute path to the written file.
-func writeSpecFile(containerName string, specBytes []byte) (string, error) {
-	dir := filepath.Join(os.TempDir(), specDir)
+func writeSpecFile(dir, containerName string, specBytes []byte) (string, error) {
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return "", fmt.Errorf("create spec directory %q: %w", dir, err)
 	}
@@ -0,0 +1,85 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// CustomContainerTemplate builds a ContainerConfig for custom-container nodes,
+// which run an arbitrary user-supplied image.
+type CustomContainerTemplate struct{}
+
+// Build creates a docker.ContainerConfig for a custom-container node.
+// Each exposed port in the config is bound to one allocated host port, in
+// order: the first port uses hostPort, the rest consume hostPorts.
+func (t *CustomContainerTemplate) Build(node model.DiagramNode, hostname, hostPort string, hostPorts ...string) (docker.ContainerConfig, error) {
+	cfg, err := parseCustomContainerConfig(node)
+	if err != nil {
+		return docker.ContainerConfig{}, err
+	}
+	if err := model.ValidateImageReference(cfg.Image); err != nil {
+		return docker.ContainerConfig{}, fmt.Errorf("custom-container node %q: %w", node.ID, err)
+	}
+
+	allocated := hostPorts
+	if hostPort != "" {
+		allocated = append([]string{hostPort}, hostPorts...)
+	}
+	if len(allocated) < len(cfg.Ports) {
+		return docker.ContainerConfig{}, fmt.Errorf("custom-container node %q exposes %d ports but %d host ports were allocated", node.ID, len(cfg.Ports), len(allocated))
+	}
+
+	ports := make(map[string]string, len(cfg.Ports))
+	for i, p := range cfg.Ports {
+		ports[allocated[i]] = strconv.Itoa(p)
+	}
+
+	env := make(map[string]string, len(cfg.Env))
+	for k, v := range cfg.Env {
+		env[k] = v
+	}
+
+	return docker.ContainerConfig{
+		Image:       cfg.Image,
+		Name:        hostname,
+		Cmd:         cfg.Command,
+		Entrypoint:  cfg.Entrypoint,
+		Env:         env,
+		Ports:       ports,
+		Hostname:    hostname,
+		NetworkName: docker.NetworkName,
+		Healthcheck: toHealthcheckConfig(cfg.Healthcheck),
+	}, nil
+}
+
+// parseCustomContainerConfig decodes a custom-container node's config.
+// Unlike other service types the config is mandatory, since it carries the image.
+func parseCustomContainerConfig(node model.DiagramNode) (model.CustomContainerConfig, error) {
+	var cfg model.CustomContainerConfig
+	if len(node.Config) == 0 {
+		return cfg, fmt.Errorf("custom-container node %q has no config", node.ID)
+	}
+	if err := json.Unmarshal(node.Config, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse custom-container config for node %q: %w", node.ID, err)
+	}
+	return cfg, nil
+}
+
+// toHealthcheckConfig converts the diagram healthcheck into a docker.HealthcheckConfig.
+func toHealthcheckConfig(hc *model.Healthcheck) *docker.HealthcheckConfig {
+	if hc == nil || len(hc.Test) == 0 {
+		return nil
+	}
+	return &docker.HealthcheckConfig{
+		Test:        hc.Test,
+		Interval:    time.Duration(hc.IntervalSeconds) * time.Second,
+		Timeout:     time.Duration(hc.TimeoutSeconds) * time.Second,
+		Retries:     hc.Retries,
+		StartPeriod: time.Duration(hc.StartPeriodSeconds) * time.Second,
+	}
+}
@@ -0,0 +1,106 @@
+package templates
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/openapi"
+)
+
+// pathParam matches an OpenAPI path template parameter such as "{id}".
+var pathParam = regexp.MustCompile(`\{[^}/]*\}`)
+
+// pathParamValue is substituted for path parameters in downstream URLs.
+const pathParamValue = "1"
+
+// wireDownstream rewrites the spec of every api-service node with outgoing
+// edges so that the mock server calls the edge targets before responding.
+// It runs after all configs are built, since targets' hostnames, ports and
+// credentials are only known then. Nothing happens when the api-service
+// template is not an APIServiceTemplate.
+func (t *Translator) wireDownstream(order []string, nodeMap map[string]model.DiagramNode, configs []docker.ContainerConfig, targets map[string]edgeTarget, deps map[string][]string) error {
+	tmpl, ok := t.registry[model.ServiceTypeAPIService].(*APIServiceTemplate)
+	if !ok {
+		return nil
+	}
+	for i, nodeID := range order {
+		node := nodeMap[nodeID]
+		if node.Type != model.ServiceTypeAPIService || len(deps[nodeID]) == 0 {
+			continue
+		}
+		endpoints, err := parseEndpoints(node)
+		if err != nil {
+			return err
+		}
+
+		downstream := make([][]openapi.Downstream, len(endpoints))
+		for j, ep := range endpoints {
+			downstream[j] = resolveDownstream(configs[i].Hostname, ep, deps[nodeID], nodeMap, configs, targets)
+		}
+		if _, err := tmpl.writeSpec(node, configs[i].Hostname, downstream); err != nil {
+			return fmt.Errorf("wire downstream calls for node %q: %w", nodeID, err)
+		}
+	}
+	return nil
+}
+
+// resolveDownstream returns the calls endpoint ep of the service named
+// source makes. Without explicit calls every edge target in edgeTargets is
+// called with defaults. Calls to nodes that were not translated, or that
+// expose no port, are dropped.
+func resolveDownstream(source string, ep model.Endpoint, edgeTargets []string, nodeMap map[string]model.DiagramNode, configs []docker.ContainerConfig, targets map[string]edgeTarget) []openapi.Downstream {
+	calls := ep.Downstream
+	if len(calls) == 0 {
+		calls = make([]model.DownstreamCall, len(edgeTargets))
+		for i, id := range edgeTargets {
+			calls[i] = model.DownstreamCall{Target: id}
+		}
+	}
+
+	var out []openapi.Downstream
+	for _, call := range calls {
+		dst, ok := targets[call.Target]
+		if !ok || dst.port == "" {
+			continue
+		}
+		cfg := configs[dst.index]
+		d := openapi.Downstream{Target: cfg.Hostname, Type: dst.nodeType}
+		switch dst.nodeType {
+		case model.ServiceTypePostgreSQL, model.ServiceTypeRedis, model.ServiceTypeRabbitMQ:
+			d.URL = connectionURL(cfg, dst.nodeType, dst.port)
+			d.Key = source
+		default:
+			method, path := httpCall(call, nodeMap[call.Target])
+			d.Method = method
+			d.URL = "http://" + cfg.Hostname + ":" + dst.port + pathParam.ReplaceAllString(path, pathParamValue)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// httpCall returns the method and path of an HTTP downstream call. Unset
+// fields default to the target's first endpoint when it is an api-service
+// with endpoints, and to GET / otherwise.
+func httpCall(call model.DownstreamCall, target model.DiagramNode) (string, string) {
+	method, path := strings.ToUpper(call.Method), call.Path
+	if path == "" && target.Type == model.ServiceTypeAPIService {
+		if endpoints, err := parseEndpoints(target); err == nil && len(endpoints) > 0 {
+			path = endpoints[0].Path
+			if method == "" {
+				method = strings.ToUpper(endpoints[0].Method)
+			}
+		}
+	}
+	if path == "" {
+		path = "/"
+	}
+	if method == "" {
+		method = http.MethodGet
+	}
+	return method, path
+}
@@ -0,0 +1,140 @@
+package templates
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// Suffixes of the connection variables injected for each edge target.
+const (
+	envSuffixHost = "_HOST"
+	envSuffixPort = "_PORT"
+	envSuffixURL  = "_URL"
+)
+
+// envPrefixFallback is prepended when a hostname would yield an env var
+// name starting with a digit.
+const envPrefixFallback = "SVC_"
+
+// edgeTarget records where a translated node landed in the config slice and
+// the container port other services should use to reach it.
+type edgeTarget struct {
+	index    int
+	nodeType string
+	port     string
+}
+
+// injectEdgeEnv adds connection variables to the source container of every
+// edge, describing how to reach the target. For an edge "API → Main DB" the
+// API container receives MAIN_DB_HOST, MAIN_DB_PORT and MAIN_DB_URL.
+// Variables already set on the source (for example user-defined env on a
+// custom container) are never overwritten. It returns the variables actually
+// injected, keyed by config index.
+func injectEdgeEnv(configs []docker.ContainerConfig, targets map[string]edgeTarget, edges []model.DiagramEdge) map[int]map[string]string {
+	prefixes := envPrefixes(configs)
+	injected := make(map[int]map[string]string)
+	for _, e := range edges {
+		src, ok := targets[e.Source]
+		if !ok {
+			continue
+		}
+		dst, ok := targets[e.Target]
+		if !ok || e.Source == e.Target {
+			continue
+		}
+
+		for k, v := range EdgeEnv(prefixes[dst.index], configs[dst.index], dst.nodeType, dst.port) {
+			if configs[src.index].Env == nil {
+				configs[src.index].Env = map[string]string{}
+			}
+			if _, exists := configs[src.index].Env[k]; !exists {
+				configs[src.index].Env[k] = v
+				if injected[src.index] == nil {
+					injected[src.index] = map[string]string{}
+				}
+				injected[src.index][k] = v
+			}
+		}
+	}
+	return injected
+}
+
+// EdgeEnv returns the connection variables, named prefix plus a suffix,
+// describing how to reach target, a translated container of the given
+// service type listening on port. The URL variable is omitted when the
+// target exposes no port.
+func EdgeEnv(prefix string, target docker.ContainerConfig, nodeType, port string) map[string]string {
+	env := map[string]string{prefix + envSuffixHost: target.Hostname}
+	if port == "" {
+		return env
+	}
+	env[prefix+envSuffixPort] = port
+	env[prefix+envSuffixURL] = connectionURL(target, nodeType, port)
+	return env
+}
+
+// envPrefixes returns the variable prefix of each config, by index. Every
+// prefix is unique: hostnames map to distinct prefixes except through the
+// fallback, so "1a" and "svc-1a" would both give SVC_1A. Prefixes without
+// the fallback are assigned first, and a fallback prefix that is taken gets
+// the first free "_2", "_3", … suffix.
+func envPrefixes(configs []docker.ContainerConfig) []string {
+	prefixes := make([]string, len(configs))
+	taken := make(map[string]bool, len(configs))
+	var fallbacks []int
+	for i, cfg := range configs {
+		p, fallback := envPrefix(cfg.Hostname)
+		if fallback {
+			fallbacks = append(fallbacks, i)
+			continue
+		}
+		prefixes[i] = p
+		taken[p] = true
+	}
+	for _, i := range fallbacks {
+		base, _ := envPrefix(configs[i].Hostname)
+		p := base
+		for n := 2; taken[p]; n++ {
+			p = fmt.Sprintf("%s_%d", base, n)
+		}
+		prefixes[i] = p
+		taken[p] = true
+	}
+	return prefixes
+}
+
+// envPrefix converts a hostname such as "main-db" into "MAIN_DB", and
+// reports whether envPrefixFallback had to be prepended.
+func envPrefix(hostname string) (string, bool) {
+	p := strings.ToUpper(strings.ReplaceAll(hostname, "-", "_"))
+	if p == "" || (p[0] >= '0' && p[0] <= '9') {
+		return envPrefixFallback + p, true
+	}
+	return p, false
+}
+
+// connectionURL builds a client connection string for the target service.
+func connectionURL(target docker.ContainerConfig, nodeType, port string) string {
+	hostPort := target.Hostname + ":" + port
+	switch nodeType {
+	case model.ServiceTypePostgreSQL:
+		u := url.URL{
+			Scheme: "postgres",
+			User:   url.UserPassword(target.Env["POSTGRES_USER"], target.Env["POSTGRES_PASSWORD"]),
+			Host:   hostPort,
+			Path:   "/" + target.Env["POSTGRES_DB"],
+		}
+		return u.String()
+	case model.ServiceTypeRedis:
+		return "redis://" + hostPort
+	case model.ServiceTypeRabbitMQ:
+		vhost := target.Env["RABBITMQ_DEFAULT_VHOST"]
+		return fmt.Sprintf("amqp://guest:guest@%s/%s", hostPort, url.PathEscape(vhost))
+	default:
+		return "http://" + hostPort
+	}
+}
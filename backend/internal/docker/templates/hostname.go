@@ -0,0 +1,88 @@
+package templates
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// maxHostnameLength is the RFC 1123 limit on a single DNS label.
+const maxHostnameLength = 63
+
+// hostnameBase derives an RFC 1123 label from a node name: the sanitized
+// name without leading or trailing hyphens, cut to maxHostnameLength. It
+// returns "" if the name has no usable characters.
+func hostnameBase(name string) string {
+	return trimLabel(sanitizeName(name), maxHostnameLength)
+}
+
+// trimLabel cuts s to at most n bytes and strips hyphens from both ends, as
+// a label may neither start nor end with one.
+func trimLabel(s string, n int) string {
+	if len(s) > n {
+		s = s[:n]
+	}
+	return strings.Trim(s, "-")
+}
+
+// AssignHostnames derives a unique hostname for every node. Nodes whose
+// names sanitize to the same label keep it in node-ID order: the first
+// keeps the bare label and the rest get -2, -3, ... suffixes, skipping
+// labels another node already uses. The assignment depends only on node
+// IDs and names, so redeploying an unchanged diagram yields the same
+// hostnames. A name without any ASCII letter or digit is an error.
+func AssignHostnames(nodes []model.DiagramNode) (map[string]string, error) {
+	sorted := make([]model.DiagramNode, len(nodes))
+	copy(sorted, nodes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	bases := make(map[string]string, len(sorted))
+	for _, n := range sorted {
+		base := hostnameBase(n.Name)
+		if base == "" {
+			return nil, fmt.Errorf("node %q: name %q has no letters or digits to derive a hostname from", n.ID, n.Name)
+		}
+		bases[n.ID] = base
+	}
+
+	// Bare labels are claimed first so that a node literally named "api-2"
+	// is never displaced by a suffixed duplicate of "api".
+	hostnames := make(map[string]string, len(sorted))
+	taken := make(map[string]bool, len(sorted))
+	for _, n := range sorted {
+		if base := bases[n.ID]; !taken[base] {
+			hostnames[n.ID] = base
+			taken[base] = true
+		}
+	}
+	for _, n := range sorted {
+		if _, ok := hostnames[n.ID]; ok {
+			continue
+		}
+		for i := 2; ; i++ {
+			suffix := "-" + strconv.Itoa(i)
+			candidate := trimLabel(bases[n.ID], maxHostnameLength-len(suffix)) + suffix
+			if !taken[candidate] {
+				hostnames[n.ID] = candidate
+				taken[candidate] = true
+				break
+			}
+		}
+	}
+	return hostnames, nil
+}
+
+// claimHostname returns base, or base with the first free -2, -3, ...
+// suffix if another container already uses it, and marks it taken.
+func claimHostname(base string, taken map[string]bool) string {
+	candidate := trimLabel(base, maxHostnameLength)
+	for i := 2; taken[candidate]; i++ {
+		suffix := "-" + strconv.Itoa(i)
+		candidate = trimLabel(base, maxHostnameLength-len(suffix)) + suffix
+	}
+	taken[candidate] = true
+	return candidate
+}
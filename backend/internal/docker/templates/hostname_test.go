@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+func TestHostnameBase(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"User API", "user-api"},
+		{"-- Edge --", "edge"},
+		{"Test  Name@#$", "test--name"},
+		{"!!!", ""},
+		{strings.Repeat("a", 70) + " b", strings.Repeat("a", 63)},
+		{strings.Repeat("a", 62) + " b", strings.Repeat("a", 62)},
+	}
+
+	for _, tc := range tests {
+		if got := hostnameBase(tc.input); got != tc.expected {
+			t.Errorf("hostnameBase(%q) = %q, want %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestAssignHostnames_Collisions(t *testing.T) {
+	nodes := []model.DiagramNode{
+		{ID: "c", Name: "User API"},
+		{ID: "b", Name: "user-api!"},
+		{ID: "a", Name: "USER API"},
+		{ID: "d", Name: "user-api-2"},
+		{ID: "e", Name: "Cache"},
+	}
+
+	got, err := AssignHostnames(nodes)
+	if err != nil {
+		t.Fatalf("AssignHostnames() returned error: %v", err)
+	}
+	expected := map[string]string{
+		"a": "user-api",
+		"b": "user-api-3",
+		"c": "user-api-4",
+		"d": "user-api-2",
+		"e": "cache",
+	}
+	for id, want := range expected {
+		if got[id] != want {
+			t.Errorf("node %q: expected hostname %q, got %q", id, want, got[id])
+		}
+	}
+
+	// The assignment must not depend on node order.
+	reversed := []model.DiagramNode{nodes[4], nodes[3], nodes[2], nodes[1], nodes[0]}
+	again, err := AssignHostnames(reversed)
+	if err != nil {
+		t.Fatalf("AssignHostnames() returned error: %v", err)
+	}
+	for id, want := range expected {
+		if again[id] != want {
+			t.Errorf("reordered node %q: expected hostname %q, got %q", id, want, again[id])
+		}
+	}
+}
+
+func TestAssignHostnames_SuffixFitsLabelLimit(t *testing.T) {
+	long := strings.Repeat("x", 80)
+	got, err := AssignHostnames([]model.DiagramNode{{ID: "a", Name: long}, {ID: "b", Name: long}})
+	if err != nil {
+		t.Fatalf("AssignHostnames() returned error: %v", err)
+	}
+	if want := strings.Repeat("x", 61) + "-2"; got["b"] != want {
+		t.Errorf("expected %q, got %q", want, got["b"])
+	}
+	for id, h := range got {
+		if len(h) > maxHostnameLength {
+			t.Errorf("node %q: hostname %q exceeds %d characters", id, h, maxHostnameLength)
+		}
+	}
+}
+
+func TestAssignHostnames_UnusableName(t *testing.T) {
+	_, err := AssignHostnames([]model.DiagramNode{{ID: "a", Name: "API"}, {ID: "b", Name: "@#$"}})
+	if err == nil {
+		t.Fatal("expected error for a name without letters or digits")
+	}
+	if !strings.Contains(err.Error(), `node "b"`) {
+		t.Errorf("expected error to name the node, got %v", err)
+	}
+}
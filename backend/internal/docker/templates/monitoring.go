@@ -0,0 +1,256 @@
+package templates
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// Service types of the monitoring containers added for diagrams with
+// Prometheus enabled. They are not diagram service types.
+const (
+	ServiceTypePrometheus = "prometheus"
+	ServiceTypeExporter   = "exporter"
+)
+
+// Monitoring images.
+const (
+	ImagePrometheus       = "prom/prometheus:v3.5.0"
+	ImagePostgresExporter = "quay.io/prometheuscommunity/postgres-exporter:latest"
+	ImageRedisExporter    = "oliver006/redis_exporter:latest"
+	ImageNginxExporter    = "nginx/nginx-prometheus-exporter:latest"
+)
+
+// Container-side ports metrics are served on.
+const (
+	PortPrometheus = "9090"
+	// PortAPIServiceMetrics is the mock server's metrics port.
+	PortAPIServiceMetrics = "9464"
+	// PortRabbitMQPrometheus is served by RabbitMQ's built-in Prometheus
+	// plugin.
+	PortRabbitMQPrometheus = "15692"
+	// PortNginxStubStatus serves nginx's stub_status page to its exporter.
+	PortNginxStubStatus  = "8080"
+	PortPostgresExporter = "9187"
+	PortRedisExporter    = "9121"
+	PortNginxExporter    = "9113"
+)
+
+// PrometheusNodeID is the node ID of the Prometheus container. Exporters
+// get exporterNodeIDPrefix followed by the ID of the node they watch.
+const (
+	PrometheusNodeID     = "monitoring:prometheus"
+	exporterNodeIDPrefix = "monitoring:exporter:"
+)
+
+// Generated monitoring files and where they are mounted.
+const (
+	containerPrometheusConfigPath = "/etc/prometheus/prometheus.yml"
+	containerStubStatusPath       = "/etc/nginx/conf.d/heph-stub-status.conf"
+	// scrapeInterval is also how often Prometheus checks its config file
+	// for changes, so a regenerated config is picked up without a restart.
+	scrapeInterval = "5s"
+)
+
+// stubStatusConf enables nginx's stub_status page on an unpublished port.
+const stubStatusConf = `server {
+    listen ` + PortNginxStubStatus + `;
+    location = /stub_status {
+        stub_status;
+    }
+}
+`
+
+// prometheusConfig is the subset of prometheus.yml the translator writes.
+type prometheusConfig struct {
+	Global        prometheusGlobal `yaml:"global"`
+	ScrapeConfigs []scrapeConfig   `yaml:"scrape_configs"`
+}
+
+type prometheusGlobal struct {
+	ScrapeInterval string            `yaml:"scrape_interval"`
+	ExternalLabels map[string]string `yaml:"external_labels,omitempty"`
+}
+
+type scrapeConfig struct {
+	JobName       string         `yaml:"job_name"`
+	StaticConfigs []staticConfig `yaml:"static_configs"`
+}
+
+type staticConfig struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+}
+
+// addMonitoring appends a Prometheus container scraping every node of
+// nodes that serves metrics: mock servers and RabbitMQ natively, and
+// PostgreSQL, Redis and nginx through an exporter container added next to
+// each. nginx nodes get a config file enabling stub_status for their
+// exporter. Custom containers are not scraped. Targets are labelled with
+// the node's ID, name and service type.
+func (t *Translator) addMonitoring(diagramID string, nodes []TranslatedNode) ([]TranslatedNode, error) {
+	taken := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		taken[n.Config.Hostname] = true
+	}
+
+	result := make([]TranslatedNode, 0, 2*len(nodes)+1)
+	scrapes := []scrapeConfig{{
+		JobName:       ServiceTypePrometheus,
+		StaticConfigs: []staticConfig{{Targets: []string{net.JoinHostPort("localhost", PortPrometheus)}}},
+	}}
+	for _, n := range nodes {
+		host := n.Config.Hostname
+		var exporter *docker.ContainerConfig
+		var port string
+		switch n.Node.Type {
+		case model.ServiceTypeAPIService:
+			port = PortAPIServiceMetrics
+		case model.ServiceTypeRabbitMQ:
+			port = PortRabbitMQPrometheus
+		case model.ServiceTypePostgreSQL:
+			port = PortPostgresExporter
+			exporter = &docker.ContainerConfig{
+				Image: ImagePostgresExporter,
+				Env:   map[string]string{"DATA_SOURCE_NAME": connectionURL(n.Config, n.Node.Type, PortPostgreSQL) + "?sslmode=disable"},
+			}
+		case model.ServiceTypeRedis:
+			port = PortRedisExporter
+			exporter = &docker.ContainerConfig{
+				Image: ImageRedisExporter,
+				Env:   map[string]string{"REDIS_ADDR": connectionURL(n.Config, n.Node.Type, PortRedis)},
+			}
+		case model.ServiceTypeNginx:
+			var err error
+			if n, err = t.enableStubStatus(n); err != nil {
+				return nil, err
+			}
+			port = PortNginxExporter
+			exporter = &docker.ContainerConfig{
+				Image: ImageNginxExporter,
+				Cmd:   []string{"--nginx.scrape-uri=http://" + net.JoinHostPort(host, PortNginxStubStatus) + "/stub_status"},
+			}
+		}
+		result = append(result, n)
+		if port == "" {
+			continue
+		}
+
+		if exporter != nil {
+			host = claimHostname(host+"-exporter", taken)
+			exporter.Name = host
+			exporter.Hostname = host
+			exporter.NetworkName = docker.NetworkName
+			if exporter.Env == nil {
+				exporter.Env = map[string]string{}
+			}
+			result = append(result, TranslatedNode{
+				Node:      model.DiagramNode{ID: exporterNodeIDPrefix + n.Node.ID, Type: ServiceTypeExporter, Name: n.Node.Name + " exporter"},
+				Config:    *exporter,
+				DependsOn: []string{n.Node.ID},
+			})
+		}
+		scrapes = append(scrapes, scrapeConfig{
+			JobName: n.Config.Hostname,
+			StaticConfigs: []staticConfig{{
+				Targets: []string{net.JoinHostPort(host, port)},
+				Labels:  map[string]string{"node_id": n.Node.ID, "node": n.Node.Name, "service_type": n.Node.Type},
+			}},
+		})
+	}
+
+	prometheus, err := t.buildPrometheus(diagramID, claimHostname(ServiceTypePrometheus, taken), scrapes)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, prometheus), nil
+}
+
+// enableStubStatus mounts a config file enabling stub_status into the
+// nginx node n.
+func (t *Translator) enableStubStatus(n TranslatedNode) (TranslatedNode, error) {
+	path, err := writeGeneratedFile(t.generatedDir(), n.Config.Hostname+"-stub-status.conf", []byte(stubStatusConf))
+	if err != nil {
+		return n, fmt.Errorf("enable stub_status for node %q: %w", n.Node.ID, err)
+	}
+	volumes := make(map[string]string, len(n.Config.Volumes)+1)
+	for host, ctr := range n.Config.Volumes {
+		volumes[host] = ctr
+	}
+	volumes[path] = containerStubStatusPath
+	n.Config.Volumes = volumes
+
+	if n.Artifacts, err = collectArtifacts(n.Config); err != nil {
+		return n, fmt.Errorf("collect artifacts for node %q: %w", n.Node.ID, err)
+	}
+	return n, nil
+}
+
+// buildPrometheus writes the Prometheus config for scrapes and returns the
+// Prometheus container, published on an allocated host port.
+func (t *Translator) buildPrometheus(diagramID, hostname string, scrapes []scrapeConfig) (TranslatedNode, error) {
+	cfg := prometheusConfig{
+		Global:        prometheusGlobal{ScrapeInterval: scrapeInterval},
+		ScrapeConfigs: scrapes,
+	}
+	if diagramID != "" {
+		cfg.Global.ExternalLabels = map[string]string{"diagram": diagramID}
+	}
+	content, err := yaml.Marshal(cfg)
+	if err != nil {
+		return TranslatedNode{}, fmt.Errorf("render prometheus config: %w", err)
+	}
+	path, err := writeGeneratedFile(t.generatedDir(), hostname+".yml", content)
+	if err != nil {
+		return TranslatedNode{}, fmt.Errorf("write prometheus config: %w", err)
+	}
+	hostPort, err := t.allocator.Allocate()
+	if err != nil {
+		return TranslatedNode{}, fmt.Errorf("allocate port for prometheus: %w", err)
+	}
+
+	config := docker.ContainerConfig{
+		Image: ImagePrometheus,
+		Name:  hostname,
+		Cmd: []string{
+			"--config.file=" + containerPrometheusConfigPath,
+			"--storage.tsdb.path=/prometheus",
+			"--enable-feature=auto-reload-config",
+			"--config.auto-reload-interval=" + scrapeInterval,
+		},
+		Env:         map[string]string{},
+		Ports:       map[string]string{hostPort: PortPrometheus},
+		Volumes:     map[string]string{path: containerPrometheusConfigPath},
+		Hostname:    hostname,
+		NetworkName: docker.NetworkName,
+		Healthcheck: infraHealthcheck("CMD", "wget", "-q", "--spider", "http://localhost:"+PortPrometheus+"/-/ready"),
+	}
+	artifacts, err := collectArtifacts(config)
+	if err != nil {
+		return TranslatedNode{}, fmt.Errorf("collect artifacts for prometheus: %w", err)
+	}
+	return TranslatedNode{
+		Node:      model.DiagramNode{ID: PrometheusNodeID, Type: ServiceTypePrometheus, Name: "Prometheus"},
+		Config:    config,
+		Artifacts: artifacts,
+	}, nil
+}
+
+// writeGeneratedFile writes content to the file name in dir, creating dir
+// if needed, and returns the file's path.
+func writeGeneratedFile(dir, name string, content []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create directory %q: %w", dir, err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return "", fmt.Errorf("write %q: %w", path, err)
+	}
+	return path, nil
+}
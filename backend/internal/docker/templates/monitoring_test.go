@@ -0,0 +1,126 @@
+package templates
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+func TestTranslator_Monitoring(t *testing.T) {
+	diagram := model.Diagram{
+		ID:   "d1",
+		Name: "Monitored",
+		Nodes: []model.DiagramNode{
+			{ID: "api", Type: model.ServiceTypeAPIService, Name: "API"},
+			{ID: "db", Type: model.ServiceTypePostgreSQL, Name: "DB"},
+			{ID: "cache", Type: model.ServiceTypeRedis, Name: "Cache"},
+			{ID: "gw", Type: model.ServiceTypeNginx, Name: "Gateway"},
+			{ID: "mq", Type: model.ServiceTypeRabbitMQ, Name: "Queue"},
+			{ID: "app", Type: model.ServiceTypeCustomContainer, Name: "Prometheus",
+				Config: json.RawMessage(`{"type":"custom-container","image":"busybox"}`)},
+		},
+		Edges:      []model.DiagramEdge{{ID: "e1", Source: "api", Target: "db"}},
+		Monitoring: &model.Monitoring{Prometheus: true},
+	}
+
+	nodes, err := NewTranslatorWithSpecDir(t.TempDir()).TranslateNodes(diagram)
+	if err != nil {
+		t.Fatalf("TranslateNodes: %v", err)
+	}
+	byID := make(map[string]TranslatedNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.Node.ID] = n
+	}
+	if len(nodes) != 10 {
+		t.Fatalf("expected 6 nodes, 3 exporters and prometheus, got %d", len(nodes))
+	}
+
+	prom := nodes[len(nodes)-1]
+	if prom.Node.ID != PrometheusNodeID || prom.Node.Type != ServiceTypePrometheus {
+		t.Fatalf("expected prometheus last, got %+v", prom.Node)
+	}
+	if prom.Config.Hostname != "prometheus-2" {
+		t.Errorf("expected the custom container to keep the hostname prometheus, got %q", prom.Config.Hostname)
+	}
+	if len(prom.Config.Ports) != 1 || len(prom.Artifacts) != 1 {
+		t.Fatalf("expected one published port and one artifact, got %+v", prom)
+	}
+
+	exporter := byID[exporterNodeIDPrefix+"db"]
+	if exporter.Config.Image != ImagePostgresExporter || !slices.Equal(exporter.DependsOn, []string{"db"}) {
+		t.Errorf("unexpected postgres exporter %+v", exporter)
+	}
+	if got := exporter.Config.Env["DATA_SOURCE_NAME"]; got != "postgres://hephaestus:hephaestus@db:5432/hephaestus?sslmode=disable" {
+		t.Errorf("unexpected data source %q", got)
+	}
+	if got := byID[exporterNodeIDPrefix+"cache"].Config.Env["REDIS_ADDR"]; got != "redis://cache:6379" {
+		t.Errorf("unexpected redis address %q", got)
+	}
+	if _, ok := byID[exporterNodeIDPrefix+"mq"]; ok {
+		t.Error("expected rabbitmq to be scraped without an exporter")
+	}
+
+	gw := byID["gw"]
+	if len(gw.Artifacts) != 1 || gw.Artifacts[0].ContainerPath != containerStubStatusPath {
+		t.Fatalf("expected nginx to mount the stub_status config, got %+v", gw.Artifacts)
+	}
+	if !strings.Contains(string(gw.Artifacts[0].Content), "stub_status;") {
+		t.Errorf("unexpected stub_status config:\n%s", gw.Artifacts[0].Content)
+	}
+
+	var cfg prometheusConfig
+	if err := yaml.Unmarshal(prom.Artifacts[0].Content, &cfg); err != nil {
+		t.Fatalf("parse prometheus config: %v", err)
+	}
+	if cfg.Global.ExternalLabels["diagram"] != "d1" {
+		t.Errorf("expected the diagram external label, got %+v", cfg.Global)
+	}
+	targets := make(map[string]string)
+	for _, s := range cfg.ScrapeConfigs {
+		targets[s.JobName] = s.StaticConfigs[0].Targets[0]
+	}
+	want := map[string]string{
+		"prometheus": "localhost:9090",
+		"api":        "api:9464",
+		"db":         "db-exporter:9187",
+		"cache":      "cache-exporter:9121",
+		"gateway":    "gateway-exporter:9113",
+		"queue":      "queue:15692",
+	}
+	if len(targets) != len(want) {
+		t.Errorf("expected %d scrape jobs, got %v", len(want), targets)
+	}
+	for job, target := range want {
+		if targets[job] != target {
+			t.Errorf("job %q: got target %q, want %q", job, targets[job], target)
+		}
+	}
+
+	written, err := os.ReadFile(prom.Artifacts[0].HostPath)
+	if err != nil || string(written) != string(prom.Artifacts[0].Content) {
+		t.Errorf("expected the config to be written to %s: %v", prom.Artifacts[0].HostPath, err)
+	}
+}
+
+func TestTranslator_MonitoringDisabled(t *testing.T) {
+	diagram := model.Diagram{
+		ID:         "d1",
+		Name:       "Plain",
+		Nodes:      []model.DiagramNode{{ID: "gw", Type: model.ServiceTypeNginx, Name: "Gateway"}},
+		Monitoring: &model.Monitoring{},
+	}
+
+	nodes, err := NewTranslatorWithSpecDir(t.TempDir()).TranslateNodes(diagram)
+	if err != nil {
+		t.Fatalf("TranslateNodes: %v", err)
+	}
+	if len(nodes) != 1 || len(nodes[0].Artifacts) != 0 {
+		t.Errorf("expected only the nginx node without artifacts, got %+v", nodes)
+	}
+}
@@ -13,9 +13,7 @@ import (
 type NginxTemplate struct{}
 
 // Build creates a docker.ContainerConfig for an Nginx service node.
-func (t *NginxTemplate) Build(node model.DiagramNode, hostPort string, _ ...string) (docker.ContainerConfig, error) {
-	hostname := sanitizeName(node.Name)
-
+func (t *NginxTemplate) Build(node model.DiagramNode, hostname, hostPort string, _ ...string) (docker.ContainerConfig, error) {
 	env := map[string]string{}
 
 	if len(node.Config) > 0 {
@@ -13,9 +13,7 @@ import (
 type PostgreSQLTemplate struct{}
 
 // Build creates a docker.ContainerConfig for a PostgreSQL service node.
-func (t *PostgreSQLTemplate) Build(node model.DiagramNode, hostPort string, _ ...string) (docker.ContainerConfig, error) {
-	hostname := sanitizeName(node.Name)
-
+func (t *PostgreSQLTemplate) Build(node model.DiagramNode, hostname, hostPort string, _ ...string) (docker.ContainerConfig, error) {
 	env := DefaultPostgresEnv()
 
 	// Validate config JSON if present. No overridable fields yet —
@@ -36,6 +34,7 @@ func (t *PostgreSQLTemplate) Build(node model.DiagramNode, hostPort string, _ ..
 		Ports:       map[string]string{hostPort: PortPostgreSQL},
 		Hostname:    hostname,
 		NetworkName: docker.NetworkName,
+		Healthcheck: infraHealthcheck("CMD-SHELL", "pg_isready -U \"$POSTGRES_USER\" -d \"$POSTGRES_DB\""),
 	}, nil
 }
 
@@ -14,9 +14,7 @@ type RabbitMQTemplate struct{}
 // Build creates a docker.ContainerConfig for a RabbitMQ service node.
 // RabbitMQ requires two host ports: the first for AMQP (5672), the second
 // for the management UI (15672). The management port is passed via hostPorts[0].
-func (t *RabbitMQTemplate) Build(node model.DiagramNode, hostPort string, hostPorts ...string) (docker.ContainerConfig, error) {
-	hostname := sanitizeName(node.Name)
-
+func (t *RabbitMQTemplate) Build(node model.DiagramNode, hostname, hostPort string, hostPorts ...string) (docker.ContainerConfig, error) {
 	env := map[string]string{}
 	vhost := "/"
 
@@ -45,5 +43,6 @@ func (t *RabbitMQTemplate) Build(node model.DiagramNode, hostPort string, hostPo
 		Ports:       ports,
 		Hostname:    hostname,
 		NetworkName: docker.NetworkName,
+		Healthcheck: infraHealthcheck("CMD", "rabbitmq-diagnostics", "-q", "ping"),
 	}, nil
 }
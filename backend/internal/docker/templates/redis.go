@@ -12,9 +12,7 @@ import (
 type RedisTemplate struct{}
 
 // Build creates a docker.ContainerConfig for a Redis service node.
-func (t *RedisTemplate) Build(node model.DiagramNode, hostPort string, _ ...string) (docker.ContainerConfig, error) {
-	hostname := sanitizeName(node.Name)
-
+func (t *RedisTemplate) Build(node model.DiagramNode, hostname, hostPort string, _ ...string) (docker.ContainerConfig, error) {
 	env := map[string]string{}
 
 	if len(node.Config) > 0 {
@@ -37,5 +35,6 @@ func (t *RedisTemplate) Build(node model.DiagramNode, hostPort string, _ ...stri
 		Ports:       map[string]string{hostPort: PortRedis},
 		Hostname:    hostname,
 		NetworkName: docker.NetworkName,
+		Healthcheck: infraHealthcheck("CMD", "redis-cli", "ping"),
 	}, nil
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
 	"github.com/stwalsh4118/hephaestus/backend/internal/model"
@@ -18,7 +19,7 @@ func TestPostgreSQLTemplate_Build(t *testing.T) {
 		Config: json.RawMessage(`{"type":"postgresql","engine":"PostgreSQL","version":"16"}`),
 	}
 
-	cfg, err := tmpl.Build(node, "15432")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "15432")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -54,7 +55,7 @@ func TestPostgreSQLTemplate_Build_EmptyConfig(t *testing.T) {
 		Name: "postgres",
 	}
 
-	cfg, err := tmpl.Build(node, "15432")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "15432")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -72,7 +73,7 @@ func TestRedisTemplate_Build(t *testing.T) {
 		Config: json.RawMessage(`{"type":"redis","maxMemory":"256mb","evictionPolicy":"allkeys-lru"}`),
 	}
 
-	cfg, err := tmpl.Build(node, "16379")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "16379")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -102,7 +103,7 @@ func TestRedisTemplate_Build_EmptyConfig(t *testing.T) {
 		Name: "redis",
 	}
 
-	cfg, err := tmpl.Build(node, "16379")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "16379")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -120,7 +121,7 @@ func TestNginxTemplate_Build(t *testing.T) {
 		Config: json.RawMessage(`{"type":"nginx","upstreamServers":["api-1","api-2"]}`),
 	}
 
-	cfg, err := tmpl.Build(node, "18080")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "18080")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -147,7 +148,7 @@ func TestNginxTemplate_Build_EmptyConfig(t *testing.T) {
 		Name: "nginx",
 	}
 
-	cfg, err := tmpl.Build(node, "18080")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "18080")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -165,7 +166,7 @@ func TestRabbitMQTemplate_Build(t *testing.T) {
 		Config: json.RawMessage(`{"type":"rabbitmq","vhost":"/events"}`),
 	}
 
-	cfg, err := tmpl.Build(node, "15672", "25672")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "15672", "25672")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -195,7 +196,7 @@ func TestRabbitMQTemplate_Build_DefaultVhost(t *testing.T) {
 		Name: "rabbitmq",
 	}
 
-	cfg, err := tmpl.Build(node, "15672")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "15672")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -212,7 +213,7 @@ func TestAPIServiceTemplate_Build_NoConfig(t *testing.T) {
 		Name: "User API",
 	}
 
-	cfg, err := tmpl.Build(node, "14010")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "14010")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -229,8 +230,8 @@ func TestAPIServiceTemplate_Build_NoConfig(t *testing.T) {
 	if cfg.NetworkName != docker.NetworkName {
 		t.Errorf("expected network %q, got %q", docker.NetworkName, cfg.NetworkName)
 	}
-	// Verify Prism Cmd is set.
-	expectedCmd := []string{"mock", "-h", "0.0.0.0", "/tmp/spec.json"}
+	// Verify mock server Cmd is set.
+	expectedCmd := []string{"-addr", ":4010", "-spec", "/tmp/spec.json"}
 	if len(cfg.Cmd) != len(expectedCmd) {
 		t.Fatalf("expected Cmd length %d, got %d", len(expectedCmd), len(cfg.Cmd))
 	}
@@ -271,14 +272,14 @@ func TestAPIServiceTemplate_Build_WithEndpoints(t *testing.T) {
 		}`),
 	}
 
-	cfg, err := tmpl.Build(node, "14010")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "14010")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
 	// Verify Cmd.
-	if len(cfg.Cmd) != 4 || cfg.Cmd[0] != "mock" {
-		t.Errorf("expected Prism mock command, got %v", cfg.Cmd)
+	if len(cfg.Cmd) != 4 || cfg.Cmd[3] != "/tmp/spec.json" {
+		t.Errorf("expected mock server command, got %v", cfg.Cmd)
 	}
 
 	// Verify volume mount.
@@ -335,14 +336,14 @@ func TestAPIServiceTemplate_Build_EmptyEndpoints(t *testing.T) {
 		}`),
 	}
 
-	cfg, err := tmpl.Build(node, "14010")
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "14010")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
 	// Should still produce a valid config with Cmd and Volumes.
 	if len(cfg.Cmd) == 0 {
-		t.Error("expected Prism Cmd even with empty endpoints")
+		t.Error("expected mock server Cmd even with empty endpoints")
 	}
 	if len(cfg.Volumes) == 0 {
 		t.Error("expected volume mount even with empty endpoints")
@@ -378,13 +379,13 @@ func TestAPIServiceTemplate_Build_InvalidConfig(t *testing.T) {
 		Config: json.RawMessage(`{invalid json`),
 	}
 
-	_, err := tmpl.Build(node, "14010")
+	_, err := tmpl.Build(node, sanitizeName(node.Name), "14010")
 	if err == nil {
 		t.Fatal("expected error for invalid config JSON")
 	}
 }
 
-func TestNewRegistry_ContainsAll5ServiceTypes(t *testing.T) {
+func TestNewRegistry_ContainsAllServiceTypes(t *testing.T) {
 	reg := NewRegistry()
 
 	expected := []string{
@@ -393,6 +394,7 @@ func TestNewRegistry_ContainsAll5ServiceTypes(t *testing.T) {
 		model.ServiceTypeRedis,
 		model.ServiceTypeNginx,
 		model.ServiceTypeRabbitMQ,
+		model.ServiceTypeCustomContainer,
 	}
 
 	if len(reg) != len(expected) {
@@ -436,7 +438,7 @@ func TestPostgreSQLTemplate_Build_InvalidConfig(t *testing.T) {
 		Config: json.RawMessage(`{invalid json`),
 	}
 
-	_, err := tmpl.Build(node, "15432")
+	_, err := tmpl.Build(node, sanitizeName(node.Name), "15432")
 	if err == nil {
 		t.Fatal("expected error for invalid config JSON")
 	}
@@ -451,7 +453,7 @@ func TestRedisTemplate_Build_InvalidConfig(t *testing.T) {
 		Config: json.RawMessage(`{invalid json`),
 	}
 
-	_, err := tmpl.Build(node, "16379")
+	_, err := tmpl.Build(node, sanitizeName(node.Name), "16379")
 	if err == nil {
 		t.Fatal("expected error for invalid config JSON")
 	}
@@ -466,7 +468,7 @@ func TestNginxTemplate_Build_InvalidConfig(t *testing.T) {
 		Config: json.RawMessage(`{invalid json`),
 	}
 
-	_, err := tmpl.Build(node, "18080")
+	_, err := tmpl.Build(node, sanitizeName(node.Name), "18080")
 	if err == nil {
 		t.Fatal("expected error for invalid config JSON")
 	}
@@ -481,8 +483,107 @@ func TestRabbitMQTemplate_Build_InvalidConfig(t *testing.T) {
 		Config: json.RawMessage(`{invalid json`),
 	}
 
-	_, err := tmpl.Build(node, "15672")
+	_, err := tmpl.Build(node, sanitizeName(node.Name), "15672")
 	if err == nil {
 		t.Fatal("expected error for invalid config JSON")
 	}
 }
+
+func TestCustomContainerTemplate_Build(t *testing.T) {
+	tmpl := &CustomContainerTemplate{}
+	node := model.DiagramNode{
+		ID:   "custom-1",
+		Type: model.ServiceTypeCustomContainer,
+		Name: "Search Engine",
+		Config: json.RawMessage(`{
+			"type":"custom-container",
+			"image":"docker.elastic.co/elasticsearch/elasticsearch:8.13.0",
+			"command":["serve"],
+			"entrypoint":["/bin/entry"],
+			"env":{"DISCOVERY_TYPE":"single-node"},
+			"ports":[9200,9300],
+			"healthcheck":{"test":["CMD","curl","-f","http://localhost:9200"],"intervalSeconds":5,"retries":3}
+		}`),
+	}
+
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "19200", "19300")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Image != "docker.elastic.co/elasticsearch/elasticsearch:8.13.0" {
+		t.Errorf("unexpected image %q", cfg.Image)
+	}
+	if cfg.Hostname != "search-engine" {
+		t.Errorf("expected hostname %q, got %q", "search-engine", cfg.Hostname)
+	}
+	if cfg.Ports["19200"] != "9200" || cfg.Ports["19300"] != "9300" {
+		t.Errorf("unexpected port mapping: %v", cfg.Ports)
+	}
+	if len(cfg.Cmd) != 1 || cfg.Cmd[0] != "serve" {
+		t.Errorf("unexpected cmd: %v", cfg.Cmd)
+	}
+	if len(cfg.Entrypoint) != 1 || cfg.Entrypoint[0] != "/bin/entry" {
+		t.Errorf("unexpected entrypoint: %v", cfg.Entrypoint)
+	}
+	if cfg.Env["DISCOVERY_TYPE"] != "single-node" {
+		t.Errorf("expected DISCOVERY_TYPE env, got %v", cfg.Env)
+	}
+	if cfg.Healthcheck == nil {
+		t.Fatal("expected healthcheck to be set")
+	}
+	if cfg.Healthcheck.Interval != 5*time.Second || cfg.Healthcheck.Retries != 3 {
+		t.Errorf("unexpected healthcheck: %+v", cfg.Healthcheck)
+	}
+	if cfg.NetworkName != docker.NetworkName {
+		t.Errorf("expected network %q, got %q", docker.NetworkName, cfg.NetworkName)
+	}
+}
+
+func TestCustomContainerTemplate_Build_NoPorts(t *testing.T) {
+	tmpl := &CustomContainerTemplate{}
+	node := model.DiagramNode{
+		ID:     "worker",
+		Type:   model.ServiceTypeCustomContainer,
+		Name:   "Worker",
+		Config: json.RawMessage(`{"type":"custom-container","image":"busybox"}`),
+	}
+
+	cfg, err := tmpl.Build(node, sanitizeName(node.Name), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Ports) != 0 {
+		t.Errorf("expected no ports, got %v", cfg.Ports)
+	}
+	if cfg.Healthcheck != nil {
+		t.Errorf("expected no healthcheck, got %+v", cfg.Healthcheck)
+	}
+}
+
+func TestCustomContainerTemplate_Build_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		ports  []string
+	}{
+		{"missing config", ``, []string{""}},
+		{"invalid JSON", `{invalid`, []string{""}},
+		{"malformed image", `{"type":"custom-container","image":"Not A/Valid:Image!"}`, []string{""}},
+		{"too few host ports", `{"type":"custom-container","image":"busybox","ports":[80,443]}`, []string{"10000"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			node := model.DiagramNode{
+				ID:     "c1",
+				Type:   model.ServiceTypeCustomContainer,
+				Name:   "custom",
+				Config: json.RawMessage(tc.config),
+			}
+			if _, err := (&CustomContainerTemplate{}).Build(node, sanitizeName(node.Name), tc.ports[0], tc.ports[1:]...); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
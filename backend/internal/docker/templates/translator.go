@@ -2,17 +2,30 @@ package templates
 
 import (
 	"fmt"
+	"os"
+	"sort"
 
 	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
 	"github.com/stwalsh4118/hephaestus/backend/internal/model"
 )
 
-// portsRequired returns the number of host ports a service type needs.
-func portsRequired(serviceType string) int {
-	if serviceType == model.ServiceTypeRabbitMQ {
-		return 2 // AMQP + management UI
+// portsRequired returns the number of host ports a node needs. Custom
+// containers need one per exposed port, which may be zero.
+func portsRequired(node model.DiagramNode) (int, error) {
+	switch node.Type {
+	case model.ServiceTypeRabbitMQ:
+		return 2, nil // AMQP + management UI
+	case model.ServiceTypeAPIService:
+		return 2, nil // API + metrics
+	case model.ServiceTypeCustomContainer:
+		cfg, err := parseCustomContainerConfig(node)
+		if err != nil {
+			return 0, err
+		}
+		return len(cfg.Ports), nil
+	default:
+		return 1, nil
 	}
-	return 1
 }
 
 // Translator converts a model.Diagram into an ordered slice of docker.ContainerConfig.
@@ -21,6 +34,31 @@ func portsRequired(serviceType string) int {
 type Translator struct {
 	registry  TemplateRegistry
 	allocator *PortAllocator
+	// specDir is the directory generated files are written to; empty
+	// means DefaultSpecDir.
+	specDir string
+}
+
+// TranslatedNode pairs a diagram node with the container config built for it.
+type TranslatedNode struct {
+	Node   model.DiagramNode
+	Config docker.ContainerConfig
+	// DependsOn lists the IDs of nodes this node depends on (its edge
+	// targets), sorted and de-duplicated.
+	DependsOn []string
+	// Artifacts are the generated files mounted into the container, such
+	// as OpenAPI specs, in container-path order.
+	Artifacts []Artifact
+	// InjectedEnv holds the connection variables added to Config.Env for
+	// this node's outgoing edges. It is nil when nothing was injected.
+	InjectedEnv map[string]string
+}
+
+// Artifact is a generated file mounted into a container.
+type Artifact struct {
+	HostPath      string `json:"hostPath"`
+	ContainerPath string `json:"containerPath"`
+	Content       []byte `json:"content"`
 }
 
 // NewTranslator creates a Translator with the default registry and port allocator.
@@ -31,10 +69,57 @@ func NewTranslator() *Translator {
 	}
 }
 
+// NewTranslatorWithSpecDir creates a Translator that writes generated OpenAPI
+// specs under dir instead of the shared temp directory. Exporters use this so
+// that translating a diagram never rewrites specs mounted by a live deployment.
+func NewTranslatorWithSpecDir(dir string) *Translator {
+	t := NewTranslator()
+	t.registry[model.ServiceTypeAPIService] = &APIServiceTemplate{SpecDir: dir}
+	t.specDir = dir
+	return t
+}
+
+// WithMockImage makes API Service nodes run image instead of
+// ImageAPIService, such as a mock server published elsewhere or built
+// locally. An empty image keeps the default. It returns t.
+func (t *Translator) WithMockImage(image string) *Translator {
+	t.registry[model.ServiceTypeAPIService] = &APIServiceTemplate{SpecDir: t.specDir, Image: image}
+	return t
+}
+
+// generatedDir returns the directory generated files other than specs,
+// such as Prometheus configs, are written to.
+func (t *Translator) generatedDir() string {
+	if t.specDir != "" {
+		return t.specDir
+	}
+	return DefaultSpecDir()
+}
+
 // Translate converts a diagram into an ordered slice of container configs.
 // The order respects dependency ordering (infrastructure before application).
 // The port allocator is reset for each translation call.
 func (t *Translator) Translate(diagram model.Diagram) ([]docker.ContainerConfig, error) {
+	nodes, err := t.TranslateNodes(diagram)
+	if err != nil {
+		return nil, err
+	}
+	if nodes == nil {
+		return nil, nil
+	}
+
+	configs := make([]docker.ContainerConfig, len(nodes))
+	for i, n := range nodes {
+		configs[i] = n.Config
+	}
+	return configs, nil
+}
+
+// TranslateNodes is like Translate but keeps each config paired with its
+// source node, its dependencies and the artifacts mounted into it. When the
+// diagram enables Prometheus, the monitoring containers follow the nodes;
+// see addMonitoring.
+func (t *Translator) TranslateNodes(diagram model.Diagram) ([]TranslatedNode, error) {
 	if len(diagram.Nodes) == 0 {
 		return nil, nil
 	}
@@ -46,6 +131,11 @@ func (t *Translator) Translate(diagram model.Diagram) ([]docker.ContainerConfig,
 		}
 	}
 
+	hostnames, err := AssignHostnames(diagram.Nodes)
+	if err != nil {
+		return nil, fmt.Errorf("assign hostnames: %w", err)
+	}
+
 	// Resolve startup order.
 	order, err := ResolveDependencies(diagram.Nodes, diagram.Edges)
 	if err != nil {
@@ -63,23 +153,107 @@ func (t *Translator) Translate(diagram model.Diagram) ([]docker.ContainerConfig,
 
 	// Build configs in dependency order.
 	configs := make([]docker.ContainerConfig, 0, len(order))
+	targets := make(map[string]edgeTarget, len(order))
 	for _, nodeID := range order {
 		node := nodeMap[nodeID]
 		tmpl := t.registry[node.Type]
 
-		n := portsRequired(node.Type)
+		n, err := portsRequired(node)
+		if err != nil {
+			return nil, fmt.Errorf("build config for node %q: %w", nodeID, err)
+		}
 		ports, err := t.allocator.AllocateN(n)
 		if err != nil {
 			return nil, fmt.Errorf("allocate ports for node %q: %w", nodeID, err)
 		}
 
-		cfg, err := tmpl.Build(node, ports[0], ports[1:]...)
+		var hostPort string
+		if len(ports) > 0 {
+			hostPort = ports[0]
+			ports = ports[1:]
+		}
+
+		cfg, err := tmpl.Build(node, hostnames[nodeID], hostPort, ports...)
 		if err != nil {
 			return nil, fmt.Errorf("build config for node %q: %w", nodeID, err)
 		}
 
+		targets[nodeID] = edgeTarget{index: len(configs), nodeType: node.Type, port: cfg.Ports[hostPort]}
 		configs = append(configs, cfg)
 	}
 
-	return configs, nil
+	injected := injectEdgeEnv(configs, targets, diagram.Edges)
+
+	deps := dependencyMap(diagram.Edges, nodeMap)
+	if err := t.wireDownstream(order, nodeMap, configs, targets, deps); err != nil {
+		return nil, err
+	}
+
+	result := make([]TranslatedNode, len(order))
+	for i, nodeID := range order {
+		artifacts, err := collectArtifacts(configs[i])
+		if err != nil {
+			return nil, fmt.Errorf("collect artifacts for node %q: %w", nodeID, err)
+		}
+		result[i] = TranslatedNode{
+			Node:        nodeMap[nodeID],
+			Config:      configs[i],
+			DependsOn:   deps[nodeID],
+			Artifacts:   artifacts,
+			InjectedEnv: injected[i],
+		}
+	}
+
+	if diagram.PrometheusEnabled() {
+		return t.addMonitoring(diagram.ID, result)
+	}
+	return result, nil
+}
+
+// dependencyMap returns, for each node, the sorted unique IDs of the nodes it
+// depends on. Edges referencing unknown nodes and self-edges are ignored.
+func dependencyMap(edges []model.DiagramEdge, nodeMap map[string]model.DiagramNode) map[string][]string {
+	seen := make(map[string]map[string]bool)
+	deps := make(map[string][]string)
+	for _, e := range edges {
+		if _, ok := nodeMap[e.Source]; !ok {
+			continue
+		}
+		if _, ok := nodeMap[e.Target]; !ok || e.Source == e.Target {
+			continue
+		}
+		if seen[e.Source] == nil {
+			seen[e.Source] = make(map[string]bool)
+		}
+		if seen[e.Source][e.Target] {
+			continue
+		}
+		seen[e.Source][e.Target] = true
+		deps[e.Source] = append(deps[e.Source], e.Target)
+	}
+	for id := range deps {
+		sort.Strings(deps[id])
+	}
+	return deps
+}
+
+// collectArtifacts reads every bind-mounted regular file of a config. Mounts
+// whose host path is not a regular file (directories, sockets) are skipped.
+func collectArtifacts(cfg docker.ContainerConfig) ([]Artifact, error) {
+	var artifacts []Artifact
+	for hostPath, containerPath := range cfg.Volumes {
+		info, err := os.Stat(hostPath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		content, err := os.ReadFile(hostPath)
+		if err != nil {
+			return nil, fmt.Errorf("read %q: %w", hostPath, err)
+		}
+		artifacts = append(artifacts, Artifact{HostPath: hostPath, ContainerPath: containerPath, Content: content})
+	}
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].ContainerPath < artifacts[j].ContainerPath
+	})
+	return artifacts, nil
 }
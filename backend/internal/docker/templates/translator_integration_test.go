@@ -198,9 +198,10 @@ func TestE2E_FullDiagramTranslation(t *testing.T) {
 			t.Errorf("RabbitMQ should have 2 port mappings, got %d", rmqPorts)
 		}
 
-		// Total unique ports: 4 single-port services + 1 dual-port = 6.
-		if len(allHostPorts) != 6 {
-			t.Errorf("expected 6 total port mappings, got %d", len(allHostPorts))
+		// Total unique ports: 3 single-port services + 2 dual-port
+		// (RabbitMQ, API service with metrics) = 7.
+		if len(allHostPorts) != 7 {
+			t.Errorf("expected 7 total port mappings, got %d", len(allHostPorts))
 		}
 	})
 
@@ -346,9 +347,9 @@ func TestE2E_PortAllocationUniqueness(t *testing.T) {
 		}
 	}
 
-	// 4 single-port + 1 dual-port (RabbitMQ) = 6 total.
-	if len(allPorts) != 6 {
-		t.Errorf("expected 6 unique host ports, got %d", len(allPorts))
+	// 3 single-port + 2 dual-port (RabbitMQ, API service) = 7 total.
+	if len(allPorts) != 7 {
+		t.Errorf("expected 7 unique host ports, got %d", len(allPorts))
 	}
 }
 
@@ -393,6 +394,7 @@ func TestE2E_OrchestratorContractCompliance(t *testing.T) {
 		for _, cp := range c.Ports {
 			validPorts := map[string]bool{
 				PortAPIService:         true,
+				PortAPIServiceMetrics:  true,
 				PortPostgreSQL:         true,
 				PortRedis:              true,
 				PortNginx:              true,
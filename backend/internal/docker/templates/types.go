@@ -1,13 +1,20 @@
 package templates
 
 import (
+	"time"
+
 	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
 	"github.com/stwalsh4118/hephaestus/backend/internal/model"
 )
 
-// Docker image constants for each service type.
+// ImageAPIService is the mock server image built from
+// docker/mock-server.Dockerfile and published by the mock-server workflow.
+// API Service nodes run it unless the Translator is given another; see
+// Translator.WithMockImage.
+const ImageAPIService = "ghcr.io/stwalsh4118/hephaestus-mock-server:latest"
+
+// Docker image constants for the other service types.
 const (
-	ImageAPIService = "stoplight/prism:latest"
 	ImagePostgreSQL = "postgres:16"
 	ImageRedis      = "redis:7"
 	ImageNginx      = "nginx:latest"
@@ -16,11 +23,11 @@ const (
 
 // Default container-side port constants for each service type.
 const (
-	PortAPIService        = "4010"
-	PortPostgreSQL        = "5432"
-	PortRedis             = "6379"
-	PortNginx             = "80"
-	PortRabbitMQAMQP      = "5672"
+	PortAPIService         = "4010"
+	PortPostgreSQL         = "5432"
+	PortRedis              = "6379"
+	PortNginx              = "80"
+	PortRabbitMQAMQP       = "5672"
 	PortRabbitMQManagement = "15672"
 )
 
@@ -33,23 +40,44 @@ func DefaultPostgresEnv() map[string]string {
 	}
 }
 
+// Healthcheck timing for the built-in infrastructure services.
+const (
+	infraHealthInterval = 5 * time.Second
+	infraHealthTimeout  = 3 * time.Second
+	infraHealthRetries  = 10
+)
+
+// infraHealthcheck returns a healthcheck running test with the standard
+// infrastructure timing.
+func infraHealthcheck(test ...string) *docker.HealthcheckConfig {
+	return &docker.HealthcheckConfig{
+		Test:     test,
+		Interval: infraHealthInterval,
+		Timeout:  infraHealthTimeout,
+		Retries:  infraHealthRetries,
+	}
+}
+
 // ContainerTemplate builds a docker.ContainerConfig from a diagram node.
-// The hostPort parameter is the allocated host port; multi-port services
+// The hostname parameter is the node's unique, RFC 1123-valid hostname
+// (see AssignHostnames), used as both container name and hostname. The
+// hostPort parameter is the allocated host port; multi-port services
 // receive additional ports via the hostPorts variadic parameter.
 type ContainerTemplate interface {
-	Build(node model.DiagramNode, hostPort string, hostPorts ...string) (docker.ContainerConfig, error)
+	Build(node model.DiagramNode, hostname, hostPort string, hostPorts ...string) (docker.ContainerConfig, error)
 }
 
 // TemplateRegistry maps service type strings to their ContainerTemplate.
 type TemplateRegistry map[string]ContainerTemplate
 
-// NewRegistry returns a TemplateRegistry populated with all 5 service templates.
+// NewRegistry returns a TemplateRegistry populated with all service templates.
 func NewRegistry() TemplateRegistry {
 	return TemplateRegistry{
-		model.ServiceTypeAPIService: &APIServiceTemplate{},
-		model.ServiceTypePostgreSQL: &PostgreSQLTemplate{},
-		model.ServiceTypeRedis:      &RedisTemplate{},
-		model.ServiceTypeNginx:      &NginxTemplate{},
-		model.ServiceTypeRabbitMQ:   &RabbitMQTemplate{},
+		model.ServiceTypeAPIService:      &APIServiceTemplate{},
+		model.ServiceTypePostgreSQL:      &PostgreSQLTemplate{},
+		model.ServiceTypeRedis:           &RedisTemplate{},
+		model.ServiceTypeNginx:           &NginxTemplate{},
+		model.ServiceTypeRabbitMQ:        &RabbitMQTemplate{},
+		model.ServiceTypeCustomContainer: &CustomContainerTemplate{},
 	}
 }
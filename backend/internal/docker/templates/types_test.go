@@ -3,6 +3,8 @@ package templates
 import (
 	"strconv"
 	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
 )
 
 func TestImageConstants_AreNonEmpty(t *testing.T) {
@@ -46,8 +48,8 @@ func TestNewRegistry_ReturnsPopulatedRegistry(t *testing.T) {
 	if reg == nil {
 		t.Fatal("NewRegistry returned nil")
 	}
-	if len(reg) != 5 {
-		t.Errorf("expected 5 entries in registry, got %d", len(reg))
+	if len(reg) != len(model.ValidServiceTypes) {
+		t.Errorf("expected %d entries in registry, got %d", len(model.ValidServiceTypes), len(reg))
 	}
 }
 
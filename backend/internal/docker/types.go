@@ -1,17 +1,48 @@
 package docker
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // ContainerNamePrefix is prepended to all managed container names to avoid
 // conflicts with the user's other Docker containers.
 const ContainerNamePrefix = "heph-"
 
+// Labels set on managed resources. Every container and the shared network
+// carry LabelManaged; the deploy manager adds the node and deployment IDs so
+// resources left behind by an earlier run can be traced back, and the node's
+// service type.
+const (
+	LabelManaged      = "io.hephaestus.managed"
+	LabelNodeID       = "io.hephaestus.node-id"
+	LabelDeploymentID = "io.hephaestus.deployment-id"
+	LabelServiceType  = "io.hephaestus.service-type"
+)
+
 // DefaultHealthCheckInterval is the default polling interval for container health checks.
 const DefaultHealthCheckInterval = 5 * time.Second
 
-// HealthStatusCallback is called by StartHealthPolling with the current status of all
-// managed containers, keyed by container ID.
-type HealthStatusCallback func(statuses map[string]ContainerStatus)
+// Health polling limits: at most HealthCheckConcurrency containers are
+// inspected at once, and each inspection is abandoned after HealthCheckTimeout.
+const (
+	HealthCheckConcurrency = 8
+	HealthCheckTimeout     = 3 * time.Second
+)
+
+// HealthTransition records a container's status changing between two polls.
+// From is empty the first time a container is observed.
+type HealthTransition struct {
+	ContainerID string          `json:"containerId"`
+	From        ContainerStatus `json:"from,omitempty"`
+	To          ContainerStatus `json:"to"`
+	At          time.Time       `json:"at"`
+}
+
+// HealthTransitionCallback is called by StartHealthPolling with the
+// transitions observed in one poll, ordered by container ID. It is not
+// called for polls in which nothing changed.
+type HealthTransitionCallback func(transitions []HealthTransition)
 
 // ContainerStatus represents the current state of a managed container.
 type ContainerStatus string
@@ -23,18 +54,36 @@ const (
 	StatusError     ContainerStatus = "error"
 	StatusHealthy   ContainerStatus = "healthy"
 	StatusUnhealthy ContainerStatus = "unhealthy"
+	StatusPaused    ContainerStatus = "paused"
 )
 
 // ContainerConfig holds the configuration needed to create a container.
 type ContainerConfig struct {
-	Image       string            `json:"image"`
-	Name        string            `json:"name"`
-	Cmd         []string          `json:"cmd,omitempty"`
-	Env         map[string]string `json:"env,omitempty"`
-	Ports       map[string]string `json:"ports,omitempty"`   // host port → container port
-	Volumes     map[string]string `json:"volumes,omitempty"` // host path → container path
-	Hostname    string            `json:"hostname,omitempty"`
-	NetworkName string            `json:"networkName,omitempty"`
+	Image       string             `json:"image"`
+	Name        string             `json:"name"`
+	Cmd         []string           `json:"cmd,omitempty"`
+	Entrypoint  []string           `json:"entrypoint,omitempty"`
+	Env         map[string]string  `json:"env,omitempty"`
+	Ports       map[string]string  `json:"ports,omitempty"`   // host port → container port
+	Volumes     map[string]string  `json:"volumes,omitempty"` // host path → container path
+	Hostname    string             `json:"hostname,omitempty"`
+	NetworkName string             `json:"networkName,omitempty"`
+	Healthcheck *HealthcheckConfig `json:"healthcheck,omitempty"`
+	Labels      map[string]string  `json:"labels,omitempty"` // LabelManaged is always added
+	// ExtraHosts are added to the container's /etc/hosts, as "host:ip";
+	// the ip "host-gateway" resolves to the Docker host.
+	ExtraHosts []string `json:"extraHosts,omitempty"`
+}
+
+// HealthcheckConfig describes a container health probe. Test uses the Docker
+// form: ["CMD", "arg", ...] or ["CMD-SHELL", "command"]. Zero durations and
+// retries fall back to the daemon defaults.
+type HealthcheckConfig struct {
+	Test        []string      `json:"test"`
+	Interval    time.Duration `json:"interval,omitempty"`
+	Timeout     time.Duration `json:"timeout,omitempty"`
+	Retries     int           `json:"retries,omitempty"`
+	StartPeriod time.Duration `json:"startPeriod,omitempty"`
 }
 
 // ContainerInfo represents the current state of a running or stopped container.
@@ -44,4 +93,25 @@ type ContainerInfo struct {
 	Image  string            `json:"image"`
 	Status ContainerStatus   `json:"status"`
 	Ports  map[string]string `json:"ports,omitempty"` // host port → container port
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Managed reports whether the container was created by an orchestrator: it
+// carries LabelManaged and its name has ContainerNamePrefix. Docker's name
+// filter matches substrings, so a name containing the prefix is not enough.
+func (c ContainerInfo) Managed() bool {
+	return c.Labels[LabelManaged] == "true" && strings.HasPrefix(c.Name, ContainerNamePrefix)
+}
+
+// ContainerStats is a sample of a container's resource usage.
+type ContainerStats struct {
+	// CPUPercent is the CPU used since the previous sample, 100 per fully
+	// used core.
+	CPUPercent       float64 `json:"cpuPercent"`
+	MemoryBytes      uint64  `json:"memoryBytes"`
+	MemoryLimitBytes uint64  `json:"memoryLimitBytes"`
+	// NetworkRxBytes and NetworkTxBytes count the bytes received and sent
+	// on every interface since the container started.
+	NetworkRxBytes uint64 `json:"networkRxBytes"`
+	NetworkTxBytes uint64 `json:"networkTxBytes"`
 }
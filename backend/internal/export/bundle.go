@@ -0,0 +1,126 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// filesDir is the bundle directory that holds generated artifacts such as
+// OpenAPI specs, one subdirectory per service.
+const filesDir = "files"
+
+// zipModTime is stamped on every zip entry so that identical bundles produce
+// byte-identical archives.
+var zipModTime = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Options tune how diagrams are translated for export.
+type Options struct {
+	// MockImage is the image api-service nodes run; empty means
+	// templates.ImageAPIService.
+	MockImage string
+}
+
+// Bundle is a set of generated files keyed by slash-separated relative path.
+type Bundle map[string][]byte
+
+// Paths returns the bundle's file paths in sorted order.
+func (b Bundle) Paths() []string {
+	paths := make([]string, 0, len(b))
+	for p := range b {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// WriteZip writes the bundle as a zip archive. Entries are written in path
+// order with a fixed timestamp, so the output is deterministic.
+func (b Bundle) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, p := range b.Paths() {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     p,
+			Method:   zip.Deflate,
+			Modified: zipModTime,
+		})
+		if err != nil {
+			return fmt.Errorf("create zip entry %q: %w", p, err)
+		}
+		if _, err := fw.Write(b[p]); err != nil {
+			return fmt.Errorf("write zip entry %q: %w", p, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("close zip: %w", err)
+	}
+	return nil
+}
+
+// artifactPath returns the bundle path for an artifact mounted into service.
+func artifactPath(service string, a templates.Artifact) string {
+	return path.Join(filesDir, service, path.Base(a.HostPath))
+}
+
+// translate converts a diagram using a throwaway spec directory, so exports
+// never rewrite spec files mounted by a running deployment.
+func translate(d model.Diagram, opts Options) ([]templates.TranslatedNode, error) {
+	dir, err := os.MkdirTemp("", "heph-export-*")
+	if err != nil {
+		return nil, fmt.Errorf("create export spec directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	nodes, err := templates.NewTranslatorWithSpecDir(dir).WithMockImage(opts.MockImage).TranslateNodes(d)
+	if err != nil {
+		return nil, fmt.Errorf("translate diagram: %w", err)
+	}
+	return nodes, nil
+}
+
+// projectName converts a diagram name into an identifier usable as a Compose
+// project, Kubernetes namespace or Terraform label: lowercase alphanumerics
+// and single hyphens. Falls back to "hephaestus" when nothing remains.
+func projectName(name string) string {
+	var b strings.Builder
+	lastHyphen := true
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+			lastHyphen = false
+		case !lastHyphen:
+			b.WriteByte('-')
+			lastHyphen = true
+		}
+	}
+	s := strings.Trim(b.String(), "-")
+	if s == "" {
+		return "hephaestus"
+	}
+	return s
+}
+
+// sortedPorts returns a config's host→container port pairs ordered by
+// numeric host port.
+func sortedPorts(ports map[string]string) [][2]string {
+	pairs := make([][2]string, 0, len(ports))
+	for host, ctr := range ports {
+		pairs = append(pairs, [2]string{host, ctr})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if len(pairs[i][0]) != len(pairs[j][0]) {
+			return len(pairs[i][0]) < len(pairs[j][0])
+		}
+		return pairs[i][0] < pairs[j][0]
+	})
+	return pairs
+}
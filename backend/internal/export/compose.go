@@ -0,0 +1,196 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeFileName is the name of the Compose file inside a compose bundle.
+const ComposeFileName = "docker-compose.yml"
+
+// Compose depends_on conditions.
+const (
+	conditionHealthy = "service_healthy"
+	conditionStarted = "service_started"
+)
+
+// composeFile is the subset of the Compose specification we emit.
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string                       `yaml:"image"`
+	Hostname    string                       `yaml:"hostname,omitempty"`
+	Entrypoint  []string                     `yaml:"entrypoint,omitempty"`
+	Command     []string                     `yaml:"command,omitempty"`
+	Environment map[string]string            `yaml:"environment,omitempty"`
+	Ports       []quoted                     `yaml:"ports,omitempty"`
+	Volumes     []string                     `yaml:"volumes,omitempty"`
+	Healthcheck *composeHealthcheck          `yaml:"healthcheck,omitempty"`
+	DependsOn   map[string]composeDependency `yaml:"depends_on,omitempty"`
+}
+
+type composeHealthcheck struct {
+	Test        []string `yaml:"test"`
+	Interval    string   `yaml:"interval,omitempty"`
+	Timeout     string   `yaml:"timeout,omitempty"`
+	Retries     int      `yaml:"retries,omitempty"`
+	StartPeriod string   `yaml:"start_period,omitempty"`
+}
+
+type composeDependency struct {
+	Condition string `yaml:"condition"`
+}
+
+// Compose translates a diagram and renders it as a Compose file. The returned
+// bundle holds docker-compose.yml plus every generated artifact (OpenAPI specs
+// and similar) under files/<service>/, referenced by relative bind mounts.
+func Compose(d model.Diagram, opts Options) (Bundle, error) {
+	nodes, err := translate(d, opts)
+	if err != nil {
+		return nil, err
+	}
+	return renderCompose(projectName(d.Name), nodes)
+}
+
+// renderCompose builds the compose bundle from already-translated nodes.
+func renderCompose(project string, nodes []templates.TranslatedNode) (Bundle, error) {
+	bundle := Bundle{}
+	serviceByID := make(map[string]string, len(nodes))
+	healthy := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		serviceByID[n.Node.ID] = n.Config.Name
+		healthy[n.Node.ID] = n.Config.Healthcheck != nil
+	}
+
+	file := composeFile{Name: project, Services: make(map[string]composeService, len(nodes))}
+	for _, n := range nodes {
+		cfg := n.Config
+		svc := composeService{
+			Image:      cfg.Image,
+			Hostname:   cfg.Hostname,
+			Entrypoint: escapeAll(cfg.Entrypoint),
+			Command:    escapeAll(cfg.Cmd),
+		}
+
+		if len(cfg.Env) > 0 {
+			svc.Environment = make(map[string]string, len(cfg.Env))
+			for k, v := range cfg.Env {
+				svc.Environment[k] = escapeCompose(v)
+			}
+		}
+
+		for _, p := range sortedPorts(cfg.Ports) {
+			svc.Ports = append(svc.Ports, quoted(p[0]+":"+p[1]))
+		}
+
+		svc.Volumes = composeVolumes(cfg.Name, cfg.Volumes, n.Artifacts, bundle)
+
+		if hc := cfg.Healthcheck; hc != nil {
+			svc.Healthcheck = &composeHealthcheck{
+				Test:        escapeAll(hc.Test),
+				Interval:    durationString(hc.Interval),
+				Timeout:     durationString(hc.Timeout),
+				Retries:     hc.Retries,
+				StartPeriod: durationString(hc.StartPeriod),
+			}
+		}
+
+		if len(n.DependsOn) > 0 {
+			svc.DependsOn = make(map[string]composeDependency, len(n.DependsOn))
+			for _, dep := range n.DependsOn {
+				cond := conditionStarted
+				if healthy[dep] {
+					cond = conditionHealthy
+				}
+				svc.DependsOn[serviceByID[dep]] = composeDependency{Condition: cond}
+			}
+		}
+
+		if _, dup := file.Services[cfg.Name]; dup {
+			return nil, fmt.Errorf("duplicate service name %q", cfg.Name)
+		}
+		file.Services[cfg.Name] = svc
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(file); err != nil {
+		return nil, fmt.Errorf("encode compose file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode compose file: %w", err)
+	}
+	bundle[ComposeFileName] = buf.Bytes()
+
+	return bundle, nil
+}
+
+// quoted is a string always emitted double-quoted. Port mappings need this:
+// YAML 1.1 parsers read values such as 10005:53 as base-60 integers.
+type quoted string
+
+// MarshalYAML implements yaml.Marshaler.
+func (q quoted) MarshalYAML() (any, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: string(q)}, nil
+}
+
+// composeVolumes renders bind mounts. Generated artifacts are copied into the
+// bundle and mounted read-only from their relative path; any other mount keeps
+// its original host path.
+func composeVolumes(service string, volumes map[string]string, artifacts []templates.Artifact, bundle Bundle) []string {
+	if len(volumes) == 0 {
+		return nil
+	}
+
+	bundled := make(map[string]bool, len(artifacts))
+	var out []string
+	for _, a := range artifacts {
+		p := artifactPath(service, a)
+		bundle[p] = a.Content
+		bundled[a.HostPath] = true
+		out = append(out, "./"+p+":"+a.ContainerPath+":ro")
+	}
+	for hostPath, containerPath := range volumes {
+		if !bundled[hostPath] {
+			out = append(out, hostPath+":"+containerPath)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// escapeCompose escapes "$" so Compose does not interpolate values that are
+// meant for the container's shell.
+func escapeCompose(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+func escapeAll(in []string) []string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = escapeCompose(s)
+	}
+	return out
+}
+
+// durationString formats d for Compose ("5s", "1m30s"); zero yields "".
+func durationString(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
@@ -0,0 +1,201 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+func exportDiagram() model.Diagram {
+	return model.Diagram{
+		ID:   "d1",
+		Name: "My Shop!",
+		Nodes: []model.DiagramNode{
+			{ID: "api", Type: model.ServiceTypeAPIService, Name: "User API", Config: json.RawMessage(`{"type":"api-service","endpoints":[{"method":"GET","path":"/users","responseSchema":""}]}`)},
+			{ID: "pg", Type: model.ServiceTypePostgreSQL, Name: "Main DB"},
+			{ID: "gw", Type: model.ServiceTypeNginx, Name: "Gateway"},
+			{ID: "dns", Type: model.ServiceTypeCustomContainer, Name: "DNS", Config: json.RawMessage(`{"type":"custom-container","image":"coredns/coredns:1.11.1","command":["sh","-c","echo $HOME"],"ports":[53]}`)},
+		},
+		Edges: []model.DiagramEdge{
+			{ID: "e1", Source: "api", Target: "pg"},
+			{ID: "e2", Source: "gw", Target: "api"},
+		},
+	}
+}
+
+// parsedCompose mirrors the fields the tests assert on.
+type parsedCompose struct {
+	Name     string `yaml:"name"`
+	Services map[string]struct {
+		Image       string            `yaml:"image"`
+		Command     []string          `yaml:"command"`
+		Environment map[string]string `yaml:"environment"`
+		Ports       []string          `yaml:"ports"`
+		Volumes     []string          `yaml:"volumes"`
+		Healthcheck *struct {
+			Test     []string `yaml:"test"`
+			Interval string   `yaml:"interval"`
+		} `yaml:"healthcheck"`
+		DependsOn map[string]struct {
+			Condition string `yaml:"condition"`
+		} `yaml:"depends_on"`
+	} `yaml:"services"`
+}
+
+func TestCompose_RendersServices(t *testing.T) {
+	bundle, err := Compose(exportDiagram(), Options{})
+	if err != nil {
+		t.Fatalf("Compose: %v", err)
+	}
+
+	var f parsedCompose
+	if err := yaml.Unmarshal(bundle[ComposeFileName], &f); err != nil {
+		t.Fatalf("unmarshal compose: %v", err)
+	}
+
+	if f.Name != "my-shop" {
+		t.Errorf("expected project name %q, got %q", "my-shop", f.Name)
+	}
+	if len(f.Services) != 4 {
+		t.Fatalf("expected 4 services, got %d", len(f.Services))
+	}
+
+	api := f.Services["user-api"]
+	if api.Image != templates.ImageAPIService {
+		t.Errorf("unexpected api image %q", api.Image)
+	}
+	if api.Environment["MAIN_DB_HOST"] != "main-db" {
+		t.Errorf("expected injected MAIN_DB_HOST, got %v", api.Environment)
+	}
+	if got := api.DependsOn["main-db"].Condition; got != conditionHealthy {
+		t.Errorf("expected api to wait for healthy main-db, got %q", got)
+	}
+	if len(api.Volumes) != 1 || api.Volumes[0] != "./files/user-api/user-api.json:/tmp/spec.json:ro" {
+		t.Errorf("unexpected api volumes: %v", api.Volumes)
+	}
+
+	gw := f.Services["gateway"]
+	if got := gw.DependsOn["user-api"].Condition; got != conditionStarted {
+		t.Errorf("expected gateway to wait for started user-api, got %q", got)
+	}
+
+	db := f.Services["main-db"]
+	if db.Healthcheck == nil || db.Healthcheck.Interval != "5s" {
+		t.Errorf("expected postgres healthcheck with 5s interval, got %+v", db.Healthcheck)
+	}
+	if !strings.Contains(db.Healthcheck.Test[1], "$$POSTGRES_USER") {
+		t.Errorf("expected $ to be escaped in healthcheck, got %q", db.Healthcheck.Test[1])
+	}
+
+	dns := f.Services["dns"]
+	if len(dns.Ports) != 1 || !strings.HasSuffix(dns.Ports[0], ":53") {
+		t.Errorf("expected port mapping to be read back as a string, got %v", dns.Ports)
+	}
+	if dns.Command[2] != "echo $$HOME" {
+		t.Errorf("expected $ to be escaped in command, got %q", dns.Command[2])
+	}
+}
+
+func TestCompose_UsesConfiguredMockImage(t *testing.T) {
+	const image = "registry.example.com/acme/mock-server:1.2.3"
+	bundle, err := Compose(exportDiagram(), Options{MockImage: image})
+	if err != nil {
+		t.Fatalf("Compose: %v", err)
+	}
+
+	var f parsedCompose
+	if err := yaml.Unmarshal(bundle[ComposeFileName], &f); err != nil {
+		t.Fatalf("unmarshal compose: %v", err)
+	}
+	if got := f.Services["user-api"].Image; got != image {
+		t.Errorf("expected api image %q, got %q", image, got)
+	}
+}
+
+func TestCompose_BundlesArtifacts(t *testing.T) {
+	bundle, err := Compose(exportDiagram(), Options{})
+	if err != nil {
+		t.Fatalf("Compose: %v", err)
+	}
+
+	spec, ok := bundle["files/user-api/user-api.json"]
+	if !ok {
+		t.Fatalf("expected spec artifact in bundle, got %v", bundle.Paths())
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatalf("artifact is not valid JSON: %v", err)
+	}
+	if doc["openapi"] != "3.0.0" {
+		t.Errorf("expected an OpenAPI document, got %v", doc["openapi"])
+	}
+}
+
+func TestCompose_IsDeterministic(t *testing.T) {
+	var archives [2][]byte
+	for i := range archives {
+		bundle, err := Compose(exportDiagram(), Options{})
+		if err != nil {
+			t.Fatalf("Compose: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := bundle.WriteZip(&buf); err != nil {
+			t.Fatalf("WriteZip: %v", err)
+		}
+		archives[i] = buf.Bytes()
+	}
+	if !bytes.Equal(archives[0], archives[1]) {
+		t.Error("expected identical archives for identical diagrams")
+	}
+}
+
+func TestBundle_WriteZip(t *testing.T) {
+	bundle := Bundle{"b.txt": []byte("b"), "a/a.txt": []byte("a")}
+
+	var buf bytes.Buffer
+	if err := bundle.WriteZip(&buf); err != nil {
+		t.Fatalf("WriteZip: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	if len(zr.File) != 2 || zr.File[0].Name != "a/a.txt" || zr.File[1].Name != "b.txt" {
+		t.Fatalf("unexpected entries: %v", zr.File)
+	}
+	rc, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatalf("open entry: %v", err)
+	}
+	defer func() { _ = rc.Close() }()
+	content, _ := io.ReadAll(rc)
+	if string(content) != "a" {
+		t.Errorf("unexpected content %q", content)
+	}
+}
+
+func TestProjectName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"My Shop!", "my-shop"},
+		{"  spaced   out ", "spaced-out"},
+		{"already-fine", "already-fine"},
+		{"@#$", "hephaestus"},
+	}
+
+	for _, tc := range tests {
+		if got := projectName(tc.input); got != tc.expected {
+			t.Errorf("projectName(%q) = %q, want %q", tc.input, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"strconv"
+	"strings"
+)
+
+// hclLineWidth is the width above which lists are written one element per line.
+const hclLineWidth = 80
+
+// hclBody is an ordered sequence of attributes and nested blocks. It renders
+// the way `terraform fmt` would: two-space indentation, "=" aligned across
+// consecutive single-line attributes, and blank lines around nested blocks.
+type hclBody struct {
+	items []hclItem
+}
+
+type hclItem struct {
+	name  string // attribute name, or the block header for blocks
+	value string // attribute expression; empty for blocks
+	block *hclBody
+}
+
+// attr appends an attribute whose value is an already-formatted expression.
+func (b *hclBody) attr(name, expr string) {
+	b.items = append(b.items, hclItem{name: name, value: expr})
+}
+
+// block appends a nested block and returns its body. Labels are quoted.
+func (b *hclBody) block(typ string, labels ...string) *hclBody {
+	header := typ
+	for _, l := range labels {
+		header += " " + strconv.Quote(l)
+	}
+	child := &hclBody{}
+	b.items = append(b.items, hclItem{name: header, block: child})
+	return child
+}
+
+// render writes the body at the given indentation depth.
+func (b *hclBody) render(sb *strings.Builder, depth int) {
+	pad := strings.Repeat("  ", depth)
+	widths := b.alignWidths()
+	for i, item := range b.items {
+		if i > 0 && (item.block != nil || b.items[i-1].block != nil) {
+			sb.WriteByte('\n')
+		}
+
+		if item.block != nil {
+			if len(item.block.items) == 0 {
+				sb.WriteString(pad + item.name + " {}\n")
+				continue
+			}
+			sb.WriteString(pad + item.name + " {\n")
+			item.block.render(sb, depth+1)
+			sb.WriteString(pad + "}\n")
+			continue
+		}
+
+		value := strings.ReplaceAll(item.value, "\n", "\n"+pad)
+		sb.WriteString(pad + item.name + strings.Repeat(" ", widths[i]-len(item.name)) + " = " + value + "\n")
+	}
+}
+
+// alignWidths returns, per attribute, the name width its "=" aligns to: the
+// longest name in its run of consecutive single-line attributes. A block or a
+// multi-line value ends a run.
+func (b *hclBody) alignWidths() []int {
+	widths := make([]int, len(b.items))
+	start := 0
+	flush := func(end int) {
+		width := 0
+		for j := start; j < end; j++ {
+			width = max(width, len(b.items[j].name))
+		}
+		for j := start; j < end; j++ {
+			widths[j] = width
+		}
+	}
+	for i, item := range b.items {
+		switch {
+		case item.block != nil:
+			flush(i)
+			start = i + 1
+		case strings.Contains(item.value, "\n"):
+			flush(i)
+			widths[i] = len(item.name)
+			start = i + 1
+		}
+	}
+	flush(len(b.items))
+	return widths
+}
+
+// String renders the body as a top-level file.
+func (b *hclBody) String() string {
+	var sb strings.Builder
+	b.render(&sb, 0)
+	return sb.String()
+}
+
+// hclString returns s as a quoted HCL string literal. Template sequences are
+// escaped so the value is taken literally.
+func hclString(s string) string {
+	return `"` + hclEscape(s) + `"`
+}
+
+// hclEscape escapes s for use inside a quoted HCL template.
+func hclEscape(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	)
+	return r.Replace(s)
+}
+
+// hclList formats already-formatted expressions as a list, on one line when
+// short and one element per line otherwise.
+func hclList(exprs []string) string {
+	if len(exprs) == 0 {
+		return "[]"
+	}
+	line := "[" + strings.Join(exprs, ", ") + "]"
+	if len(line) <= hclLineWidth {
+		return line
+	}
+	var sb strings.Builder
+	sb.WriteString("[\n")
+	for _, e := range exprs {
+		sb.WriteString("  " + e + ",\n")
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// hclStrings formats a list of string literals.
+func hclStrings(values []string) string {
+	exprs := make([]string, len(values))
+	for i, v := range values {
+		exprs[i] = hclString(v)
+	}
+	return hclList(exprs)
+}
+
+// hclIdent converts a name into a Terraform identifier: lowercase letters,
+// digits and underscores, not starting with a digit.
+func hclIdent(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	s := b.String()
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "svc_" + s
+	}
+	return s
+}
@@ -0,0 +1,509 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// KubernetesFileName is the name of the manifest file inside a Kubernetes bundle.
+const KubernetesFileName = "kubernetes.yaml"
+
+// Recommended Kubernetes labels applied to every generated object.
+const (
+	labelName      = "app.kubernetes.io/name"
+	labelPartOf    = "app.kubernetes.io/part-of"
+	labelManagedBy = "app.kubernetes.io/managed-by"
+	managedByValue = "hephaestus"
+)
+
+// Kubernetes defaults for values the diagram does not specify.
+const (
+	defaultStorageSize = "1Gi"
+	dataVolumeName     = "data"
+	filesVolumeName    = "files"
+	waitImage          = "busybox:1.36"
+	maxDNSLabelLength  = 63
+)
+
+// secretEnvMarkers flag environment variables that are moved into a Secret.
+// URLs with embedded passwords are detected separately.
+var secretEnvMarkers = []string{"PASSWORD", "SECRET", "TOKEN"}
+
+// statefulData lists the service types rendered as StatefulSets, with the
+// directory each keeps its data in.
+var statefulData = map[string]string{
+	model.ServiceTypePostgreSQL: "/var/lib/postgresql/data",
+	model.ServiceTypeRedis:      "/data",
+	model.ServiceTypeRabbitMQ:   "/var/lib/rabbitmq",
+}
+
+// k8sObject is the union of the top-level fields used by the emitted kinds.
+type k8sObject struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sMeta           `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	StringData map[string]string `yaml:"stringData,omitempty"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	BinaryData map[string]string `yaml:"binaryData,omitempty"`
+	Spec       any               `yaml:"spec,omitempty"`
+}
+
+type k8sMeta struct {
+	Name      string            `yaml:"name,omitempty"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type k8sServiceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []k8sServicePort  `yaml:"ports"`
+}
+
+type k8sServicePort struct {
+	Name       string `yaml:"name"`
+	Port       int    `yaml:"port"`
+	TargetPort int    `yaml:"targetPort"`
+}
+
+type k8sWorkloadSpec struct {
+	Replicas             int            `yaml:"replicas"`
+	ServiceName          string         `yaml:"serviceName,omitempty"`
+	Selector             k8sSelector    `yaml:"selector"`
+	Template             k8sPodTemplate `yaml:"template"`
+	VolumeClaimTemplates []k8sClaim     `yaml:"volumeClaimTemplates,omitempty"`
+}
+
+type k8sSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+type k8sPodTemplate struct {
+	Metadata k8sMeta    `yaml:"metadata"`
+	Spec     k8sPodSpec `yaml:"spec"`
+}
+
+type k8sPodSpec struct {
+	InitContainers []k8sContainer `yaml:"initContainers,omitempty"`
+	Containers     []k8sContainer `yaml:"containers"`
+	Volumes        []k8sVolume    `yaml:"volumes,omitempty"`
+}
+
+type k8sContainer struct {
+	Name           string             `yaml:"name"`
+	Image          string             `yaml:"image"`
+	Command        []string           `yaml:"command,omitempty"`
+	Args           []string           `yaml:"args,omitempty"`
+	Ports          []k8sContainerPort `yaml:"ports,omitempty"`
+	Env            []k8sEnvVar        `yaml:"env,omitempty"`
+	Resources      *k8sResources      `yaml:"resources,omitempty"`
+	VolumeMounts   []k8sVolumeMount   `yaml:"volumeMounts,omitempty"`
+	ReadinessProbe *k8sProbe          `yaml:"readinessProbe,omitempty"`
+	LivenessProbe  *k8sProbe          `yaml:"livenessProbe,omitempty"`
+}
+
+type k8sContainerPort struct {
+	ContainerPort int `yaml:"containerPort"`
+}
+
+type k8sEnvVar struct {
+	Name      string        `yaml:"name"`
+	Value     string        `yaml:"value,omitempty"`
+	ValueFrom *k8sEnvSource `yaml:"valueFrom,omitempty"`
+}
+
+type k8sEnvSource struct {
+	SecretKeyRef k8sKeyRef `yaml:"secretKeyRef"`
+}
+
+type k8sKeyRef struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+}
+
+type k8sResources struct {
+	Limits map[string]string `yaml:"limits,omitempty"`
+}
+
+type k8sVolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	SubPath   string `yaml:"subPath,omitempty"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type k8sVolume struct {
+	Name      string          `yaml:"name"`
+	ConfigMap k8sConfigMapRef `yaml:"configMap"`
+}
+
+type k8sConfigMapRef struct {
+	Name string `yaml:"name"`
+}
+
+type k8sProbe struct {
+	Exec                k8sExec `yaml:"exec"`
+	InitialDelaySeconds int     `yaml:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int     `yaml:"periodSeconds,omitempty"`
+	TimeoutSeconds      int     `yaml:"timeoutSeconds,omitempty"`
+	FailureThreshold    int     `yaml:"failureThreshold,omitempty"`
+}
+
+type k8sExec struct {
+	Command []string `yaml:"command"`
+}
+
+type k8sClaim struct {
+	Metadata k8sMeta      `yaml:"metadata"`
+	Spec     k8sClaimSpec `yaml:"spec"`
+}
+
+type k8sClaimSpec struct {
+	AccessModes []string          `yaml:"accessModes"`
+	Resources   k8sClaimResources `yaml:"resources"`
+}
+
+type k8sClaimResources struct {
+	Requests map[string]string `yaml:"requests"`
+}
+
+// Kubernetes translates a diagram and renders it as Kubernetes manifests in a
+// namespace named after the diagram. For every node it emits, in order: a
+// Secret holding credential environment variables, a ConfigMap holding the
+// generated artifacts (OpenAPI specs and similar), a ClusterIP Service for its
+// ports, and a StatefulSet (PostgreSQL, Redis, RabbitMQ) or Deployment.
+// Edges become init containers that wait for each dependency's Service.
+// Bind mounts other than generated artifacts have no cluster equivalent and
+// are omitted.
+func Kubernetes(d model.Diagram, opts Options) (Bundle, error) {
+	nodes, err := translate(d, opts)
+	if err != nil {
+		return nil, err
+	}
+	return renderKubernetes(projectName(d.Name), nodes)
+}
+
+// renderKubernetes builds the Kubernetes bundle from already-translated nodes.
+func renderKubernetes(namespace string, nodes []templates.TranslatedNode) (Bundle, error) {
+	byID := make(map[string]docker.ContainerConfig, len(nodes))
+	for _, n := range nodes {
+		byID[n.Node.ID] = n.Config
+	}
+
+	objects := []k8sObject{{
+		APIVersion: "v1",
+		Kind:       "Namespace",
+		Metadata:   k8sMeta{Name: namespace, Labels: map[string]string{labelManagedBy: managedByValue}},
+	}}
+
+	seen := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		name := n.Config.Name
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate service name %q", name)
+		}
+		seen[name] = true
+
+		objs, err := kubernetesObjects(namespace, n, byID)
+		if err != nil {
+			return nil, fmt.Errorf("render node %q: %w", n.Node.ID, err)
+		}
+		objects = append(objects, objs...)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, obj := range objects {
+		if err := enc.Encode(obj); err != nil {
+			return nil, fmt.Errorf("encode %s %q: %w", obj.Kind, obj.Metadata.Name, err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode kubernetes manifests: %w", err)
+	}
+
+	return Bundle{KubernetesFileName: buf.Bytes()}, nil
+}
+
+// kubernetesObjects renders the objects for a single translated node.
+func kubernetesObjects(namespace string, n templates.TranslatedNode, byID map[string]docker.ContainerConfig) ([]k8sObject, error) {
+	cfg := n.Config
+	name := cfg.Name
+	labels := map[string]string{
+		labelName:      name,
+		labelPartOf:    namespace,
+		labelManagedBy: managedByValue,
+	}
+	meta := func(objName string) k8sMeta {
+		return k8sMeta{Name: objName, Namespace: namespace, Labels: labels}
+	}
+
+	var objects []k8sObject
+	container := k8sContainer{
+		Name:    name,
+		Image:   cfg.Image,
+		Command: cfg.Entrypoint,
+		Args:    cfg.Cmd,
+	}
+
+	dataDir, stateful := statefulData[n.Node.Type]
+	env := make(map[string]string, len(cfg.Env)+1)
+	for k, v := range cfg.Env {
+		env[k] = v
+	}
+	if n.Node.Type == model.ServiceTypePostgreSQL {
+		// The claim's root holds lost+found, which initdb refuses.
+		env["PGDATA"] = dataDir + "/pgdata"
+	}
+
+	secretName := name + "-secret"
+	secret := map[string]string{}
+	for _, k := range sortedKeys(env) {
+		v := env[k]
+		if isSecretEnv(k, v) {
+			secret[k] = v
+			container.Env = append(container.Env, k8sEnvVar{
+				Name:      k,
+				ValueFrom: &k8sEnvSource{SecretKeyRef: k8sKeyRef{Name: secretName, Key: k}},
+			})
+			continue
+		}
+		container.Env = append(container.Env, k8sEnvVar{Name: k, Value: v})
+	}
+	if len(secret) > 0 {
+		objects = append(objects, k8sObject{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata:   meta(secretName),
+			Type:       "Opaque",
+			StringData: secret,
+		})
+	}
+
+	var volumes []k8sVolume
+	if len(n.Artifacts) > 0 {
+		cmName := name + "-files"
+		cm := k8sObject{APIVersion: "v1", Kind: "ConfigMap", Metadata: meta(cmName)}
+		for _, a := range n.Artifacts {
+			key := path.Base(a.HostPath)
+			if utf8.Valid(a.Content) {
+				if cm.Data == nil {
+					cm.Data = map[string]string{}
+				}
+				cm.Data[key] = string(a.Content)
+			} else {
+				if cm.BinaryData == nil {
+					cm.BinaryData = map[string]string{}
+				}
+				cm.BinaryData[key] = base64.StdEncoding.EncodeToString(a.Content)
+			}
+			container.VolumeMounts = append(container.VolumeMounts, k8sVolumeMount{
+				Name:      filesVolumeName,
+				MountPath: a.ContainerPath,
+				SubPath:   key,
+				ReadOnly:  true,
+			})
+		}
+		objects = append(objects, cm)
+		volumes = append(volumes, k8sVolume{Name: filesVolumeName, ConfigMap: k8sConfigMapRef{Name: cmName}})
+	}
+
+	ports, err := containerPorts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(ports) > 0 {
+		svc := k8sServiceSpec{Selector: map[string]string{labelName: name}}
+		for _, p := range ports {
+			container.Ports = append(container.Ports, k8sContainerPort{ContainerPort: p})
+			svc.Ports = append(svc.Ports, k8sServicePort{Name: fmt.Sprintf("tcp-%d", p), Port: p, TargetPort: p})
+		}
+		objects = append(objects, k8sObject{APIVersion: "v1", Kind: "Service", Metadata: meta(name), Spec: svc})
+	}
+
+	container.ReadinessProbe = probeFor(cfg.Healthcheck)
+	container.LivenessProbe = probeFor(cfg.Healthcheck)
+
+	replicas := 1
+	if r := n.Node.Resources; r != nil {
+		if r.Replicas > 0 {
+			replicas = r.Replicas
+		}
+		container.Resources = resourceLimits(r)
+	}
+
+	pod := k8sPodSpec{Volumes: volumes}
+	for _, dep := range n.DependsOn {
+		if init, ok := waitContainer(byID[dep]); ok {
+			pod.InitContainers = append(pod.InitContainers, init)
+		}
+	}
+
+	spec := k8sWorkloadSpec{
+		Replicas: replicas,
+		Selector: k8sSelector{MatchLabels: map[string]string{labelName: name}},
+		Template: k8sPodTemplate{Metadata: k8sMeta{Labels: labels}},
+	}
+	kind := "Deployment"
+	if stateful {
+		kind = "StatefulSet"
+		spec.ServiceName = name
+		size := defaultStorageSize
+		if r := n.Node.Resources; r != nil && r.StorageGB > 0 {
+			size = fmt.Sprintf("%dGi", r.StorageGB)
+		}
+		spec.VolumeClaimTemplates = []k8sClaim{{
+			Metadata: k8sMeta{Name: dataVolumeName},
+			Spec: k8sClaimSpec{
+				AccessModes: []string{"ReadWriteOnce"},
+				Resources:   k8sClaimResources{Requests: map[string]string{"storage": size}},
+			},
+		}}
+		container.VolumeMounts = append(container.VolumeMounts, k8sVolumeMount{Name: dataVolumeName, MountPath: dataDir})
+	}
+	pod.Containers = []k8sContainer{container}
+	spec.Template.Spec = pod
+
+	objects = append(objects, k8sObject{APIVersion: "apps/v1", Kind: kind, Metadata: meta(name), Spec: spec})
+	return objects, nil
+}
+
+// containerPorts returns the distinct container-side ports of cfg in
+// ascending order.
+func containerPorts(cfg docker.ContainerConfig) ([]int, error) {
+	seen := make(map[int]bool, len(cfg.Ports))
+	var ports []int
+	for _, ctr := range cfg.Ports {
+		p, err := strconv.Atoi(ctr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid container port %q: %w", ctr, err)
+		}
+		if !seen[p] {
+			seen[p] = true
+			ports = append(ports, p)
+		}
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
+
+// waitContainer returns an init container that blocks until dep's first port
+// accepts connections. Dependencies without ports cannot be probed.
+func waitContainer(dep docker.ContainerConfig) (k8sContainer, bool) {
+	ports, err := containerPorts(dep)
+	if err != nil || len(ports) == 0 {
+		return k8sContainer{}, false
+	}
+	host := dep.Hostname
+	if host == "" {
+		host = dep.Name
+	}
+	script := fmt.Sprintf("until nc -z %s %d; do echo waiting for %s; sleep 2; done", host, ports[0], host)
+	return k8sContainer{
+		Name:    dnsLabel("wait-for-" + dep.Name),
+		Image:   waitImage,
+		Command: []string{"sh", "-c", script},
+	}, true
+}
+
+// probeFor converts a Docker healthcheck into an exec probe. Docker's
+// CMD-SHELL form runs through sh -c, as the Docker daemon does.
+func probeFor(hc *docker.HealthcheckConfig) *k8sProbe {
+	if hc == nil || len(hc.Test) == 0 {
+		return nil
+	}
+	var cmd []string
+	switch hc.Test[0] {
+	case "NONE":
+		return nil
+	case "CMD":
+		cmd = hc.Test[1:]
+	case "CMD-SHELL":
+		cmd = []string{"sh", "-c", strings.Join(hc.Test[1:], " ")}
+	default:
+		cmd = hc.Test
+	}
+	if len(cmd) == 0 {
+		return nil
+	}
+	return &k8sProbe{
+		Exec:                k8sExec{Command: cmd},
+		InitialDelaySeconds: ceilSeconds(hc.StartPeriod),
+		PeriodSeconds:       ceilSeconds(hc.Interval),
+		TimeoutSeconds:      ceilSeconds(hc.Timeout),
+		FailureThreshold:    hc.Retries,
+	}
+}
+
+// resourceLimits maps node resources onto container limits. Returns nil when
+// no limit is set.
+func resourceLimits(r *model.Resources) *k8sResources {
+	limits := map[string]string{}
+	if r.CPUs > 0 {
+		limits["cpu"] = fmt.Sprintf("%dm", int(math.Round(r.CPUs*1000)))
+	}
+	if r.MemoryMB > 0 {
+		limits["memory"] = fmt.Sprintf("%dMi", r.MemoryMB)
+	}
+	if len(limits) == 0 {
+		return nil
+	}
+	return &k8sResources{Limits: limits}
+}
+
+// isSecretEnv reports whether an environment variable carries a credential:
+// its name contains a secret marker or its value is a URL with a password.
+func isSecretEnv(key, value string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range secretEnvMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// dnsLabel truncates s to the 63-character limit of a DNS label.
+func dnsLabel(s string) string {
+	if len(s) > maxDNSLabelLength {
+		s = s[:maxDNSLabelLength]
+	}
+	return strings.TrimRight(s, "-")
+}
+
+// ceilSeconds rounds d up to whole seconds; zero yields zero.
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
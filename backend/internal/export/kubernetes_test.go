@@ -0,0 +1,236 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// parsedObject mirrors the manifest fields the tests assert on.
+type parsedObject struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	StringData map[string]string `yaml:"stringData"`
+	Data       map[string]string `yaml:"data"`
+	Spec       struct {
+		Replicas    int    `yaml:"replicas"`
+		ServiceName string `yaml:"serviceName"`
+		Ports       []struct {
+			Port int `yaml:"port"`
+		} `yaml:"ports"`
+		Template struct {
+			Spec struct {
+				InitContainers []struct {
+					Name    string   `yaml:"name"`
+					Command []string `yaml:"command"`
+				} `yaml:"initContainers"`
+				Containers []struct {
+					Image string   `yaml:"image"`
+					Args  []string `yaml:"args"`
+					Env   []struct {
+						Name      string `yaml:"name"`
+						Value     string `yaml:"value"`
+						ValueFrom *struct {
+							SecretKeyRef struct {
+								Name string `yaml:"name"`
+							} `yaml:"secretKeyRef"`
+						} `yaml:"valueFrom"`
+					} `yaml:"env"`
+					Resources struct {
+						Limits map[string]string `yaml:"limits"`
+					} `yaml:"resources"`
+					VolumeMounts []struct {
+						MountPath string `yaml:"mountPath"`
+						SubPath   string `yaml:"subPath"`
+					} `yaml:"volumeMounts"`
+					ReadinessProbe *struct {
+						Exec struct {
+							Command []string `yaml:"command"`
+						} `yaml:"exec"`
+						PeriodSeconds    int `yaml:"periodSeconds"`
+						FailureThreshold int `yaml:"failureThreshold"`
+					} `yaml:"readinessProbe"`
+				} `yaml:"containers"`
+			} `yaml:"spec"`
+		} `yaml:"template"`
+		VolumeClaimTemplates []struct {
+			Spec struct {
+				Resources struct {
+					Requests map[string]string `yaml:"requests"`
+				} `yaml:"resources"`
+			} `yaml:"spec"`
+		} `yaml:"volumeClaimTemplates"`
+	} `yaml:"spec"`
+}
+
+func renderKubernetesDiagram(t *testing.T, d model.Diagram) map[string]parsedObject {
+	t.Helper()
+	bundle, err := Kubernetes(d, Options{})
+	if err != nil {
+		t.Fatalf("Kubernetes: %v", err)
+	}
+
+	objects := make(map[string]parsedObject)
+	dec := yaml.NewDecoder(bytes.NewReader(bundle[KubernetesFileName]))
+	for {
+		var obj parsedObject
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("decode manifest: %v", err)
+		}
+		objects[obj.Kind+"/"+obj.Metadata.Name] = obj
+	}
+	return objects
+}
+
+func TestKubernetes_RendersWorkloads(t *testing.T) {
+	d := exportDiagram()
+	d.Nodes[0].Resources = &model.Resources{Replicas: 3, CPUs: 0.5, MemoryMB: 256}
+	objects := renderKubernetesDiagram(t, d)
+
+	for _, key := range []string{
+		"Namespace/my-shop",
+		"Deployment/user-api", "Service/user-api", "ConfigMap/user-api-files", "Secret/user-api-secret",
+		"StatefulSet/main-db", "Service/main-db", "Secret/main-db-secret",
+		"Deployment/gateway", "Service/gateway",
+		"Deployment/dns", "Service/dns",
+	} {
+		if _, ok := objects[key]; !ok {
+			t.Errorf("missing %s; got %v", key, mapKeys(objects))
+		}
+	}
+
+	api := objects["Deployment/user-api"]
+	if api.Metadata.Namespace != "my-shop" {
+		t.Errorf("expected namespace my-shop, got %q", api.Metadata.Namespace)
+	}
+	if api.Spec.Replicas != 3 {
+		t.Errorf("expected 3 replicas, got %d", api.Spec.Replicas)
+	}
+	ctr := api.Spec.Template.Spec.Containers[0]
+	if ctr.Resources.Limits["cpu"] != "500m" || ctr.Resources.Limits["memory"] != "256Mi" {
+		t.Errorf("unexpected limits %v", ctr.Resources.Limits)
+	}
+	if len(ctr.VolumeMounts) != 1 || ctr.VolumeMounts[0].MountPath != "/tmp/spec.json" || ctr.VolumeMounts[0].SubPath != "user-api.json" {
+		t.Errorf("unexpected spec mount %+v", ctr.VolumeMounts)
+	}
+	if !strings.Contains(objects["ConfigMap/user-api-files"].Data["user-api.json"], `"/users"`) {
+		t.Error("expected OpenAPI spec in ConfigMap")
+	}
+	if init := api.Spec.Template.Spec.InitContainers; len(init) != 1 || init[0].Name != "wait-for-main-db" ||
+		!strings.Contains(init[0].Command[2], "nc -z main-db 5432") {
+		t.Errorf("expected init container waiting for main-db, got %+v", init)
+	}
+}
+
+func TestKubernetes_StorageSize(t *testing.T) {
+	d := exportDiagram()
+	d.Nodes[1].Resources = &model.Resources{StorageGB: 50}
+	objects := renderKubernetesDiagram(t, d)
+
+	claims := objects["StatefulSet/main-db"].Spec.VolumeClaimTemplates
+	if len(claims) != 1 || claims[0].Spec.Resources.Requests["storage"] != "50Gi" {
+		t.Errorf("expected a 50Gi claim, got %+v", claims)
+	}
+}
+
+func TestKubernetes_StatefulInfrastructure(t *testing.T) {
+	objects := renderKubernetesDiagram(t, exportDiagram())
+
+	db := objects["StatefulSet/main-db"]
+	if db.Spec.ServiceName != "main-db" || len(db.Spec.VolumeClaimTemplates) != 1 {
+		t.Errorf("expected serviceName and volume claim, got %+v", db.Spec)
+	}
+	ctr := db.Spec.Template.Spec.Containers[0]
+	if ctr.ReadinessProbe == nil || ctr.ReadinessProbe.Exec.Command[0] != "sh" || ctr.ReadinessProbe.PeriodSeconds != 5 || ctr.ReadinessProbe.FailureThreshold != 10 {
+		t.Errorf("unexpected readiness probe %+v", ctr.ReadinessProbe)
+	}
+	if db.Spec.Replicas != 1 {
+		t.Errorf("expected default of 1 replica, got %d", db.Spec.Replicas)
+	}
+	if size := db.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests["storage"]; size != "1Gi" {
+		t.Errorf("expected the default 1Gi claim, got %q", size)
+	}
+
+	secret := objects["Secret/main-db-secret"]
+	if secret.StringData["POSTGRES_PASSWORD"] != "hephaestus" {
+		t.Errorf("expected POSTGRES_PASSWORD in secret, got %v", secret.StringData)
+	}
+	for _, e := range ctr.Env {
+		if e.Name == "POSTGRES_PASSWORD" && (e.Value != "" || e.ValueFrom == nil) {
+			t.Errorf("expected POSTGRES_PASSWORD to reference the secret, got %+v", e)
+		}
+	}
+
+	// The injected connection URL embeds credentials and must be a secret too.
+	if _, ok := objects["Secret/user-api-secret"].StringData["MAIN_DB_URL"]; !ok {
+		t.Errorf("expected MAIN_DB_URL in user-api secret, got %v", objects["Secret/user-api-secret"].StringData)
+	}
+}
+
+func TestKubernetes_CustomContainer(t *testing.T) {
+	objects := renderKubernetesDiagram(t, exportDiagram())
+
+	dns := objects["Deployment/dns"]
+	ctr := dns.Spec.Template.Spec.Containers[0]
+	if ctr.Image != "coredns/coredns:1.11.1" {
+		t.Errorf("unexpected image %q", ctr.Image)
+	}
+	if strings.Join(ctr.Args, " ") != "sh -c echo $HOME" {
+		t.Errorf("expected command to map to args unescaped, got %v", ctr.Args)
+	}
+	if svc := objects["Service/dns"]; len(svc.Spec.Ports) != 1 || svc.Spec.Ports[0].Port != 53 {
+		t.Errorf("expected service port 53, got %+v", svc.Spec.Ports)
+	}
+}
+
+func TestKubernetes_Deterministic(t *testing.T) {
+	a, err := Kubernetes(exportDiagram(), Options{})
+	if err != nil {
+		t.Fatalf("Kubernetes: %v", err)
+	}
+	b, err := Kubernetes(exportDiagram(), Options{})
+	if err != nil {
+		t.Fatalf("Kubernetes: %v", err)
+	}
+	if !bytes.Equal(a[KubernetesFileName], b[KubernetesFileName]) {
+		t.Error("expected identical manifests for identical diagrams")
+	}
+}
+
+func TestIsSecretEnv(t *testing.T) {
+	tests := []struct {
+		key, value string
+		want       bool
+	}{
+		{"POSTGRES_PASSWORD", "x", true},
+		{"api_token", "x", true},
+		{"DB_URL", "postgres://u:p@db:5432/app", true},
+		{"CACHE_URL", "redis://cache:6379", false},
+		{"LOG_LEVEL", "debug", false},
+	}
+	for _, tc := range tests {
+		if got := isSecretEnv(tc.key, tc.value); got != tc.want {
+			t.Errorf("isSecretEnv(%q, %q) = %v, want %v", tc.key, tc.value, got, tc.want)
+		}
+	}
+}
+
+func mapKeys(m map[string]parsedObject) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
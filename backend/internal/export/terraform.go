@@ -0,0 +1,191 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// File names inside a Terraform bundle.
+const (
+	TerraformVersionsFile  = "versions.tf"
+	TerraformVariablesFile = "variables.tf"
+	TerraformMainFile      = "main.tf"
+)
+
+// Terraform provider pin for the generated module.
+const (
+	terraformProviderSource  = "kreuzwerker/docker"
+	terraformProviderVersion = "~> 3.0"
+)
+
+// Terraform translates a diagram and renders it as a Terraform module for the
+// kreuzwerker/docker provider. The module holds one docker_network, and per
+// node a docker_image and a docker_container. Dependencies are expressed as
+// depends_on references, and containers with a healthcheck set wait = true so
+// dependents start once they are healthy, as `docker compose` would. Generated
+// artifacts are copied under files/<service>/ and written into containers with
+// upload blocks. Host ports and credential environment variables become
+// variables whose defaults are the values the translator chose.
+func Terraform(d model.Diagram, opts Options) (Bundle, error) {
+	nodes, err := translate(d, opts)
+	if err != nil {
+		return nil, err
+	}
+	return renderTerraform(projectName(d.Name), nodes)
+}
+
+// renderTerraform builds the Terraform bundle from already-translated nodes.
+func renderTerraform(project string, nodes []templates.TranslatedNode) (Bundle, error) {
+	bundle := Bundle{}
+
+	idents := make(map[string]string, len(nodes))
+	seen := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		ident := hclIdent(n.Config.Name)
+		if other, dup := seen[ident]; dup {
+			return nil, fmt.Errorf("services %q and %q map to the same Terraform name %q", other, n.Config.Name, ident)
+		}
+		seen[ident] = n.Config.Name
+		idents[n.Node.ID] = ident
+	}
+
+	versions := &hclBody{}
+	tf := versions.block("terraform")
+	providers := tf.block("required_providers")
+	providers.attr("docker", "{\n  source  = "+hclString(terraformProviderSource)+"\n  version = "+hclString(terraformProviderVersion)+"\n}")
+	versions.block("provider", "docker")
+
+	variables := &hclBody{}
+	network := variables.block("variable", "network_name")
+	network.attr("description", hclString("Name of the Docker network all containers join."))
+	network.attr("type", "string")
+	network.attr("default", hclString(project))
+
+	main := &hclBody{}
+	net := main.block("resource", "docker_network", "this")
+	net.attr("name", "var.network_name")
+
+	for _, n := range nodes {
+		ident := idents[n.Node.ID]
+		cfg := n.Config
+
+		img := main.block("resource", "docker_image", ident)
+		img.attr("name", hclString(cfg.Image))
+		img.attr("keep_locally", "true")
+
+		ctr := main.block("resource", "docker_container", ident)
+		ctr.attr("name", hclString(cfg.Name))
+		ctr.attr("image", "docker_image."+ident+".image_id")
+		if cfg.Hostname != "" {
+			ctr.attr("hostname", hclString(cfg.Hostname))
+		}
+		if len(cfg.Entrypoint) > 0 {
+			ctr.attr("entrypoint", hclStrings(cfg.Entrypoint))
+		}
+		if len(cfg.Cmd) > 0 {
+			ctr.attr("command", hclStrings(cfg.Cmd))
+		}
+
+		if len(cfg.Env) > 0 {
+			env := make([]string, 0, len(cfg.Env))
+			for _, k := range sortedKeys(cfg.Env) {
+				v := cfg.Env[k]
+				if !isSecretEnv(k, v) {
+					env = append(env, hclString(k+"="+v))
+					continue
+				}
+				name := ident + "_" + hclIdent(k)
+				secret := variables.block("variable", name)
+				secret.attr("description", hclString(fmt.Sprintf("Value of %s for %s.", k, cfg.Name)))
+				secret.attr("type", "string")
+				secret.attr("default", hclString(v))
+				secret.attr("sensitive", "true")
+				env = append(env, `"`+hclEscape(k+"=")+"${var."+name+`}"`)
+			}
+			ctr.attr("env", hclList(env))
+		}
+		if cfg.Healthcheck != nil {
+			ctr.attr("wait", "true")
+		}
+
+		nets := ctr.block("networks_advanced")
+		nets.attr("name", "docker_network.this.name")
+		if cfg.Hostname != "" {
+			nets.attr("aliases", hclStrings([]string{cfg.Hostname}))
+		}
+
+		for _, p := range sortedPorts(cfg.Ports) {
+			internal, err := strconv.Atoi(p[1])
+			if err != nil {
+				return nil, fmt.Errorf("node %q: invalid container port %q", n.Node.ID, p[1])
+			}
+			external, err := strconv.Atoi(p[0])
+			if err != nil {
+				return nil, fmt.Errorf("node %q: invalid host port %q", n.Node.ID, p[0])
+			}
+			name := fmt.Sprintf("%s_port_%d", ident, internal)
+			v := variables.block("variable", name)
+			v.attr("description", hclString(fmt.Sprintf("Host port published for %s container port %d.", cfg.Name, internal)))
+			v.attr("type", "number")
+			v.attr("default", strconv.Itoa(external))
+
+			ports := ctr.block("ports")
+			ports.attr("internal", strconv.Itoa(internal))
+			ports.attr("external", "var."+name)
+		}
+
+		if hc := cfg.Healthcheck; hc != nil {
+			h := ctr.block("healthcheck")
+			h.attr("test", hclStrings(hc.Test))
+			if s := durationString(hc.Interval); s != "" {
+				h.attr("interval", hclString(s))
+			}
+			if s := durationString(hc.Timeout); s != "" {
+				h.attr("timeout", hclString(s))
+			}
+			if hc.Retries > 0 {
+				h.attr("retries", strconv.Itoa(hc.Retries))
+			}
+			if s := durationString(hc.StartPeriod); s != "" {
+				h.attr("start_period", hclString(s))
+			}
+		}
+
+		bundled := make(map[string]bool, len(n.Artifacts))
+		for _, a := range n.Artifacts {
+			p := artifactPath(cfg.Name, a)
+			bundle[p] = a.Content
+			bundled[a.HostPath] = true
+
+			up := ctr.block("upload")
+			up.attr("file", hclString(a.ContainerPath))
+			up.attr("content", `file("${path.module}/`+hclEscape(p)+`")`)
+		}
+		for _, hostPath := range sortedKeys(cfg.Volumes) {
+			if bundled[hostPath] {
+				continue
+			}
+			vol := ctr.block("volumes")
+			vol.attr("host_path", hclString(hostPath))
+			vol.attr("container_path", hclString(cfg.Volumes[hostPath]))
+		}
+
+		if len(n.DependsOn) > 0 {
+			deps := make([]string, len(n.DependsOn))
+			for i, dep := range n.DependsOn {
+				deps[i] = "docker_container." + idents[dep]
+			}
+			sort.Strings(deps)
+			ctr.attr("depends_on", hclList(deps))
+		}
+	}
+
+	bundle[TerraformVersionsFile] = []byte(versions.String())
+	bundle[TerraformVariablesFile] = []byte(variables.String())
+	bundle[TerraformMainFile] = []byte(main.String())
+	return bundle, nil
+}
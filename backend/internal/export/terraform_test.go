@@ -0,0 +1,119 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTerraform_RendersModule(t *testing.T) {
+	bundle, err := Terraform(exportDiagram(), Options{})
+	if err != nil {
+		t.Fatalf("Terraform: %v", err)
+	}
+
+	want := []string{"files/user-api/user-api.json", TerraformMainFile, TerraformVariablesFile, TerraformVersionsFile}
+	if got := bundle.Paths(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected paths %v, got %v", want, got)
+	}
+
+	main := string(bundle[TerraformMainFile])
+	for _, s := range []string{
+		`resource "docker_network" "this" {`,
+		`resource "docker_image" "main_db" {`,
+		`resource "docker_container" "main_db" {`,
+		`  image    = docker_image.main_db.image_id`,
+		`    external = var.main_db_port_5432`,
+		`    "POSTGRES_PASSWORD=${var.main_db_postgres_password}",`,
+		`  wait = true`,
+		`  depends_on = [docker_container.main_db]`,
+		`  depends_on = [docker_container.user_api]`,
+		`    content = file("${path.module}/files/user-api/user-api.json")`,
+		`    test     = ["CMD-SHELL", "pg_isready -U \"$POSTGRES_USER\" -d \"$POSTGRES_DB\""]`,
+		`  command  = ["sh", "-c", "echo $HOME"]`,
+	} {
+		if !strings.Contains(main, s) {
+			t.Errorf("expected main.tf to contain %q:\n%s", s, main)
+		}
+	}
+	if strings.Contains(main, "hephaestus:hephaestus@") {
+		t.Error("credentials in connection URLs must be variables, not literals")
+	}
+
+	vars := string(bundle[TerraformVariablesFile])
+	for _, s := range []string{
+		`variable "network_name" {`,
+		`  default     = "my-shop"`,
+		`variable "main_db_port_5432" {`,
+		`  type        = number`,
+		`variable "user_api_main_db_url" {`,
+		`  sensitive   = true`,
+	} {
+		if !strings.Contains(vars, s) {
+			t.Errorf("expected variables.tf to contain %q:\n%s", s, vars)
+		}
+	}
+
+	if !strings.Contains(string(bundle[TerraformVersionsFile]), `source  = "kreuzwerker/docker"`) {
+		t.Errorf("expected provider requirement in versions.tf:\n%s", bundle[TerraformVersionsFile])
+	}
+}
+
+func TestTerraform_Deterministic(t *testing.T) {
+	a, err := Terraform(exportDiagram(), Options{})
+	if err != nil {
+		t.Fatalf("Terraform: %v", err)
+	}
+	b, err := Terraform(exportDiagram(), Options{})
+	if err != nil {
+		t.Fatalf("Terraform: %v", err)
+	}
+	for _, p := range a.Paths() {
+		if !bytes.Equal(a[p], b[p]) {
+			t.Errorf("expected identical %s for identical diagrams", p)
+		}
+	}
+}
+
+func TestHCLString_EscapesTemplates(t *testing.T) {
+	got := hclString("a\"b\\c ${x} %{y}\n")
+	want := `"a\"b\\c $${x} %%{y}\n"`
+	if got != want {
+		t.Errorf("hclString: got %s, want %s", got, want)
+	}
+}
+
+func TestHCLIdent(t *testing.T) {
+	tests := map[string]string{
+		"main-db":  "main_db",
+		"User.API": "user_api",
+		"9lives":   "svc_9lives",
+	}
+	for in, want := range tests {
+		if got := hclIdent(in); got != want {
+			t.Errorf("hclIdent(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHCLBody_AlignsAttributeRuns(t *testing.T) {
+	b := &hclBody{}
+	r := b.block("resource", "x", "y")
+	r.attr("a", "1")
+	r.attr("long_name", "2")
+	r.block("inner")
+	r.attr("bb", "3")
+
+	want := `resource "x" "y" {
+  a         = 1
+  long_name = 2
+
+  inner {}
+
+  bb = 3
+}
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
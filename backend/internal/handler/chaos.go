@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/containerd/errdefs"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/chaos"
+)
+
+// ChaosHandler applies chaos actions to deployed nodes and manages
+// scheduled chaos experiments.
+type ChaosHandler struct {
+	controller *chaos.Controller
+}
+
+// NewChaosHandler creates a ChaosHandler backed by the given controller.
+func NewChaosHandler(controller *chaos.Controller) *ChaosHandler {
+	return &ChaosHandler{controller: controller}
+}
+
+// RegisterRoutes registers chaos routes on the given mux.
+func (h *ChaosHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/chaos/actions", h.Do)
+	mux.HandleFunc("GET /api/chaos/experiments", h.ListExperiments)
+	mux.HandleFunc("POST /api/chaos/experiments", h.ScheduleExperiment)
+	mux.HandleFunc("GET /api/chaos/experiments/{id}", h.GetExperiment)
+	mux.HandleFunc("DELETE /api/chaos/experiments/{id}", h.CancelExperiment)
+}
+
+// Do handles POST /api/chaos/actions. The action is applied immediately and
+// its result returned; it is also published over the WebSocket.
+func (h *ChaosHandler) Do(w http.ResponseWriter, r *http.Request) {
+	var req chaos.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	res, err := h.controller.Do(r.Context(), req)
+	if err != nil {
+		writeChaosError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ListExperiments handles GET /api/chaos/experiments.
+func (h *ChaosHandler) ListExperiments(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.controller.Experiments())
+}
+
+// ScheduleExperiment handles POST /api/chaos/experiments.
+func (h *ChaosHandler) ScheduleExperiment(w http.ResponseWriter, r *http.Request) {
+	var spec chaos.ExperimentSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	e, err := h.controller.Schedule(spec)
+	if err != nil {
+		writeChaosError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, e)
+}
+
+// GetExperiment handles GET /api/chaos/experiments/{id}.
+func (h *ChaosHandler) GetExperiment(w http.ResponseWriter, r *http.Request) {
+	e, err := h.controller.Experiment(r.PathValue("id"))
+	if err != nil {
+		writeChaosError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+// CancelExperiment handles DELETE /api/chaos/experiments/{id}. A running
+// experiment is reverted before it is returned.
+func (h *ChaosHandler) CancelExperiment(w http.ResponseWriter, r *http.Request) {
+	e, err := h.controller.Cancel(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeChaosError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+// writeChaosError maps chaos and orchestrator errors to responses.
+func writeChaosError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, chaos.ErrInvalid):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, chaos.ErrUnknownNode), errors.Is(err, chaos.ErrExperimentNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, chaos.ErrNotDeployed), errors.Is(err, chaos.ErrExperimentFinished), errdefs.IsConflict(err):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "chaos action failed: "+err.Error())
+	}
+}
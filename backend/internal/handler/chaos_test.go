@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/chaos"
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+func setupChaosTest(t *testing.T) (*http.ServeMux, *storage.FileStore, *deploy.Manager, *chaos.Controller) {
+	t.Helper()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	orch := docker.NewSimulatedOrchestrator(docker.SimulationConfig{})
+	manager := deploy.NewManager(orch, nil, docker.ModeSimulated, nil)
+	controller := chaos.NewController(orch, manager, nil)
+	t.Cleanup(func() { _ = controller.Shutdown(t.Context()) })
+	mux := http.NewServeMux()
+	NewChaosHandler(controller).RegisterRoutes(mux)
+	return mux, store, manager, controller
+}
+
+func TestChaos_Actions(t *testing.T) {
+	mux, store, manager, _ := setupChaosTest(t)
+
+	rec := doDeployRequest(mux, http.MethodPost, "/api/chaos/actions", `{"nodeId": "gw", "action": "pause"}`)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("action without a deployment: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	deployGateway(t, store, manager)
+
+	rec = doDeployRequest(mux, http.MethodPost, "/api/chaos/actions", `{"nodeId": "gw", "action": "kill", "signal": "SIGTERM"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var res chaos.Result
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if res.Action != chaos.ActionKill || res.Signal != "SIGTERM" || res.ContainerID == "" {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"invalid JSON", `{`, http.StatusBadRequest},
+		{"unknown action", `{"nodeId": "gw", "action": "explode"}`, http.StatusBadRequest},
+		{"unknown node", `{"nodeId": "missing", "action": "pause"}`, http.StatusNotFound},
+		{"pause a stopped container", `{"nodeId": "gw", "action": "pause"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doDeployRequest(mux, http.MethodPost, "/api/chaos/actions", tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("status: got %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestChaos_Experiments(t *testing.T) {
+	mux, store, manager, _ := setupChaosTest(t)
+	deployGateway(t, store, manager)
+
+	rec := doDeployRequest(mux, http.MethodPost, "/api/chaos/experiments", `{"nodeId": "gw", "action": "restart", "durationSeconds": 10}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("irreversible experiment: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = doDeployRequest(mux, http.MethodPost, "/api/chaos/experiments",
+		`{"nodeId": "gw", "action": "pause", "startAt": "2099-01-01T00:00:00Z", "durationSeconds": 10}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("experiment too far ahead: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = doDeployRequest(mux, http.MethodPost, "/api/chaos/experiments", `{"nodeId": "gw", "action": "disconnect", "durationSeconds": 600}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	var e chaos.Experiment
+	if err := json.NewDecoder(rec.Body).Decode(&e); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if e.ID == "" || e.Action != chaos.ActionDisconnect || e.DurationSeconds != 600 {
+		t.Errorf("unexpected experiment %+v", e)
+	}
+
+	rec = doGet(mux, "/api/chaos/experiments")
+	var list []chaos.Experiment
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != e.ID {
+		t.Errorf("unexpected experiments %+v", list)
+	}
+	if rec := doGet(mux, "/api/chaos/experiments/"+e.ID); rec.Code != http.StatusOK {
+		t.Errorf("get: got %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec = doDeployRequest(mux, http.MethodDelete, "/api/chaos/experiments/"+e.ID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("cancel: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&e); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if e.State != chaos.ExperimentCancelled {
+		t.Errorf("expected cancelled, got %q", e.State)
+	}
+
+	if rec := doDeployRequest(mux, http.MethodDelete, "/api/chaos/experiments/"+e.ID, ""); rec.Code != http.StatusConflict {
+		t.Errorf("cancel twice: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := doGet(mux, "/api/chaos/experiments/missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("get missing: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/cost"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+// CostHandler serves cloud cost estimates of stored diagrams.
+type CostHandler struct {
+	store storage.DiagramStore
+}
+
+// NewCostHandler creates a CostHandler backed by the given store.
+func NewCostHandler(store storage.DiagramStore) *CostHandler {
+	return &CostHandler{store: store}
+}
+
+// RegisterRoutes registers the cost estimate route on the given mux.
+func (h *CostHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/diagrams/{id}/cost", h.Estimate)
+}
+
+// Estimate handles GET /api/diagrams/{id}/cost, pricing the diagram per node
+// and month on provider (default aws) in region (default the provider's
+// base region).
+func (h *CostHandler) Estimate(w http.ResponseWriter, r *http.Request) {
+	d, ok := loadDiagram(w, h.store, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	provider := cost.Provider(r.URL.Query().Get("provider"))
+	if provider == "" {
+		provider = cost.ProviderAWS
+	}
+	est, err := cost.EstimateDiagram(*d, provider, r.URL.Query().Get("region"))
+	if err != nil {
+		if errors.Is(err, cost.ErrInvalid) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "estimate cost: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, est)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/cost"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+func setupCostTest(t *testing.T) (*http.ServeMux, *storage.FileStore) {
+	t.Helper()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	mux := http.NewServeMux()
+	NewCostHandler(store).RegisterRoutes(mux)
+	return mux, store
+}
+
+func TestCost_Estimate(t *testing.T) {
+	mux, store := setupCostTest(t)
+	id := storeExportDiagram(t, store)
+
+	rec := doGet(mux, "/api/diagrams/"+id+"/cost?provider=gcp&region=europe-west1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var est cost.Estimate
+	if err := json.Unmarshal(rec.Body.Bytes(), &est); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if est.DiagramID != id || est.Provider != cost.ProviderGCP || est.Region != "europe-west1" || len(est.Nodes) != 2 || est.TotalMonthly <= 0 {
+		t.Errorf("unexpected estimate %+v", est)
+	}
+
+	if err := json.Unmarshal(doGet(mux, "/api/diagrams/"+id+"/cost").Body.Bytes(), &est); err != nil || est.Provider != cost.ProviderAWS || est.Region != "us-east-1" {
+		t.Errorf("expected the AWS base region by default, got %+v (%v)", est, err)
+	}
+}
+
+func TestCost_EstimateErrors(t *testing.T) {
+	mux, store := setupCostTest(t)
+	id := storeExportDiagram(t, store)
+
+	for target, want := range map[string]int{
+		"/api/diagrams/" + id + "/cost?provider=oracle":          http.StatusBadRequest,
+		"/api/diagrams/" + id + "/cost?provider=aws&region=mars": http.StatusBadRequest,
+		"/api/diagrams/nope/cost":                                http.StatusNotFound,
+	} {
+		if rec := doGet(mux, target); rec.Code != want {
+			t.Errorf("%s: got %d, want %d", target, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+// deployErrorResponse is the body of a failed deploy or reconfigure that
+// was rolled back.
+type deployErrorResponse struct {
+	Error    string                `json:"error"`
+	Rollback deploy.RollbackReport `json:"rollback"`
+}
+
+// DeployHandler serves the deploy, teardown and status endpoints.
+type DeployHandler struct {
+	store   storage.DiagramStore
+	manager *deploy.Manager
+}
+
+// NewDeployHandler creates a DeployHandler backed by the given manager. The
+// revision recorded for a deployed diagram is looked up in store.
+func NewDeployHandler(store storage.DiagramStore, manager *deploy.Manager) *DeployHandler {
+	return &DeployHandler{store: store, manager: manager}
+}
+
+// RegisterRoutes registers deploy routes on the given mux.
+func (h *DeployHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/deploy", h.Deploy)
+	mux.HandleFunc("PATCH /api/deploy", h.Reconfigure)
+	mux.HandleFunc("DELETE /api/deploy", h.Teardown)
+	mux.HandleFunc("GET /api/deploy/status", h.Status)
+}
+
+// Deploy handles POST /api/deploy. The body is the diagram to deploy; it
+// replaces any current deployment.
+func (h *DeployHandler) Deploy(w http.ResponseWriter, r *http.Request) {
+	var d model.Diagram
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	if err := model.ValidateDiagram(&d); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.storedRevision(&d)
+
+	// A deploy must run to completion even if the client goes away, or it
+	// would leave a half-created deployment behind.
+	status, err := h.manager.Deploy(context.WithoutCancel(r.Context()), d)
+	if err != nil {
+		if errors.Is(err, deploy.ErrTranslation) {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		var deployErr *deploy.DeployError
+		if errors.As(err, &deployErr) {
+			writeJSON(w, http.StatusInternalServerError, deployErrorResponse{
+				Error:    "deploy failed: " + err.Error(),
+				Rollback: deployErr.Rollback,
+			})
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "deploy failed: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// Reconfigure handles PATCH /api/deploy. The body is the deployed diagram
+// with changes that only affect generated files, such as an api-service's
+// simulated latency and errors; they are applied without recreating
+// containers. Other changes need POST /api/deploy and get a 409.
+func (h *DeployHandler) Reconfigure(w http.ResponseWriter, r *http.Request) {
+	var d model.Diagram
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	if err := model.ValidateDiagram(&d); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.storedRevision(&d)
+
+	result, err := h.manager.Reconfigure(d)
+	if err != nil {
+		var deployErr *deploy.DeployError
+		switch {
+		case errors.Is(err, deploy.ErrTranslation):
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, deploy.ErrNotDeployed), errors.Is(err, deploy.ErrRedeployRequired):
+			writeError(w, http.StatusConflict, err.Error())
+		case errors.As(err, &deployErr):
+			writeJSON(w, http.StatusInternalServerError, deployErrorResponse{
+				Error:    "reconfigure failed: " + err.Error(),
+				Rollback: deployErr.Rollback,
+			})
+		default:
+			writeError(w, http.StatusInternalServerError, "reconfigure failed: "+err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// Teardown handles DELETE /api/deploy.
+func (h *DeployHandler) Teardown(w http.ResponseWriter, r *http.Request) {
+	status, err := h.manager.Teardown(context.WithoutCancel(r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "teardown failed: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// Status handles GET /api/deploy/status.
+func (h *DeployHandler) Status(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.manager.Status())
+}
+
+// storedRevision replaces the client-sent revision of d, which the store
+// manages, with the revision of the stored diagram with d's ID. It is 0
+// when the diagram was never saved or d differs from the stored copy, such
+// as unsaved edits, since no stored revision describes what is deployed.
+func (h *DeployHandler) storedRevision(d *model.Diagram) {
+	d.Revision = 0
+	stored, err := h.store.Get(d.ID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) && !errors.Is(err, storage.ErrInvalidID) {
+			log.Printf("failed to look up revision of diagram %q: %v", d.ID, err)
+		}
+		return
+	}
+	if sameContent(*d, *stored) {
+		d.Revision = stored.Revision
+	}
+}
+
+// sameContent reports whether a and b describe the same diagram, ignoring
+// their revisions and how node configs are formatted.
+func sameContent(a, b model.Diagram) bool {
+	a.Revision, b.Revision = 0, 0
+	ca, errA := canonicalJSON(a)
+	cb, errB := canonicalJSON(b)
+	return errA == nil && errB == nil && reflect.DeepEqual(ca, cb)
+}
+
+// canonicalJSON round-trips v through JSON into maps and slices, which
+// compare equal regardless of key order and whitespace.
+func canonicalJSON(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
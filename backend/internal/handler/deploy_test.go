@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+const deployDiagramJSON = `{
+	"id": "d1",
+	"name": "Deploy Test",
+	"nodes": [
+		{"id": "api", "type": "custom-container", "name": "API", "position": {"x": 0, "y": 0},
+		 "config": {"type": "custom-container", "image": "example/api:1", "ports": [8080]}},
+		{"id": "db", "type": "postgresql", "name": "DB", "position": {"x": 0, "y": 0}}
+	],
+	"edges": [{"id": "e1", "source": "api", "target": "db"}]
+}`
+
+func setupDeployMux(t *testing.T, cfg docker.SimulationConfig) *http.ServeMux {
+	t.Helper()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	manager := deploy.NewManager(docker.NewSimulatedOrchestrator(cfg), nil, docker.ModeSimulated, nil)
+	mux := http.NewServeMux()
+	NewDeployHandler(store, manager).RegisterRoutes(mux)
+	return mux
+}
+
+func doDeployRequest(mux *http.ServeMux, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDeploy_Success(t *testing.T) {
+	mux := setupDeployMux(t, docker.SimulationConfig{})
+
+	rec := doDeployRequest(mux, http.MethodPost, "/api/deploy", deployDiagramJSON)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var status deploy.Status
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if status.State != deploy.StateDeployed || status.Mode != docker.ModeSimulated || len(status.Nodes) != 2 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	rec = doDeployRequest(mux, http.MethodGet, "/api/deploy/status", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), `"state":"deployed"`) {
+		t.Errorf("expected deployed state, got %s", rec.Body.String())
+	}
+
+	rec = doDeployRequest(mux, http.MethodDelete, "/api/deploy", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), `"state":"idle"`) {
+		t.Errorf("expected idle state after teardown, got %s", rec.Body.String())
+	}
+}
+
+func TestDeploy_RecordsStoredRevision(t *testing.T) {
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	history, err := storage.NewFileDeploymentStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileDeploymentStore: %v", err)
+	}
+	manager := deploy.NewManager(docker.NewSimulatedOrchestrator(docker.SimulationConfig{}), history, docker.ModeSimulated, nil)
+	mux := http.NewServeMux()
+	NewDeployHandler(store, manager).RegisterRoutes(mux)
+
+	var d model.Diagram
+	if err := json.Unmarshal([]byte(deployDiagramJSON), &d); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	saved, err := store.Create(&d)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if saved, err = store.Update(saved.ID, saved); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	deployed := func(body string) int {
+		t.Helper()
+		rec := doDeployRequest(mux, http.MethodPost, "/api/deploy", body)
+		var status deploy.Status
+		if err := json.NewDecoder(rec.Body).Decode(&status); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("deploy: got %d (%v)", rec.Code, err)
+		}
+		record, err := history.Get(status.DeploymentID)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		return record.DiagramRevision
+	}
+
+	// The client-sent revision is ignored in favour of the stored one.
+	body := strings.Replace(deployDiagramJSON, `"id": "d1"`, `"id": "`+saved.ID+`", "revision": 99`, 1)
+	if got := deployed(body); got != 2 {
+		t.Errorf("stored diagram: recorded revision %d, want 2", got)
+	}
+	// Key order and whitespace in node configs do not matter.
+	reordered := strings.Replace(body, `{"type": "custom-container", "image": "example/api:1", "ports": [8080]}`, `{"ports":[8080],"image":"example/api:1","type":"custom-container"}`, 1)
+	if got := deployed(reordered); got != 2 {
+		t.Errorf("reformatted stored diagram: recorded revision %d, want 2", got)
+	}
+	edited := strings.Replace(body, `"example/api:1"`, `"example/api:2"`, 1)
+	if got := deployed(edited); got != 0 {
+		t.Errorf("edited diagram: recorded revision %d, want 0", got)
+	}
+	unsaved := strings.Replace(deployDiagramJSON, `"id": "d1"`, `"id": "d1", "revision": 99`, 1)
+	if got := deployed(unsaved); got != 0 {
+		t.Errorf("unsaved diagram: recorded revision %d, want 0", got)
+	}
+}
+
+func TestDeploy_InvalidJSON(t *testing.T) {
+	rec := doDeployRequest(setupDeployMux(t, docker.SimulationConfig{}), http.MethodPost, "/api/deploy", "{")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestDeploy_InvalidDiagram(t *testing.T) {
+	rec := doDeployRequest(setupDeployMux(t, docker.SimulationConfig{}), http.MethodPost, "/api/deploy", `{"name":""}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestDeploy_TranslationError(t *testing.T) {
+	cyclic := strings.Replace(deployDiagramJSON, `"edges": [`, `"edges": [{"id": "e2", "source": "db", "target": "api"},`, 1)
+	rec := doDeployRequest(setupDeployMux(t, docker.SimulationConfig{}), http.MethodPost, "/api/deploy", cyclic)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status: got %d, want %d (body %s)", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+	}
+}
+
+func TestDeploy_OrchestratorFailure(t *testing.T) {
+	mux := setupDeployMux(t, docker.SimulationConfig{
+		Failures: map[string]docker.SimFailure{"db": docker.SimFailCreate},
+	})
+	rec := doDeployRequest(mux, http.MethodPost, "/api/deploy", deployDiagramJSON)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+
+	var resp deployErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !strings.Contains(resp.Error, "deploy failed") {
+		t.Errorf("expected deploy failed error, got %q", resp.Error)
+	}
+	if !resp.Rollback.Complete || len(resp.Rollback.Steps) != 1 || resp.Rollback.Steps[0].Action != deploy.RollbackRemoveNetwork {
+		t.Errorf("expected the network to be rolled back, got %+v", resp.Rollback)
+	}
+}
+
+func TestDeploy_Reconfigure(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	mux := setupDeployMux(t, docker.SimulationConfig{})
+	withRate := func(rate string) string {
+		return strings.Replace(deployDiagramJSON, `"nodes": [`, `"nodes": [
+		{"id": "orders", "type": "api-service", "name": "Orders", "position": {"x": 0, "y": 0},
+		 "config": {"type": "api-service", "endpoints": [{"method": "GET", "path": "/orders", "errors": {"rate": `+rate+`}}]}},`, 1)
+	}
+
+	if rec := doDeployRequest(mux, http.MethodPatch, "/api/deploy", withRate("0.1")); rec.Code != http.StatusConflict {
+		t.Errorf("status with nothing deployed: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/deploy", withRate("0.1")); rec.Code != http.StatusOK {
+		t.Fatalf("deploy status: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	rec := doDeployRequest(mux, http.MethodPatch, "/api/deploy", withRate("0.5"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var result deploy.Reconfiguration
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(result.Updated) != 1 || result.Updated[0] != "orders" || result.Status.State != deploy.StateDeployed {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	imageChange := strings.Replace(withRate("0.5"), "example/api:1", "example/api:2", 1)
+	if rec := doDeployRequest(mux, http.MethodPatch, "/api/deploy", imageChange); rec.Code != http.StatusConflict {
+		t.Errorf("status for an image change: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := doDeployRequest(mux, http.MethodPatch, "/api/deploy", withRate("2")); rec.Code != http.StatusBadRequest {
+		t.Errorf("status for an invalid rate: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+// DeploymentHandler serves the deployment history.
+type DeploymentHandler struct {
+	store storage.DeploymentStore
+}
+
+// NewDeploymentHandler creates a DeploymentHandler backed by the given store.
+func NewDeploymentHandler(store storage.DeploymentStore) *DeploymentHandler {
+	return &DeploymentHandler{store: store}
+}
+
+// RegisterRoutes registers deployment history routes on the given mux.
+func (h *DeploymentHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/deployments", h.List)
+	mux.HandleFunc("GET /api/deployments/{id}", h.Get)
+}
+
+// List handles GET /api/deployments. Records are returned most recent first;
+// ?diagramId= restricts them to one diagram.
+func (h *DeploymentHandler) List(w http.ResponseWriter, r *http.Request) {
+	deployments, err := h.store.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list deployments")
+		return
+	}
+
+	if diagramID := r.URL.Query().Get("diagramId"); diagramID != "" {
+		filtered := []model.Deployment{}
+		for _, d := range deployments {
+			if d.DiagramID == diagramID {
+				filtered = append(filtered, d)
+			}
+		}
+		deployments = filtered
+	}
+
+	writeJSON(w, http.StatusOK, deployments)
+}
+
+// Get handles GET /api/deployments/{id}.
+func (h *DeploymentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	d, err := h.store.Get(r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrDeploymentNotFound):
+			writeError(w, http.StatusNotFound, "deployment not found")
+		case errors.Is(err, storage.ErrInvalidID):
+			writeError(w, http.StatusBadRequest, "invalid deployment ID")
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to retrieve deployment")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, d)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+func setupDeploymentTest(t *testing.T) (*http.ServeMux, *storage.FileDeploymentStore) {
+	t.Helper()
+	store, err := storage.NewFileDeploymentStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileDeploymentStore: %v", err)
+	}
+	mux := http.NewServeMux()
+	NewDeploymentHandler(store).RegisterRoutes(mux)
+	return mux, store
+}
+
+func doGet(mux *http.ServeMux, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDeployments_List(t *testing.T) {
+	mux, store := setupDeploymentTest(t)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, diagramID := range []string{"a", "b", "a"} {
+		if _, err := store.Create(&model.Deployment{DiagramID: diagramID, Status: model.DeploymentDeployed, StartedAt: base.Add(time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	rec := doGet(mux, "/api/deployments")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	var all []model.Deployment
+	if err := json.NewDecoder(rec.Body).Decode(&all); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(all) != 3 || !all[0].StartedAt.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("expected 3 deployments newest first, got %+v", all)
+	}
+
+	rec = doGet(mux, "/api/deployments?diagramId=a")
+	var filtered []model.Deployment
+	if err := json.NewDecoder(rec.Body).Decode(&filtered); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(filtered) != 2 {
+		t.Errorf("expected 2 deployments for diagram a, got %d", len(filtered))
+	}
+}
+
+func TestDeployments_ListEmpty(t *testing.T) {
+	mux, _ := setupDeploymentTest(t)
+	rec := doGet(mux, "/api/deployments")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	if body := rec.Body.String(); body != "[]\n" {
+		t.Errorf("expected empty array, got %q", body)
+	}
+}
+
+func TestDeployments_Get(t *testing.T) {
+	mux, store := setupDeploymentTest(t)
+	created, err := store.Create(&model.Deployment{DiagramID: "a", Status: model.DeploymentFailed, Error: "boom"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	rec := doGet(mux, "/api/deployments/"+created.ID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got model.Deployment
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.ID != created.ID || got.Error != "boom" {
+		t.Errorf("unexpected deployment %+v", got)
+	}
+}
+
+func TestDeployments_GetNotFound(t *testing.T) {
+	mux, _ := setupDeploymentTest(t)
+	rec := doGet(mux, "/api/deployments/missing")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/export"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+// Export content types and query values.
+const (
+	contentTypeYAML = "application/yaml"
+	contentTypeZip  = "application/zip"
+	formatParam     = "format"
+	formatYAML      = "yaml"
+)
+
+// ExportHandler serves diagram exports in third-party formats.
+type ExportHandler struct {
+	store storage.DiagramStore
+	opts  export.Options
+}
+
+// NewExportHandler creates an ExportHandler backed by the given store that
+// translates diagrams with opts.
+func NewExportHandler(store storage.DiagramStore, opts export.Options) *ExportHandler {
+	return &ExportHandler{store: store, opts: opts}
+}
+
+// RegisterRoutes registers export routes on the given mux.
+func (h *ExportHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/diagrams/{id}/export/compose", h.Compose)
+	mux.HandleFunc("GET /api/diagrams/{id}/export/kubernetes", h.Kubernetes)
+	mux.HandleFunc("GET /api/diagrams/{id}/export/terraform", h.Terraform)
+}
+
+// Compose handles GET /api/diagrams/{id}/export/compose. By default it returns
+// a zip holding docker-compose.yml and the generated artifacts it mounts;
+// ?format=yaml returns only the Compose file.
+func (h *ExportHandler) Compose(w http.ResponseWriter, r *http.Request) {
+	d, ok := loadDiagram(w, h.store, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	bundle, err := export.Compose(*d, h.opts)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "export failed: "+err.Error())
+		return
+	}
+
+	if r.URL.Query().Get(formatParam) == formatYAML {
+		writeAttachment(w, contentTypeYAML, export.ComposeFileName, bundle[export.ComposeFileName])
+		return
+	}
+	writeBundle(w, d.ID+"-compose.zip", bundle)
+}
+
+// Kubernetes handles GET /api/diagrams/{id}/export/kubernetes. Artifacts are
+// embedded in ConfigMaps, so the response is a single multi-document YAML file.
+func (h *ExportHandler) Kubernetes(w http.ResponseWriter, r *http.Request) {
+	d, ok := loadDiagram(w, h.store, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	bundle, err := export.Kubernetes(*d, h.opts)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "export failed: "+err.Error())
+		return
+	}
+
+	writeAttachment(w, contentTypeYAML, d.ID+"-"+export.KubernetesFileName, bundle[export.KubernetesFileName])
+}
+
+// Terraform handles GET /api/diagrams/{id}/export/terraform. The response is
+// a zip holding the Terraform module and the generated artifacts it uploads.
+func (h *ExportHandler) Terraform(w http.ResponseWriter, r *http.Request) {
+	d, ok := loadDiagram(w, h.store, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	bundle, err := export.Terraform(*d, h.opts)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "export failed: "+err.Error())
+		return
+	}
+
+	writeBundle(w, d.ID+"-terraform.zip", bundle)
+}
+
+// loadDiagram fetches a diagram and writes the matching error response if it
+// cannot be loaded.
+func loadDiagram(w http.ResponseWriter, store storage.DiagramStore, id string) (*model.Diagram, bool) {
+	d, err := store.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			writeError(w, http.StatusNotFound, "diagram not found")
+		case errors.Is(err, storage.ErrInvalidID):
+			writeError(w, http.StatusBadRequest, "invalid diagram ID")
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to retrieve diagram")
+		}
+		return nil, false
+	}
+	return d, true
+}
+
+// writeBundle writes an export bundle as a zip attachment.
+func writeBundle(w http.ResponseWriter, filename string, bundle export.Bundle) {
+	var buf bytes.Buffer
+	if err := bundle.WriteZip(&buf); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to write export bundle")
+		return
+	}
+	writeAttachment(w, contentTypeZip, filename, buf.Bytes())
+}
+
+// writeAttachment writes body as a downloadable file.
+func writeAttachment(w http.ResponseWriter, contentType, filename string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("failed to write export response: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/export"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+func setupExportTest(t *testing.T) (*http.ServeMux, *storage.FileStore) {
+	t.Helper()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	mux := http.NewServeMux()
+	NewExportHandler(store, export.Options{}).RegisterRoutes(mux)
+	return mux, store
+}
+
+func storeExportDiagram(t *testing.T, store storage.DiagramStore) string {
+	t.Helper()
+	d, err := store.Create(&model.Diagram{
+		Name: "Export Test",
+		Nodes: []model.DiagramNode{
+			{ID: "api", Type: model.ServiceTypeAPIService, Name: "My API", Position: &model.Position{}},
+			{ID: "db", Type: model.ServiceTypePostgreSQL, Name: "DB", Position: &model.Position{}},
+		},
+		Edges: []model.DiagramEdge{{ID: "e1", Source: "api", Target: "db"}},
+	})
+	if err != nil {
+		t.Fatalf("store.Create: %v", err)
+	}
+	return d.ID
+}
+
+func TestExportCompose_Zip(t *testing.T) {
+	mux, store := setupExportTest(t)
+	id := storeExportDiagram(t, store)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/diagrams/"+id+"/export/compose", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != contentTypeZip {
+		t.Errorf("Content-Type: got %q, want %q", ct, contentTypeZip)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, "attachment") {
+		t.Errorf("Content-Disposition: got %q, want attachment", cd)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	if !names[export.ComposeFileName] {
+		t.Errorf("expected %s in zip, got %v", export.ComposeFileName, names)
+	}
+	if !names["files/my-api/my-api.json"] {
+		t.Errorf("expected spec artifact in zip, got %v", names)
+	}
+}
+
+func TestExportCompose_YAML(t *testing.T) {
+	mux, store := setupExportTest(t)
+	id := storeExportDiagram(t, store)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/diagrams/"+id+"/export/compose?format=yaml", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != contentTypeYAML {
+		t.Errorf("Content-Type: got %q, want %q", ct, contentTypeYAML)
+	}
+	if !strings.Contains(rec.Body.String(), "condition: service_healthy") {
+		t.Errorf("expected healthy depends_on condition in compose file:\n%s", rec.Body.String())
+	}
+}
+
+func TestExportCompose_NotFound(t *testing.T) {
+	mux, _ := setupExportTest(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/diagrams/does-not-exist/export/compose", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestExportCompose_UntranslatableDiagram(t *testing.T) {
+	mux, store := setupExportTest(t)
+	d, err := store.Create(&model.Diagram{
+		Name: "Cycle",
+		Nodes: []model.DiagramNode{
+			{ID: "a", Type: model.ServiceTypeAPIService, Name: "A", Position: &model.Position{}},
+			{ID: "b", Type: model.ServiceTypeAPIService, Name: "B", Position: &model.Position{}},
+		},
+		Edges: []model.DiagramEdge{
+			{ID: "e1", Source: "a", Target: "b"},
+			{ID: "e2", Source: "b", Target: "a"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("store.Create: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/diagrams/"+d.ID+"/export/compose", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	var resp errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !strings.Contains(resp.Error, "cyclic") {
+		t.Errorf("expected cyclic dependency error, got %q", resp.Error)
+	}
+}
+
+func TestExportKubernetes(t *testing.T) {
+	mux, store := setupExportTest(t)
+	id := storeExportDiagram(t, store)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/diagrams/"+id+"/export/kubernetes", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != contentTypeYAML {
+		t.Errorf("Content-Type: got %q, want %q", ct, contentTypeYAML)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"kind: Namespace", "kind: StatefulSet", "kind: Deployment", "kind: ConfigMap"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in manifests:\n%s", want, body)
+		}
+	}
+}
+
+func TestExportKubernetes_NotFound(t *testing.T) {
+	mux, _ := setupExportTest(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/diagrams/does-not-exist/export/kubernetes", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestExportTerraform(t *testing.T) {
+	mux, store := setupExportTest(t)
+	id := storeExportDiagram(t, store)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/diagrams/"+id+"/export/terraform", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != contentTypeZip {
+		t.Errorf("Content-Type: got %q, want %q", ct, contentTypeZip)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	for _, want := range []string{export.TerraformMainFile, export.TerraformVariablesFile, export.TerraformVersionsFile, "files/my-api/my-api.json"} {
+		if !names[want] {
+			t.Errorf("expected %s in zip, got %v", want, names)
+		}
+	}
+}
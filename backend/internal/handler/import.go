@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/importer"
+)
+
+// maxImportBytes caps the size of an uploaded Compose file.
+const maxImportBytes = 1 << 20
+
+// ImportHandler converts third-party formats into diagrams.
+type ImportHandler struct {
+	// mockImage is the configured mock server image, recognised on import
+	// alongside the published one.
+	mockImage string
+}
+
+// NewImportHandler creates an ImportHandler. mockImage is the image
+// api-service nodes run, if not the published mock server; empty means none.
+func NewImportHandler(mockImage string) *ImportHandler {
+	return &ImportHandler{mockImage: mockImage}
+}
+
+// RegisterRoutes registers import routes on the given mux.
+func (h *ImportHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/diagrams/import/compose", h.Compose)
+}
+
+// Compose handles POST /api/diagrams/import/compose. The request body is the
+// raw Compose YAML; the optional ?name= query overrides the diagram name.
+// The diagram is returned, not persisted: clients save it with POST /api/diagrams.
+func (h *ImportHandler) Compose(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "compose file too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	result, err := importer.Compose(data, r.URL.Query().Get("name"), h.mockImage)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
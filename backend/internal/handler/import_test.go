@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/importer"
+)
+
+const importTestCompose = `
+name: from-file
+services:
+  db:
+    image: postgres:16
+  app:
+    image: example/app:1
+    ports: ["8080:80"]
+    depends_on: [db]
+`
+
+func doImport(t *testing.T, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	NewImportHandler("").RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestImportCompose_Success(t *testing.T) {
+	rec := doImport(t, "/api/diagrams/import/compose", importTestCompose)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var result importer.Result
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if result.Diagram.Name != "from-file" {
+		t.Errorf("name: got %q, want %q", result.Diagram.Name, "from-file")
+	}
+	if len(result.Diagram.Nodes) != 2 {
+		t.Errorf("nodes: got %d, want 2", len(result.Diagram.Nodes))
+	}
+	if len(result.Diagram.Edges) != 1 {
+		t.Errorf("edges: got %d, want 1", len(result.Diagram.Edges))
+	}
+}
+
+func TestImportCompose_NameOverride(t *testing.T) {
+	rec := doImport(t, "/api/diagrams/import/compose?name=Override", importTestCompose)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	var result importer.Result
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if result.Diagram.Name != "Override" {
+		t.Errorf("name: got %q, want %q", result.Diagram.Name, "Override")
+	}
+}
+
+func TestImportCompose_InvalidYAML(t *testing.T) {
+	rec := doImport(t, "/api/diagrams/import/compose", "services: [")
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestImportCompose_NoServices(t *testing.T) {
+	rec := doImport(t, "/api/diagrams/import/compose", "version: '3'\n")
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestImportCompose_TooLarge(t *testing.T) {
+	rec := doImport(t, "/api/diagrams/import/compose", strings.Repeat("#", maxImportBytes+1))
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
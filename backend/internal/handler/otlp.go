@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/otlp"
+	"github.com/stwalsh4118/hephaestus/backend/internal/tracing"
+)
+
+// maxOTLPBodyBytes bounds an OTLP export request.
+const maxOTLPBodyBytes = 8 << 20
+
+// OTLPHandler receives spans over OTLP/HTTP with the JSON encoding.
+type OTLPHandler struct {
+	store   *tracing.Store
+	manager *deploy.Manager
+}
+
+// NewOTLPHandler creates an OTLPHandler filing spans in store, using the
+// deployment of manager for spans that do not name theirs.
+func NewOTLPHandler(store *tracing.Store, manager *deploy.Manager) *OTLPHandler {
+	return &OTLPHandler{store: store, manager: manager}
+}
+
+// RegisterRoutes registers the OTLP trace export route on the given mux.
+func (h *OTLPHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST "+otlp.TracesPath, h.Export)
+}
+
+// Export handles POST /v1/traces. Spans that cannot be filed are reported
+// as rejected in a partial success, as OTLP specifies.
+func (h *OTLPHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != contentTypeJSON {
+		writeError(w, http.StatusUnsupportedMediaType, "only the OTLP JSON encoding (application/json) is supported")
+		return
+	}
+
+	var req otlp.ExportTraceServiceRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxOTLPBodyBytes)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid OTLP request: "+err.Error())
+		return
+	}
+
+	resp := otlp.ExportTraceServiceResponse{}
+	if rejected := h.store.Ingest(req, h.manager.Status()); rejected > 0 {
+		resp.PartialSuccess = &otlp.PartialSuccess{
+			RejectedSpans: otlp.Int64(rejected),
+			ErrorMessage:  "spans without a deployment, with malformed IDs or beyond the trace size limit were dropped",
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/otlp"
+	"github.com/stwalsh4118/hephaestus/backend/internal/tracing"
+)
+
+// exportJSON is an OTLP export of one server span of the gateway of the
+// diagram deployed by deployDiagram, and one span with a malformed ID.
+const exportJSON = `{"resourceSpans":[{"resource":{"attributes":[{"key":"heph.node_id","value":{"stringValue":"gw"}}]},
+"scopeSpans":[{"spans":[
+{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736","spanId":"00f067aa0ba902b7","name":"GET /","kind":2,
+ "startTimeUnixNano":"1767225600000000000","endTimeUnixNano":"1767225600025000000","status":{"code":2,"message":"boom"}},
+{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736","spanId":"nope","name":"bad","kind":1,
+ "startTimeUnixNano":1767225600000000000,"endTimeUnixNano":1767225600000000000}
+]}]}]}`
+
+func setupOTLPTest() (*http.ServeMux, *tracing.Store, *deploy.Manager) {
+	store := tracing.NewStore()
+	manager := deploy.NewManager(docker.NewSimulatedOrchestrator(docker.SimulationConfig{}), nil, docker.ModeSimulated, nil)
+	mux := http.NewServeMux()
+	NewOTLPHandler(store, manager).RegisterRoutes(mux)
+	NewTracesHandler(store, manager).RegisterRoutes(mux)
+	return mux, store, manager
+}
+
+func postExport(mux *http.ServeMux, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, otlp.TracesPath, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOTLP_Export(t *testing.T) {
+	mux, store, manager := setupOTLPTest()
+	deployDiagram(t, manager, nil)
+
+	rec := postExport(mux, "application/json", exportJSON)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp otlp.ExportTraceServiceResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.PartialSuccess == nil || resp.PartialSuccess.RejectedSpans != 1 {
+		t.Errorf("expected 1 rejected span, got %+v", resp.PartialSuccess)
+	}
+
+	w, err := store.Trace("d1", "4bf92f3577b34da6a3ce929d0e0e4736")
+	if err != nil {
+		t.Fatalf("Trace: %v", err)
+	}
+	if sp := w.Spans[0]; len(w.Spans) != 1 || sp.NodeID != "gw" || !sp.Error || sp.Message != "boom" || sp.DurationMs != 25 {
+		t.Errorf("unexpected spans %+v", w.Spans)
+	}
+}
+
+func TestOTLP_ExportErrors(t *testing.T) {
+	mux, _, _ := setupOTLPTest()
+
+	if rec := postExport(mux, "application/x-protobuf", "\x0a\x00"); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("protobuf: got %d, want %d", rec.Code, http.StatusUnsupportedMediaType)
+	}
+	if rec := postExport(mux, "application/json", "{"); rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	big := `{"resourceSpans":[],"pad":"` + strings.Repeat("x", maxOTLPBodyBytes) + `"}`
+	if rec := postExport(mux, "application/json", big); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("too large: got %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	// Nothing is deployed, so spans without a deployment are rejected.
+	rec := postExport(mux, "application/json; charset=utf-8", exportJSON)
+	var resp otlp.ExportTraceServiceResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("got %d (%v)", rec.Code, err)
+	}
+	if resp.PartialSuccess == nil || resp.PartialSuccess.RejectedSpans != 2 {
+		t.Errorf("expected 2 rejected spans, got %+v", resp.PartialSuccess)
+	}
+}
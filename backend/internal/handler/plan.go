@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+// PlanHandler serves deployment plans for stored diagrams.
+type PlanHandler struct {
+	store   storage.DiagramStore
+	manager *deploy.Manager
+}
+
+// NewPlanHandler creates a PlanHandler that plans stored diagrams against the
+// manager's running deployment.
+func NewPlanHandler(store storage.DiagramStore, manager *deploy.Manager) *PlanHandler {
+	return &PlanHandler{store: store, manager: manager}
+}
+
+// RegisterRoutes registers plan routes on the given mux.
+func (h *PlanHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/diagrams/{id}/plan", h.Plan)
+}
+
+// Plan handles POST /api/diagrams/{id}/plan. It is a dry run: nothing is
+// created, started or removed.
+func (h *PlanHandler) Plan(w http.ResponseWriter, r *http.Request) {
+	d, ok := loadDiagram(w, h.store, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	plan, err := h.manager.Plan(*d)
+	if err != nil {
+		if errors.Is(err, deploy.ErrTranslation) {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "plan failed: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, plan)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+func setupPlanTest(t *testing.T) (*http.ServeMux, *storage.FileStore) {
+	t.Helper()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	manager := deploy.NewManager(docker.NewSimulatedOrchestrator(docker.SimulationConfig{}), nil, docker.ModeSimulated, nil)
+	mux := http.NewServeMux()
+	NewPlanHandler(store, manager).RegisterRoutes(mux)
+	return mux, store
+}
+
+func doPlan(mux *http.ServeMux, id string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/diagrams/"+id+"/plan", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPlan_Success(t *testing.T) {
+	mux, store := setupPlanTest(t)
+	id := storeExportDiagram(t, store)
+
+	rec := doPlan(mux, id)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d; body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var plan deploy.Plan
+	if err := json.NewDecoder(rec.Body).Decode(&plan); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if plan.DiagramID != id {
+		t.Errorf("diagramId: got %q, want %q", plan.DiagramID, id)
+	}
+	if len(plan.Nodes) != 2 || len(plan.Levels) != 2 || len(plan.Changes) != 2 {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+	if len(plan.Nodes) == 2 && len(plan.Nodes[1].Files) != 1 {
+		t.Errorf("expected the API spec in files, got %+v", plan.Nodes[1].Files)
+	}
+}
+
+func TestPlan_NotFound(t *testing.T) {
+	mux, _ := setupPlanTest(t)
+	rec := doPlan(mux, "missing")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestPlan_TranslationError(t *testing.T) {
+	mux, store := setupPlanTest(t)
+	d, err := store.Create(&model.Diagram{
+		Name: "Cycle",
+		Nodes: []model.DiagramNode{
+			{ID: "a", Type: model.ServiceTypeRedis, Name: "A", Position: &model.Position{}},
+			{ID: "b", Type: model.ServiceTypeRedis, Name: "B", Position: &model.Position{}},
+		},
+		Edges: []model.DiagramEdge{
+			{ID: "e1", Source: "a", Target: "b"},
+			{ID: "e2", Source: "b", Target: "a"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("store.Create: %v", err)
+	}
+
+	rec := doPlan(mux, d.ID)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
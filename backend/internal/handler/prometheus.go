@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+)
+
+// prometheusQueryTimeout bounds a proxied query, including reading its
+// result.
+const prometheusQueryTimeout = 30 * time.Second
+
+// prometheusParams are the query parameters passed on to Prometheus.
+var prometheusParams = []string{"query", "time", "start", "end", "step", "timeout"}
+
+// PrometheusHandler proxies PromQL queries to the Prometheus server of the
+// current deployment.
+type PrometheusHandler struct {
+	manager *deploy.Manager
+	client  *http.Client
+}
+
+// NewPrometheusHandler creates a PrometheusHandler for the deployments of
+// manager.
+func NewPrometheusHandler(manager *deploy.Manager) *PrometheusHandler {
+	return &PrometheusHandler{
+		manager: manager,
+		client:  &http.Client{Timeout: prometheusQueryTimeout},
+	}
+}
+
+// RegisterRoutes registers the metrics query route on the given mux.
+func (h *PrometheusHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/metrics", h.Query)
+}
+
+// Query handles GET /api/metrics. It runs the PromQL expression in the
+// query parameter against the deployment's Prometheus: an instant query,
+// or a range query when start is given. Prometheus's response, errors
+// included, is returned as is.
+func (h *PrometheusHandler) Query(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	if params.Get("query") == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	status := h.manager.Status()
+	if status.State != deploy.StateDeployed {
+		writeError(w, http.StatusConflict, "no deployment is running")
+		return
+	}
+	base, ok := prometheusURL(status)
+	if !ok {
+		writeError(w, http.StatusConflict, "the deployment has no Prometheus; enable monitoring.prometheus on the diagram and redeploy")
+		return
+	}
+
+	path := "/api/v1/query"
+	if params.Get("start") != "" {
+		path = "/api/v1/query_range"
+	}
+	forward := url.Values{}
+	for _, p := range prometheusParams {
+		if v := params.Get(p); v != "" {
+			forward.Set(p, v)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, base+path+"?"+forward.Encode(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "build prometheus request: "+err.Error())
+		return
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "query prometheus: "+err.Error())
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.WriteHeader(resp.StatusCode)
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		log.Printf("failed to copy prometheus response: %v", err)
+	}
+}
+
+// prometheusURL returns the base URL of the deployment's Prometheus: its
+// port published on the host, or its address on the shared network when
+// it is not published.
+func prometheusURL(status deploy.Status) (string, bool) {
+	for _, n := range status.Nodes {
+		if n.NodeID != templates.PrometheusNodeID || n.ContainerID == "" {
+			continue
+		}
+		var published []string
+		for host, ctr := range n.Ports {
+			if ctr == templates.PortPrometheus {
+				published = append(published, host)
+			}
+		}
+		if len(published) == 0 {
+			return "http://" + net.JoinHostPort(n.Name, templates.PortPrometheus), true
+		}
+		sort.Strings(published)
+		return "http://" + net.JoinHostPort("127.0.0.1", published[0]), true
+	}
+	return "", false
+}
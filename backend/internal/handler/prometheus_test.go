@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// redirectTransport sends every request to target, keeping its path and
+// query, and records the URL it was meant for.
+type redirectTransport struct {
+	target    *url.URL
+	requested []*url.URL
+}
+
+func (rt *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requested = append(rt.requested, req.URL)
+	out := req.Clone(req.Context())
+	out.URL.Scheme = rt.target.Scheme
+	out.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(out)
+}
+
+func setupPrometheusTest(t *testing.T) (*http.ServeMux, *deploy.Manager, *redirectTransport) {
+	t.Helper()
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") == "bad(" {
+			w.Header().Set("Content-Type", contentTypeJSON)
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data"}`))
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeJSON)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	t.Cleanup(prom.Close)
+	target, _ := url.Parse(prom.URL)
+
+	manager := deploy.NewManager(docker.NewSimulatedOrchestrator(docker.SimulationConfig{}), nil, docker.ModeSimulated, nil)
+	h := NewPrometheusHandler(manager)
+	transport := &redirectTransport{target: target}
+	h.client.Transport = transport
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return mux, manager, transport
+}
+
+func deployDiagram(t *testing.T, manager *deploy.Manager, monitoring *model.Monitoring) {
+	t.Helper()
+	d := model.Diagram{
+		ID:         "d1",
+		Name:       "Monitored",
+		Nodes:      []model.DiagramNode{{ID: "gw", Type: model.ServiceTypeNginx, Name: "Gateway", Position: &model.Position{}}},
+		Edges:      []model.DiagramEdge{},
+		Monitoring: monitoring,
+	}
+	if _, err := manager.Deploy(context.Background(), d); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+}
+
+func TestPrometheus_Query(t *testing.T) {
+	mux, manager, transport := setupPrometheusTest(t)
+
+	if rec := doGet(mux, "/api/metrics"); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing query: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := doGet(mux, "/api/metrics?query=up"); rec.Code != http.StatusConflict {
+		t.Errorf("no deployment: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+	deployDiagram(t, manager, nil)
+	if rec := doGet(mux, "/api/metrics?query=up"); rec.Code != http.StatusConflict {
+		t.Errorf("deployment without prometheus: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	deployDiagram(t, manager, &model.Monitoring{Prometheus: true})
+	rec := doGet(mux, "/api/metrics?query=up&time=100&ignored=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec.Body.String() != `{"status":"success","data":{"resultType":"vector","result":[]}}` {
+		t.Errorf("expected the prometheus response as is, got %s", rec.Body.String())
+	}
+
+	rec = doGet(mux, "/api/metrics?query=rate(x[1m])&start=1&end=2&step=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("range status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := doGet(mux, "/api/metrics?query=bad("); rec.Code != http.StatusBadRequest {
+		t.Errorf("prometheus error: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	if len(transport.requested) != 3 {
+		t.Fatalf("expected 3 proxied queries, got %v", transport.requested)
+	}
+	instant, rng := transport.requested[0], transport.requested[1]
+	if instant.Host == "" || instant.Hostname() != "127.0.0.1" {
+		t.Errorf("expected the published prometheus port, got %s", instant)
+	}
+	if instant.Path != "/api/v1/query" || instant.RawQuery != "query=up&time=100" {
+		t.Errorf("unexpected instant query %s", instant)
+	}
+	if rng.Path != "/api/v1/query_range" || rng.Query().Get("step") != "1" {
+		t.Errorf("unexpected range query %s", rng)
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+)
+
+// SystemHandler serves information about the backend itself.
+type SystemHandler struct {
+	manager *deploy.Manager
+}
+
+// NewSystemHandler creates a SystemHandler backed by the given manager.
+func NewSystemHandler(manager *deploy.Manager) *SystemHandler {
+	return &SystemHandler{manager: manager}
+}
+
+// RegisterRoutes registers system routes on the given mux.
+func (h *SystemHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/system/orphans", h.Orphans)
+}
+
+// Orphans handles GET /api/system/orphans, the report of the startup sweep
+// for resources left behind by an earlier run.
+func (h *SystemHandler) Orphans(w http.ResponseWriter, _ *http.Request) {
+	report, ok := h.manager.LastSweep()
+	if !ok {
+		writeError(w, http.StatusNotFound, "no orphan sweep has run")
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+)
+
+func TestSystem_OrphansBeforeSweep(t *testing.T) {
+	manager := deploy.NewManager(docker.NewSimulatedOrchestrator(docker.SimulationConfig{}), nil, docker.ModeSimulated, nil)
+	mux := http.NewServeMux()
+	NewSystemHandler(manager).RegisterRoutes(mux)
+
+	rec := doGet(mux, "/api/system/orphans")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestSystem_Orphans(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	sim := docker.NewSimulatedOrchestrator(docker.SimulationConfig{})
+	if err := sim.CreateNetwork(context.Background()); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	manager := deploy.NewManager(sim, nil, docker.ModeSimulated, nil)
+	if _, err := manager.Sweep(context.Background(), deploy.OrphanRemove); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	mux := http.NewServeMux()
+	NewSystemHandler(manager).RegisterRoutes(mux)
+
+	rec := doGet(mux, "/api/system/orphans")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	var report deploy.SweepReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.Policy != deploy.OrphanRemove || len(report.Resources) != 1 || report.Resources[0].Kind != deploy.OrphanKindNetwork {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
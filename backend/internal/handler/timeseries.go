@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/tsdb"
+)
+
+// Defaults of a time-series query.
+const (
+	defaultTimeSeriesRange  = 15 * time.Minute
+	defaultTimeSeriesPoints = 250
+)
+
+// TimeSeriesResponse is the result of a time-series query.
+type TimeSeriesResponse struct {
+	DeploymentID string           `json:"deploymentId"`
+	Metric       string           `json:"metric"`
+	Aggregation  tsdb.Aggregation `json:"aggregation"`
+	Start        time.Time        `json:"start"`
+	End          time.Time        `json:"end"`
+	// Step is in seconds.
+	Step   float64       `json:"step"`
+	Series []tsdb.Series `json:"series"`
+}
+
+// TimeSeriesListResponse lists the series stored for a deployment.
+type TimeSeriesListResponse struct {
+	DeploymentID string     `json:"deploymentId"`
+	Series       []tsdb.Key `json:"series"`
+}
+
+// TimeSeriesHandler serves the metrics recorded in the embedded
+// time-series store.
+type TimeSeriesHandler struct {
+	store   *tsdb.Store
+	manager *deploy.Manager
+	now     func() time.Time
+}
+
+// NewTimeSeriesHandler creates a TimeSeriesHandler reading store, which
+// defaults queries to the current deployment of manager.
+func NewTimeSeriesHandler(store *tsdb.Store, manager *deploy.Manager) *TimeSeriesHandler {
+	return &TimeSeriesHandler{store: store, manager: manager, now: time.Now}
+}
+
+// RegisterRoutes registers the time-series routes on the given mux.
+func (h *TimeSeriesHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/timeseries", h.Query)
+	mux.HandleFunc("GET /api/timeseries/series", h.List)
+}
+
+// Query handles GET /api/timeseries. It returns the series of metric,
+// optionally limited to node, between start and end, aggregated every
+// step with agg.
+func (h *TimeSeriesHandler) Query(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	id, ok := deploymentParam(w, r, h.manager)
+	if !ok {
+		return
+	}
+
+	q := tsdb.Query{
+		Deployment:  id,
+		Node:        params.Get("node"),
+		Metric:      params.Get("metric"),
+		End:         h.now(),
+		Aggregation: tsdb.AggAvg,
+	}
+	if v := params.Get("end"); v != "" {
+		end, err := parseTime(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid end: "+err.Error())
+			return
+		}
+		q.End = end
+	}
+	q.Start = q.End.Add(-defaultTimeSeriesRange)
+	if v := params.Get("start"); v != "" {
+		start, err := parseTime(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid start: "+err.Error())
+			return
+		}
+		q.Start = start
+	}
+	q.Step = max((q.End.Sub(q.Start) / defaultTimeSeriesPoints).Truncate(time.Second), time.Second)
+	if v := params.Get("step"); v != "" {
+		step, err := parseStep(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid step: "+err.Error())
+			return
+		}
+		q.Step = step
+	}
+	if v := params.Get("agg"); v != "" {
+		q.Aggregation = tsdb.Aggregation(v)
+	}
+
+	series, err := h.store.Query(q)
+	if err != nil {
+		if errors.Is(err, tsdb.ErrInvalid) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "query time series: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, TimeSeriesResponse{
+		DeploymentID: id,
+		Metric:       q.Metric,
+		Aggregation:  q.Aggregation,
+		Start:        q.Start,
+		End:          q.End,
+		Step:         q.Step.Seconds(),
+		Series:       series,
+	})
+}
+
+// List handles GET /api/timeseries/series, listing the series of a
+// deployment.
+func (h *TimeSeriesHandler) List(w http.ResponseWriter, r *http.Request) {
+	id, ok := deploymentParam(w, r, h.manager)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, TimeSeriesListResponse{DeploymentID: id, Series: h.store.Keys(id)})
+}
+
+// deploymentParam returns the deployment query parameter, defaulting to
+// the current deployment of manager. It writes an error response when
+// there is neither.
+func deploymentParam(w http.ResponseWriter, r *http.Request, manager *deploy.Manager) (string, bool) {
+	if id := r.URL.Query().Get("deployment"); id != "" {
+		return id, true
+	}
+	status := manager.Status()
+	if status.State == deploy.StateIdle {
+		writeError(w, http.StatusConflict, "no deployment is running; pass deployment to query a past one")
+		return "", false
+	}
+	return tsdb.DeploymentID(status), true
+}
+
+// parseTime parses an RFC 3339 time or Unix seconds, as Prometheus accepts.
+func parseTime(v string) (time.Time, error) {
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		whole, frac := math.Modf(secs)
+		return time.Unix(int64(whole), int64(frac*1e9)).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither RFC 3339 nor Unix seconds", v)
+	}
+	return t, nil
+}
+
+// parseStep parses a duration such as 30s, or a number of seconds.
+func parseStep(v string) (time.Duration, error) {
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%q is neither a duration nor seconds", v)
+	}
+	return d, nil
+}
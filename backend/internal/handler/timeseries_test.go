@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/tsdb"
+)
+
+func setupTimeSeriesTest(t *testing.T) (*http.ServeMux, *tsdb.Store, *deploy.Manager, time.Time) {
+	t.Helper()
+	store := tsdb.NewStore()
+	manager := deploy.NewManager(docker.NewSimulatedOrchestrator(docker.SimulationConfig{}), nil, docker.ModeSimulated, nil)
+	h := NewTimeSeriesHandler(store, manager)
+	now := time.Date(2026, 1, 1, 0, 10, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return mux, store, manager, now
+}
+
+func TestTimeSeries_Query(t *testing.T) {
+	mux, store, manager, now := setupTimeSeriesTest(t)
+	for i := range 60 {
+		at := now.Add(-time.Duration(60-i) * 5 * time.Second)
+		store.Append(tsdb.Key{Deployment: "old", Node: "api", Metric: tsdb.MetricCPUPercent}, at, float64(i))
+		store.Append(tsdb.Key{Deployment: "d1", Node: "gw", Metric: tsdb.MetricCPUPercent}, at, 1)
+	}
+
+	if rec := doGet(mux, "/api/timeseries?metric=cpu_percent"); rec.Code != http.StatusConflict {
+		t.Errorf("no deployment: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	rec := doGet(mux, "/api/timeseries?deployment=old&metric=cpu_percent&start=1767225300&step=1m&agg=max")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp TimeSeriesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.DeploymentID != "old" || resp.Step != 60 || !resp.End.Equal(now) || len(resp.Series) != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	points := resp.Series[0].Points
+	if len(points) != 6 || points[len(points)-1].V != 59 || resp.Series[0].Node != "api" {
+		t.Errorf("expected 6 per-minute maxima ending at 59, got %+v", resp.Series[0])
+	}
+
+	for _, target := range []string{
+		"/api/timeseries?deployment=old",
+		"/api/timeseries?deployment=old&metric=cpu_percent&agg=median",
+		"/api/timeseries?deployment=old&metric=cpu_percent&start=yesterday",
+		"/api/timeseries?deployment=old&metric=cpu_percent&step=0",
+		"/api/timeseries?deployment=old&metric=cpu_percent&start=2026-01-01T00:00:00Z&step=1ms",
+	} {
+		if rec := doGet(mux, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	deployDiagram(t, manager, nil)
+	rec = doGet(mux, "/api/timeseries?metric=cpu_percent&node=gw")
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("current deployment: got %d (%v)", rec.Code, err)
+	}
+	if resp.DeploymentID != "d1" || len(resp.Series) != 1 || len(resp.Series[0].Points) == 0 {
+		t.Errorf("expected the current deployment's series, got %+v", resp)
+	}
+}
+
+func TestTimeSeries_List(t *testing.T) {
+	mux, store, _, now := setupTimeSeriesTest(t)
+	store.Append(tsdb.Key{Deployment: "d1", Node: "gw", Metric: tsdb.MetricMemoryBytes}, now, 1)
+	store.Append(tsdb.Key{Deployment: "d1", Node: "api", Metric: tsdb.MetricCPUPercent}, now, 1)
+
+	rec := doGet(mux, "/api/timeseries/series?deployment=d1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp TimeSeriesListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Series) != 2 || resp.Series[0].Node != "api" || resp.Series[1].Metric != tsdb.MetricMemoryBytes {
+		t.Errorf("unexpected series %+v", resp.Series)
+	}
+
+	rec = doGet(mux, "/api/timeseries/series?deployment=unknown")
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"deploymentId":"unknown","series":[]}`+"\n" {
+		t.Errorf("expected an empty list, got %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/tracing"
+)
+
+// maxTraceLimit bounds the limit parameter of a trace listing.
+const maxTraceLimit = tracing.DefaultMaxTraces
+
+// TraceListResponse lists the traces of a deployment.
+type TraceListResponse struct {
+	DeploymentID string                 `json:"deploymentId"`
+	Traces       []tracing.TraceSummary `json:"traces"`
+}
+
+// EdgeListResponse lists the per-edge latency of a deployment.
+type EdgeListResponse struct {
+	DeploymentID string              `json:"deploymentId"`
+	Edges        []tracing.EdgeStats `json:"edges"`
+}
+
+// TracesHandler serves the traces received from deployed services.
+type TracesHandler struct {
+	store   *tracing.Store
+	manager *deploy.Manager
+}
+
+// NewTracesHandler creates a TracesHandler reading store, which defaults
+// queries to the current deployment of manager.
+func NewTracesHandler(store *tracing.Store, manager *deploy.Manager) *TracesHandler {
+	return &TracesHandler{store: store, manager: manager}
+}
+
+// RegisterRoutes registers the trace routes on the given mux.
+func (h *TracesHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/traces", h.List)
+	mux.HandleFunc("GET /api/traces/edges", h.Edges)
+	mux.HandleFunc("GET /api/traces/{traceId}", h.Get)
+}
+
+// List handles GET /api/traces, returning the most recent traces,
+// optionally only those through node, lasting at least minDuration or
+// with errors.
+func (h *TracesHandler) List(w http.ResponseWriter, r *http.Request) {
+	id, ok := deploymentParam(w, r, h.manager)
+	if !ok {
+		return
+	}
+	params := r.URL.Query()
+	f := tracing.TraceFilter{Node: params.Get("node"), ErrorsOnly: params.Get("errors") == "true"}
+	if v := params.Get("minDuration"); v != "" {
+		d, err := parseStep(v)
+		if err != nil || d < 0 {
+			writeError(w, http.StatusBadRequest, "minDuration must be a duration such as 250ms")
+			return
+		}
+		f.MinDuration = d
+	}
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxTraceLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxTraceLimit))
+			return
+		}
+		f.Limit = n
+	}
+	writeJSON(w, http.StatusOK, TraceListResponse{DeploymentID: id, Traces: h.store.Traces(id, f)})
+}
+
+// Get handles GET /api/traces/{traceId}, returning the trace's waterfall.
+func (h *TracesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := deploymentParam(w, r, h.manager)
+	if !ok {
+		return
+	}
+	waterfall, err := h.store.Trace(id, r.PathValue("traceId"))
+	if err != nil {
+		if errors.Is(err, tracing.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "trace not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "get trace: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, waterfall)
+}
+
+// Edges handles GET /api/traces/edges, aggregating call latency per edge
+// over the stored traces, or those since the since parameter.
+func (h *TracesHandler) Edges(w http.ResponseWriter, r *http.Request) {
+	id, ok := deploymentParam(w, r, h.manager)
+	if !ok {
+		return
+	}
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since: "+err.Error())
+			return
+		}
+		since = t
+	}
+	writeJSON(w, http.StatusOK, EdgeListResponse{DeploymentID: id, Edges: h.store.Edges(id, since)})
+}
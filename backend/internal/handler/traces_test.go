@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/tracing"
+)
+
+func TestTraces_ListAndGet(t *testing.T) {
+	mux, store, manager := setupOTLPTest()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.Add("old", []tracing.Span{
+		{TraceID: "t1", SpanID: "a", Name: "GET /", Kind: "server", NodeID: "gw", Start: start, End: start.Add(40 * time.Millisecond), DurationMs: 40},
+		{TraceID: "t1", SpanID: "b", ParentSpanID: "a", Name: "api", Kind: "client", NodeID: "gw", TargetNodeID: "api", Start: start.Add(5 * time.Millisecond), End: start.Add(35 * time.Millisecond), DurationMs: 30, Error: true},
+		{TraceID: "t2", SpanID: "a", Name: "GET /health", Kind: "server", NodeID: "gw", Start: start.Add(time.Second), End: start.Add(time.Second + time.Millisecond), DurationMs: 1},
+	})
+
+	if rec := doGet(mux, "/api/traces"); rec.Code != http.StatusConflict {
+		t.Errorf("no deployment: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	rec := doGet(mux, "/api/traces?deployment=old&minDuration=10ms")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var list TraceListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list.Traces) != 1 || list.Traces[0].TraceID != "t1" || list.Traces[0].Errors != 1 || list.Traces[0].Root != "GET /" {
+		t.Errorf("unexpected listing %+v", list)
+	}
+	if err := json.Unmarshal(doGet(mux, "/api/traces?deployment=old&limit=1").Body.Bytes(), &list); err != nil || len(list.Traces) != 1 || list.Traces[0].TraceID != "t2" {
+		t.Errorf("expected the newest trace, got %+v (%v)", list, err)
+	}
+	for _, target := range []string{
+		"/api/traces?deployment=old&limit=0",
+		"/api/traces?deployment=old&limit=5000",
+		"/api/traces?deployment=old&minDuration=slow",
+	} {
+		if rec := doGet(mux, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	rec = doGet(mux, "/api/traces/t1?deployment=old")
+	var w tracing.Waterfall
+	if err := json.Unmarshal(rec.Body.Bytes(), &w); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("get: got %d (%v)", rec.Code, err)
+	}
+	if len(w.Spans) != 2 || w.Spans[1].Depth != 1 || w.Spans[1].OffsetMs != 5 || w.DurationMs != 40 {
+		t.Errorf("unexpected waterfall %+v", w)
+	}
+	if rec := doGet(mux, "/api/traces/missing?deployment=old"); rec.Code != http.StatusNotFound {
+		t.Errorf("missing: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	deployDiagram(t, manager, nil)
+	if err := json.Unmarshal(doGet(mux, "/api/traces").Body.Bytes(), &list); err != nil || list.DeploymentID != "d1" || len(list.Traces) != 0 {
+		t.Errorf("expected the current deployment's empty listing, got %+v (%v)", list, err)
+	}
+}
+
+func TestTraces_Edges(t *testing.T) {
+	mux, store, _ := setupOTLPTest()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, d := range []float64{10, 30} {
+		at := start.Add(time.Duration(i) * time.Minute)
+		store.Add("old", []tracing.Span{{TraceID: "t", SpanID: string(rune('a' + i)), Kind: "client", NodeID: "gw", TargetNodeID: "api", Start: at, End: at, DurationMs: d}})
+	}
+
+	var resp EdgeListResponse
+	if err := json.Unmarshal(doGet(mux, "/api/traces/edges?deployment=old").Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Edges) != 1 || resp.Edges[0].Calls != 2 || resp.Edges[0].Latency.Avg != 20 {
+		t.Errorf("unexpected edges %+v", resp.Edges)
+	}
+	if err := json.Unmarshal(doGet(mux, "/api/traces/edges?deployment=old&since=2026-01-01T00:00:30Z").Body.Bytes(), &resp); err != nil || len(resp.Edges) != 1 || resp.Edges[0].Calls != 1 {
+		t.Errorf("expected one call since the cut-off, got %+v (%v)", resp.Edges, err)
+	}
+	if rec := doGet(mux, "/api/traces/edges?deployment=old&since=soon"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid since: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
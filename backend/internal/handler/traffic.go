@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+	"github.com/stwalsh4118/hephaestus/backend/internal/traffic"
+)
+
+// trafficStartRequest is the optional body of POST /api/traffic/start.
+// It names a load profile or gives ad hoc options, whose zero values fall
+// back to the traffic defaults. An empty body runs the active profile.
+type trafficStartRequest struct {
+	ProfileID       string `json:"profileId"`
+	DurationSeconds int    `json:"durationSeconds"`
+	VUs             int    `json:"vus"`
+	Rate            int    `json:"rate"`
+	ThinkTimeMs     int    `json:"thinkTimeMs"`
+}
+
+// adHoc reports whether the request sets any ad hoc option.
+func (r trafficStartRequest) adHoc() bool {
+	return r.DurationSeconds != 0 || r.VUs != 0 || r.Rate != 0 || r.ThinkTimeMs != 0
+}
+
+// trafficConfigRequest is the body of PUT /api/traffic/config.
+type trafficConfigRequest struct {
+	ProfileID string `json:"profileId"`
+}
+
+// TrafficHandler starts and stops load against the running deployment and
+// manages the load profiles it runs.
+type TrafficHandler struct {
+	store      storage.DiagramStore
+	manager    *deploy.Manager
+	controller *traffic.Controller
+	profiles   *traffic.Profiles
+}
+
+// NewTrafficHandler creates a TrafficHandler that sends traffic to the
+// manager's deployment. store is read for the diagram of a deployment the
+// manager adopted from a previous run.
+func NewTrafficHandler(store storage.DiagramStore, manager *deploy.Manager, controller *traffic.Controller, profiles *traffic.Profiles) *TrafficHandler {
+	return &TrafficHandler{store: store, manager: manager, controller: controller, profiles: profiles}
+}
+
+// RegisterRoutes registers traffic routes on the given mux.
+func (h *TrafficHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/traffic/start", h.Start)
+	mux.HandleFunc("POST /api/traffic/stop", h.Stop)
+	mux.HandleFunc("GET /api/traffic/status", h.Status)
+	mux.HandleFunc("GET /api/traffic/config", h.GetConfig)
+	mux.HandleFunc("PUT /api/traffic/config", h.UpdateConfig)
+	mux.HandleFunc("GET /api/traffic/profiles", h.ListProfiles)
+	mux.HandleFunc("POST /api/traffic/profiles", h.CreateProfile)
+	mux.HandleFunc("GET /api/traffic/profiles/{id}", h.GetProfile)
+	mux.HandleFunc("PUT /api/traffic/profiles/{id}", h.UpdateProfile)
+	mux.HandleFunc("DELETE /api/traffic/profiles/{id}", h.DeleteProfile)
+}
+
+// Start handles POST /api/traffic/start. Traffic goes to the entry points
+// of the deployed diagram and runs in the background; progress is streamed
+// over the WebSocket. A simulated deployment has nothing to send traffic
+// to, so it is refused.
+func (h *TrafficHandler) Start(w http.ResponseWriter, r *http.Request) {
+	var req trafficStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	opts, ok := h.startOptions(w, req)
+	if !ok {
+		return
+	}
+	if err := opts.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status := h.manager.Status()
+	if status.State != deploy.StateDeployed {
+		writeError(w, http.StatusConflict, "no deployment is running")
+		return
+	}
+	if status.Mode == docker.ModeSimulated {
+		writeError(w, http.StatusConflict, "traffic needs real containers; the simulated orchestrator runs none")
+		return
+	}
+	d, ok := h.deployedDiagram(w, status)
+	if !ok {
+		return
+	}
+
+	targets := make(map[string]traffic.Target, len(status.Nodes))
+	for _, n := range status.Nodes {
+		if n.ContainerID != "" {
+			targets[n.NodeID] = traffic.Target{Hostname: n.Name, Ports: n.Ports}
+		}
+	}
+	endpoints, err := traffic.EntryPoints(d, targets)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	run, err := h.controller.Start(endpoints, opts)
+	if err != nil {
+		if errors.Is(err, traffic.ErrRunning) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "start traffic: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// deployedDiagram returns the diagram of the deployment described by
+// status, as the manager deployed it, so unsaved edits in the store do not
+// change where traffic goes. A deployment adopted from a previous run does
+// not record its diagram; the stored copy is used for it instead.
+func (h *TrafficHandler) deployedDiagram(w http.ResponseWriter, status deploy.Status) (model.Diagram, bool) {
+	if d, ok := h.manager.Diagram(); ok && d.ID == status.DiagramID {
+		return d, true
+	}
+	d, ok := loadDiagram(w, h.store, status.DiagramID)
+	if !ok {
+		return model.Diagram{}, false
+	}
+	return *d, true
+}
+
+// startOptions resolves the options a start request runs with, writing an
+// error response if it cannot.
+func (h *TrafficHandler) startOptions(w http.ResponseWriter, req trafficStartRequest) (traffic.Options, bool) {
+	switch {
+	case req.ProfileID != "" && req.adHoc():
+		writeError(w, http.StatusBadRequest, "profileId cannot be combined with durationSeconds, vus, rate or thinkTimeMs")
+		return traffic.Options{}, false
+	case req.ProfileID != "":
+		p, ok := h.loadProfile(w, req.ProfileID)
+		if !ok {
+			return traffic.Options{}, false
+		}
+		return traffic.ProfileOptions(p), true
+	case req.adHoc():
+		return traffic.Options{
+			Duration:  time.Duration(req.DurationSeconds) * time.Second,
+			VUs:       req.VUs,
+			Rate:      req.Rate,
+			ThinkTime: time.Duration(req.ThinkTimeMs) * time.Millisecond,
+		}, true
+	}
+	p, err := h.profiles.Active()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to retrieve the active profile")
+		return traffic.Options{}, false
+	}
+	return traffic.ProfileOptions(p), true
+}
+
+// Stop handles POST /api/traffic/stop and returns the finished run.
+func (h *TrafficHandler) Stop(w http.ResponseWriter, _ *http.Request) {
+	run, err := h.controller.Stop()
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+// Status handles GET /api/traffic/status, the active or last run.
+func (h *TrafficHandler) Status(w http.ResponseWriter, _ *http.Request) {
+	run, ok := h.controller.Status()
+	if !ok {
+		writeError(w, http.StatusNotFound, "no traffic has run")
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+// GetConfig handles GET /api/traffic/config, the active load profile that
+// a start request without options runs.
+func (h *TrafficHandler) GetConfig(w http.ResponseWriter, _ *http.Request) {
+	p, err := h.profiles.Active()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to retrieve the active profile")
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// UpdateConfig handles PUT /api/traffic/config, which makes a profile
+// active and returns it.
+func (h *TrafficHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
+	var req trafficConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.ProfileID == "" {
+		writeError(w, http.StatusBadRequest, "profileId is required")
+		return
+	}
+	p, err := h.profiles.SetActive(req.ProfileID)
+	if err != nil {
+		writeProfileError(w, err, "failed to set the active profile")
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// ListProfiles handles GET /api/traffic/profiles: the presets, then the
+// stored profiles by name.
+func (h *TrafficHandler) ListProfiles(w http.ResponseWriter, _ *http.Request) {
+	profiles, err := h.profiles.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list profiles")
+		return
+	}
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+// CreateProfile handles POST /api/traffic/profiles.
+func (h *TrafficHandler) CreateProfile(w http.ResponseWriter, r *http.Request) {
+	var p model.LoadProfile
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if err := traffic.ValidateProfile(&p); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := h.profiles.Create(&p)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create profile")
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// GetProfile handles GET /api/traffic/profiles/{id}.
+func (h *TrafficHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.loadProfile(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// UpdateProfile handles PUT /api/traffic/profiles/{id}. Presets cannot be
+// changed.
+func (h *TrafficHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var p model.LoadProfile
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if err := traffic.ValidateProfile(&p); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updated, err := h.profiles.Update(r.PathValue("id"), &p)
+	if err != nil {
+		writeProfileError(w, err, "failed to update profile")
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteProfile handles DELETE /api/traffic/profiles/{id}. Deleting the
+// active profile makes the default preset active. Presets cannot be
+// deleted.
+func (h *TrafficHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	if err := h.profiles.Delete(r.PathValue("id")); err != nil {
+		writeProfileError(w, err, "failed to delete profile")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadProfile fetches a preset or stored profile, writing an error
+// response if it cannot.
+func (h *TrafficHandler) loadProfile(w http.ResponseWriter, id string) (model.LoadProfile, bool) {
+	p, err := h.profiles.Get(id)
+	if err != nil {
+		writeProfileError(w, err, "failed to retrieve profile")
+		return model.LoadProfile{}, false
+	}
+	return p, true
+}
+
+// writeProfileError maps profile errors to responses, falling back to a
+// 500 with msg.
+func writeProfileError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, storage.ErrProfileNotFound):
+		writeError(w, http.StatusNotFound, "profile not found")
+	case errors.Is(err, storage.ErrInvalidID):
+		writeError(w, http.StatusBadRequest, "invalid profile ID")
+	case errors.Is(err, traffic.ErrPresetReadOnly):
+		writeError(w, http.StatusForbidden, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, msg)
+	}
+}
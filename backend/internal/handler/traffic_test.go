@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+	"github.com/stwalsh4118/hephaestus/backend/internal/traffic"
+)
+
+// idleGenerator sends nothing and runs until it is stopped.
+type idleGenerator struct{}
+
+func (idleGenerator) Name() string { return "idle" }
+
+func (idleGenerator) Run(ctx context.Context, _ []traffic.Endpoint, _ traffic.Options, _ func(traffic.Stats)) (traffic.Stats, error) {
+	<-ctx.Done()
+	return traffic.Stats{}, nil
+}
+
+func setupTrafficTest(t *testing.T) (*http.ServeMux, *storage.FileStore, *deploy.Manager) {
+	t.Helper()
+	// The mode is only a label on the deployment, so the simulated
+	// orchestrator can stand in for docker while idleGenerator sends nothing.
+	return setupTrafficTestMode(t, docker.ModeDocker)
+}
+
+func setupTrafficTestMode(t *testing.T, mode string) (*http.ServeMux, *storage.FileStore, *deploy.Manager) {
+	t.Helper()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	profileStore, err := storage.NewFileProfileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileProfileStore: %v", err)
+	}
+	manager := deploy.NewManager(docker.NewSimulatedOrchestrator(docker.SimulationConfig{}), nil, mode, nil)
+	mux := http.NewServeMux()
+	NewTrafficHandler(store, manager, traffic.NewController(idleGenerator{}, nil), traffic.NewProfiles(profileStore)).RegisterRoutes(mux)
+	return mux, store, manager
+}
+
+// deployGateway stores and deploys a diagram with a single nginx node.
+func deployGateway(t *testing.T, store *storage.FileStore, manager *deploy.Manager) {
+	t.Helper()
+	d, err := store.Create(&model.Diagram{
+		Name:  "Gateway",
+		Nodes: []model.DiagramNode{{ID: "gw", Type: model.ServiceTypeNginx, Name: "Gateway", Position: &model.Position{}}},
+	})
+	if err != nil {
+		t.Fatalf("store.Create: %v", err)
+	}
+	if _, err := manager.Deploy(context.Background(), *d); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+}
+
+func TestTraffic_StartAndStop(t *testing.T) {
+	mux, store, manager := setupTrafficTest(t)
+	deployGateway(t, store, manager)
+
+	rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", `{"durationSeconds": 60, "vus": 2, "thinkTimeMs": 250}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status: got %d, want %d (body %s)", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	var run traffic.Run
+	if err := json.NewDecoder(rec.Body).Decode(&run); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if run.State != traffic.RunRunning || run.Options.VUs != 2 || run.Options.ThinkTime != 250*time.Millisecond || len(run.Endpoints) != 1 || run.Endpoints[0].URL != "http://gateway:80/" {
+		t.Errorf("unexpected run %+v", run)
+	}
+
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", ""); rec.Code != http.StatusConflict {
+		t.Errorf("second start status: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	rec = doDeployRequest(mux, http.MethodPost, "/api/traffic/stop", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stop status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&run); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if run.State != traffic.RunStopped {
+		t.Errorf("expected stopped run, got %q", run.State)
+	}
+
+	if rec := doGet(mux, "/api/traffic/status"); rec.Code != http.StatusOK {
+		t.Errorf("status endpoint: got %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestTraffic_UsesDeployedDiagram(t *testing.T) {
+	mux, store, manager := setupTrafficTest(t)
+
+	// Never saved: the store has nothing under this ID.
+	unsaved := model.Diagram{
+		ID:    "11111111-1111-4111-8111-111111111111",
+		Name:  "Unsaved",
+		Nodes: []model.DiagramNode{{ID: "gw", Type: model.ServiceTypeNginx, Name: "Edge", Position: &model.Position{}}},
+		Edges: []model.DiagramEdge{},
+	}
+	if _, err := manager.Deploy(context.Background(), unsaved); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+	rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", `{"durationSeconds": 60}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("unsaved diagram: got %d, want %d (body %s)", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	doDeployRequest(mux, http.MethodPost, "/api/traffic/stop", "")
+
+	// Saved, then edited after the deploy: traffic follows the deployment.
+	deployGateway(t, store, manager)
+	status := manager.Status()
+	stored, err := store.Get(status.DiagramID)
+	if err != nil {
+		t.Fatalf("store.Get: %v", err)
+	}
+	stored.Nodes[0].Type = model.ServiceTypeRedis
+	if _, err := store.Update(stored.ID, stored); err != nil {
+		t.Fatalf("store.Update: %v", err)
+	}
+	rec = doDeployRequest(mux, http.MethodPost, "/api/traffic/start", `{"durationSeconds": 60}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("edited diagram: got %d, want %d (body %s)", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	var run traffic.Run
+	if err := json.NewDecoder(rec.Body).Decode(&run); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(run.Endpoints) != 1 || run.Endpoints[0].URL != "http://gateway:80/" {
+		t.Errorf("expected the deployed gateway as entry point, got %+v", run.Endpoints)
+	}
+	doDeployRequest(mux, http.MethodPost, "/api/traffic/stop", "")
+}
+
+func TestTraffic_Errors(t *testing.T) {
+	mux, _, _ := setupTrafficTest(t)
+
+	if rec := doGet(mux, "/api/traffic/status"); rec.Code != http.StatusNotFound {
+		t.Errorf("status before any run: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/stop", ""); rec.Code != http.StatusConflict {
+		t.Errorf("stop without a run: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", ""); rec.Code != http.StatusConflict {
+		t.Errorf("start without a deployment: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", `{"vus": -1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("start with bad options: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", `{"thinkTimeMs": 120000}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("start with bad think time: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTraffic_SimulatedDeployment(t *testing.T) {
+	mux, store, manager := setupTrafficTestMode(t, docker.ModeSimulated)
+	deployGateway(t, store, manager)
+
+	rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if !strings.Contains(rec.Body.String(), "simulated") {
+		t.Errorf("expected a simulated mode error, got %s", rec.Body.String())
+	}
+}
+
+func TestTraffic_NoEntryPoints(t *testing.T) {
+	mux, store, manager := setupTrafficTest(t)
+	d, err := store.Create(&model.Diagram{
+		Name:  "Cache",
+		Nodes: []model.DiagramNode{{ID: "c", Type: model.ServiceTypeRedis, Name: "Cache", Position: &model.Position{}}},
+	})
+	if err != nil {
+		t.Fatalf("store.Create: %v", err)
+	}
+	if _, err := manager.Deploy(context.Background(), *d); err != nil {
+		t.Fatalf("Deploy: %v", err)
+	}
+
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", ""); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestTraffic_StartRunsProfiles(t *testing.T) {
+	mux, store, manager := setupTrafficTest(t)
+	deployGateway(t, store, manager)
+
+	start := func(body string) traffic.Run {
+		t.Helper()
+		rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", body)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("start status: got %d, want %d (body %s)", rec.Code, http.StatusAccepted, rec.Body.String())
+		}
+		var run traffic.Run
+		if err := json.NewDecoder(rec.Body).Decode(&run); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/stop", ""); rec.Code != http.StatusOK {
+			t.Fatalf("stop status: got %d, want %d", rec.Code, http.StatusOK)
+		}
+		return run
+	}
+
+	if run := start(""); run.Options.Profile != traffic.DefaultProfileID || run.Options.VUs != traffic.DefaultVUs || len(run.Options.Stages) != 1 {
+		t.Errorf("expected the default profile without a body, got %+v", run.Options)
+	}
+	if run := start(`{"profileId": "spike"}`); run.Options.Profile != "spike" || run.Options.Rate != 200 || run.Options.Duration != 70*time.Second {
+		t.Errorf("expected the spike profile, got %+v", run.Options)
+	}
+
+	if rec := doDeployRequest(mux, http.MethodPut, "/api/traffic/config", `{"profileId": "step"}`); rec.Code != http.StatusOK {
+		t.Fatalf("config status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	if run := start(""); run.Options.Profile != "step" {
+		t.Errorf("expected the active step profile, got %+v", run.Options)
+	}
+
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", `{"profileId": "missing"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("start with unknown profile: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/start", `{"profileId": "spike", "vus": 3}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("start with profile and options: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTraffic_Config(t *testing.T) {
+	mux, _, _ := setupTrafficTest(t)
+
+	rec := doGet(mux, "/api/traffic/config")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	var active model.LoadProfile
+	if err := json.NewDecoder(rec.Body).Decode(&active); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if active.ID != traffic.DefaultProfileID || !active.Preset {
+		t.Errorf("expected the default preset, got %+v", active)
+	}
+
+	if rec := doDeployRequest(mux, http.MethodPut, "/api/traffic/config", `{"profileId": "missing"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown profile: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := doDeployRequest(mux, http.MethodPut, "/api/traffic/config", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing profileId: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestTraffic_ProfileCRUD(t *testing.T) {
+	mux, _, _ := setupTrafficTest(t)
+	body := `{"name": "Lunch rush", "model": "open", "stages": [{"durationSeconds": 60, "target": 50}, {"durationSeconds": 30, "target": 50, "ramp": "step"}]}`
+
+	rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/profiles", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status: got %d, want %d (body %s)", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	var created model.LoadProfile
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.ID == "" || created.Name != "Lunch rush" || len(created.Stages) != 2 {
+		t.Fatalf("unexpected profile %+v", created)
+	}
+
+	rec = doGet(mux, "/api/traffic/profiles")
+	var list []model.LoadProfile
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list) != len(traffic.Presets())+1 || list[len(list)-1].ID != created.ID {
+		t.Errorf("expected presets then the new profile, got %d profiles", len(list))
+	}
+
+	updated := `{"name": "Lunch rush", "model": "closed", "stages": [{"durationSeconds": 60, "target": 5}]}`
+	if rec := doDeployRequest(mux, http.MethodPut, "/api/traffic/profiles/"+created.ID, updated); rec.Code != http.StatusOK {
+		t.Errorf("update status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	rec = doGet(mux, "/api/traffic/profiles/"+created.ID)
+	var got model.LoadProfile
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Model != model.LoadModelClosed {
+		t.Errorf("expected the updated profile, got %+v", got)
+	}
+
+	if rec := doDeployRequest(mux, http.MethodDelete, "/api/traffic/profiles/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete status: got %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec := doGet(mux, "/api/traffic/profiles/"+created.ID); rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestTraffic_ProfileErrors(t *testing.T) {
+	mux, _, _ := setupTrafficTest(t)
+
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/profiles", `{"name": "x", "model": "open", "stages": []}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("create invalid: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := doDeployRequest(mux, http.MethodPost, "/api/traffic/profiles", `{"name": "x", "model": "open", "stages": [{"durationSeconds": 10, "target": 20000}]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("create over the rate limit: got %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := doGet(mux, "/api/traffic/profiles/spike"); rec.Code != http.StatusOK {
+		t.Errorf("get preset: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	valid := `{"name": "x", "model": "open", "stages": [{"durationSeconds": 10, "target": 20}]}`
+	if rec := doDeployRequest(mux, http.MethodPut, "/api/traffic/profiles/spike", valid); rec.Code != http.StatusForbidden {
+		t.Errorf("update preset: got %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec := doDeployRequest(mux, http.MethodDelete, "/api/traffic/profiles/spike", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("delete preset: got %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec := doDeployRequest(mux, http.MethodPut, "/api/traffic/profiles/missing", valid); rec.Code != http.StatusNotFound {
+		t.Errorf("update missing: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -17,11 +19,44 @@ const (
 	wsWriteWait       = 10 * time.Second
 	wsCORSOriginEnv   = "CORS_ORIGIN"
 	wsDefaultOrigin   = "http://localhost:3000"
+	// wsSendBuffer is the number of outgoing messages queued per client.
+	// A client that falls further behind misses messages rather than
+	// stalling the broadcaster.
+	wsSendBuffer = 16
 )
 
-// WebSocketHandler handles WebSocket connections at /ws/status.
+// WebSocket message types.
+const (
+	WSMessageDeploymentStatus = "deployment.status"
+	// WSMessageOrchestratorEvent carries a docker.Event.
+	WSMessageOrchestratorEvent = "orchestrator.event"
+	// WSMessageTrafficStats carries a traffic.Run.
+	WSMessageTrafficStats = "traffic.stats"
+	// WSMessageChaosEvent carries a chaos.Event.
+	WSMessageChaosEvent = "chaos.event"
+)
+
+// wsMessage is the envelope for every server-to-client message.
+type wsMessage struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// WebSocketHandler handles WebSocket connections at /ws/status and
+// broadcasts messages to every connected client.
 type WebSocketHandler struct {
 	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	clients map[chan []byte]struct{}
+	// snapshots produce the messages sent to a client as soon as it
+	// connects, so it starts from the current state.
+	snapshots []wsSnapshot
+}
+
+type wsSnapshot struct {
+	msgType string
+	data    func() any
 }
 
 // NewWebSocketHandler creates a WebSocketHandler with origin checking.
@@ -39,7 +74,75 @@ func NewWebSocketHandler() *WebSocketHandler {
 				return r.Header.Get("Origin") == "" || r.Header.Get("Origin") == origin
 			},
 		},
+		clients: make(map[chan []byte]struct{}),
+	}
+}
+
+// Broadcast sends a message of the given type to every connected client.
+// It never blocks: clients whose send buffer is full skip the message.
+func (h *WebSocketHandler) Broadcast(msgType string, data any) {
+	payload, err := json.Marshal(wsMessage{Type: msgType, Data: data})
+	if err != nil {
+		log.Printf("websocket broadcast: marshal %s: %v", msgType, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for send := range h.clients {
+		select {
+		case send <- payload:
+		default:
+			log.Printf("websocket broadcast: client send buffer full; dropping %s", msgType)
+		}
+	}
+}
+
+// SendOnConnect registers a message of the given type that every new client
+// receives first, with data evaluated at connect time.
+func (h *WebSocketHandler) SendOnConnect(msgType string, data func() any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.snapshots = append(h.snapshots, wsSnapshot{msgType: msgType, data: data})
+}
+
+// Clients returns the number of connected clients.
+func (h *WebSocketHandler) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+func (h *WebSocketHandler) addClient() chan []byte {
+	send := make(chan []byte, wsSendBuffer)
+	h.mu.Lock()
+	snapshots := h.snapshots
+	h.mu.Unlock()
+
+	// Queue the snapshots before registering the client so they precede
+	// any broadcast.
+	for _, s := range snapshots {
+		payload, err := json.Marshal(wsMessage{Type: s.msgType, Data: s.data()})
+		if err != nil {
+			log.Printf("websocket snapshot: marshal %s: %v", s.msgType, err)
+			continue
+		}
+		select {
+		case send <- payload:
+		default:
+		}
 	}
+
+	h.mu.Lock()
+	h.clients[send] = struct{}{}
+	h.mu.Unlock()
+	return send
+}
+
+func (h *WebSocketHandler) removeClient(send chan []byte) {
+	h.mu.Lock()
+	delete(h.clients, send)
+	h.mu.Unlock()
 }
 
 // RegisterRoutes registers the WebSocket route on the given mux.
@@ -71,13 +174,24 @@ func (h *WebSocketHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
 	})
 
-	// Start a goroutine to send periodic pings.
+	send := h.addClient()
+	defer h.removeClient(send)
+
+	// The writer goroutine is the only one writing to conn: it sends
+	// broadcast messages and periodic pings.
 	done := make(chan struct{})
 	go func() {
 		ticker := time.NewTicker(wsPingInterval)
 		defer ticker.Stop()
 		for {
 			select {
+			case msg := <-send:
+				if err := conn.SetWriteDeadline(time.Now().Add(wsWriteWait)); err != nil {
+					return
+				}
+				if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+					return
+				}
 			case <-ticker.C:
 				if err := conn.SetWriteDeadline(time.Now().Add(wsWriteWait)); err != nil {
 					return
@@ -91,7 +205,8 @@ func (h *WebSocketHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	// Read loop: required for ping/pong handling. No business logic.
+	// Read loop: required for ping/pong and close handling. Client messages
+	// are ignored.
 	for {
 		_, _, err := conn.ReadMessage()
 		if err != nil {
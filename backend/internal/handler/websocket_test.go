@@ -120,3 +120,77 @@ func TestWebSocket_NonWSRequest(t *testing.T) {
 		t.Errorf("status: got %d, want %d", resp.StatusCode, http.StatusBadRequest)
 	}
 }
+
+func TestWebSocket_Broadcast(t *testing.T) {
+	t.Setenv("CORS_ORIGIN", "")
+	h := NewWebSocketHandler()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(server), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Wait for the server side to register the client.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		h.mu.Lock()
+		n := len(h.clients)
+		h.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("client was not registered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	h.Broadcast(WSMessageDeploymentStatus, map[string]string{"state": "deployed"})
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg struct {
+		Type string            `json:"type"`
+		Data map[string]string `json:"data"`
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if msg.Type != WSMessageDeploymentStatus || msg.Data["state"] != "deployed" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+}
+
+func TestWebSocket_SendOnConnect(t *testing.T) {
+	t.Setenv("CORS_ORIGIN", "")
+	h := NewWebSocketHandler()
+	state := "idle"
+	h.SendOnConnect(WSMessageDeploymentStatus, func() any { return map[string]string{"state": state} })
+	state = "deployed"
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(server), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg struct {
+		Type string            `json:"type"`
+		Data map[string]string `json:"data"`
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if msg.Type != WSMessageDeploymentStatus || msg.Data["state"] != "deployed" {
+		t.Errorf("expected the state at connect time, got %+v", msg)
+	}
+}
@@ -0,0 +1,446 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/google/uuid"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultDiagramName is used when neither the caller nor the Compose file
+// provides a name.
+const defaultDiagramName = "Imported Compose"
+
+// edgeLabel is the label given to edges created from depends_on and links.
+const edgeLabel = "depends on"
+
+// Defaults mirroring the frontend config forms.
+const (
+	defaultPostgresEngine  = "PostgreSQL"
+	defaultPostgresVersion = "16"
+	defaultRedisMaxMemory  = "256mb"
+	defaultRedisEviction   = "noeviction"
+	defaultRabbitMQVhost   = "/"
+	defaultAPIServicePort  = 4010
+)
+
+// ErrNoServices is returned when the Compose file defines no services.
+var ErrNoServices = errors.New("compose file defines no services")
+
+// Result is a validated diagram built from a Compose file, plus a warning for
+// everything that could not be represented and was dropped.
+type Result struct {
+	Diagram  model.Diagram `json:"diagram"`
+	Warnings []string      `json:"warnings"`
+}
+
+// Compose parses a Compose file and maps it onto a diagram. Known images
+// (postgres, redis, nginx, rabbitmq, stoplight/prism and the hephaestus
+// mock server) become their service
+// types; anything else becomes a custom-container node. Edges come from
+// depends_on and links, and nodes are laid out left to right by dependency
+// depth. name overrides the diagram name; when empty, the Compose project
+// name is used. mockImage is the mock server image api-service nodes are
+// configured to run, if not templates.ImageAPIService; it is recognised too.
+func Compose(data []byte, name, mockImage string) (*Result, error) {
+	var file composeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse compose file: %w", err)
+	}
+	if len(file.Services) == 0 {
+		return nil, ErrNoServices
+	}
+
+	w := &warnings{}
+	for _, key := range sortedKeys(file.Extra) {
+		if key != "version" && !strings.HasPrefix(key, "x-") {
+			w.addf("top-level %q is not supported and was dropped", key)
+		}
+	}
+
+	names := sortedKeys(file.Services)
+	nodes := make([]model.DiagramNode, 0, len(names))
+	imported := make(map[string]bool, len(names))
+	for _, svcName := range names {
+		svc := file.Services[svcName]
+		node, ok := mapService(svcName, svc, mockImage, w)
+		if !ok {
+			continue
+		}
+		if err := model.ValidateNode(&node); err != nil {
+			w.addf("service %q: %v; service was skipped", svcName, err)
+			continue
+		}
+		nodes = append(nodes, node)
+		imported[svcName] = true
+	}
+
+	edges := buildEdges(names, file.Services, imported, w)
+	layout(nodes, edges)
+
+	if name == "" {
+		name = file.Name
+	}
+	if name == "" {
+		name = defaultDiagramName
+	}
+
+	d := model.Diagram{
+		ID:    uuid.New().String(),
+		Name:  name,
+		Nodes: nodes,
+		Edges: edges,
+	}
+	if err := model.ValidateDiagram(&d); err != nil {
+		return nil, err
+	}
+
+	return &Result{Diagram: d, Warnings: w.list}, nil
+}
+
+// warnings accumulates human-readable notes about dropped input.
+type warnings struct {
+	list []string
+}
+
+func (w *warnings) addf(format string, args ...any) {
+	w.list = append(w.list, fmt.Sprintf(format, args...))
+}
+
+// mapService converts one Compose service into a diagram node. It returns
+// false when the service cannot be represented at all.
+func mapService(name string, svc composeService, mockImage string, w *warnings) (model.DiagramNode, bool) {
+	for _, key := range sortedKeys(svc.Extra) {
+		w.addf("service %q: %q is not supported and was dropped", name, key)
+	}
+	for _, p := range append(svc.Ports.Invalid, svc.Expose.Invalid...) {
+		w.addf("service %q: port %q could not be mapped to a single container port and was dropped", name, p)
+	}
+	for _, k := range svc.Environment.Unset {
+		w.addf("service %q: environment variable %q has no value and was dropped", name, k)
+	}
+
+	if svc.Image == "" {
+		w.addf("service %q: has no image (build-only services are not supported) and was skipped", name)
+		return model.DiagramNode{}, false
+	}
+	if err := model.ValidateImageReference(svc.Image); err != nil {
+		w.addf("service %q: %v; service was skipped", name, err)
+		return model.DiagramNode{}, false
+	}
+
+	// The position is replaced by layout once edges are known.
+	node := model.DiagramNode{ID: name, Name: name, Position: &model.Position{}}
+	var cfg any
+	switch classifyImage(svc.Image, mockImage) {
+	case model.ServiceTypePostgreSQL:
+		node.Type = model.ServiceTypePostgreSQL
+		cfg = mapPostgres(name, svc, w)
+	case model.ServiceTypeRedis:
+		node.Type = model.ServiceTypeRedis
+		cfg = mapRedis(name, svc, w)
+	case model.ServiceTypeNginx:
+		node.Type = model.ServiceTypeNginx
+		cfg = mapNginx(name, svc, w)
+	case model.ServiceTypeRabbitMQ:
+		node.Type = model.ServiceTypeRabbitMQ
+		cfg = mapRabbitMQ(name, svc, w)
+	case model.ServiceTypeAPIService:
+		node.Type = model.ServiceTypeAPIService
+		cfg = mapAPIService(name, svc, w)
+	default:
+		node.Type = model.ServiceTypeCustomContainer
+		cfg = mapCustom(name, svc, w)
+	}
+
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		w.addf("service %q: could not encode config: %v; service was skipped", name, err)
+		return model.DiagramNode{}, false
+	}
+	node.Config = raw
+	node.Description = fmt.Sprintf("Imported from Compose service %q (%s)", name, svc.Image)
+	return node, true
+}
+
+// legacyMockServerRepository is the repository of the locally built image
+// api-service nodes were exported with before the mock server was published.
+const legacyMockServerRepository = "hephaestus/mock-server"
+
+// isMockServer reports whether named is the mock server image: the
+// published one, the configured mockImage, or the legacy local build.
+func isMockServer(named reference.Named, mockImage string) bool {
+	if reference.Path(named) == legacyMockServerRepository {
+		return true
+	}
+	for _, image := range []string{templates.ImageAPIService, mockImage} {
+		if image == "" {
+			continue
+		}
+		if mock, err := reference.ParseNormalizedNamed(image); err == nil && mock.Name() == named.Name() {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyImage maps an image reference onto a built-in service type by its
+// repository name. Unknown images map to the custom-container type.
+func classifyImage(image, mockImage string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return model.ServiceTypeCustomContainer
+	}
+	if isMockServer(named, mockImage) {
+		return model.ServiceTypeAPIService
+	}
+	switch path.Base(reference.Path(named)) {
+	case "postgres", "postgresql":
+		return model.ServiceTypePostgreSQL
+	case "redis":
+		return model.ServiceTypeRedis
+	case "nginx":
+		return model.ServiceTypeNginx
+	case "rabbitmq":
+		return model.ServiceTypeRabbitMQ
+	case "prism":
+		return model.ServiceTypeAPIService
+	default:
+		return model.ServiceTypeCustomContainer
+	}
+}
+
+// imageTag returns the tag of an image reference, or "" if it has none.
+func imageTag(image string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return ""
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		return tagged.Tag()
+	}
+	return ""
+}
+
+func mapPostgres(name string, svc composeService, w *warnings) model.PostgresqlConfig {
+	warnDropped(name, svc, w, "environment", "command", "entrypoint", "healthcheck")
+	version := imageTag(svc.Image)
+	if version == "" || version == "latest" {
+		version = defaultPostgresVersion
+	}
+	return model.PostgresqlConfig{Type: model.ServiceTypePostgreSQL, Engine: defaultPostgresEngine, Version: version}
+}
+
+func mapRedis(name string, svc composeService, w *warnings) model.RedisConfig {
+	warnDropped(name, svc, w, "environment", "entrypoint", "healthcheck")
+	cfg := model.RedisConfig{Type: model.ServiceTypeRedis, MaxMemory: defaultRedisMaxMemory, EvictionPolicy: defaultRedisEviction}
+
+	var rest []string
+	args := svc.Command
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--maxmemory" && i+1 < len(args):
+			cfg.MaxMemory = args[i+1]
+			i++
+		case args[i] == "--maxmemory-policy" && i+1 < len(args):
+			cfg.EvictionPolicy = args[i+1]
+			i++
+		case args[i] == "redis-server":
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	if len(rest) > 0 {
+		w.addf("service %q: redis arguments %q were dropped", name, strings.Join(rest, " "))
+	}
+	return cfg
+}
+
+func mapNginx(name string, svc composeService, w *warnings) model.NginxConfig {
+	warnDropped(name, svc, w, "environment", "command", "entrypoint", "healthcheck")
+	upstreams := append([]string{}, svc.DependsOn...)
+	for _, l := range svc.Links {
+		target, _, _ := strings.Cut(l, ":")
+		upstreams = append(upstreams, target)
+	}
+	return model.NginxConfig{Type: model.ServiceTypeNginx, UpstreamServers: uniqueSorted(upstreams)}
+}
+
+func mapRabbitMQ(name string, svc composeService, w *warnings) model.RabbitMQConfig {
+	warnDropped(name, svc, w, "command", "entrypoint", "healthcheck")
+	cfg := model.RabbitMQConfig{Type: model.ServiceTypeRabbitMQ, Vhost: defaultRabbitMQVhost}
+	for _, k := range sortedKeys(svc.Environment.Values) {
+		if k == "RABBITMQ_DEFAULT_VHOST" {
+			cfg.Vhost = unescape(svc.Environment.Values[k])
+			continue
+		}
+		w.addf("service %q: environment variable %q was dropped", name, k)
+	}
+	return cfg
+}
+
+func mapAPIService(name string, svc composeService, w *warnings) model.ApiServiceConfig {
+	warnDropped(name, svc, w, "environment", "command", "entrypoint", "healthcheck")
+	w.addf("service %q: the mounted OpenAPI spec is not imported; define endpoints in the config panel", name)
+	port := defaultAPIServicePort
+	if ports := svc.Ports.Ports; len(ports) > 0 {
+		port = ports[0]
+	}
+	return model.ApiServiceConfig{Type: model.ServiceTypeAPIService, Endpoints: []model.Endpoint{}, Port: port}
+}
+
+func mapCustom(name string, svc composeService, w *warnings) model.CustomContainerConfig {
+	cfg := model.CustomContainerConfig{
+		Type:       model.ServiceTypeCustomContainer,
+		Image:      svc.Image,
+		Command:    unescapeAll(svc.Command),
+		Entrypoint: unescapeAll(svc.Entrypoint),
+		Ports:      uniquePorts(append(svc.Ports.Ports, svc.Expose.Ports...)),
+	}
+
+	if len(svc.Environment.Values) > 0 {
+		cfg.Env = make(map[string]string, len(svc.Environment.Values))
+		for _, k := range sortedKeys(svc.Environment.Values) {
+			v := svc.Environment.Values[k]
+			if !model.ValidEnvName(k) {
+				w.addf("service %q: environment variable %q is not a valid name and was dropped", name, k)
+				continue
+			}
+			if strings.Contains(v, "${") {
+				w.addf("service %q: environment variable %q uses interpolation, which is not resolved", name, k)
+			}
+			cfg.Env[k] = unescape(v)
+		}
+	}
+
+	if hc := svc.Healthcheck; hc != nil && !hc.Disable && len(hc.Test) > 0 && hc.Test[0] != "NONE" {
+		cfg.Healthcheck = mapHealthcheck(name, hc, w)
+	}
+	return cfg
+}
+
+func mapHealthcheck(name string, hc *composeHealthcheck, w *warnings) *model.Healthcheck {
+	out := &model.Healthcheck{Test: unescapeAll(hc.Test), Retries: hc.Retries}
+	for _, f := range []struct {
+		field string
+		value string
+		dst   *int
+	}{
+		{"interval", hc.Interval, &out.IntervalSeconds},
+		{"timeout", hc.Timeout, &out.TimeoutSeconds},
+		{"start_period", hc.StartPeriod, &out.StartPeriodSeconds},
+	} {
+		secs, err := parseSeconds(f.value)
+		if err != nil {
+			w.addf("service %q: healthcheck %s %q is not a valid duration and was dropped", name, f.field, f.value)
+			continue
+		}
+		*f.dst = secs
+	}
+	return out
+}
+
+// warnDropped reports each listed field the service sets that the target
+// service type has no place for.
+func warnDropped(name string, svc composeService, w *warnings, fields ...string) {
+	set := map[string]bool{
+		"environment": len(svc.Environment.Values) > 0,
+		"command":     len(svc.Command) > 0,
+		"entrypoint":  len(svc.Entrypoint) > 0,
+		"healthcheck": svc.Healthcheck != nil,
+	}
+	for _, f := range fields {
+		if set[f] {
+			w.addf("service %q: %s is not configurable for this service type and was dropped", name, f)
+		}
+	}
+}
+
+// buildEdges creates one edge per dependency (depends_on and links) between
+// imported services, in service-name order.
+func buildEdges(names []string, services map[string]composeService, imported map[string]bool, w *warnings) []model.DiagramEdge {
+	edges := []model.DiagramEdge{}
+	for _, src := range names {
+		if !imported[src] {
+			continue
+		}
+		svc := services[src]
+		deps := append([]string{}, svc.DependsOn...)
+		for _, l := range svc.Links {
+			target, _, _ := strings.Cut(l, ":")
+			deps = append(deps, target)
+		}
+		for _, dst := range uniqueSorted(deps) {
+			if !imported[dst] {
+				w.addf("service %q: dependency on %q was dropped because that service was not imported", src, dst)
+				continue
+			}
+			edges = append(edges, model.DiagramEdge{
+				ID:     "e-" + src + "-" + dst,
+				Source: src,
+				Target: dst,
+				Label:  edgeLabel,
+			})
+		}
+	}
+	return edges
+}
+
+// unescape reverses Compose's "$$" escaping.
+func unescape(s string) string {
+	return strings.ReplaceAll(s, "$$", "$")
+}
+
+func unescapeAll(in []string) []string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = unescape(s)
+	}
+	return out
+}
+
+func uniqueSorted(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// uniquePorts de-duplicates ports while keeping their first-seen order.
+func uniquePorts(in []int) []int {
+	seen := make(map[int]bool, len(in))
+	var out []int
+	for _, p := range in {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,384 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/export"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+const sampleCompose = `
+name: shop
+version: "3.9"
+services:
+  db:
+    image: postgres:15-alpine
+    environment:
+      POSTGRES_PASSWORD: secret
+    volumes:
+      - pgdata:/var/lib/postgresql/data
+  cache:
+    image: redis:7
+    command: redis-server --maxmemory 128mb --maxmemory-policy allkeys-lru --appendonly yes
+  queue:
+    image: rabbitmq:3-management
+    environment:
+      - RABBITMQ_DEFAULT_VHOST=orders
+  mock:
+    image: stoplight/prism:4
+    ports:
+      - "4010:4010"
+  web:
+    image: nginx:1.25
+    depends_on:
+      api:
+        condition: service_started
+  api:
+    image: ghcr.io/acme/api:2.1
+    command: ["serve", "--port", "8080"]
+    environment:
+      - LOG_LEVEL=debug
+      - FROM_HOST
+      - PRICE=$$5
+    ports:
+      - "127.0.0.1:18080:8080/tcp"
+      - target: 9090
+        published: 19090
+      - "7000-7005:7000-7005"
+    healthcheck:
+      test: curl -f http://localhost:8080/health
+      interval: 10s
+      retries: 5
+    depends_on: [db, cache]
+    links:
+      - queue:mq
+      - ghost
+  builder:
+    build: ./builder
+volumes:
+  pgdata: {}
+`
+
+func importSample(t *testing.T) *Result {
+	t.Helper()
+	res, err := Compose([]byte(sampleCompose), "", "")
+	if err != nil {
+		t.Fatalf("Compose: %v", err)
+	}
+	return res
+}
+
+func nodeByID(t *testing.T, d model.Diagram, id string) model.DiagramNode {
+	t.Helper()
+	for _, n := range d.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	t.Fatalf("node %q not found", id)
+	return model.DiagramNode{}
+}
+
+func TestCompose_MapsKnownImages(t *testing.T) {
+	res := importSample(t)
+
+	if res.Diagram.Name != "shop" {
+		t.Errorf("expected diagram name %q, got %q", "shop", res.Diagram.Name)
+	}
+	if err := model.ValidateDiagram(&res.Diagram); err != nil {
+		t.Fatalf("imported diagram is invalid: %v", err)
+	}
+
+	wantTypes := map[string]string{
+		"db":    model.ServiceTypePostgreSQL,
+		"cache": model.ServiceTypeRedis,
+		"queue": model.ServiceTypeRabbitMQ,
+		"mock":  model.ServiceTypeAPIService,
+		"web":   model.ServiceTypeNginx,
+		"api":   model.ServiceTypeCustomContainer,
+	}
+	if len(res.Diagram.Nodes) != len(wantTypes) {
+		t.Fatalf("expected %d nodes, got %d", len(wantTypes), len(res.Diagram.Nodes))
+	}
+	for id, want := range wantTypes {
+		if got := nodeByID(t, res.Diagram, id).Type; got != want {
+			t.Errorf("node %q: expected type %q, got %q", id, want, got)
+		}
+	}
+
+	var pg model.PostgresqlConfig
+	_ = json.Unmarshal(nodeByID(t, res.Diagram, "db").Config, &pg)
+	if pg.Version != "15-alpine" {
+		t.Errorf("expected postgres version from tag, got %q", pg.Version)
+	}
+
+	var redis model.RedisConfig
+	_ = json.Unmarshal(nodeByID(t, res.Diagram, "cache").Config, &redis)
+	if redis.MaxMemory != "128mb" || redis.EvictionPolicy != "allkeys-lru" {
+		t.Errorf("unexpected redis config: %+v", redis)
+	}
+
+	var rmq model.RabbitMQConfig
+	_ = json.Unmarshal(nodeByID(t, res.Diagram, "queue").Config, &rmq)
+	if rmq.Vhost != "orders" {
+		t.Errorf("expected vhost %q, got %q", "orders", rmq.Vhost)
+	}
+
+	var nginx model.NginxConfig
+	_ = json.Unmarshal(nodeByID(t, res.Diagram, "web").Config, &nginx)
+	if len(nginx.UpstreamServers) != 1 || nginx.UpstreamServers[0] != "api" {
+		t.Errorf("expected upstream [api], got %v", nginx.UpstreamServers)
+	}
+}
+
+func TestCompose_MapsUnknownImageToCustomContainer(t *testing.T) {
+	res := importSample(t)
+
+	var cfg model.CustomContainerConfig
+	if err := json.Unmarshal(nodeByID(t, res.Diagram, "api").Config, &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if cfg.Image != "ghcr.io/acme/api:2.1" {
+		t.Errorf("unexpected image %q", cfg.Image)
+	}
+	if strings.Join(cfg.Command, " ") != "serve --port 8080" {
+		t.Errorf("unexpected command %v", cfg.Command)
+	}
+	if cfg.Env["LOG_LEVEL"] != "debug" || cfg.Env["PRICE"] != "$5" {
+		t.Errorf("unexpected env %v", cfg.Env)
+	}
+	if len(cfg.Ports) != 2 || cfg.Ports[0] != 8080 || cfg.Ports[1] != 9090 {
+		t.Errorf("expected ports [8080 9090], got %v", cfg.Ports)
+	}
+	if cfg.Healthcheck == nil {
+		t.Fatal("expected healthcheck")
+	}
+	if cfg.Healthcheck.Test[0] != "CMD-SHELL" || cfg.Healthcheck.IntervalSeconds != 10 || cfg.Healthcheck.Retries != 5 {
+		t.Errorf("unexpected healthcheck %+v", cfg.Healthcheck)
+	}
+}
+
+func TestCompose_MapsMockServerImages(t *testing.T) {
+	const configured = "registry.example.com/acme/mock-server:1.2.3"
+	for _, image := range []string{templates.ImageAPIService, "hephaestus/mock-server:latest", configured} {
+		res, err := Compose([]byte("services:\n  api:\n    image: "+image+"\n"), "", configured)
+		if err != nil {
+			t.Fatalf("import %s: %v", image, err)
+		}
+		if got := nodeByID(t, res.Diagram, "api").Type; got != model.ServiceTypeAPIService {
+			t.Errorf("%s: expected type %q, got %q", image, model.ServiceTypeAPIService, got)
+		}
+	}
+}
+
+func TestCompose_EdgesFromDependsOnAndLinks(t *testing.T) {
+	res := importSample(t)
+
+	got := make(map[string]bool)
+	for _, e := range res.Diagram.Edges {
+		got[e.Source+"->"+e.Target] = true
+	}
+	for _, want := range []string{"api->db", "api->cache", "api->queue", "web->api"} {
+		if !got[want] {
+			t.Errorf("missing edge %s; got %v", want, got)
+		}
+	}
+	if len(res.Diagram.Edges) != 4 {
+		t.Errorf("expected 4 edges, got %d", len(res.Diagram.Edges))
+	}
+}
+
+func TestCompose_LayoutByDependencyDepth(t *testing.T) {
+	res := importSample(t)
+
+	db := nodeByID(t, res.Diagram, "db").Position
+	api := nodeByID(t, res.Diagram, "api").Position
+	web := nodeByID(t, res.Diagram, "web").Position
+	if !(db.X < api.X && api.X < web.X) {
+		t.Errorf("expected columns db < api < web, got %v < %v < %v", db.X, api.X, web.X)
+	}
+
+	seen := make(map[model.Position]string)
+	for _, n := range res.Diagram.Nodes {
+		if other, dup := seen[*n.Position]; dup {
+			t.Errorf("nodes %q and %q overlap at %+v", other, n.ID, *n.Position)
+		}
+		seen[*n.Position] = n.ID
+	}
+}
+
+func TestCompose_Warnings(t *testing.T) {
+	res := importSample(t)
+
+	for _, want := range []string{
+		`top-level "volumes" is not supported`,
+		`service "builder": has no image`,
+		`service "db": "volumes" is not supported`,
+		`service "db": environment is not configurable`,
+		`service "api": port "7000-7005:7000-7005" could not be mapped`,
+		`service "api": environment variable "FROM_HOST" has no value`,
+		`service "api": dependency on "ghost" was dropped`,
+		`service "cache": redis arguments "--appendonly yes" were dropped`,
+		`service "mock": the mounted OpenAPI spec is not imported`,
+	} {
+		found := false
+		for _, w := range res.Warnings {
+			if strings.Contains(w, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected warning containing %q in %v", want, res.Warnings)
+		}
+	}
+	for _, w := range res.Warnings {
+		if strings.Contains(w, `"version"`) {
+			t.Errorf("version key should be ignored silently, got %q", w)
+		}
+	}
+}
+
+func TestCompose_DropsOnlyUnrepresentableInput(t *testing.T) {
+	const data = `
+services:
+  app:
+    image: example/app:1
+    environment:
+      "BAD KEY": x
+      GOOD: y
+    depends_on: [__]
+  __:
+    image: example/other:1
+`
+	res, err := Compose([]byte(data), "", "")
+	if err != nil {
+		t.Fatalf("Compose: %v", err)
+	}
+	if len(res.Diagram.Nodes) != 1 {
+		t.Fatalf("expected only app to be imported, got %+v", res.Diagram.Nodes)
+	}
+	var cfg model.CustomContainerConfig
+	if err := json.Unmarshal(nodeByID(t, res.Diagram, "app").Config, &cfg); err != nil {
+		t.Fatalf("unmarshal config: %v", err)
+	}
+	if len(cfg.Env) != 1 || cfg.Env["GOOD"] != "y" {
+		t.Errorf("expected only GOOD to be kept, got %v", cfg.Env)
+	}
+	for _, want := range []string{
+		`service "app": environment variable "BAD KEY" is not a valid name and was dropped`,
+		`service "__": validation failed: node.name "__" must contain a letter or digit`,
+		`service "app": dependency on "__" was dropped`,
+	} {
+		found := false
+		for _, w := range res.Warnings {
+			if strings.Contains(w, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected warning containing %q in %v", want, res.Warnings)
+		}
+	}
+}
+
+func TestCompose_NameOverride(t *testing.T) {
+	res, err := Compose([]byte(sampleCompose), "My Import", "")
+	if err != nil {
+		t.Fatalf("Compose: %v", err)
+	}
+	if res.Diagram.Name != "My Import" {
+		t.Errorf("expected name override, got %q", res.Diagram.Name)
+	}
+}
+
+func TestCompose_Errors(t *testing.T) {
+	if _, err := Compose([]byte("services: {}"), "", ""); !errors.Is(err, ErrNoServices) {
+		t.Errorf("expected ErrNoServices, got %v", err)
+	}
+	if _, err := Compose([]byte("services: [unclosed"), "", ""); err == nil {
+		t.Error("expected parse error")
+	}
+	if _, err := Compose([]byte("services:\n  a:\n    image: x\n    command: \"unterminated 'quote\"\n"), "", ""); err == nil {
+		t.Error("expected error for unterminated quote in command")
+	}
+}
+
+func TestCompose_RoundTripsExport(t *testing.T) {
+	original := model.Diagram{
+		ID:   "rt",
+		Name: "Round Trip",
+		Nodes: []model.DiagramNode{
+			{ID: "pg", Type: model.ServiceTypePostgreSQL, Name: "Main DB", Position: &model.Position{}},
+			{ID: "cache", Type: model.ServiceTypeRedis, Name: "Cache", Position: &model.Position{}},
+			{ID: "api", Type: model.ServiceTypeAPIService, Name: "User API", Position: &model.Position{}},
+			{ID: "worker", Type: model.ServiceTypeCustomContainer, Name: "Worker", Position: &model.Position{}, Config: json.RawMessage(`{"type":"custom-container","image":"busybox:1.36","command":["sh","-c","echo $HOME"],"ports":[53]}`)},
+		},
+		Edges: []model.DiagramEdge{
+			{ID: "e1", Source: "api", Target: "pg"},
+			{ID: "e2", Source: "api", Target: "cache"},
+			{ID: "e3", Source: "worker", Target: "api"},
+		},
+	}
+
+	bundle, err := export.Compose(original, export.Options{})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	res, err := Compose(bundle[export.ComposeFileName], "", "")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	wantTypes := map[string]string{
+		"main-db":  model.ServiceTypePostgreSQL,
+		"cache":    model.ServiceTypeRedis,
+		"user-api": model.ServiceTypeAPIService,
+		"worker":   model.ServiceTypeCustomContainer,
+	}
+	for id, want := range wantTypes {
+		if got := nodeByID(t, res.Diagram, id).Type; got != want {
+			t.Errorf("node %q: expected type %q, got %q", id, want, got)
+		}
+	}
+	if len(res.Diagram.Edges) != len(original.Edges) {
+		t.Errorf("expected %d edges, got %d", len(original.Edges), len(res.Diagram.Edges))
+	}
+
+	var worker model.CustomContainerConfig
+	_ = json.Unmarshal(nodeByID(t, res.Diagram, "worker").Config, &worker)
+	if worker.Command[2] != "echo $HOME" {
+		t.Errorf("expected $$ to be unescaped, got %q", worker.Command[2])
+	}
+	if len(worker.Ports) != 1 || worker.Ports[0] != 53 {
+		t.Errorf("expected port 53 to survive the round trip, got %v", worker.Ports)
+	}
+}
+
+func TestShellSplit(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"echo hi", []string{"echo", "hi"}},
+		{`sh -c "echo 'a b'"`, []string{"sh", "-c", "echo 'a b'"}},
+		{`a\ b 'c d' ""`, []string{"a b", "c d", ""}},
+		{"  spaced\tout  ", []string{"spaced", "out"}},
+	}
+	for _, tc := range tests {
+		got, err := shellSplit(tc.input)
+		if err != nil {
+			t.Errorf("shellSplit(%q): %v", tc.input, err)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(tc.want, "|") || len(got) != len(tc.want) {
+			t.Errorf("shellSplit(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package importer
+
+import "github.com/stwalsh4118/hephaestus/backend/internal/model"
+
+// Auto-layout grid, in canvas units.
+const (
+	layoutOriginX   = 100
+	layoutOriginY   = 100
+	layoutColumnGap = 280
+	layoutRowGap    = 160
+)
+
+// layout assigns canvas positions in columns by dependency depth: services
+// with no dependencies sit in the leftmost column, and each service sits one
+// column to the right of its deepest dependency. Within a column, nodes keep
+// their slice order. Cycles are broken by treating a back-edge as depth zero.
+func layout(nodes []model.DiagramNode, edges []model.DiagramEdge) {
+	deps := make(map[string][]string, len(nodes))
+	for _, e := range edges {
+		deps[e.Source] = append(deps[e.Source], e.Target)
+	}
+
+	depth := make(map[string]int, len(nodes))
+	visiting := make(map[string]bool, len(nodes))
+	var visit func(id string) int
+	visit = func(id string) int {
+		if d, ok := depth[id]; ok {
+			return d
+		}
+		if visiting[id] {
+			return -1
+		}
+		visiting[id] = true
+		d := 0
+		for _, dep := range deps[id] {
+			if dd := visit(dep) + 1; dd > d {
+				d = dd
+			}
+		}
+		visiting[id] = false
+		depth[id] = d
+		return d
+	}
+
+	rows := make(map[int]int)
+	for i := range nodes {
+		col := visit(nodes[i].ID)
+		row := rows[col]
+		rows[col]++
+		nodes[i].Position = &model.Position{
+			X: float64(layoutOriginX + col*layoutColumnGap),
+			Y: float64(layoutOriginY + row*layoutRowGap),
+		}
+	}
+}
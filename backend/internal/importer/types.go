@@ -0,0 +1,274 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// composeFile is the subset of the Compose specification the importer reads.
+// Unrecognised top-level keys are collected in Extra so they can be reported.
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+	Extra    map[string]yaml.Node      `yaml:",inline"`
+}
+
+// composeService is a single Compose service. Fields accept every syntax the
+// Compose specification allows (short and long forms); anything else lands in
+// Extra and is reported as dropped.
+type composeService struct {
+	Image         string               `yaml:"image"`
+	Command       stringOrList         `yaml:"command"`
+	Entrypoint    stringOrList         `yaml:"entrypoint"`
+	Environment   envMap               `yaml:"environment"`
+	Ports         portList             `yaml:"ports"`
+	Expose        portList             `yaml:"expose"`
+	DependsOn     nameSet              `yaml:"depends_on"`
+	Links         []string             `yaml:"links"`
+	Healthcheck   *composeHealthcheck  `yaml:"healthcheck"`
+	Hostname      string               `yaml:"hostname"`
+	ContainerName string               `yaml:"container_name"`
+	Extra         map[string]yaml.Node `yaml:",inline"`
+}
+
+type composeHealthcheck struct {
+	Test        healthTest `yaml:"test"`
+	Interval    string     `yaml:"interval"`
+	Timeout     string     `yaml:"timeout"`
+	Retries     int        `yaml:"retries"`
+	StartPeriod string     `yaml:"start_period"`
+	Disable     bool       `yaml:"disable"`
+}
+
+// stringOrList accepts `command: echo hi` and `command: ["echo", "hi"]`.
+// The string form is split like a shell would, honouring quotes.
+type stringOrList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (s *stringOrList) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		words, err := shellSplit(value.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", value.Line, err)
+		}
+		*s = words
+		return nil
+	case yaml.SequenceNode:
+		var list []string
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+		*s = list
+		return nil
+	default:
+		return fmt.Errorf("line %d: expected a string or a list of strings", value.Line)
+	}
+}
+
+// healthTest accepts `test: curl -f x` (run through a shell) and the list form.
+type healthTest []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (h *healthTest) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		*h = []string{"CMD-SHELL", value.Value}
+		return nil
+	case yaml.SequenceNode:
+		var list []string
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+		*h = list
+		return nil
+	default:
+		return fmt.Errorf("line %d: healthcheck test must be a string or a list", value.Line)
+	}
+}
+
+// envMap accepts both the mapping and the `KEY=VALUE` list form. Keys listed
+// without a value take their value from the host at `docker compose up` time;
+// they cannot be imported and are recorded in Unset.
+type envMap struct {
+	Values map[string]string
+	Unset  []string
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (e *envMap) UnmarshalYAML(value *yaml.Node) error {
+	e.Values = map[string]string{}
+	switch value.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			k, v := value.Content[i].Value, value.Content[i+1]
+			if v.Tag == "!!null" {
+				e.Unset = append(e.Unset, k)
+				continue
+			}
+			e.Values[k] = v.Value
+		}
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			k, v, ok := strings.Cut(item.Value, "=")
+			if !ok {
+				e.Unset = append(e.Unset, k)
+				continue
+			}
+			e.Values[k] = v
+		}
+	default:
+		return fmt.Errorf("line %d: environment must be a mapping or a list", value.Line)
+	}
+	sort.Strings(e.Unset)
+	return nil
+}
+
+// portList collects container-side ports from the short ("8080:80/tcp",
+// "127.0.0.1:8080:80", "80") and long ({target: 80, published: 8080}) forms.
+// Entries that cannot be reduced to a single port, such as ranges, are kept
+// verbatim in Invalid.
+type portList struct {
+	Ports   []int
+	Invalid []string
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *portList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.SequenceNode {
+		return fmt.Errorf("line %d: ports must be a list", value.Line)
+	}
+	for _, item := range value.Content {
+		switch item.Kind {
+		case yaml.ScalarNode:
+			port, ok := containerPort(item.Value)
+			if !ok {
+				p.Invalid = append(p.Invalid, item.Value)
+				continue
+			}
+			p.Ports = append(p.Ports, port)
+		case yaml.MappingNode:
+			var long struct {
+				Target int `yaml:"target"`
+			}
+			if err := item.Decode(&long); err != nil || long.Target == 0 {
+				p.Invalid = append(p.Invalid, fmt.Sprintf("line %d", item.Line))
+				continue
+			}
+			p.Ports = append(p.Ports, long.Target)
+		default:
+			p.Invalid = append(p.Invalid, fmt.Sprintf("line %d", item.Line))
+		}
+	}
+	return nil
+}
+
+// containerPort extracts the container port from a short port mapping.
+func containerPort(spec string) (int, bool) {
+	spec, _, _ = strings.Cut(spec, "/")
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		spec = spec[i+1:]
+	}
+	port, err := strconv.Atoi(spec)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, false
+	}
+	return port, true
+}
+
+// nameSet accepts `depends_on` as a list of names or a mapping of name to
+// options. Names are stored sorted.
+type nameSet []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (n *nameSet) UnmarshalYAML(value *yaml.Node) error {
+	var names []string
+	switch value.Kind {
+	case yaml.SequenceNode:
+		if err := value.Decode(&names); err != nil {
+			return err
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(value.Content); i += 2 {
+			names = append(names, value.Content[i].Value)
+		}
+	default:
+		return fmt.Errorf("line %d: depends_on must be a list or a mapping", value.Line)
+	}
+	sort.Strings(names)
+	*n = names
+	return nil
+}
+
+// shellSplit splits s into words the way a POSIX shell would for simple
+// input: whitespace separates words, and single quotes, double quotes and
+// backslashes escape.
+func shellSplit(s string) ([]string, error) {
+	var (
+		words   []string
+		cur     strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				cur.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
+
+// parseSeconds converts a Compose duration ("10s", "1m30s") into whole
+// seconds. Empty strings yield zero.
+func parseSeconds(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return int(d / time.Second), nil
+}
@@ -0,0 +1,263 @@
+// Package metrics exposes the backend's own metrics in the Prometheus
+// exposition format: HTTP traffic, deploys, container start-up and Docker
+// API health.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+)
+
+// namespace prefixes every metric name.
+const namespace = "hephaestus"
+
+// Deploy outcomes, the values of the outcome label.
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+)
+
+// listTimeout bounds the container listing done for each scrape.
+const listTimeout = 2 * time.Second
+
+// containerStatuses are the statuses reported by the managed container
+// gauge, always all of them so that series drop to zero rather than vanish.
+var containerStatuses = []docker.ContainerStatus{
+	docker.StatusCreated,
+	docker.StatusRunning,
+	docker.StatusHealthy,
+	docker.StatusUnhealthy,
+	docker.StatusPaused,
+	docker.StatusStopped,
+	docker.StatusError,
+}
+
+// Metrics holds the backend's metrics and the registry they are served
+// from. It implements middleware.RequestObserver.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	deploys         *prometheus.CounterVec
+	deployDuration  *prometheus.HistogramVec
+	timeToHealthy   *prometheus.HistogramVec
+	imagePull       prometheus.Histogram
+	dockerAPIErrors *prometheus.CounterVec
+
+	mu sync.Mutex
+	// deployState is the last deployment state seen by ObserveDeployment.
+	deployState deploy.State
+	// starting maps started containers that have not been healthy yet to
+	// their service type and start time.
+	starting map[string]startingContainer
+	// serviceTypes maps created containers to their service type.
+	serviceTypes map[string]string
+}
+
+// startingContainer is a container waiting to turn healthy.
+type startingContainer struct {
+	serviceType string
+	startedAt   time.Time
+}
+
+// New creates Metrics with Go runtime and process metrics registered.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by method, route pattern and status code.",
+		}, []string{"method", "route", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time to serve HTTP requests, by method and route pattern. WebSocket connections are not included.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		deploys: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "deploys_total",
+			Help:      "Deploys that ran to completion, by outcome.",
+		}, []string{"outcome"}),
+		deployDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "deploy_duration_seconds",
+			Help:      "Time from the start of a deploy to its outcome, by outcome.",
+			Buckets:   []float64{1, 2, 5, 10, 20, 30, 60, 120, 300, 600},
+		}, []string{"outcome"}),
+		timeToHealthy: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "time_to_healthy_seconds",
+			Help:      "Time from a container starting to its first healthy check, by service type.",
+			Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120, 300},
+		}, []string{"service_type"}),
+		imagePull: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "image_pull_duration_seconds",
+			Help:      "Time to pull container images.",
+			Buckets:   []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 120, 300},
+		}),
+		dockerAPIErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "docker_api_errors_total",
+			Help:      "Failed Docker API calls, by operation.",
+		}, []string{"operation"}),
+		starting:     make(map[string]startingContainer),
+		serviceTypes: make(map[string]string),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.deploys,
+		m.deployDuration,
+		m.timeToHealthy,
+		m.imagePull,
+		m.dockerAPIErrors,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a served HTTP request.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	if status != http.StatusSwitchingProtocols {
+		m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+	}
+}
+
+// ObserveDeployment records a deploy when a status snapshot shows it
+// finishing. It matches deploy.Notifier.
+func (m *Metrics) ObserveDeployment(s deploy.Status) {
+	m.mu.Lock()
+	prev := m.deployState
+	m.deployState = s.State
+	m.mu.Unlock()
+
+	if prev != deploy.StateDeploying || s.State == deploy.StateDeploying {
+		return
+	}
+	outcome := OutcomeSucceeded
+	switch s.State {
+	case deploy.StateDeployed:
+	case deploy.StateFailed:
+		outcome = OutcomeFailed
+	default:
+		return
+	}
+	m.deploys.WithLabelValues(outcome).Inc()
+	if s.StartedAt != nil {
+		m.deployDuration.WithLabelValues(outcome).Observe(time.Since(*s.StartedAt).Seconds())
+	}
+}
+
+// ObserveEvent records image pulls and the time containers take to turn
+// healthy. It matches docker.EventHandler. Only containers labelled with
+// docker.LabelServiceType are timed.
+func (m *Metrics) ObserveEvent(e docker.Event) {
+	if e.Type == docker.EventImagePulled {
+		m.imagePull.Observe(e.Duration.Seconds())
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch e.Type {
+	case docker.EventContainerCreated:
+		if t := e.Labels[docker.LabelServiceType]; t != "" {
+			m.serviceTypes[e.ContainerID] = t
+		}
+	case docker.EventContainerStarted, docker.EventContainerRestarted:
+		if t, ok := m.serviceTypes[e.ContainerID]; ok {
+			m.starting[e.ContainerID] = startingContainer{serviceType: t, startedAt: e.At}
+		}
+	case docker.EventContainerStopped, docker.EventContainerKilled:
+		delete(m.starting, e.ContainerID)
+	case docker.EventContainerRemoved:
+		delete(m.starting, e.ContainerID)
+		delete(m.serviceTypes, e.ContainerID)
+	case docker.EventHealthChanged:
+		c, ok := m.starting[e.ContainerID]
+		if !ok || e.Health == nil || e.Health.To != docker.StatusHealthy {
+			return
+		}
+		delete(m.starting, e.ContainerID)
+		m.timeToHealthy.WithLabelValues(c.serviceType).Observe(e.At.Sub(c.startedAt).Seconds())
+	}
+}
+
+// ObserveDockerAPIError counts a failed Docker API call. It matches
+// docker.APIErrorHook.
+func (m *Metrics) ObserveDockerAPIError(operation string, _ error) {
+	m.dockerAPIErrors.WithLabelValues(operation).Inc()
+}
+
+// CountContainers reports the orchestrator's managed containers by status,
+// listing them on every scrape.
+func (m *Metrics) CountContainers(orch docker.Orchestrator) {
+	m.registry.MustRegister(&containerCollector{
+		orch: orch,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "managed_containers"),
+			"Managed containers, by status.",
+			[]string{"status"}, nil,
+		),
+	})
+}
+
+// CountWebSocketClients reports the number of connected WebSocket clients
+// given by count.
+func (m *Metrics) CountWebSocketClients(count func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_connections",
+		Help:      "Connected WebSocket clients.",
+	}, func() float64 { return float64(count()) }))
+}
+
+// containerCollector counts an orchestrator's containers at scrape time.
+type containerCollector struct {
+	orch docker.Orchestrator
+	desc *prometheus.Desc
+}
+
+func (c *containerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect lists the containers. When listing fails, no sample is reported
+// rather than a misleading zero.
+func (c *containerCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+	defer cancel()
+	infos, err := c.orch.ListContainers(ctx)
+	if err != nil {
+		return
+	}
+
+	counts := make(map[docker.ContainerStatus]int, len(containerStatuses))
+	for _, info := range infos {
+		counts[info.Status]++
+	}
+	for _, st := range containerStatuses {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(counts[st]), string(st))
+	}
+}
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+)
+
+// scrape returns the exposition served by m.
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	return rec.Body.String()
+}
+
+// expectSamples fails unless every sample line appears in the exposition.
+func expectSamples(t *testing.T, body string, samples ...string) {
+	t.Helper()
+	lines := make(map[string]bool)
+	for _, line := range strings.Split(body, "\n") {
+		lines[line] = true
+	}
+	for _, s := range samples {
+		if !lines[s] {
+			t.Errorf("expected sample %q in:\n%s", s, body)
+		}
+	}
+}
+
+func TestMetrics_Requests(t *testing.T) {
+	m := New()
+	m.ObserveRequest(http.MethodGet, "/api/diagrams/{id}", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/api/diagrams/{id}", http.StatusNotFound, 40*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/ws/status", http.StatusSwitchingProtocols, 0)
+
+	expectSamples(t, scrape(t, m),
+		`hephaestus_http_requests_total{code="200",method="GET",route="/api/diagrams/{id}"} 1`,
+		`hephaestus_http_requests_total{code="404",method="GET",route="/api/diagrams/{id}"} 1`,
+		`hephaestus_http_requests_total{code="101",method="GET",route="/ws/status"} 1`,
+		`hephaestus_http_request_duration_seconds_count{method="GET",route="/api/diagrams/{id}"} 2`,
+	)
+	if body := scrape(t, m); strings.Contains(body, `hephaestus_http_request_duration_seconds_count{method="GET",route="/ws/status"}`) {
+		t.Error("expected WebSocket connections to be left out of request durations")
+	}
+}
+
+func TestMetrics_Deploys(t *testing.T) {
+	m := New()
+	started := time.Now().Add(-3 * time.Second)
+
+	for _, s := range []deploy.Status{
+		{State: deploy.StateIdle},
+		{State: deploy.StateDeploying, StartedAt: &started},
+		{State: deploy.StateDeploying, StartedAt: &started},
+		{State: deploy.StateDeployed, StartedAt: &started},
+		// Health updates of the running deployment are not deploys.
+		{State: deploy.StateDeployed, StartedAt: &started},
+		{State: deploy.StateIdle},
+		{State: deploy.StateDeploying, StartedAt: &started},
+		{State: deploy.StateFailed, StartedAt: &started},
+	} {
+		m.ObserveDeployment(s)
+	}
+
+	expectSamples(t, scrape(t, m),
+		`hephaestus_deploys_total{outcome="succeeded"} 1`,
+		`hephaestus_deploys_total{outcome="failed"} 1`,
+		`hephaestus_deploy_duration_seconds_bucket{outcome="succeeded",le="2"} 0`,
+		`hephaestus_deploy_duration_seconds_bucket{outcome="succeeded",le="5"} 1`,
+	)
+}
+
+func TestMetrics_Events(t *testing.T) {
+	m := New()
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	health := func(id string, to docker.ContainerStatus, after time.Duration) docker.Event {
+		return docker.Event{Type: docker.EventHealthChanged, At: at.Add(after), ContainerID: id,
+			Health: &docker.HealthTransition{ContainerID: id, To: to}}
+	}
+
+	for _, e := range []docker.Event{
+		{Type: docker.EventImagePulled, Image: "postgres:16", Duration: 4 * time.Second},
+		{Type: docker.EventContainerCreated, ContainerID: "db", Labels: map[string]string{docker.LabelServiceType: "postgresql"}},
+		{Type: docker.EventContainerCreated, ContainerID: "k6"},
+		{Type: docker.EventContainerStarted, At: at, ContainerID: "db"},
+		{Type: docker.EventContainerStarted, At: at, ContainerID: "k6"},
+		health("db", docker.StatusRunning, time.Second),
+		health("db", docker.StatusHealthy, 3*time.Second),
+		// Only the first healthy check after a start counts.
+		health("db", docker.StatusUnhealthy, 4*time.Second),
+		health("db", docker.StatusHealthy, 5*time.Second),
+		health("k6", docker.StatusHealthy, time.Second),
+	} {
+		m.ObserveEvent(e)
+	}
+
+	body := scrape(t, m)
+	expectSamples(t, body,
+		`hephaestus_image_pull_duration_seconds_sum 4`,
+		`hephaestus_time_to_healthy_seconds_count{service_type="postgresql"} 1`,
+		`hephaestus_time_to_healthy_seconds_sum{service_type="postgresql"} 3`,
+	)
+	if strings.Contains(body, `service_type=""`) {
+		t.Error("expected containers without a service type to be left out")
+	}
+}
+
+func TestMetrics_DockerAPIErrors(t *testing.T) {
+	m := New()
+	m.ObserveDockerAPIError("ContainerInspect", errors.New("boom"))
+	m.ObserveDockerAPIError("ContainerInspect", errors.New("boom"))
+
+	expectSamples(t, scrape(t, m), `hephaestus_docker_api_errors_total{operation="ContainerInspect"} 2`)
+}
+
+func TestMetrics_Gauges(t *testing.T) {
+	ctx := context.Background()
+	sim := docker.NewSimulatedOrchestrator(docker.SimulationConfig{})
+	_ = sim.CreateNetwork(ctx)
+	a, _ := sim.CreateContainer(ctx, docker.ContainerConfig{Name: "a"})
+	_ = sim.StartContainer(ctx, a)
+	b, _ := sim.CreateContainer(ctx, docker.ContainerConfig{Name: "b"})
+	_ = sim.StartContainer(ctx, b)
+	_ = sim.PauseContainer(ctx, b)
+	_, _ = sim.CreateContainer(ctx, docker.ContainerConfig{Name: "c"})
+
+	m := New()
+	m.CountContainers(sim)
+	m.CountWebSocketClients(func() int { return 3 })
+
+	expectSamples(t, scrape(t, m),
+		`hephaestus_managed_containers{status="running"} 1`,
+		`hephaestus_managed_containers{status="paused"} 1`,
+		`hephaestus_managed_containers{status="created"} 1`,
+		`hephaestus_managed_containers{status="healthy"} 0`,
+		`hephaestus_websocket_connections 3`,
+	)
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// UnmatchedRoute is the route reported for requests no mux pattern served,
+// such as unknown paths and CORS preflights.
+const UnmatchedRoute = "unmatched"
+
+// RequestObserver records served requests.
+type RequestObserver interface {
+	// ObserveRequest records a request to route that was answered with
+	// status after duration. Requests upgraded to another protocol, such as
+	// WebSocket connections, report http.StatusSwitchingProtocols and no
+	// duration.
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// Instrument returns middleware that reports every request to observer.
+// The route is the path of the http.ServeMux pattern that served the
+// request, such as /api/diagrams/{id}, so requests group by route rather
+// than by concrete path; it must wrap the mux.
+func Instrument(observer RequestObserver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+
+			if sw.hijacked {
+				observer.ObserveRequest(r.Method, route(r), http.StatusSwitchingProtocols, 0)
+				return
+			}
+			observer.ObserveRequest(r.Method, route(r), sw.status, time.Since(start))
+		})
+	}
+}
+
+// route returns the path of the pattern that served r.
+func route(r *http.Request) string {
+	if r.Pattern == "" {
+		return UnmatchedRoute
+	}
+	// Patterns may start with a method: "GET /api/diagrams/{id}".
+	if _, path, ok := strings.Cut(r.Pattern, " "); ok {
+		return path
+	}
+	return r.Pattern
+}
+
+// statusWriter records the status written through it. It passes hijacking
+// through so WebSocket upgrades still work.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
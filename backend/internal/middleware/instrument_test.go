@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// observation is one request reported to recordingObserver.
+type observation struct {
+	method, route string
+	status        int
+	duration      time.Duration
+}
+
+type recordingObserver struct {
+	observed []observation
+}
+
+func (o *recordingObserver) ObserveRequest(method, route string, status int, duration time.Duration) {
+	o.observed = append(o.observed, observation{method, route, status, duration})
+}
+
+func TestInstrument_ReportsRoutePatterns(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/diagrams/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	observer := &recordingObserver{}
+	handler := Instrument(observer)(CORS()(mux))
+
+	for _, target := range []string{"/api/diagrams/abc", "/plain", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodOptions, "/api/diagrams/abc", nil))
+
+	want := []observation{
+		{http.MethodGet, "/api/diagrams/{id}", http.StatusNotFound, 0},
+		{http.MethodGet, "/plain", http.StatusOK, 0},
+		{http.MethodGet, UnmatchedRoute, http.StatusNotFound, 0},
+		{http.MethodOptions, UnmatchedRoute, http.StatusNoContent, 0},
+	}
+	if len(observer.observed) != len(want) {
+		t.Fatalf("expected %d observations, got %+v", len(want), observer.observed)
+	}
+	for i, w := range want {
+		got := observer.observed[i]
+		if got.method != w.method || got.route != w.route || got.status != w.status {
+			t.Errorf("observation %d: got %+v, want %+v", i, got, w)
+		}
+		if got.duration <= 0 {
+			t.Errorf("observation %d: expected a duration, got %v", i, got.duration)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package mockserver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq" // registers the "postgres" database/sql driver
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/openapi"
+)
+
+// Downstream call settings. HTTP calls time out after httpTimeout, Redis
+// keys expire after redisKeyTTL, and queues hold at most queueMaxLength
+// messages, since nothing consumes them.
+const (
+	httpTimeout    = 10 * time.Second
+	redisKeyPrefix = "heph:"
+	redisKeyTTL    = time.Minute
+	queueMaxLength = 10000
+)
+
+// Clients makes downstream calls, keeping one connection pool per target
+// URL so that calls under load reuse connections.
+type Clients struct {
+	http *http.Client
+
+	mu     sync.Mutex
+	dbs    map[string]*sql.DB
+	redis  map[string]*redis.Client
+	queues map[string]*publisher
+}
+
+// NewClients creates an empty set of clients.
+func NewClients() *Clients {
+	return &Clients{
+		http:   &http.Client{Timeout: httpTimeout},
+		dbs:    make(map[string]*sql.DB),
+		redis:  make(map[string]*redis.Client),
+		queues: make(map[string]*publisher),
+	}
+}
+
+// Call makes one downstream call: a request to HTTP services, SELECT 1 on
+// PostgreSQL, a SET then GET on Redis and a publish to RabbitMQ. HTTP
+// statuses of 400 and above are errors.
+func (c *Clients) Call(ctx context.Context, d openapi.Downstream) error {
+	switch d.Type {
+	case model.ServiceTypePostgreSQL:
+		db, err := c.db(d.URL)
+		if err != nil {
+			return err
+		}
+		_, err = db.ExecContext(ctx, "SELECT 1")
+		return err
+	case model.ServiceTypeRedis:
+		client, err := c.redisClient(d.URL)
+		if err != nil {
+			return err
+		}
+		key := redisKeyPrefix + d.Key
+		if err := client.Set(ctx, key, time.Now().UnixNano(), redisKeyTTL).Err(); err != nil {
+			return err
+		}
+		return client.Get(ctx, key).Err()
+	case model.ServiceTypeRabbitMQ:
+		return c.publisher(d.URL).publish(ctx, d.Key, []byte(`{}`))
+	default:
+		return c.request(ctx, d)
+	}
+}
+
+// request sends an HTTP request, carrying the trace context of ctx if any.
+// Methods that usually carry a body send an empty JSON object.
+func (c *Clients) request(ctx context.Context, d openapi.Downstream) error {
+	var body io.Reader
+	switch d.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+	default:
+		body = strings.NewReader("{}")
+	}
+	req, err := http.NewRequestWithContext(ctx, d.Method, d.URL, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if sc, ok := spanFromContext(ctx); ok {
+		req.Header.Set(traceparentHeader, sc.traceparent())
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s %s: status %d", d.Method, d.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// db returns the pool for a postgres:// URL. The deployed database does not
+// use TLS, so sslmode defaults to disable.
+func (c *Clients) db(rawURL string) (*sql.DB, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if db, ok := c.dbs[rawURL]; ok {
+		return db, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse postgres url: %w", err)
+	}
+	q := u.Query()
+	if q.Get("sslmode") == "" {
+		q.Set("sslmode", "disable")
+		u.RawQuery = q.Encode()
+	}
+	db, err := sql.Open("postgres", u.String())
+	if err != nil {
+		return nil, err
+	}
+	c.dbs[rawURL] = db
+	return db, nil
+}
+
+// redisClient returns the client for a redis:// URL.
+func (c *Clients) redisClient(rawURL string) (*redis.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.redis[rawURL]; ok {
+		return client, nil
+	}
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+	client := redis.NewClient(opts)
+	c.redis[rawURL] = client
+	return client, nil
+}
+
+// publisher returns the publisher for an amqp:// URL.
+func (c *Clients) publisher(rawURL string) *publisher {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.queues[rawURL]
+	if !ok {
+		p = &publisher{url: rawURL}
+		c.queues[rawURL] = p
+	}
+	return p
+}
+
+// Close closes every pool and connection.
+func (c *Clients) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, db := range c.dbs {
+		_ = db.Close()
+	}
+	for _, client := range c.redis {
+		_ = client.Close()
+	}
+	for _, p := range c.queues {
+		p.close()
+	}
+	c.http.CloseIdleConnections()
+}
+
+// publisher publishes to RabbitMQ over one channel, reconnecting after the
+// connection or channel is lost.
+type publisher struct {
+	url string
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	ch       *amqp.Channel
+	declared map[string]bool
+}
+
+// publish sends body to queue through the default exchange, declaring the
+// queue on first use.
+func (p *publisher) publish(ctx context.Context, queue string, body []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.connect(); err != nil {
+		return err
+	}
+	if !p.declared[queue] {
+		args := amqp.Table{"x-max-length": queueMaxLength}
+		if _, err := p.ch.QueueDeclare(queue, false, false, false, false, args); err != nil {
+			p.reset()
+			return fmt.Errorf("declare queue %q: %w", queue, err)
+		}
+		p.declared[queue] = true
+	}
+	err := p.ch.PublishWithContext(ctx, "", queue, false, false, amqp.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+	})
+	if err != nil {
+		p.reset()
+		return fmt.Errorf("publish to %q: %w", queue, err)
+	}
+	return nil
+}
+
+// connect opens the connection and channel unless both are open.
+func (p *publisher) connect() error {
+	if p.conn != nil && !p.conn.IsClosed() && p.ch != nil && !p.ch.IsClosed() {
+		return nil
+	}
+	p.reset()
+	conn, err := amqp.Dial(p.url)
+	if err != nil {
+		return fmt.Errorf("dial rabbitmq: %w", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("open rabbitmq channel: %w", err)
+	}
+	p.conn, p.ch, p.declared = conn, ch, make(map[string]bool)
+	return nil
+}
+
+// reset closes the connection so the next publish reconnects.
+func (p *publisher) reset() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	p.conn, p.ch = nil, nil
+}
+
+// close closes the connection.
+func (p *publisher) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reset()
+}
@@ -0,0 +1,69 @@
+package mockserver
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// z99 is the standard normal quantile of the 99th percentile, used to fit
+// a lognormal distribution to its median and p99.
+const z99 = 2.3263478740408408
+
+// timeoutHold is how long a request picked to time out is held before the
+// server gives up and answers 504. Clients normally give up first.
+const timeoutHold = 60 * time.Second
+
+// source is the randomness faults are drawn from. *rand.Rand satisfies it,
+// which lets tests use a seeded source.
+type source interface {
+	Float64() float64
+	NormFloat64() float64
+	IntN(n int) int
+}
+
+// globalSource draws from math/rand/v2's global, concurrency-safe source.
+type globalSource struct{}
+
+func (globalSource) Float64() float64     { return rand.Float64() }
+func (globalSource) NormFloat64() float64 { return rand.NormFloat64() }
+func (globalSource) IntN(n int) int       { return rand.IntN(n) }
+
+// sampleLatency draws one delay from l. Normal samples below zero count as
+// zero, and every sample is capped at model.MaxSimulatedLatencyMs.
+func sampleLatency(l *model.Latency, r source) time.Duration {
+	if l == nil {
+		return 0
+	}
+	var ms float64
+	switch l.Distribution {
+	case model.LatencyFixed:
+		ms = l.ValueMs
+	case model.LatencyUniform:
+		ms = l.MinMs + r.Float64()*(l.MaxMs-l.MinMs)
+	case model.LatencyNormal:
+		ms = l.MeanMs + r.NormFloat64()*l.StdDevMs
+	case model.LatencyLognormal:
+		if l.P50Ms <= 0 {
+			return 0
+		}
+		sigma := math.Log(max(l.P99Ms, l.P50Ms)/l.P50Ms) / z99
+		ms = l.P50Ms * math.Exp(sigma*r.NormFloat64())
+	}
+	ms = min(max(ms, 0), model.MaxSimulatedLatencyMs)
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// injectedStatus returns the status of an injected error, or 0 when the
+// request should succeed.
+func injectedStatus(e *model.ErrorInjection, r source) int {
+	if e == nil || e.Rate <= 0 || r.Float64() >= e.Rate {
+		return 0
+	}
+	if len(e.StatusCodes) == 0 {
+		return 500
+	}
+	return e.StatusCodes[r.IntN(len(e.StatusCodes))]
+}
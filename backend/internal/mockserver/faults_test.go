@@ -0,0 +1,77 @@
+package mockserver
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// samples draws n latencies from l, sorted.
+func samples(l *model.Latency, n int) []time.Duration {
+	r := rand.New(rand.NewPCG(1, 2))
+	out := make([]time.Duration, n)
+	for i := range out {
+		out[i] = sampleLatency(l, r)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// within reports whether got is within 10% of want.
+func within(got, want time.Duration) bool {
+	diff := got - want
+	return diff >= -want/10 && diff <= want/10
+}
+
+func TestSampleLatency_Distributions(t *testing.T) {
+	const n = 20000
+	if got := samples(&model.Latency{Distribution: model.LatencyFixed, ValueMs: 25}, 10); got[0] != 25*time.Millisecond || got[9] != 25*time.Millisecond {
+		t.Errorf("fixed: expected 25ms, got %v..%v", got[0], got[9])
+	}
+	if got := samples(nil, 1); got[0] != 0 {
+		t.Errorf("nil: expected no latency, got %v", got[0])
+	}
+
+	uniform := samples(&model.Latency{Distribution: model.LatencyUniform, MinMs: 10, MaxMs: 50}, n)
+	if uniform[0] < 10*time.Millisecond || uniform[n-1] > 50*time.Millisecond || !within(uniform[n/2], 30*time.Millisecond) {
+		t.Errorf("uniform: expected 10-50ms around 30ms, got %v..%v, median %v", uniform[0], uniform[n-1], uniform[n/2])
+	}
+
+	normal := samples(&model.Latency{Distribution: model.LatencyNormal, MeanMs: 100, StdDevMs: 20}, n)
+	if !within(normal[n/2], 100*time.Millisecond) || !within(normal[n*84/100], 120*time.Millisecond) {
+		t.Errorf("normal: expected median 100ms and p84 120ms, got %v and %v", normal[n/2], normal[n*84/100])
+	}
+	if clamped := samples(&model.Latency{Distribution: model.LatencyNormal, MeanMs: 1, StdDevMs: 50}, 1000); clamped[0] != 0 {
+		t.Errorf("normal: expected negative samples to count as zero, got %v", clamped[0])
+	}
+
+	lognormal := samples(&model.Latency{Distribution: model.LatencyLognormal, P50Ms: 20, P99Ms: 400}, n)
+	if !within(lognormal[n/2], 20*time.Millisecond) || !within(lognormal[n*99/100], 400*time.Millisecond) {
+		t.Errorf("lognormal: expected p50 20ms and p99 400ms, got %v and %v", lognormal[n/2], lognormal[n*99/100])
+	}
+}
+
+func TestInjectedStatus(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	e := &model.ErrorInjection{Rate: 0.25, StatusCodes: []int{500, 503}}
+	counts := map[int]int{}
+	for range 10000 {
+		counts[injectedStatus(e, r)]++
+	}
+	if failed := counts[500] + counts[503]; failed < 2300 || failed > 2700 {
+		t.Errorf("expected about 2500 failures at rate 0.25, got %d", failed)
+	}
+	if counts[500] == 0 || counts[503] == 0 || len(counts) != 3 {
+		t.Errorf("expected both status codes and successes, got %v", counts)
+	}
+
+	if got := injectedStatus(&model.ErrorInjection{Rate: 1}, r); got != 500 {
+		t.Errorf("expected 500 without status codes, got %d", got)
+	}
+	if got := injectedStatus(nil, r); got != 0 {
+		t.Errorf("expected no error without injection, got %d", got)
+	}
+}
@@ -0,0 +1,138 @@
+package mockserver
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/openapi"
+)
+
+// namespace prefixes every metric name.
+const namespace = "mockserver"
+
+// unmatchedRoute is the route reported for requests no operation matched.
+const unmatchedRoute = "unmatched"
+
+// statusClientClosed is reported for requests the client gave up on
+// before any response was written, such as simulated timeouts.
+const statusClientClosed = 499
+
+// Downstream call outcomes, the values of the outcome label.
+const (
+	outcomeOK    = "ok"
+	outcomeError = "error"
+)
+
+// Metrics records the requests a Server serves and the downstream calls
+// it makes, for Prometheus to scrape.
+type Metrics struct {
+	registry   *prometheus.Registry
+	requests   *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	downstream *prometheus.CounterVec
+}
+
+// NewMetrics creates Metrics with Go runtime and process metrics
+// registered.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Requests served, by method, operation path and status code.",
+		}, []string{"method", "route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Time to serve requests, by method and operation path.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		downstream: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "downstream_calls_total",
+			Help:      "Calls made to downstream services, by target and outcome.",
+		}, []string{"target", "outcome"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.downstream,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Instrument returns s as a handler that records every request. Requests
+// are grouped by the path template of the operation they matched, such as
+// /users/{id}.
+func (m *Metrics) Instrument(s *Server) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := unmatchedRoute
+		if rt, ok := s.route(r.Method, r.URL.Path); ok {
+			route = rt.path
+		}
+
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: statusClientClosed}
+		s.ServeHTTP(sw, r)
+
+		m.requests.WithLabelValues(r.Method, route, strconv.Itoa(sw.status)).Inc()
+		m.duration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Caller returns caller with every call it makes counted.
+func (m *Metrics) Caller(caller Caller) Caller {
+	return &countingCaller{Caller: caller, calls: m.downstream}
+}
+
+// countingCaller counts the outcome of each downstream call.
+type countingCaller struct {
+	Caller
+	calls *prometheus.CounterVec
+}
+
+func (c *countingCaller) Call(ctx context.Context, d openapi.Downstream) error {
+	err := c.Caller.Call(ctx, d)
+	outcome := outcomeOK
+	if err != nil {
+		outcome = outcomeError
+	}
+	c.calls.WithLabelValues(d.Target, outcome).Inc()
+	return err
+}
+
+// statusWriter records the status of the response written through it.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
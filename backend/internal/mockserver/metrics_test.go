@@ -0,0 +1,55 @@
+package mockserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/openapi"
+)
+
+func TestMetrics_InstrumentsRequestsAndCalls(t *testing.T) {
+	spec, err := openapi.GenerateMockSpec([]model.Endpoint{
+		{Method: "GET", Path: "/users/{id}"},
+		{Method: "POST", Path: "/orders"},
+	}, "Test", [][]openapi.Downstream{
+		{{Target: "db", Type: model.ServiceTypePostgreSQL}},
+		{{Target: "fail", Type: model.ServiceTypeAPIService}},
+	})
+	if err != nil {
+		t.Fatalf("GenerateMockSpec: %v", err)
+	}
+	m := NewMetrics()
+	s, err := New(spec, m.Caller(&fakeCaller{}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	handler := m.Instrument(s)
+
+	for _, req := range [][2]string{
+		{http.MethodGet, "/users/1"},
+		{http.MethodGet, "/users/2"},
+		{http.MethodPost, "/orders"},
+		{http.MethodGet, "/missing"},
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req[0], req[1], nil))
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, sample := range []string{
+		`mockserver_requests_total{code="200",method="GET",route="/users/{id}"} 2`,
+		`mockserver_requests_total{code="502",method="POST",route="/orders"} 1`,
+		`mockserver_requests_total{code="404",method="GET",route="unmatched"} 1`,
+		`mockserver_request_duration_seconds_count{method="GET",route="/users/{id}"} 2`,
+		`mockserver_downstream_calls_total{outcome="ok",target="db"} 2`,
+		`mockserver_downstream_calls_total{outcome="error",target="fail"} 1`,
+	} {
+		if !strings.Contains(body, sample+"\n") {
+			t.Errorf("expected sample %q in:\n%s", sample, body)
+		}
+	}
+}
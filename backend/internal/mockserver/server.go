@@ -0,0 +1,166 @@
+// Package mockserver serves fake responses for an OpenAPI spec generated by
+// the openapi package. Unlike a plain mock, each operation first calls the
+// downstream services listed in its x-heph-downstream extension, so load
+// sent to one api-service propagates through the deployed topology, and
+// operations can be made slow or unreliable through the x-heph-latency,
+// x-heph-errors and x-heph-timeout-rate extensions.
+package mockserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/openapi"
+)
+
+// Caller makes downstream calls.
+type Caller interface {
+	Call(ctx context.Context, d openapi.Downstream) error
+}
+
+// Server serves the operations of a spec. The spec can be replaced while
+// serving with Reload or Watch.
+type Server struct {
+	routes atomic.Pointer[[]route]
+	caller Caller
+	rand   source
+	// hold is how long requests picked to time out are held.
+	hold time.Duration
+}
+
+// New creates a Server for spec that makes downstream calls through caller.
+func New(spec []byte, caller Caller) (*Server, error) {
+	s := &Server{caller: caller, rand: globalSource{}, hold: timeoutHold}
+	if err := s.Reload(spec); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload replaces the served spec. Requests already in flight finish with
+// the spec they started with. On error the current spec is kept.
+func (s *Server) Reload(spec []byte) error {
+	routes, err := parseRoutes(spec)
+	if err != nil {
+		return err
+	}
+	s.routes.Store(&routes)
+	return nil
+}
+
+// Watch reloads the spec at path whenever its content changes, checking
+// every interval until ctx is done. A spec that fails to parse, such as
+// one read halfway through being rewritten, is retried on the next check.
+func (s *Server) Watch(ctx context.Context, path string, interval time.Duration) {
+	last, _ := os.ReadFile(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		spec, err := os.ReadFile(path)
+		if err != nil || bytes.Equal(spec, last) {
+			continue
+		}
+		if err := s.Reload(spec); err != nil {
+			log.Printf("mockserver: reload %s: %v", path, err)
+			continue
+		}
+		last = spec
+		log.Printf("mockserver: reloaded %s", path)
+	}
+}
+
+// errorResponse is the JSON body of error responses.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP simulates the matching operation. A request picked to time out
+// is held until the client gives up. Otherwise the server waits the
+// simulated latency, answers an injected error if one is picked, and then
+// calls the operation's downstream services in order before answering with
+// its example response. Unknown routes get a 404 and a failed downstream
+// call a 502; remaining calls are skipped.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.Copy(io.Discard, r.Body)
+
+	rt, ok := s.route(r.Method, r.URL.Path)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("no operation for %s %s", r.Method, r.URL.Path)})
+		return
+	}
+
+	if rt.timeoutRate > 0 && s.rand.Float64() < rt.timeoutRate {
+		if wait(r.Context(), s.hold) {
+			writeJSON(w, http.StatusGatewayTimeout, errorResponse{Error: "simulated timeout"})
+		}
+		return
+	}
+	if !wait(r.Context(), sampleLatency(rt.latency, s.rand)) {
+		return
+	}
+	if status := injectedStatus(rt.errors, s.rand); status != 0 {
+		writeJSON(w, status, errorResponse{Error: "simulated error"})
+		return
+	}
+
+	for _, d := range rt.downstream {
+		if err := s.caller.Call(r.Context(), d); err != nil {
+			writeJSON(w, http.StatusBadGateway, errorResponse{Error: fmt.Sprintf("call %s: %v", d.Target, err)})
+			return
+		}
+	}
+
+	if rt.body == nil {
+		w.WriteHeader(rt.status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(rt.status)
+	_, _ = w.Write(rt.body)
+}
+
+// route returns the first route matching method and path.
+func (s *Server) route(method, path string) (route, bool) {
+	segments := splitPath(path)
+	for _, rt := range *s.routes.Load() {
+		if rt.match(method, segments) {
+			return rt, true
+		}
+	}
+	return route{}, false
+}
+
+// wait sleeps for d and reports whether it did so without ctx ending.
+func wait(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// writeJSON writes v as a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,240 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/openapi"
+)
+
+// fakeCaller records downstream calls and fails calls to the target fail.
+type fakeCaller struct {
+	calls []string
+}
+
+func (f *fakeCaller) Call(_ context.Context, d openapi.Downstream) error {
+	f.calls = append(f.calls, d.Target)
+	if d.Target == "fail" {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// newTestServer generates a spec for endpoints with the given downstream
+// calls and serves it.
+func newTestServer(t *testing.T, endpoints []model.Endpoint, downstream [][]openapi.Downstream) (*Server, *fakeCaller) {
+	t.Helper()
+	spec, err := openapi.GenerateMockSpec(endpoints, "Test", downstream)
+	if err != nil {
+		t.Fatalf("GenerateMockSpec: %v", err)
+	}
+	caller := &fakeCaller{}
+	s, err := New(spec, caller)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return s, caller
+}
+
+func serve(s *Server, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestServer_ExampleResponses(t *testing.T) {
+	s, _ := newTestServer(t, []model.Endpoint{
+		{Method: "GET", Path: "/users/{id}", ResponseSchema: `{"type":"object","properties":{"id":{"type":"integer"},"name":{"type":"string","example":"Ada"},"tags":{"type":"array","items":{"type":"string"}},"role":{"enum":["admin","user"]}}}`},
+		{Method: "GET", Path: "/users/me", ResponseSchema: `{"type":"object","properties":{"me":{"type":"boolean"}}}`},
+		{Method: "POST", Path: "/echo", ResponseSchema: "plain text"},
+	}, nil)
+
+	rec := serve(s, http.MethodGet, "/users/42")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	var user map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &user); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if user["id"] != float64(0) || user["name"] != "Ada" || user["role"] != "admin" {
+		t.Errorf("unexpected example %v", user)
+	}
+	if tags, ok := user["tags"].([]any); !ok || len(tags) != 1 || tags[0] != "string" {
+		t.Errorf("expected one example tag, got %v", user["tags"])
+	}
+
+	if rec := serve(s, http.MethodGet, "/users/me"); rec.Body.String() != `{"me":true}` {
+		t.Errorf("expected the literal path to win over the template, got %s", rec.Body.String())
+	}
+	if rec := serve(s, http.MethodPost, "/echo/"); rec.Body.String() != `"plain text"` {
+		t.Errorf("expected the string example, got %s", rec.Body.String())
+	}
+}
+
+func TestServer_UnknownRoute(t *testing.T) {
+	s, caller := newTestServer(t, []model.Endpoint{{Method: "GET", Path: "/users"}}, nil)
+
+	for _, tc := range []struct{ method, target string }{
+		{http.MethodGet, "/orders"},
+		{http.MethodPost, "/users"},
+		{http.MethodGet, "/users/1"},
+	} {
+		if rec := serve(s, tc.method, tc.target); rec.Code != http.StatusNotFound {
+			t.Errorf("%s %s: got %d, want %d", tc.method, tc.target, rec.Code, http.StatusNotFound)
+		}
+	}
+	if len(caller.calls) != 0 {
+		t.Errorf("expected no downstream calls, got %v", caller.calls)
+	}
+}
+
+func TestServer_CallsDownstreamInOrder(t *testing.T) {
+	s, caller := newTestServer(t, []model.Endpoint{{Method: "GET", Path: "/orders"}}, [][]openapi.Downstream{{
+		{Target: "users", Type: model.ServiceTypeAPIService},
+		{Target: "main-db", Type: model.ServiceTypePostgreSQL},
+		{Target: "cache", Type: model.ServiceTypeRedis},
+	}})
+
+	if rec := serve(s, http.MethodGet, "/orders"); rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	want := []string{"users", "main-db", "cache"}
+	if len(caller.calls) != len(want) {
+		t.Fatalf("expected calls %v, got %v", want, caller.calls)
+	}
+	for i := range want {
+		if caller.calls[i] != want[i] {
+			t.Errorf("call %d: got %q, want %q", i, caller.calls[i], want[i])
+		}
+	}
+}
+
+func TestServer_DownstreamFailure(t *testing.T) {
+	s, caller := newTestServer(t, []model.Endpoint{{Method: "GET", Path: "/orders"}}, [][]openapi.Downstream{{
+		{Target: "fail", Type: model.ServiceTypeAPIService},
+		{Target: "cache", Type: model.ServiceTypeRedis},
+	}})
+
+	rec := serve(s, http.MethodGet, "/orders")
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusBadGateway)
+	}
+	var body errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error != "call fail: connection refused" {
+		t.Errorf("unexpected error body %s", rec.Body.String())
+	}
+	if len(caller.calls) != 1 {
+		t.Errorf("expected calls to stop at the failure, got %v", caller.calls)
+	}
+}
+
+func TestServer_InjectedErrors(t *testing.T) {
+	s, caller := newTestServer(t, []model.Endpoint{{
+		Method: "GET",
+		Path:   "/orders",
+		Errors: &model.ErrorInjection{Rate: 1, StatusCodes: []int{503}},
+	}}, [][]openapi.Downstream{{{Target: "cache", Type: model.ServiceTypeRedis}}})
+
+	rec := serve(s, http.MethodGet, "/orders")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if len(caller.calls) != 0 {
+		t.Errorf("expected an injected error to skip downstream calls, got %v", caller.calls)
+	}
+}
+
+func TestServer_Latency(t *testing.T) {
+	s, _ := newTestServer(t, []model.Endpoint{{
+		Method:  "GET",
+		Path:    "/slow",
+		Latency: &model.Latency{Distribution: model.LatencyFixed, ValueMs: 50},
+	}}, nil)
+
+	start := time.Now()
+	if rec := serve(s, http.MethodGet, "/slow"); rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected at least 50ms of latency, took %v", elapsed)
+	}
+}
+
+func TestServer_Timeouts(t *testing.T) {
+	s, caller := newTestServer(t, []model.Endpoint{{Method: "GET", Path: "/hang", TimeoutRate: 1}}, [][]openapi.Downstream{{{Target: "users"}}})
+	s.hold = 20 * time.Millisecond
+
+	if rec := serve(s, http.MethodGet, "/hang"); rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+
+	// A client that gives up first gets no response at all.
+	s.hold = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hang", nil).WithContext(ctx))
+	if time.Since(start) > 5*time.Second || rec.Body.Len() != 0 {
+		t.Errorf("expected the request to be dropped when the client gave up, got %d %q", rec.Code, rec.Body.String())
+	}
+	if len(caller.calls) != 0 {
+		t.Errorf("expected timeouts to skip downstream calls, got %v", caller.calls)
+	}
+}
+
+func TestServer_WatchReloadsSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.json")
+	write := func(endpoints []model.Endpoint) {
+		spec, err := openapi.GenerateSpec(endpoints, "Watched")
+		if err != nil {
+			t.Fatalf("GenerateSpec: %v", err)
+		}
+		if err := os.WriteFile(path, spec, 0o644); err != nil {
+			t.Fatalf("write spec: %v", err)
+		}
+	}
+	write([]model.Endpoint{{Method: "GET", Path: "/orders"}})
+	spec, _ := os.ReadFile(path)
+	s, err := New(spec, &fakeCaller{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Watch(ctx, path, 10*time.Millisecond)
+
+	// A half-written spec is skipped and the current one kept.
+	if err := os.WriteFile(path, []byte(`{"paths":`), 0o644); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if rec := serve(s, http.MethodGet, "/orders"); rec.Code != http.StatusOK {
+		t.Fatalf("expected the current spec to be kept, got %d", rec.Code)
+	}
+
+	write([]model.Endpoint{{Method: "GET", Path: "/orders", Errors: &model.ErrorInjection{Rate: 1, StatusCodes: []int{429}}}})
+	deadline := time.Now().Add(5 * time.Second)
+	for serve(s, http.MethodGet, "/orders").Code != http.StatusTooManyRequests {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the rewritten spec to be served")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNew_InvalidSpec(t *testing.T) {
+	if _, err := New([]byte("not json"), &fakeCaller{}); err == nil {
+		t.Error("expected error for an invalid spec")
+	}
+}
@@ -0,0 +1,204 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/openapi"
+)
+
+// maxExampleDepth bounds recursion when building examples from schemas.
+const maxExampleDepth = 10
+
+// document is the subset of an OpenAPI document the mock server reads.
+type document struct {
+	Paths map[string]map[string]operation `json:"paths"`
+}
+
+// operation is one method of a path.
+type operation struct {
+	Responses   map[string]response   `json:"responses"`
+	Downstream  []openapi.Downstream  `json:"x-heph-downstream"`
+	Latency     *model.Latency        `json:"x-heph-latency"`
+	Errors      *model.ErrorInjection `json:"x-heph-errors"`
+	TimeoutRate float64               `json:"x-heph-timeout-rate"`
+}
+
+// response is a documented response of an operation.
+type response struct {
+	Content map[string]struct {
+		Schema json.RawMessage `json:"schema"`
+	} `json:"content"`
+}
+
+// route is an operation ready to serve.
+type route struct {
+	method string
+	// path is the spec's path template, such as /users/{id}.
+	path string
+	// segments are the path's segments; a "{param}" segment matches any
+	// value.
+	segments   []string
+	params     int
+	status     int
+	body       []byte
+	downstream []openapi.Downstream
+
+	latency     *model.Latency
+	errors      *model.ErrorInjection
+	timeoutRate float64
+}
+
+// parseRoutes builds the routes of an OpenAPI document. Routes with fewer
+// path parameters come first, so literal paths win over templates.
+func parseRoutes(spec []byte) ([]route, error) {
+	var doc document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+
+	var routes []route
+	for path, item := range doc.Paths {
+		segments := splitPath(path)
+		params := 0
+		for _, seg := range segments {
+			if isParam(seg) {
+				params++
+			}
+		}
+		for method, op := range item {
+			status, body, err := op.example()
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			routes = append(routes, route{
+				method:      strings.ToUpper(method),
+				path:        path,
+				segments:    segments,
+				params:      params,
+				status:      status,
+				body:        body,
+				downstream:  op.Downstream,
+				latency:     op.Latency,
+				errors:      op.Errors,
+				timeoutRate: op.TimeoutRate,
+			})
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].params != routes[j].params {
+			return routes[i].params < routes[j].params
+		}
+		return strings.Join(routes[i].segments, "/") < strings.Join(routes[j].segments, "/")
+	})
+	return routes, nil
+}
+
+// example returns the lowest documented 2xx status and a JSON body built
+// from its schema. Without a 2xx response it answers 200 with no body.
+func (op operation) example() (int, []byte, error) {
+	status := 0
+	for code := range op.Responses {
+		n, err := strconv.Atoi(code)
+		if err != nil || n < 200 || n > 299 {
+			continue
+		}
+		if status == 0 || n < status {
+			status = n
+		}
+	}
+	if status == 0 {
+		return http.StatusOK, nil, nil
+	}
+
+	media, ok := op.Responses[strconv.Itoa(status)].Content["application/json"]
+	if !ok || len(media.Schema) == 0 {
+		return status, nil, nil
+	}
+	var schema any
+	if err := json.Unmarshal(media.Schema, &schema); err != nil {
+		return 0, nil, fmt.Errorf("parse response schema: %w", err)
+	}
+	body, err := json.Marshal(exampleValue(schema, 0))
+	if err != nil {
+		return 0, nil, fmt.Errorf("encode example: %w", err)
+	}
+	return status, body, nil
+}
+
+// exampleValue builds a value matching a JSON schema, preferring the
+// schema's example, default and first enum value over generated ones.
+func exampleValue(schema any, depth int) any {
+	s, ok := schema.(map[string]any)
+	if !ok || depth > maxExampleDepth {
+		return nil
+	}
+	for _, key := range []string{"example", "default"} {
+		if v, ok := s[key]; ok {
+			return v
+		}
+	}
+	if enum, ok := s["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	typ, _ := s["type"].(string)
+	if _, ok := s["properties"]; ok && typ == "" {
+		typ = "object"
+	}
+	switch typ {
+	case "object":
+		out := map[string]any{}
+		props, _ := s["properties"].(map[string]any)
+		for name, prop := range props {
+			out[name] = exampleValue(prop, depth+1)
+		}
+		return out
+	case "array":
+		return []any{exampleValue(s["items"], depth+1)}
+	case "string":
+		return "string"
+	case "integer", "number":
+		if v, ok := s["minimum"].(float64); ok {
+			return v
+		}
+		return 0
+	case "boolean":
+		return true
+	default:
+		return nil
+	}
+}
+
+// match reports whether the route serves method and path segments.
+func (r route) match(method string, segments []string) bool {
+	if r.method != method || len(r.segments) != len(segments) {
+		return false
+	}
+	for i, seg := range r.segments {
+		if !isParam(seg) && seg != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPath splits a URL path into its segments, ignoring leading and
+// trailing slashes.
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// isParam reports whether a path segment is a template parameter.
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
@@ -0,0 +1,351 @@
+package mockserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/openapi"
+	"github.com/stwalsh4118/hephaestus/backend/internal/otlp"
+)
+
+// traceparentHeader carries the W3C trace context of a request.
+const traceparentHeader = "traceparent"
+
+// Span export settings. Spans are sent in batches of up to exportBatchSize
+// at least every exportInterval; once exportQueueSize spans are waiting,
+// new ones are dropped rather than slowing requests down.
+const (
+	exportBatchSize = 512
+	exportQueueSize = 4096
+	exportInterval  = time.Second
+	exportTimeout   = 5 * time.Second
+)
+
+// scopeName identifies the mock server's instrumentation in exports.
+const scopeName = "github.com/stwalsh4118/hephaestus/backend/internal/mockserver"
+
+// spanContext identifies a span within its trace.
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+	sampled bool
+}
+
+// parseTraceparent parses a version 00 traceparent header. Invalid
+// headers, including all-zero IDs, are rejected.
+func parseTraceparent(h string) (spanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return spanContext{}, false
+	}
+	var sc spanContext
+	if _, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil || sc.traceID == [16]byte{} {
+		return spanContext{}, false
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil || sc.spanID == [8]byte{} {
+		return spanContext{}, false
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return spanContext{}, false
+	}
+	sc.sampled = flags&1 == 1
+	return sc, true
+}
+
+// traceparent formats sc as a traceparent header.
+func (sc spanContext) traceparent() string {
+	flags := "00"
+	if sc.sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(sc.traceID[:]) + "-" + hex.EncodeToString(sc.spanID[:]) + "-" + flags
+}
+
+type spanContextKey struct{}
+
+// contextWithSpan returns ctx carrying sc as the current span.
+func contextWithSpan(ctx context.Context, sc spanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// spanFromContext returns the current span of ctx.
+func spanFromContext(ctx context.Context) (spanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(spanContext)
+	return sc, ok
+}
+
+// span is a span in progress.
+type span struct {
+	sc     spanContext
+	parent [8]byte
+	name   string
+	kind   otlp.SpanKind
+	start  time.Time
+}
+
+// TracerConfig configures a Tracer.
+type TracerConfig struct {
+	// Endpoint is the OTLP/HTTP base URL spans are exported to, e.g.
+	// http://collector:4318. Empty disables export; trace context is still
+	// propagated.
+	Endpoint string
+	// Resource holds the attributes describing this server, such as
+	// service.name.
+	Resource map[string]string
+	// SampleRatio is the fraction of new traces recorded. Requests with an
+	// incoming traceparent follow its sampled flag instead.
+	SampleRatio float64
+}
+
+// Tracer records a span for every request a Server serves and every
+// downstream call it makes, and propagates W3C trace context to HTTP
+// downstream services.
+type Tracer struct {
+	cfg    TracerConfig
+	client *http.Client
+	rand   source
+	now    func() time.Time
+	queue  chan otlp.Span
+
+	dropped atomic.Int64
+	failing atomic.Bool
+}
+
+// NewTracer creates a Tracer. Spans are only sent while Run is running.
+func NewTracer(cfg TracerConfig) *Tracer {
+	return &Tracer{
+		cfg:    cfg,
+		client: &http.Client{Timeout: exportTimeout},
+		rand:   globalSource{},
+		now:    time.Now,
+		queue:  make(chan otlp.Span, exportQueueSize),
+	}
+}
+
+// Instrument returns next with a server span recorded for every request,
+// named after the operation of s it matches. The span continues the trace
+// of an incoming traceparent header, or starts a new one.
+func (t *Tracer) Instrument(s *Server, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := unmatchedRoute
+		if rt, ok := s.route(r.Method, r.URL.Path); ok {
+			route = rt.path
+		}
+
+		sp := span{name: r.Method + " " + route, kind: otlp.SpanKindServer, start: t.now()}
+		if parent, ok := parseTraceparent(r.Header.Get(traceparentHeader)); ok {
+			sp.sc = parent
+			sp.parent = parent.spanID
+		} else {
+			sp.sc.traceID = newTraceID()
+			sp.sc.sampled = t.rand.Float64() < t.cfg.SampleRatio
+		}
+		sp.sc.spanID = newSpanID()
+
+		sw := &statusWriter{ResponseWriter: w, status: statusClientClosed}
+		next.ServeHTTP(sw, r.WithContext(contextWithSpan(r.Context(), sp.sc)))
+
+		status := otlp.Status{}
+		if sw.status >= http.StatusInternalServerError || sw.status == statusClientClosed {
+			status = otlp.Status{Code: otlp.StatusError, Message: http.StatusText(sw.status)}
+		}
+		t.finish(sp, status,
+			otlp.String(otlp.AttrHTTPMethod, r.Method),
+			otlp.String(otlp.AttrHTTPRoute, route),
+			otlp.Int(otlp.AttrHTTPStatus, int64(sw.status)),
+		)
+	})
+}
+
+// Caller returns caller with a client span recorded for every call made
+// while serving a traced request. HTTP calls carry the client span as
+// their traceparent.
+func (t *Tracer) Caller(caller Caller) Caller {
+	return &tracingCaller{Caller: caller, tracer: t}
+}
+
+// tracingCaller records a client span around each downstream call.
+type tracingCaller struct {
+	Caller
+	tracer *Tracer
+}
+
+func (c *tracingCaller) Call(ctx context.Context, d openapi.Downstream) error {
+	parent, ok := spanFromContext(ctx)
+	if !ok {
+		return c.Caller.Call(ctx, d)
+	}
+	sp := span{
+		sc:     spanContext{traceID: parent.traceID, spanID: newSpanID(), sampled: parent.sampled},
+		parent: parent.spanID,
+		name:   d.Target,
+		kind:   otlp.SpanKindClient,
+		start:  c.tracer.now(),
+	}
+	err := c.Caller.Call(contextWithSpan(ctx, sp.sc), d)
+
+	status := otlp.Status{}
+	if err != nil {
+		status = otlp.Status{Code: otlp.StatusError, Message: err.Error()}
+	}
+	attrs := []otlp.KeyValue{
+		otlp.String(otlp.AttrPeerService, d.Target),
+		otlp.String(otlp.AttrTargetType, d.Type),
+	}
+	if d.Method != "" {
+		attrs = append(attrs, otlp.String(otlp.AttrHTTPMethod, d.Method))
+	}
+	c.tracer.finish(sp, status, attrs...)
+	return err
+}
+
+// finish ends sp now and queues it for export if its trace is sampled.
+func (t *Tracer) finish(sp span, status otlp.Status, attrs ...otlp.KeyValue) {
+	if !sp.sc.sampled || t.cfg.Endpoint == "" {
+		return
+	}
+	out := otlp.Span{
+		TraceID:           hex.EncodeToString(sp.sc.traceID[:]),
+		SpanID:            hex.EncodeToString(sp.sc.spanID[:]),
+		Name:              sp.name,
+		Kind:              sp.kind,
+		StartTimeUnixNano: otlp.Int64(sp.start.UnixNano()),
+		EndTimeUnixNano:   otlp.Int64(t.now().UnixNano()),
+		Attributes:        attrs,
+		Status:            status,
+	}
+	if sp.parent != [8]byte{} {
+		out.ParentSpanID = hex.EncodeToString(sp.parent[:])
+	}
+	select {
+	case t.queue <- out:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+// Run exports queued spans until ctx is done, then sends what is left.
+// It does nothing when no endpoint is configured.
+func (t *Tracer) Run(ctx context.Context) {
+	if t.cfg.Endpoint == "" {
+		return
+	}
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := make([]otlp.Span, 0, exportBatchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) > 0 {
+			t.export(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			for len(t.queue) > 0 {
+				batch = append(batch, <-t.queue)
+				if len(batch) == exportBatchSize {
+					flush(context.Background())
+				}
+			}
+			flush(context.Background())
+			return
+		case sp := <-t.queue:
+			batch = append(batch, sp)
+			if len(batch) == exportBatchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		}
+	}
+}
+
+// export sends spans to the endpoint. Failures are logged when exports
+// start and stop failing, not for every batch.
+func (t *Tracer) export(ctx context.Context, spans []otlp.Span) {
+	err := t.post(ctx, spans)
+	if dropped := t.dropped.Swap(0); dropped > 0 {
+		log.Printf("mockserver: dropped %d spans: export queue full", dropped)
+	}
+	if err != nil {
+		if !t.failing.Swap(true) {
+			log.Printf("mockserver: export spans: %v", err)
+		}
+		return
+	}
+	if t.failing.Swap(false) {
+		log.Printf("mockserver: exporting spans again")
+	}
+}
+
+// post sends one batch as an OTLP/HTTP JSON request.
+func (t *Tracer) post(ctx context.Context, spans []otlp.Span) error {
+	keys := make([]string, 0, len(t.cfg.Resource))
+	for k := range t.cfg.Resource {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	resource := otlp.Resource{}
+	for _, k := range keys {
+		resource.Attributes = append(resource.Attributes, otlp.String(k, t.cfg.Resource[k]))
+	}
+	body, err := json.Marshal(otlp.ExportTraceServiceRequest{ResourceSpans: []otlp.ResourceSpans{{
+		Resource:   resource,
+		ScopeSpans: []otlp.ScopeSpans{{Scope: otlp.Scope{Name: scopeName}, Spans: spans}},
+	}}})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+	url := strings.TrimSuffix(t.cfg.Endpoint, "/") + otlp.TracesPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("POST %s: status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// newTraceID returns a random, non-zero trace ID.
+func newTraceID() [16]byte {
+	var id [16]byte
+	for id == [16]byte{} {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+// newSpanID returns a random, non-zero span ID.
+func newSpanID() [8]byte {
+	var id [8]byte
+	for id == [8]byte{} {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
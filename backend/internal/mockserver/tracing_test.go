@@ -0,0 +1,168 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/openapi"
+	"github.com/stwalsh4118/hephaestus/backend/internal/otlp"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := parseTraceparent(valid)
+	if !ok || !sc.sampled {
+		t.Fatalf("expected a sampled span context, got %v %v", sc, ok)
+	}
+	if got := sc.traceparent(); got != valid {
+		t.Errorf("round trip: got %q, want %q", got, valid)
+	}
+	if sc, ok := parseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"); !ok || sc.sampled {
+		t.Error("expected an unsampled span context")
+	}
+
+	for _, h := range []string{
+		"",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		if _, ok := parseTraceparent(h); ok {
+			t.Errorf("expected %q to be rejected", h)
+		}
+	}
+}
+
+// collector is an OTLP receiver recording the spans exported to it.
+type collector struct {
+	mu       sync.Mutex
+	resource map[string]string
+	spans    []otlp.Span
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req otlp.ExportTraceServiceRequest
+	if r.URL.Path != otlp.TracesPath || json.NewDecoder(r.Body).Decode(&req) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range req.ResourceSpans {
+		c.resource = otlp.Attributes(rs.Resource.Attributes)
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+}
+
+func TestTracer_PropagatesAndExports(t *testing.T) {
+	var downstreamHeader string
+	downstream := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		downstreamHeader = r.Header.Get(traceparentHeader)
+	}))
+	defer downstream.Close()
+	col := &collector{}
+	receiver := httptest.NewServer(col)
+	defer receiver.Close()
+
+	spec, err := openapi.GenerateMockSpec([]model.Endpoint{{Method: "GET", Path: "/orders/{id}"}}, "Test",
+		[][]openapi.Downstream{{{Target: "users", Type: model.ServiceTypeAPIService, URL: downstream.URL + "/users/1", Method: http.MethodGet}}})
+	if err != nil {
+		t.Fatalf("GenerateMockSpec: %v", err)
+	}
+	tracer := NewTracer(TracerConfig{Endpoint: receiver.URL, Resource: map[string]string{otlp.AttrServiceName: "orders"}, SampleRatio: 1})
+	clients := NewClients()
+	defer clients.Close()
+	s, err := New(spec, tracer.Caller(clients))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		tracer.Run(ctx)
+		close(done)
+	}()
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	req.Header.Set(traceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	tracer.Instrument(s, s).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	cancel()
+	<-done
+
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	if col.resource[otlp.AttrServiceName] != "orders" {
+		t.Errorf("expected the resource to carry service.name, got %v", col.resource)
+	}
+	if len(col.spans) != 2 {
+		t.Fatalf("expected a client and a server span, got %d", len(col.spans))
+	}
+	client, server := col.spans[0], col.spans[1]
+	if server.Kind != otlp.SpanKindServer || server.Name != "GET /orders/{id}" {
+		t.Errorf("unexpected server span %+v", server)
+	}
+	if server.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("expected the server span to continue the incoming trace, got %+v", server)
+	}
+	if client.Kind != otlp.SpanKindClient || client.ParentSpanID != server.SpanID || client.TraceID != server.TraceID {
+		t.Errorf("expected a client span under the server span, got %+v", client)
+	}
+	if attrs := otlp.Attributes(client.Attributes); attrs[otlp.AttrPeerService] != "users" {
+		t.Errorf("expected peer.service users, got %v", attrs)
+	}
+	if want := "00-" + client.TraceID + "-" + client.SpanID + "-01"; downstreamHeader != want {
+		t.Errorf("downstream traceparent: got %q, want %q", downstreamHeader, want)
+	}
+}
+
+func TestTracer_UnsampledTracesAreNotExported(t *testing.T) {
+	s, caller := newTestServer(t, []model.Endpoint{{Method: "GET", Path: "/ping"}}, nil)
+	tracer := NewTracer(TracerConfig{Endpoint: "http://collector:4318", SampleRatio: 0})
+	h := tracer.Instrument(s, s)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(traceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if n := len(tracer.queue); n != 0 {
+		t.Errorf("expected no spans queued, got %d", n)
+	}
+	if len(caller.calls) != 0 {
+		t.Errorf("expected no downstream calls, got %v", caller.calls)
+	}
+}
+
+func TestTracer_RecordsFailedCalls(t *testing.T) {
+	s, _ := newTestServer(t, []model.Endpoint{{Method: "GET", Path: "/checkout"}},
+		[][]openapi.Downstream{{{Target: "fail", Type: model.ServiceTypeAPIService, URL: "http://fail/x", Method: http.MethodGet}}})
+	tracer := NewTracer(TracerConfig{Endpoint: "http://collector:4318", SampleRatio: 1})
+	s.caller = tracer.Caller(s.caller)
+
+	tracer.Instrument(s, s).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/checkout", nil))
+
+	if len(tracer.queue) != 2 {
+		t.Fatalf("expected 2 spans queued, got %d", len(tracer.queue))
+	}
+	client, server := <-tracer.queue, <-tracer.queue
+	if client.Status.Code != otlp.StatusError || client.Status.Message == "" {
+		t.Errorf("expected the client span to record the error, got %+v", client.Status)
+	}
+	if server.Status.Code != otlp.StatusError {
+		t.Errorf("expected the 502 server span to be an error, got %+v", server.Status)
+	}
+}
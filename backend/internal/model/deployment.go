@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+)
+
+// Deployment record statuses.
+const (
+	DeploymentDeploying = "deploying"
+	DeploymentDeployed  = "deployed"
+	DeploymentFailed    = "failed"
+	DeploymentTornDown  = "torn-down"
+)
+
+// Deployment is the persisted record of one deploy of a diagram, from the
+// moment it started until it failed or was torn down.
+type Deployment struct {
+	ID              string `json:"id"`
+	DiagramID       string `json:"diagramId"`
+	DiagramName     string `json:"diagramName"`
+	DiagramRevision int    `json:"diagramRevision,omitempty"`
+	// Mode is the orchestrator that ran the deployment: docker or simulated.
+	Mode   string `json:"mode"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// StartedAt is when the deploy began, DeployedAt when every container
+	// was running, and EndedAt when the deployment failed or was torn down.
+	StartedAt  time.Time  `json:"startedAt"`
+	DeployedAt *time.Time `json:"deployedAt,omitempty"`
+	EndedAt    *time.Time `json:"endedAt,omitempty"`
+	// DetachedAt is set while the backend is stopped with the deployment
+	// left running, and cleared when the next start reattaches to it.
+	DetachedAt *time.Time `json:"detachedAt,omitempty"`
+	// Containers are in startup order.
+	Containers []DeployedContainer `json:"containers"`
+}
+
+// DeployedContainer is the container created for one diagram node.
+type DeployedContainer struct {
+	NodeID      string                 `json:"nodeId"`
+	ContainerID string                 `json:"containerId,omitempty"`
+	Ports       map[string]string      `json:"ports,omitempty"` // host port → container port
+	Config      docker.ContainerConfig `json:"config"`
+	Error       string                 `json:"error,omitempty"`
+}
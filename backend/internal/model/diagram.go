@@ -4,20 +4,22 @@ import "encoding/json"
 
 // Service type constants matching the frontend ServiceType union.
 const (
-	ServiceTypeAPIService = "api-service"
-	ServiceTypePostgreSQL = "postgresql"
-	ServiceTypeRedis      = "redis"
-	ServiceTypeNginx      = "nginx"
-	ServiceTypeRabbitMQ   = "rabbitmq"
+	ServiceTypeAPIService      = "api-service"
+	ServiceTypePostgreSQL      = "postgresql"
+	ServiceTypeRedis           = "redis"
+	ServiceTypeNginx           = "nginx"
+	ServiceTypeRabbitMQ        = "rabbitmq"
+	ServiceTypeCustomContainer = "custom-container"
 )
 
 // ValidServiceTypes is the set of allowed service type values.
 var ValidServiceTypes = map[string]bool{
-	ServiceTypeAPIService: true,
-	ServiceTypePostgreSQL: true,
-	ServiceTypeRedis:      true,
-	ServiceTypeNginx:      true,
-	ServiceTypeRabbitMQ:   true,
+	ServiceTypeAPIService:      true,
+	ServiceTypePostgreSQL:      true,
+	ServiceTypeRedis:           true,
+	ServiceTypeNginx:           true,
+	ServiceTypeRabbitMQ:        true,
+	ServiceTypeCustomContainer: true,
 }
 
 // Position represents x/y coordinates on the canvas.
@@ -34,6 +36,18 @@ type DiagramNode struct {
 	Description string          `json:"description"`
 	Position    *Position       `json:"position"`
 	Config      json.RawMessage `json:"config,omitempty"`
+	Resources   *Resources      `json:"resources,omitempty"`
+}
+
+// Resources describes how a node scales and what it may consume. Local
+// Docker deployments run a single unconstrained container; exporters such as
+// the Kubernetes one map these onto replicas and resource limits, and the
+// cost estimator onto instance classes. Zero values mean "unset".
+type Resources struct {
+	Replicas  int     `json:"replicas,omitempty"`
+	CPUs      float64 `json:"cpus,omitempty"`      // CPU cores, e.g. 0.5
+	MemoryMB  int     `json:"memoryMb,omitempty"`  // memory limit in MiB
+	StorageGB int     `json:"storageGb,omitempty"` // persistent storage in GiB
 }
 
 // DiagramEdge represents a connection between two nodes.
@@ -46,10 +60,29 @@ type DiagramEdge struct {
 
 // Diagram is the top-level structure matching the frontend DiagramJson schema.
 type Diagram struct {
-	ID    string        `json:"id"`
-	Name  string        `json:"name"`
-	Nodes []DiagramNode `json:"nodes"`
-	Edges []DiagramEdge `json:"edges"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Revision is managed by the store: 1 on create, incremented on every
+	// update. Values sent by clients are ignored.
+	Revision int           `json:"revision,omitempty"`
+	Nodes    []DiagramNode `json:"nodes"`
+	Edges    []DiagramEdge `json:"edges"`
+	// Monitoring adds observability services to deployments of the
+	// diagram. Nil deploys none.
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+}
+
+// Monitoring selects the observability services deployed alongside a
+// diagram's nodes.
+type Monitoring struct {
+	// Prometheus deploys a Prometheus server that scrapes every node with
+	// metrics, adding exporters for services without a metrics endpoint.
+	Prometheus bool `json:"prometheus"`
+}
+
+// PrometheusEnabled reports whether deployments of d include Prometheus.
+func (d Diagram) PrometheusEnabled() bool {
+	return d.Monitoring != nil && d.Monitoring.Prometheus
 }
 
 // Endpoint represents an API service endpoint definition.
@@ -57,6 +90,61 @@ type Endpoint struct {
 	Method         string `json:"method"`
 	Path           string `json:"path"`
 	ResponseSchema string `json:"responseSchema"`
+	// Weight is the endpoint's relative share of generated traffic; 0
+	// counts as 1.
+	Weight int `json:"weight,omitempty"`
+	// Downstream lists the calls the mock server makes to edge targets
+	// before responding. When empty, every outgoing edge target is called.
+	Downstream []DownstreamCall `json:"downstream,omitempty"`
+	// Latency, Errors and TimeoutRate make the mock server misbehave. They
+	// can be changed on a running deployment without recreating it.
+	Latency *Latency        `json:"latency,omitempty"`
+	Errors  *ErrorInjection `json:"errors,omitempty"`
+	// TimeoutRate is the fraction of requests, 0 to 1, that never get a
+	// response before the client gives up.
+	TimeoutRate float64 `json:"timeoutRate,omitempty"`
+}
+
+// LatencyDistribution names how simulated response latency is drawn.
+type LatencyDistribution string
+
+const (
+	LatencyFixed     LatencyDistribution = "fixed"
+	LatencyUniform   LatencyDistribution = "uniform"
+	LatencyNormal    LatencyDistribution = "normal"
+	LatencyLognormal LatencyDistribution = "lognormal"
+)
+
+// Latency is the simulated response latency of an endpoint, in
+// milliseconds. Fixed uses ValueMs, uniform MinMs to MaxMs and normal
+// MeanMs and StdDevMs. Lognormal is fitted to the P50Ms and P99Ms
+// percentiles, which gives the long tail real services have.
+type Latency struct {
+	Distribution LatencyDistribution `json:"distribution"`
+	ValueMs      float64             `json:"valueMs,omitempty"`
+	MinMs        float64             `json:"minMs,omitempty"`
+	MaxMs        float64             `json:"maxMs,omitempty"`
+	MeanMs       float64             `json:"meanMs,omitempty"`
+	StdDevMs     float64             `json:"stdDevMs,omitempty"`
+	P50Ms        float64             `json:"p50Ms,omitempty"`
+	P99Ms        float64             `json:"p99Ms,omitempty"`
+}
+
+// ErrorInjection makes an endpoint fail a fraction of its requests. Each
+// failure answers with one of StatusCodes, picked uniformly; without
+// status codes it answers 500.
+type ErrorInjection struct {
+	Rate        float64 `json:"rate"`
+	StatusCodes []int   `json:"statusCodes,omitempty"`
+}
+
+// DownstreamCall is a call an api-service endpoint makes to one of the
+// nodes it has an edge to. Method and Path only apply to HTTP targets; when
+// Path is empty the target's first endpoint, or "/", is requested.
+type DownstreamCall struct {
+	Target string `json:"target"`
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path,omitempty"`
 }
 
 // ApiServiceConfig is the configuration for api-service nodes.
@@ -91,3 +179,24 @@ type RabbitMQConfig struct {
 	Type  string `json:"type"`
 	Vhost string `json:"vhost"`
 }
+
+// CustomContainerConfig is the configuration for custom-container nodes.
+type CustomContainerConfig struct {
+	Type        string            `json:"type"`
+	Image       string            `json:"image"`
+	Command     []string          `json:"command,omitempty"`
+	Entrypoint  []string          `json:"entrypoint,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	Ports       []int             `json:"ports,omitempty"` // container-side ports to expose
+	Healthcheck *Healthcheck      `json:"healthcheck,omitempty"`
+}
+
+// Healthcheck describes a container health probe. Test follows the Docker
+// convention: ["CMD", "arg", ...] or ["CMD-SHELL", "command"].
+type Healthcheck struct {
+	Test               []string `json:"test"`
+	IntervalSeconds    int      `json:"intervalSeconds,omitempty"`
+	TimeoutSeconds     int      `json:"timeoutSeconds,omitempty"`
+	Retries            int      `json:"retries,omitempty"`
+	StartPeriodSeconds int      `json:"startPeriodSeconds,omitempty"`
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -235,6 +236,17 @@ func TestValidateDiagram_MissingNodeName(t *testing.T) {
 	assertContains(t, ve.Errors, "nodes[0].name is required")
 }
 
+func TestValidateDiagram_NodeNameWithoutHostnameChars(t *testing.T) {
+	d := validDiagram()
+	d.Nodes[0].Name = "!!! ---"
+	err := ValidateDiagram(d)
+	if err == nil {
+		t.Fatal("expected error for node name without letters or digits")
+	}
+	ve := err.(*ValidationError)
+	assertContains(t, ve.Errors, `nodes[0].name "!!! ---" must contain a letter or digit to derive a hostname from`)
+}
+
 func TestValidateDiagram_MissingEdgeSource(t *testing.T) {
 	d := validDiagram()
 	d.Edges[0].Source = ""
@@ -321,3 +333,134 @@ func assertContains(t *testing.T, errs []string, target string) {
 	}
 	t.Errorf("expected error %q in %v", target, errs)
 }
+
+func TestValidateDiagram_CustomContainer(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"valid", `{"type":"custom-container","image":"ghcr.io/acme/app:1.2","ports":[8080],"healthcheck":{"test":["CMD","true"]}}`, ""},
+		{"valid digest", `{"type":"custom-container","image":"busybox@sha256:7b3ccabffc97de872a30dfd234fd972a66d247c8cfc69b0550f276481852627c"}`, ""},
+		{"missing config", ``, "nodes[0].config is required for custom-container nodes"},
+		{"missing image", `{"type":"custom-container"}`, "nodes[0].config.image is required"},
+		{"uppercase image", `{"type":"custom-container","image":"Acme/App"}`, "nodes[0].config.image: invalid image reference"},
+		{"bad tag", `{"type":"custom-container","image":"nginx:la test"}`, "nodes[0].config.image: invalid image reference"},
+		{"port out of range", `{"type":"custom-container","image":"nginx","ports":[70000]}`, "nodes[0].config.ports[0] 70000 is out of range 1-65535"},
+		{"duplicate port", `{"type":"custom-container","image":"nginx","ports":[80,80]}`, "nodes[0].config.ports[1] 80 is duplicated"},
+		{"bad env key", `{"type":"custom-container","image":"nginx","env":{"A=B":"x"}}`, "nodes[0].config.env key \"A=B\" is not a valid variable name"},
+		{"empty healthcheck", `{"type":"custom-container","image":"nginx","healthcheck":{"test":[]}}`, "nodes[0].config.healthcheck.test is required"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &Diagram{
+				ID:   "test",
+				Name: "test",
+				Nodes: []DiagramNode{
+					{
+						ID:       "n1",
+						Type:     ServiceTypeCustomContainer,
+						Name:     "custom",
+						Position: &Position{X: 0, Y: 0},
+						Config:   json.RawMessage(tc.config),
+					},
+				},
+				Edges: []DiagramEdge{},
+			}
+
+			err := ValidateDiagram(d)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+			ve, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("expected *ValidationError, got %T (%v)", err, err)
+			}
+			for _, e := range ve.Errors {
+				if strings.HasPrefix(e, tc.wantErr) {
+					return
+				}
+			}
+			t.Errorf("expected error starting with %q in %v", tc.wantErr, ve.Errors)
+		})
+	}
+}
+
+func TestValidateDiagram_NegativeResources(t *testing.T) {
+	d := validDiagram()
+	d.Nodes[0].Resources = &Resources{Replicas: -1, CPUs: -0.5, MemoryMB: -1, StorageGB: -1}
+	err := ValidateDiagram(d)
+	if err == nil {
+		t.Fatal("expected error for negative resources")
+	}
+	ve := err.(*ValidationError)
+	assertContains(t, ve.Errors, "nodes[0].resources.replicas must not be negative")
+	assertContains(t, ve.Errors, "nodes[0].resources.cpus must not be negative")
+	assertContains(t, ve.Errors, "nodes[0].resources.memoryMb must not be negative")
+	assertContains(t, ve.Errors, "nodes[0].resources.storageGb must not be negative")
+}
+
+func TestValidateDiagram_NegativeEndpointWeight(t *testing.T) {
+	d := validDiagram()
+	d.Nodes[0].Config = json.RawMessage(`{"type":"api-service","endpoints":[{"method":"GET","path":"/a","weight":3},{"method":"GET","path":"/b","weight":-1}],"port":8080}`)
+	err := ValidateDiagram(d)
+	if err == nil {
+		t.Fatal("expected error for negative weight")
+	}
+	ve := err.(*ValidationError)
+	if len(ve.Errors) != 1 {
+		t.Errorf("expected 1 error, got %v", ve.Errors)
+	}
+	assertContains(t, ve.Errors, "nodes[0].config.endpoints[1].weight must not be negative")
+}
+
+func TestValidateDiagram_EndpointFaults(t *testing.T) {
+	d := validDiagram()
+	d.Nodes[0].Config = json.RawMessage(`{"type":"api-service","endpoints":[` +
+		`{"method":"GET","path":"/ok","latency":{"distribution":"lognormal","p50Ms":20,"p99Ms":400},"errors":{"rate":0.1,"statusCodes":[500,503]},"timeoutRate":0.01},` +
+		`{"method":"GET","path":"/a","latency":{"distribution":"uniform","minMs":50,"maxMs":10},"errors":{"rate":1.5,"statusCodes":[200]},"timeoutRate":-0.1},` +
+		`{"method":"GET","path":"/b","latency":{"distribution":"lognormal","p50Ms":0,"p99Ms":90000}},` +
+		`{"method":"GET","path":"/c","latency":{"distribution":"pareto"}}` +
+		`],"port":8080}`)
+	err := ValidateDiagram(d)
+	if err == nil {
+		t.Fatal("expected error for invalid faults")
+	}
+	ve := err.(*ValidationError)
+	want := []string{
+		"nodes[0].config.endpoints[1].latency.maxMs must not be less than minMs",
+		"nodes[0].config.endpoints[1].errors.rate must be between 0 and 1",
+		"nodes[0].config.endpoints[1].errors.statusCodes[0] must be between 400 and 599",
+		"nodes[0].config.endpoints[1].timeoutRate must be between 0 and 1",
+		"nodes[0].config.endpoints[2].latency.p99Ms must be between 0 and 60000",
+		"nodes[0].config.endpoints[2].latency.p50Ms must be positive",
+		"nodes[0].config.endpoints[3].latency.distribution \"pareto\" must be fixed, uniform, normal or lognormal",
+	}
+	if len(ve.Errors) != len(want) {
+		t.Errorf("expected %d errors, got %v", len(want), ve.Errors)
+	}
+	for _, msg := range want {
+		assertContains(t, ve.Errors, msg)
+	}
+}
+
+func TestValidateDiagram_DownstreamCalls(t *testing.T) {
+	d := validDiagram()
+	d.Nodes[0].Config = json.RawMessage(`{"type":"api-service","endpoints":[{"method":"GET","path":"/a","downstream":[{"target":"node-2","method":"POST","path":"/orders"},{"target":"node-3"},{"target":"","method":"TRACE","path":"orders"}]}],"port":8080}`)
+	err := ValidateDiagram(d)
+	if err == nil {
+		t.Fatal("expected error for invalid downstream calls")
+	}
+	ve := err.(*ValidationError)
+	if len(ve.Errors) != 4 {
+		t.Errorf("expected 4 errors, got %v", ve.Errors)
+	}
+	assertContains(t, ve.Errors, "nodes[0].config.endpoints[0].downstream[1].target \"node-3\" has no edge from this node")
+	assertContains(t, ve.Errors, "nodes[0].config.endpoints[0].downstream[2].target is required")
+	assertContains(t, ve.Errors, "nodes[0].config.endpoints[0].downstream[2].method \"TRACE\" is not supported")
+	assertContains(t, ve.Errors, "nodes[0].config.endpoints[0].downstream[2].path must start with /")
+}
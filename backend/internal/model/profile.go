@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// LoadModel is how a load profile's stage targets are interpreted.
+type LoadModel string
+
+const (
+	// LoadModelOpen targets are request rates in requests per second,
+	// sent whatever the response times.
+	LoadModelOpen LoadModel = "open"
+	// LoadModelClosed targets are numbers of virtual users sending
+	// requests back to back.
+	LoadModelClosed LoadModel = "closed"
+)
+
+// RampShape is how a stage moves from the previous stage's target to its
+// own.
+type RampShape string
+
+const (
+	// RampLinear moves evenly over the stage. It is the default.
+	RampLinear RampShape = "linear"
+	// RampStep jumps to the target as the stage starts.
+	RampStep RampShape = "step"
+)
+
+// LoadStage is one phase of a load profile.
+type LoadStage struct {
+	DurationSeconds int `json:"durationSeconds"`
+	// Target is the rate or number of virtual users, depending on the
+	// profile's model, reached by the end of the stage. The first stage
+	// starts from 0.
+	Target int       `json:"target"`
+	Ramp   RampShape `json:"ramp,omitempty"`
+}
+
+// LoadProfile is a reusable shape of traffic over time.
+type LoadProfile struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Model       LoadModel `json:"model"`
+	// ThinkTimeMs is the pause between a virtual user's requests in the
+	// closed model.
+	ThinkTimeMs int         `json:"thinkTimeMs,omitempty"`
+	Stages      []LoadStage `json:"stages"`
+	// Preset marks the built-in profiles, which cannot be changed.
+	Preset bool `json:"preset,omitempty"`
+	// CreatedAt and UpdatedAt are managed by the store.
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+}
+
+// Duration returns the total length of the profile's stages.
+func (p LoadProfile) Duration() time.Duration {
+	var total time.Duration
+	for _, s := range p.Stages {
+		total += time.Duration(s.DurationSeconds) * time.Second
+	}
+	return total
+}
+
+// Peak returns the highest stage target.
+func (p LoadProfile) Peak() int {
+	peak := 0
+	for _, s := range p.Stages {
+		peak = max(peak, s.Target)
+	}
+	return peak
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func validProfile() *LoadProfile {
+	return &LoadProfile{
+		Name:  "Ramp",
+		Model: LoadModelOpen,
+		Stages: []LoadStage{
+			{DurationSeconds: 60, Target: 100},
+			{DurationSeconds: 30, Target: 100, Ramp: RampStep},
+		},
+	}
+}
+
+func TestLoadProfile_DurationAndPeak(t *testing.T) {
+	p := validProfile()
+	if p.Duration() != 90*time.Second || p.Peak() != 100 {
+		t.Errorf("got duration %v, peak %d", p.Duration(), p.Peak())
+	}
+}
+
+func TestValidateLoadProfile_Valid(t *testing.T) {
+	if err := ValidateLoadProfile(validProfile()); err != nil {
+		t.Errorf("expected valid profile, got %v", err)
+	}
+}
+
+func TestValidateLoadProfile_Invalid(t *testing.T) {
+	p := &LoadProfile{
+		Model:       "burst",
+		ThinkTimeMs: -1,
+		Stages: []LoadStage{
+			{DurationSeconds: 0, Target: -5, Ramp: "curve"},
+		},
+	}
+	err := ValidateLoadProfile(p)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	ve := err.(*ValidationError)
+	assertContains(t, ve.Errors, "name is required")
+	assertContains(t, ve.Errors, `model "burst" must be open or closed`)
+	assertContains(t, ve.Errors, "thinkTimeMs must not be negative")
+	assertContains(t, ve.Errors, "stages[0].durationSeconds must be at least 1")
+	assertContains(t, ve.Errors, "stages[0].target must not be negative")
+	assertContains(t, ve.Errors, `stages[0].ramp "curve" must be linear or step`)
+	assertContains(t, ve.Errors, "at least one stage must have a positive target")
+}
+
+func TestValidateLoadProfile_NoStages(t *testing.T) {
+	p := validProfile()
+	p.Stages = nil
+	err := ValidateLoadProfile(p)
+	if err == nil {
+		t.Fatal("expected error for a profile without stages")
+	}
+	assertContains(t, err.(*ValidationError).Errors, "at least one stage is required")
+}
@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/distribution/reference"
 )
 
-// ValidationError holds all validation failures for a diagram.
+// ValidationError holds all validation failures for a diagram or load
+// profile.
 type ValidationError struct {
 	Errors []string
 }
@@ -33,22 +36,32 @@ func ValidateDiagram(d *Diagram) error {
 	}
 
 	for i, node := range d.Nodes {
-		errs = append(errs, validateNode(i, &node)...)
+		errs = append(errs, validateNode(fmt.Sprintf("nodes[%d]", i), &node)...)
 	}
 
 	for i, edge := range d.Edges {
 		errs = append(errs, validateEdge(i, &edge)...)
 	}
 
+	errs = append(errs, validateDownstream(d)...)
+
 	if len(errs) > 0 {
 		return &ValidationError{Errors: errs}
 	}
 	return nil
 }
 
-func validateNode(index int, n *DiagramNode) []string {
+// ValidateNode checks a single node as ValidateDiagram does, without the
+// checks that span nodes, such as downstream call targets.
+func ValidateNode(n *DiagramNode) error {
+	if errs := validateNode("node", n); len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func validateNode(prefix string, n *DiagramNode) []string {
 	var errs []string
-	prefix := fmt.Sprintf("nodes[%d]", index)
 
 	if n.ID == "" {
 		errs = append(errs, fmt.Sprintf("%s.id is required", prefix))
@@ -60,6 +73,8 @@ func validateNode(index int, n *DiagramNode) []string {
 	}
 	if n.Name == "" {
 		errs = append(errs, fmt.Sprintf("%s.name is required", prefix))
+	} else if !hasHostnameChars(n.Name) {
+		errs = append(errs, fmt.Sprintf("%s.name %q must contain a letter or digit to derive a hostname from", prefix, n.Name))
 	}
 	if n.Position == nil {
 		errs = append(errs, fmt.Sprintf("%s.position is required", prefix))
@@ -67,11 +82,36 @@ func validateNode(index int, n *DiagramNode) []string {
 
 	if len(n.Config) > 0 {
 		errs = append(errs, validateConfig(prefix, n.Type, n.Config)...)
+	} else if n.Type == ServiceTypeCustomContainer {
+		errs = append(errs, fmt.Sprintf("%s.config is required for %s nodes", prefix, ServiceTypeCustomContainer))
+	}
+
+	if r := n.Resources; r != nil {
+		if r.Replicas < 0 {
+			errs = append(errs, fmt.Sprintf("%s.resources.replicas must not be negative", prefix))
+		}
+		if r.CPUs < 0 {
+			errs = append(errs, fmt.Sprintf("%s.resources.cpus must not be negative", prefix))
+		}
+		if r.MemoryMB < 0 {
+			errs = append(errs, fmt.Sprintf("%s.resources.memoryMb must not be negative", prefix))
+		}
+		if r.StorageGB < 0 {
+			errs = append(errs, fmt.Sprintf("%s.resources.storageGb must not be negative", prefix))
+		}
 	}
 
 	return errs
 }
 
+// hasHostnameChars reports whether name has an ASCII letter or digit, the
+// only characters that survive when a node name becomes a container hostname.
+func hasHostnameChars(name string) bool {
+	return strings.IndexFunc(name, func(r rune) bool {
+		return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+	}) >= 0
+}
+
 func validateEdge(index int, e *DiagramEdge) []string {
 	var errs []string
 	prefix := fmt.Sprintf("edges[%d]", index)
@@ -99,5 +139,241 @@ func validateConfig(prefix string, nodeType string, raw json.RawMessage) []strin
 	if base.Type != nodeType {
 		return []string{fmt.Sprintf("%s.config.type %q does not match node type %q", prefix, base.Type, nodeType)}
 	}
+	switch nodeType {
+	case ServiceTypeCustomContainer:
+		return validateCustomContainerConfig(prefix, raw)
+	case ServiceTypeAPIService:
+		return validateAPIServiceConfig(prefix, raw)
+	}
+	return nil
+}
+
+func validateAPIServiceConfig(prefix string, raw json.RawMessage) []string {
+	var cfg ApiServiceConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return []string{fmt.Sprintf("%s.config: invalid api-service config: %v", prefix, err)}
+	}
+
+	var errs []string
+	for i, ep := range cfg.Endpoints {
+		if ep.Weight < 0 {
+			errs = append(errs, fmt.Sprintf("%s.config.endpoints[%d].weight must not be negative", prefix, i))
+		}
+		epPrefix := fmt.Sprintf("%s.config.endpoints[%d]", prefix, i)
+		errs = append(errs, validateLatency(epPrefix, ep.Latency)...)
+		if ep.Errors != nil {
+			if ep.Errors.Rate < 0 || ep.Errors.Rate > 1 {
+				errs = append(errs, fmt.Sprintf("%s.errors.rate must be between 0 and 1", epPrefix))
+			}
+			for j, code := range ep.Errors.StatusCodes {
+				if code < 400 || code > 599 {
+					errs = append(errs, fmt.Sprintf("%s.errors.statusCodes[%d] must be between 400 and 599", epPrefix, j))
+				}
+			}
+		}
+		if ep.TimeoutRate < 0 || ep.TimeoutRate > 1 {
+			errs = append(errs, fmt.Sprintf("%s.timeoutRate must be between 0 and 1", epPrefix))
+		}
+		for j, call := range ep.Downstream {
+			callPrefix := fmt.Sprintf("%s.config.endpoints[%d].downstream[%d]", prefix, i, j)
+			if call.Target == "" {
+				errs = append(errs, fmt.Sprintf("%s.target is required", callPrefix))
+			}
+			if call.Method != "" && !downstreamMethods[strings.ToUpper(call.Method)] {
+				errs = append(errs, fmt.Sprintf("%s.method %q is not supported", callPrefix, call.Method))
+			}
+			if call.Path != "" && !strings.HasPrefix(call.Path, "/") {
+				errs = append(errs, fmt.Sprintf("%s.path must start with /", callPrefix))
+			}
+		}
+	}
+	return errs
+}
+
+// MaxSimulatedLatencyMs bounds every latency setting of an endpoint.
+const MaxSimulatedLatencyMs = 60000
+
+// validateLatency checks that l has a known distribution and the settings
+// that distribution needs.
+func validateLatency(prefix string, l *Latency) []string {
+	if l == nil {
+		return nil
+	}
+	prefix += ".latency"
+	var errs []string
+	inRange := func(field string, v float64) {
+		if v < 0 || v > MaxSimulatedLatencyMs {
+			errs = append(errs, fmt.Sprintf("%s.%s must be between 0 and %d", prefix, field, MaxSimulatedLatencyMs))
+		}
+	}
+	switch l.Distribution {
+	case LatencyFixed:
+		inRange("valueMs", l.ValueMs)
+	case LatencyUniform:
+		inRange("minMs", l.MinMs)
+		inRange("maxMs", l.MaxMs)
+		if l.MaxMs < l.MinMs {
+			errs = append(errs, fmt.Sprintf("%s.maxMs must not be less than minMs", prefix))
+		}
+	case LatencyNormal:
+		inRange("meanMs", l.MeanMs)
+		inRange("stdDevMs", l.StdDevMs)
+	case LatencyLognormal:
+		inRange("p50Ms", l.P50Ms)
+		inRange("p99Ms", l.P99Ms)
+		if l.P50Ms <= 0 {
+			errs = append(errs, fmt.Sprintf("%s.p50Ms must be positive", prefix))
+		}
+		if l.P99Ms < l.P50Ms {
+			errs = append(errs, fmt.Sprintf("%s.p99Ms must not be less than p50Ms", prefix))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("%s.distribution %q must be fixed, uniform, normal or lognormal", prefix, l.Distribution))
+	}
+	return errs
+}
+
+// downstreamMethods are the HTTP methods a downstream call may use.
+var downstreamMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true,
+}
+
+// validateDownstream checks that every downstream call of an api-service
+// endpoint targets a node the service has an edge to. Configs that do not
+// parse are reported by validateConfig.
+func validateDownstream(d *Diagram) []string {
+	connected := make(map[string]map[string]bool)
+	for _, e := range d.Edges {
+		if connected[e.Source] == nil {
+			connected[e.Source] = make(map[string]bool)
+		}
+		connected[e.Source][e.Target] = true
+	}
+
+	var errs []string
+	for i, n := range d.Nodes {
+		if n.Type != ServiceTypeAPIService || len(n.Config) == 0 {
+			continue
+		}
+		var cfg ApiServiceConfig
+		if err := json.Unmarshal(n.Config, &cfg); err != nil {
+			continue
+		}
+		for j, ep := range cfg.Endpoints {
+			for k, call := range ep.Downstream {
+				if call.Target != "" && !connected[n.ID][call.Target] {
+					errs = append(errs, fmt.Sprintf("nodes[%d].config.endpoints[%d].downstream[%d].target %q has no edge from this node", i, j, k, call.Target))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// Port bounds for custom-container exposed ports.
+const (
+	minPort = 1
+	maxPort = 65535
+)
+
+func validateCustomContainerConfig(prefix string, raw json.RawMessage) []string {
+	var cfg CustomContainerConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return []string{fmt.Sprintf("%s.config: invalid custom-container config: %v", prefix, err)}
+	}
+
+	var errs []string
+	if cfg.Image == "" {
+		errs = append(errs, fmt.Sprintf("%s.config.image is required", prefix))
+	} else if err := ValidateImageReference(cfg.Image); err != nil {
+		errs = append(errs, fmt.Sprintf("%s.config.image: %v", prefix, err))
+	}
+
+	seen := make(map[int]bool, len(cfg.Ports))
+	for i, p := range cfg.Ports {
+		if p < minPort || p > maxPort {
+			errs = append(errs, fmt.Sprintf("%s.config.ports[%d] %d is out of range %d-%d", prefix, i, p, minPort, maxPort))
+			continue
+		}
+		if seen[p] {
+			errs = append(errs, fmt.Sprintf("%s.config.ports[%d] %d is duplicated", prefix, i, p))
+		}
+		seen[p] = true
+	}
+
+	for k := range cfg.Env {
+		if !ValidEnvName(k) {
+			errs = append(errs, fmt.Sprintf("%s.config.env key %q is not a valid variable name", prefix, k))
+		}
+	}
+
+	if hc := cfg.Healthcheck; hc != nil {
+		if len(hc.Test) == 0 {
+			errs = append(errs, fmt.Sprintf("%s.config.healthcheck.test is required", prefix))
+		}
+		if hc.IntervalSeconds < 0 || hc.TimeoutSeconds < 0 || hc.Retries < 0 || hc.StartPeriodSeconds < 0 {
+			errs = append(errs, fmt.Sprintf("%s.config.healthcheck values must not be negative", prefix))
+		}
+	}
+
+	return errs
+}
+
+// ValidEnvName reports whether k can be passed to a container as an
+// environment variable name: non-empty, without "=" or whitespace.
+func ValidEnvName(k string) bool {
+	return k != "" && !strings.ContainsAny(k, "= \t\n")
+}
+
+// ValidateImageReference checks that ref is a well-formed Docker image
+// reference such as "nginx", "ghcr.io/org/app:1.2" or "app@sha256:...".
+func ValidateImageReference(ref string) error {
+	if strings.TrimSpace(ref) != ref {
+		return fmt.Errorf("image reference %q must not contain surrounding whitespace", ref)
+	}
+	if _, err := reference.ParseNormalizedNamed(ref); err != nil {
+		return fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	return nil
+}
+
+// ValidateLoadProfile checks that a LoadProfile is well formed. Limits on
+// duration and targets belong to the traffic generators and are checked
+// there.
+func ValidateLoadProfile(p *LoadProfile) error {
+	var errs []string
+
+	if strings.TrimSpace(p.Name) == "" {
+		errs = append(errs, "name is required")
+	}
+	if p.Model != LoadModelOpen && p.Model != LoadModelClosed {
+		errs = append(errs, fmt.Sprintf("model %q must be %s or %s", p.Model, LoadModelOpen, LoadModelClosed))
+	}
+	if p.ThinkTimeMs < 0 {
+		errs = append(errs, "thinkTimeMs must not be negative")
+	}
+	if len(p.Stages) == 0 {
+		errs = append(errs, "at least one stage is required")
+	}
+
+	for i, s := range p.Stages {
+		prefix := fmt.Sprintf("stages[%d]", i)
+		if s.DurationSeconds < 1 {
+			errs = append(errs, fmt.Sprintf("%s.durationSeconds must be at least 1", prefix))
+		}
+		if s.Target < 0 {
+			errs = append(errs, fmt.Sprintf("%s.target must not be negative", prefix))
+		}
+		if s.Ramp != "" && s.Ramp != RampLinear && s.Ramp != RampStep {
+			errs = append(errs, fmt.Sprintf("%s.ramp %q must be %s or %s", prefix, s.Ramp, RampLinear, RampStep))
+		}
+	}
+	if len(p.Stages) > 0 && p.Peak() == 0 {
+		errs = append(errs, "at least one stage must have a positive target")
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
 	return nil
 }
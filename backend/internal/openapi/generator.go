@@ -29,8 +29,34 @@ type pathItem map[string]operation
 
 // operation describes a single API operation.
 type operation struct {
-	Summary   string              `json:"summary"`
-	Responses map[string]response `json:"responses"`
+	Summary     string                `json:"summary"`
+	Responses   map[string]response   `json:"responses"`
+	Downstream  []Downstream          `json:"x-heph-downstream,omitempty"`
+	Latency     *model.Latency        `json:"x-heph-latency,omitempty"`
+	Errors      *model.ErrorInjection `json:"x-heph-errors,omitempty"`
+	TimeoutRate float64               `json:"x-heph-timeout-rate,omitempty"`
+}
+
+// Operation extensions read by the mock server. ExtDownstream lists the
+// calls it makes before it responds; the others carry the endpoint's
+// simulated latency, injected errors and timeout rate.
+const (
+	ExtDownstream  = "x-heph-downstream"
+	ExtLatency     = "x-heph-latency"
+	ExtErrors      = "x-heph-errors"
+	ExtTimeoutRate = "x-heph-timeout-rate"
+)
+
+// Downstream is a call to another service, resolved to an address on the
+// shared network. For HTTP targets URL includes the path and Method is the
+// request method; for data stores URL is the connection string and Key
+// names the Redis key or RabbitMQ queue used.
+type Downstream struct {
+	Target string `json:"target"`
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+	Method string `json:"method,omitempty"`
+	Key    string `json:"key,omitempty"`
 }
 
 // response describes a single response from an API operation.
@@ -52,10 +78,18 @@ const contentTypeJSON = "application/json"
 
 // GenerateSpec converts a slice of endpoint definitions into a valid OpenAPI 3.0.0
 // JSON document. The title parameter is used for the spec's info.title field.
+// Simulated latency, errors and timeouts are emitted as x-heph-* extensions.
 func GenerateSpec(endpoints []model.Endpoint, title string) ([]byte, error) {
+	return GenerateMockSpec(endpoints, title, nil)
+}
+
+// GenerateMockSpec is like GenerateSpec but also lists downstream[i], the
+// calls endpoint i makes, in the operation's x-heph-downstream extension.
+// downstream may be shorter than endpoints, or nil.
+func GenerateMockSpec(endpoints []model.Endpoint, title string, downstream [][]Downstream) ([]byte, error) {
 	paths := make(map[string]pathItem, len(endpoints))
 
-	for _, ep := range endpoints {
+	for i, ep := range endpoints {
 		method := strings.ToLower(ep.Method)
 		if !isValidMethod(method) {
 			return nil, fmt.Errorf("unsupported HTTP method: %q", ep.Method)
@@ -64,7 +98,10 @@ func GenerateSpec(endpoints []model.Endpoint, title string) ([]byte, error) {
 		schema := parseResponseSchema(ep.ResponseSchema)
 
 		op := operation{
-			Summary: fmt.Sprintf("%s %s", strings.ToUpper(method), ep.Path),
+			Summary:     fmt.Sprintf("%s %s", strings.ToUpper(method), ep.Path),
+			Latency:     ep.Latency,
+			Errors:      ep.Errors,
+			TimeoutRate: ep.TimeoutRate,
 			Responses: map[string]response{
 				"200": {
 					Description: "Successful response",
@@ -75,6 +112,10 @@ func GenerateSpec(endpoints []model.Endpoint, title string) ([]byte, error) {
 			},
 		}
 
+		if i < len(downstream) {
+			op.Downstream = downstream[i]
+		}
+
 		pi, exists := paths[ep.Path]
 		if !exists {
 			pi = make(pathItem)
@@ -285,3 +285,76 @@ func TestGenerateSpec_RequiredOpenAPIFields(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateMockSpec_DownstreamExtension(t *testing.T) {
+	endpoints := []model.Endpoint{
+		{Method: "GET", Path: "/orders"},
+		{Method: "POST", Path: "/orders"},
+	}
+	downstream := [][]Downstream{
+		{
+			{Target: "main-db", Type: model.ServiceTypePostgreSQL, URL: "postgres://u:p@main-db:5432/app"},
+			{Target: "users", Type: model.ServiceTypeAPIService, URL: "http://users:4010/users", Method: "GET"},
+		},
+	}
+
+	data, err := GenerateMockSpec(endpoints, "Orders", downstream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var raw struct {
+		Paths map[string]map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	var calls []Downstream
+	if err := json.Unmarshal(raw.Paths["/orders"]["get"][ExtDownstream], &calls); err != nil {
+		t.Fatalf("decode %s: %v", ExtDownstream, err)
+	}
+	if len(calls) != 2 || calls[0] != downstream[0][0] || calls[1] != downstream[0][1] {
+		t.Errorf("expected downstream calls %+v, got %+v", downstream[0], calls)
+	}
+	if _, ok := raw.Paths["/orders"]["post"][ExtDownstream]; ok {
+		t.Error("expected no extension on an endpoint without downstream calls")
+	}
+}
+
+func TestGenerateSpec_FaultExtensions(t *testing.T) {
+	endpoints := []model.Endpoint{
+		{
+			Method:      "GET",
+			Path:        "/slow",
+			Latency:     &model.Latency{Distribution: model.LatencyLognormal, P50Ms: 20, P99Ms: 400},
+			Errors:      &model.ErrorInjection{Rate: 0.1, StatusCodes: []int{503}},
+			TimeoutRate: 0.01,
+		},
+		{Method: "GET", Path: "/fast"},
+	}
+
+	data, err := GenerateSpec(endpoints, "Faults")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var raw struct {
+		Paths map[string]map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	slow := raw.Paths["/slow"]["get"]
+	var latency model.Latency
+	if err := json.Unmarshal(slow[ExtLatency], &latency); err != nil || latency != *endpoints[0].Latency {
+		t.Errorf("expected %s %+v, got %s (%v)", ExtLatency, *endpoints[0].Latency, slow[ExtLatency], err)
+	}
+	if string(slow[ExtErrors]) == "" || string(slow[ExtTimeoutRate]) != "0.01" {
+		t.Errorf("expected %s and %s, got %s and %s", ExtErrors, ExtTimeoutRate, slow[ExtErrors], slow[ExtTimeoutRate])
+	}
+	for _, ext := range []string{ExtLatency, ExtErrors, ExtTimeoutRate} {
+		if _, ok := raw.Paths["/fast"]["get"][ext]; ok {
+			t.Errorf("expected no %s on an endpoint without faults", ext)
+		}
+	}
+}
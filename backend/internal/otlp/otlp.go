@@ -0,0 +1,214 @@
+// Package otlp holds the OTLP/HTTP JSON encoding of trace exports, shared
+// by the mock server, which sends spans, and the backend, which receives
+// them. Only the fields hephaestus uses are modelled.
+package otlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TracesPath is the path spans are posted to, relative to the endpoint.
+const TracesPath = "/v1/traces"
+
+// Resource and span attributes hephaestus sets on top of the OpenTelemetry
+// semantic conventions.
+const (
+	AttrServiceName  = "service.name"
+	AttrPeerService  = "peer.service"
+	AttrNodeID       = "heph.node_id"
+	AttrDeploymentID = "heph.deployment_id"
+	AttrTargetType   = "heph.target_type"
+	AttrHTTPMethod   = "http.request.method"
+	AttrHTTPRoute    = "http.route"
+	AttrHTTPStatus   = "http.response.status_code"
+)
+
+// SpanKind is the role of a span in a call.
+type SpanKind int
+
+// Span kinds.
+const (
+	SpanKindUnspecified SpanKind = 0
+	SpanKindInternal    SpanKind = 1
+	SpanKindServer      SpanKind = 2
+	SpanKindClient      SpanKind = 3
+	SpanKindProducer    SpanKind = 4
+	SpanKindConsumer    SpanKind = 5
+)
+
+// String returns the kind in lower case, e.g. "server".
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindInternal:
+		return "internal"
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	case SpanKindProducer:
+		return "producer"
+	case SpanKindConsumer:
+		return "consumer"
+	default:
+		return "unspecified"
+	}
+}
+
+// StatusCode is the outcome of a span.
+type StatusCode int
+
+// Status codes.
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// ExportTraceServiceRequest is the body of a trace export.
+type ExportTraceServiceRequest struct {
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+// ExportTraceServiceResponse is the reply to a trace export.
+type ExportTraceServiceResponse struct {
+	PartialSuccess *PartialSuccess `json:"partialSuccess,omitempty"`
+}
+
+// PartialSuccess reports spans a receiver did not keep.
+type PartialSuccess struct {
+	RejectedSpans Int64  `json:"rejectedSpans,omitempty"`
+	ErrorMessage  string `json:"errorMessage,omitempty"`
+}
+
+// ResourceSpans are the spans of one resource, such as a service.
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+}
+
+// Resource describes the entity producing spans.
+type Resource struct {
+	Attributes []KeyValue `json:"attributes,omitempty"`
+}
+
+// ScopeSpans are the spans of one instrumentation scope.
+type ScopeSpans struct {
+	Scope Scope  `json:"scope"`
+	Spans []Span `json:"spans"`
+}
+
+// Scope identifies the instrumentation that produced spans.
+type Scope struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// Span is one timed operation. IDs are lower-case hex, as the JSON
+// encoding requires.
+type Span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              SpanKind   `json:"kind"`
+	StartTimeUnixNano Int64      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   Int64      `json:"endTimeUnixNano"`
+	Attributes        []KeyValue `json:"attributes,omitempty"`
+	Status            Status     `json:"status"`
+}
+
+// Start returns the start time of s.
+func (s Span) Start() time.Time {
+	return time.Unix(0, int64(s.StartTimeUnixNano))
+}
+
+// End returns the end time of s.
+func (s Span) End() time.Time {
+	return time.Unix(0, int64(s.EndTimeUnixNano))
+}
+
+// Status is the outcome of a span.
+type Status struct {
+	Code    StatusCode `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+// KeyValue is an attribute.
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// String returns a string attribute.
+func String(key, value string) KeyValue {
+	return KeyValue{Key: key, Value: AnyValue{StringValue: &value}}
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int64) KeyValue {
+	v := Int64(value)
+	return KeyValue{Key: key, Value: AnyValue{IntValue: &v}}
+}
+
+// AnyValue is an attribute value. Exactly one field is set.
+type AnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *Int64   `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// String formats the value, or returns "" for types not modelled here.
+func (v AnyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	case v.IntValue != nil:
+		return strconv.FormatInt(int64(*v.IntValue), 10)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'g', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// Attributes returns attrs as a map of formatted values.
+func Attributes(attrs []KeyValue) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, kv := range attrs {
+		m[kv.Key] = kv.Value.String()
+	}
+	return m
+}
+
+// Int64 is a 64-bit integer, encoded as a JSON string as OTLP requires
+// and decoded from a string or a number.
+type Int64 int64
+
+// MarshalJSON encodes i as a decimal string.
+func (i Int64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(i), 10))
+}
+
+// UnmarshalJSON decodes a decimal string or a number.
+func (i *Int64) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(b, &n); err != nil {
+			return fmt.Errorf("otlp: integer must be a string or number, got %s", b)
+		}
+		s = n.String()
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("otlp: invalid integer %q: %w", s, err)
+	}
+	*i = Int64(v)
+	return nil
+}
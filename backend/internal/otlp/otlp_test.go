@@ -0,0 +1,38 @@
+package otlp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInt64_JSON(t *testing.T) {
+	b, err := json.Marshal(Int64(1767225600000000000))
+	if err != nil || string(b) != `"1767225600000000000"` {
+		t.Fatalf("Marshal: got %s (%v)", b, err)
+	}
+
+	for _, in := range []string{`"1767225600000000000"`, `1767225600000000000`} {
+		var i Int64
+		if err := json.Unmarshal([]byte(in), &i); err != nil || i != 1767225600000000000 {
+			t.Errorf("Unmarshal(%s): got %d (%v)", in, i, err)
+		}
+	}
+	for _, in := range []string{`"soon"`, `true`, `1.5`} {
+		var i Int64
+		if err := json.Unmarshal([]byte(in), &i); err == nil {
+			t.Errorf("Unmarshal(%s): expected an error", in)
+		}
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	yes := true
+	attrs := Attributes([]KeyValue{
+		String(AttrServiceName, "orders"),
+		Int(AttrHTTPStatus, 200),
+		{Key: "flag", Value: AnyValue{BoolValue: &yes}},
+	})
+	if attrs[AttrServiceName] != "orders" || attrs[AttrHTTPStatus] != "200" || attrs["flag"] != "true" {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// Compile-time assertion that FileDeploymentStore implements DeploymentStore.
+var _ DeploymentStore = (*FileDeploymentStore)(nil)
+
+const defaultDeploymentDir = "./data/deployments"
+
+// FileDeploymentStore implements DeploymentStore using individual JSON files
+// on disk, one per deployment.
+type FileDeploymentStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewFileDeploymentStore creates a FileDeploymentStore that persists records
+// in the given directory. If dir is empty, the default directory is used. The
+// directory is created if it does not exist.
+func NewFileDeploymentStore(dir string) (*FileDeploymentStore, error) {
+	if dir == "" {
+		dir = defaultDeploymentDir
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create deployment storage directory: %w", err)
+	}
+	return &FileDeploymentStore{dir: dir}, nil
+}
+
+// Create persists a new deployment record with a generated UUID and returns
+// the stored copy.
+func (fs *FileDeploymentStore) Create(d *model.Deployment) (*model.Deployment, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	out := *d
+	out.ID = uuid.New().String()
+
+	if err := fs.write(&out); err != nil {
+		return nil, fmt.Errorf("create deployment: %w", err)
+	}
+	return &out, nil
+}
+
+// Get retrieves a deployment record by ID. Returns ErrDeploymentNotFound if
+// the file does not exist.
+func (fs *FileDeploymentStore) Get(id string) (*model.Deployment, error) {
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	return fs.read(id)
+}
+
+// Update replaces an existing deployment record. Returns
+// ErrDeploymentNotFound if the ID does not exist.
+func (fs *FileDeploymentStore) Update(id string, d *model.Deployment) (*model.Deployment, error) {
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, err := fs.read(id); err != nil {
+		return nil, err
+	}
+
+	out := *d
+	out.ID = id
+	if err := fs.write(&out); err != nil {
+		return nil, fmt.Errorf("update deployment: %w", err)
+	}
+	return &out, nil
+}
+
+// List returns every deployment record, most recently started first.
+func (fs *FileDeploymentStore) List() ([]model.Deployment, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	entries, err := os.ReadDir(fs.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read deployment storage directory: %w", err)
+	}
+
+	deployments := []model.Deployment{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		d, err := fs.read(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, *d)
+	}
+
+	sort.SliceStable(deployments, func(i, j int) bool {
+		return deployments[i].StartedAt.After(deployments[j].StartedAt)
+	})
+	return deployments, nil
+}
+
+func (fs *FileDeploymentStore) filePath(id string) string {
+	return filepath.Join(fs.dir, id+".json")
+}
+
+func (fs *FileDeploymentStore) read(id string) (*model.Deployment, error) {
+	data, err := os.ReadFile(fs.filePath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrDeploymentNotFound
+		}
+		return nil, fmt.Errorf("read deployment file: %w", err)
+	}
+
+	var d model.Deployment
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("unmarshal deployment: %w", err)
+	}
+	return &d, nil
+}
+
+func (fs *FileDeploymentStore) write(d *model.Deployment) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal deployment: %w", err)
+	}
+	return writeFileAtomic(fs.dir, fs.filePath(d.ID), data)
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+func newTestDeploymentStore(t *testing.T) *FileDeploymentStore {
+	t.Helper()
+	store, err := NewFileDeploymentStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileDeploymentStore: %v", err)
+	}
+	return store
+}
+
+func sampleDeployment(startedAt time.Time) *model.Deployment {
+	return &model.Deployment{
+		DiagramID:       "d1",
+		DiagramName:     "Test Diagram",
+		DiagramRevision: 3,
+		Mode:            docker.ModeSimulated,
+		Status:          model.DeploymentDeploying,
+		StartedAt:       startedAt,
+		Containers: []model.DeployedContainer{
+			{NodeID: "n1", Config: docker.ContainerConfig{Name: "db", Image: "postgres:16"}},
+		},
+	}
+}
+
+func TestDeploymentStore_CreateGetUpdate(t *testing.T) {
+	store := newTestDeploymentStore(t)
+	started := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	created, err := store.Create(sampleDeployment(started))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.ID == "" {
+		t.Fatal("expected non-empty ID")
+	}
+
+	ended := started.Add(time.Minute)
+	created.Status = model.DeploymentTornDown
+	created.EndedAt = &ended
+	created.Containers[0].ContainerID = "abc"
+	if _, err := store.Update(created.ID, created); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got, err := store.Get(created.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Status != model.DeploymentTornDown || got.EndedAt == nil || !got.EndedAt.Equal(ended) {
+		t.Errorf("unexpected record %+v", got)
+	}
+	if got.DiagramRevision != 3 || got.Containers[0].ContainerID != "abc" || got.Containers[0].Config.Image != "postgres:16" {
+		t.Errorf("unexpected record contents %+v", got)
+	}
+}
+
+func TestDeploymentStore_ListNewestFirst(t *testing.T) {
+	store := newTestDeploymentStore(t)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, offset := range []time.Duration{time.Hour, 0, 2 * time.Hour} {
+		if _, err := store.Create(sampleDeployment(base.Add(offset))); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("List: got %d, want 3", len(list))
+	}
+	for i := 1; i < len(list); i++ {
+		if list[i].StartedAt.After(list[i-1].StartedAt) {
+			t.Errorf("expected newest first, got %v before %v", list[i-1].StartedAt, list[i].StartedAt)
+		}
+	}
+}
+
+func TestDeploymentStore_ListEmpty(t *testing.T) {
+	list, err := newTestDeploymentStore(t).List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if list == nil || len(list) != 0 {
+		t.Errorf("expected empty non-nil list, got %v", list)
+	}
+}
+
+func TestDeploymentStore_NotFound(t *testing.T) {
+	store := newTestDeploymentStore(t)
+	if _, err := store.Get("missing"); !errors.Is(err, ErrDeploymentNotFound) {
+		t.Errorf("Get: expected ErrDeploymentNotFound, got %v", err)
+	}
+	if _, err := store.Update("missing", sampleDeployment(time.Now())); !errors.Is(err, ErrDeploymentNotFound) {
+		t.Errorf("Update: expected ErrDeploymentNotFound, got %v", err)
+	}
+	if _, err := store.Get("../etc"); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Get: expected ErrInvalidID, got %v", err)
+	}
+}
@@ -39,13 +39,15 @@ func NewFileStore(dir string) (*FileStore, error) {
 	return &FileStore{dir: dir}, nil
 }
 
-// Create persists a new diagram with a generated UUID and returns the stored copy.
+// Create persists a new diagram with a generated UUID at revision 1 and
+// returns the stored copy.
 func (fs *FileStore) Create(d *model.Diagram) (*model.Diagram, error) {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
 	out := *d
 	out.ID = uuid.New().String()
+	out.Revision = 1
 
 	if err := fs.writeDiagram(&out); err != nil {
 		return nil, fmt.Errorf("create diagram: %w", err)
@@ -65,7 +67,8 @@ func (fs *FileStore) Get(id string) (*model.Diagram, error) {
 	return fs.readDiagram(id)
 }
 
-// Update replaces an existing diagram on disk. Returns ErrNotFound if the ID does not exist.
+// Update replaces an existing diagram on disk and increments its revision.
+// Returns ErrNotFound if the ID does not exist.
 func (fs *FileStore) Update(id string, d *model.Diagram) (*model.Diagram, error) {
 	if err := validateID(id); err != nil {
 		return nil, err
@@ -74,12 +77,14 @@ func (fs *FileStore) Update(id string, d *model.Diagram) (*model.Diagram, error)
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
-	if _, err := fs.readDiagram(id); err != nil {
+	existing, err := fs.readDiagram(id)
+	if err != nil {
 		return nil, err
 	}
 
 	out := *d
 	out.ID = id
+	out.Revision = existing.Revision + 1
 	if err := fs.writeDiagram(&out); err != nil {
 		return nil, fmt.Errorf("update diagram: %w", err)
 	}
@@ -119,9 +124,12 @@ func (fs *FileStore) writeDiagram(d *model.Diagram) error {
 	if err != nil {
 		return fmt.Errorf("marshal diagram: %w", err)
 	}
+	return writeFileAtomic(fs.dir, fs.filePath(d.ID), data)
+}
 
-	// Write to temp file first, then rename for atomicity.
-	tmp, err := os.CreateTemp(fs.dir, "*.tmp")
+// writeFileAtomic writes data to a temp file in dir, then renames it to path.
+func writeFileAtomic(dir, path string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, "*.tmp")
 	if err != nil {
 		return fmt.Errorf("create temp file: %w", err)
 	}
@@ -137,7 +145,7 @@ func (fs *FileStore) writeDiagram(d *model.Diagram) error {
 		return fmt.Errorf("close temp file: %w", err)
 	}
 
-	if err := os.Rename(tmpPath, fs.filePath(d.ID)); err != nil {
+	if err := os.Rename(tmpPath, path); err != nil {
 		_ = os.Remove(tmpPath)
 		return fmt.Errorf("rename temp file: %w", err)
 	}
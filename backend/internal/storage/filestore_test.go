@@ -110,6 +110,37 @@ func TestUpdate_PersistsChanges(t *testing.T) {
 	}
 }
 
+func TestRevision_IncrementsOnUpdate(t *testing.T) {
+	store := newTestStore(t)
+	in := sampleDiagram()
+	in.Revision = 42
+	created, err := store.Create(in)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.Revision != 1 {
+		t.Errorf("Revision after create: got %d, want 1", created.Revision)
+	}
+
+	for want := 2; want <= 3; want++ {
+		updated, err := store.Update(created.ID, sampleDiagram())
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if updated.Revision != want {
+			t.Errorf("Revision after update: got %d, want %d", updated.Revision, want)
+		}
+	}
+
+	got, err := store.Get(created.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Revision != 3 {
+		t.Errorf("Revision after re-read: got %d, want 3", got.Revision)
+	}
+}
+
 func TestGet_NotFound(t *testing.T) {
 	store := newTestStore(t)
 	_, err := store.Get("nonexistent-id")
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// Compile-time assertion that FileProfileStore implements ProfileStore.
+var _ ProfileStore = (*FileProfileStore)(nil)
+
+const defaultProfileDir = "./data/profiles"
+
+// FileProfileStore implements ProfileStore using individual JSON files on
+// disk, one per load profile.
+type FileProfileStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewFileProfileStore creates a FileProfileStore that persists profiles in
+// the given directory. If dir is empty, the default directory is used. The
+// directory is created if it does not exist.
+func NewFileProfileStore(dir string) (*FileProfileStore, error) {
+	if dir == "" {
+		dir = defaultProfileDir
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create profile storage directory: %w", err)
+	}
+	return &FileProfileStore{dir: dir}, nil
+}
+
+// Create persists a new profile with a generated UUID and returns the
+// stored copy.
+func (fs *FileProfileStore) Create(p *model.LoadProfile) (*model.LoadProfile, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	out := *p
+	out.ID = uuid.New().String()
+	out.Preset = false
+	out.CreatedAt = time.Now().UTC()
+	out.UpdatedAt = out.CreatedAt
+
+	if err := fs.write(&out); err != nil {
+		return nil, fmt.Errorf("create profile: %w", err)
+	}
+	return &out, nil
+}
+
+// Get retrieves a profile by ID. Returns ErrProfileNotFound if the file
+// does not exist.
+func (fs *FileProfileStore) Get(id string) (*model.LoadProfile, error) {
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	return fs.read(id)
+}
+
+// Update replaces an existing profile, keeping its creation time. Returns
+// ErrProfileNotFound if the ID does not exist.
+func (fs *FileProfileStore) Update(id string, p *model.LoadProfile) (*model.LoadProfile, error) {
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	existing, err := fs.read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := *p
+	out.ID = id
+	out.Preset = false
+	out.CreatedAt = existing.CreatedAt
+	out.UpdatedAt = time.Now().UTC()
+	if err := fs.write(&out); err != nil {
+		return nil, fmt.Errorf("update profile: %w", err)
+	}
+	return &out, nil
+}
+
+// Delete removes a profile. Returns ErrProfileNotFound if the ID does not
+// exist.
+func (fs *FileProfileStore) Delete(id string) error {
+	if err := validateID(id); err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := os.Remove(fs.filePath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrProfileNotFound
+		}
+		return fmt.Errorf("delete profile: %w", err)
+	}
+	return nil
+}
+
+// List returns every profile, ordered by name.
+func (fs *FileProfileStore) List() ([]model.LoadProfile, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	entries, err := os.ReadDir(fs.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read profile storage directory: %w", err)
+	}
+
+	profiles := []model.LoadProfile{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		p, err := fs.read(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *p)
+	}
+
+	sort.SliceStable(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles, nil
+}
+
+func (fs *FileProfileStore) filePath(id string) string {
+	return filepath.Join(fs.dir, id+".json")
+}
+
+func (fs *FileProfileStore) read(id string) (*model.LoadProfile, error) {
+	data, err := os.ReadFile(fs.filePath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrProfileNotFound
+		}
+		return nil, fmt.Errorf("read profile file: %w", err)
+	}
+
+	var p model.LoadProfile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal profile: %w", err)
+	}
+	return &p, nil
+}
+
+func (fs *FileProfileStore) write(p *model.LoadProfile) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal profile: %w", err)
+	}
+	return writeFileAtomic(fs.dir, fs.filePath(p.ID), data)
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+func newTestProfileStore(t *testing.T) *FileProfileStore {
+	t.Helper()
+	store, err := NewFileProfileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileProfileStore: %v", err)
+	}
+	return store
+}
+
+func sampleProfile(name string) *model.LoadProfile {
+	return &model.LoadProfile{
+		Name:   name,
+		Model:  model.LoadModelOpen,
+		Stages: []model.LoadStage{{DurationSeconds: 60, Target: 100}},
+		Preset: true, // never persisted
+	}
+}
+
+func TestProfileStore_CRUD(t *testing.T) {
+	store := newTestProfileStore(t)
+
+	created, err := store.Create(sampleProfile("ramp"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.ID == "" || created.Preset || created.CreatedAt.IsZero() || !created.UpdatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("unexpected created profile %+v", created)
+	}
+
+	update := sampleProfile("ramp")
+	update.Stages = append(update.Stages, model.LoadStage{DurationSeconds: 30, Target: 100, Ramp: model.RampStep})
+	updated, err := store.Update(created.ID, update)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) || updated.UpdatedAt.Before(created.UpdatedAt) || updated.Preset {
+		t.Errorf("unexpected timestamps or preset flag %+v", updated)
+	}
+
+	got, err := store.Get(created.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(got.Stages) != 2 || got.Stages[1].Ramp != model.RampStep {
+		t.Errorf("unexpected stages %+v", got.Stages)
+	}
+
+	if err := store.Delete(created.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(created.ID); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Get after Delete: expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+func TestProfileStore_ListByName(t *testing.T) {
+	store := newTestProfileStore(t)
+	for _, name := range []string{"soak", "burst", "night"} {
+		if _, err := store.Create(sampleProfile(name)); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 3 || list[0].Name != "burst" || list[1].Name != "night" || list[2].Name != "soak" {
+		t.Errorf("expected profiles by name, got %+v", list)
+	}
+}
+
+func TestProfileStore_NotFound(t *testing.T) {
+	store := newTestProfileStore(t)
+	if _, err := store.Get("missing"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Get: expected ErrProfileNotFound, got %v", err)
+	}
+	if _, err := store.Update("missing", sampleProfile("x")); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Update: expected ErrProfileNotFound, got %v", err)
+	}
+	if err := store.Delete("missing"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Delete: expected ErrProfileNotFound, got %v", err)
+	}
+	if err := store.Delete("../etc"); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Delete: expected ErrInvalidID, got %v", err)
+	}
+}
@@ -9,6 +9,14 @@ import (
 // ErrNotFound is returned when a diagram ID does not exist in the store.
 var ErrNotFound = errors.New("diagram not found")
 
+// ErrDeploymentNotFound is returned when a deployment ID does not exist in
+// the store.
+var ErrDeploymentNotFound = errors.New("deployment not found")
+
+// ErrProfileNotFound is returned when a load profile ID does not exist in
+// the store.
+var ErrProfileNotFound = errors.New("profile not found")
+
 // DiagramStore defines the persistence operations for diagrams.
 type DiagramStore interface {
 	// Create persists a new diagram and returns it with a generated ID.
@@ -20,3 +28,41 @@ type DiagramStore interface {
 	// Update replaces an existing diagram. Returns ErrNotFound if the ID does not exist.
 	Update(id string, d *model.Diagram) (*model.Diagram, error)
 }
+
+// DeploymentStore defines the persistence operations for deployment records.
+type DeploymentStore interface {
+	// Create persists a new deployment record and returns it with a generated ID.
+	Create(d *model.Deployment) (*model.Deployment, error)
+
+	// Get retrieves a deployment by ID. Returns ErrDeploymentNotFound if it
+	// does not exist.
+	Get(id string) (*model.Deployment, error)
+
+	// Update replaces an existing deployment record. Returns
+	// ErrDeploymentNotFound if the ID does not exist.
+	Update(id string, d *model.Deployment) (*model.Deployment, error)
+
+	// List returns every deployment, most recently started first.
+	List() ([]model.Deployment, error)
+}
+
+// ProfileStore defines the persistence operations for load profiles.
+type ProfileStore interface {
+	// Create persists a new profile and returns it with a generated ID.
+	Create(p *model.LoadProfile) (*model.LoadProfile, error)
+
+	// Get retrieves a profile by ID. Returns ErrProfileNotFound if it does
+	// not exist.
+	Get(id string) (*model.LoadProfile, error)
+
+	// Update replaces an existing profile. Returns ErrProfileNotFound if the
+	// ID does not exist.
+	Update(id string, p *model.LoadProfile) (*model.LoadProfile, error)
+
+	// Delete removes a profile. Returns ErrProfileNotFound if the ID does
+	// not exist.
+	Delete(id string) error
+
+	// List returns every profile, ordered by name.
+	List() ([]model.LoadProfile, error)
+}
@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/otlp"
+)
+
+// Receiver defaults. Containers reach the backend on the Docker host
+// through HostGateway, which ExportHook maps to the host's address.
+const (
+	DefaultReceiverAddr = ":4318"
+	HostGateway         = "host.docker.internal"
+	DefaultEndpoint     = "http://" + HostGateway + ":4318"
+)
+
+// ExportHook returns a pre-create hook that points the mock servers of
+// api-service nodes at the OTLP receiver at endpoint, through the standard
+// OpenTelemetry variables. Their resource carries the node and deployment
+// IDs from the container's labels, so spans are filed without guessing.
+// Values are percent-encoded as the OpenTelemetry specification requires,
+// so IDs containing "," or "=" survive.
+func ExportHook(endpoint string) docker.PreCreateHook {
+	return func(_ context.Context, cfg *docker.ContainerConfig) error {
+		if cfg.Labels[docker.LabelServiceType] != string(model.ServiceTypeAPIService) {
+			return nil
+		}
+		if cfg.Env == nil {
+			cfg.Env = make(map[string]string)
+		}
+		var resource []string
+		if v := cfg.Labels[docker.LabelNodeID]; v != "" {
+			resource = append(resource, otlp.AttrNodeID+"="+url.PathEscape(v))
+		}
+		if v := cfg.Labels[docker.LabelDeploymentID]; v != "" {
+			resource = append(resource, otlp.AttrDeploymentID+"="+url.PathEscape(v))
+		}
+
+		cfg.Env["OTEL_EXPORTER_OTLP_ENDPOINT"] = endpoint
+		cfg.Env["OTEL_SERVICE_NAME"] = cfg.Name
+		cfg.Env["OTEL_RESOURCE_ATTRIBUTES"] = strings.Join(resource, ",")
+		cfg.ExtraHosts = append(cfg.ExtraHosts, HostGateway+":host-gateway")
+		return nil
+	}
+}
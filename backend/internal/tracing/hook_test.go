@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+func TestExportHook(t *testing.T) {
+	hook := ExportHook(DefaultEndpoint)
+
+	cfg := &docker.ContainerConfig{Name: "orders", Labels: map[string]string{
+		docker.LabelServiceType:  string(model.ServiceTypeAPIService),
+		docker.LabelNodeID:       "n1",
+		docker.LabelDeploymentID: "dep-1",
+	}}
+	if err := hook(context.Background(), cfg); err != nil {
+		t.Fatalf("hook: %v", err)
+	}
+	want := map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT": DefaultEndpoint,
+		"OTEL_SERVICE_NAME":           "orders",
+		"OTEL_RESOURCE_ATTRIBUTES":    "heph.node_id=n1,heph.deployment_id=dep-1",
+	}
+	for k, v := range want {
+		if cfg.Env[k] != v {
+			t.Errorf("%s: got %q, want %q", k, cfg.Env[k], v)
+		}
+	}
+	if len(cfg.ExtraHosts) != 1 || cfg.ExtraHosts[0] != "host.docker.internal:host-gateway" {
+		t.Errorf("unexpected extra hosts %v", cfg.ExtraHosts)
+	}
+
+	odd := &docker.ContainerConfig{Name: "odd", Labels: map[string]string{
+		docker.LabelServiceType: string(model.ServiceTypeAPIService),
+		docker.LabelNodeID:      "a,b=c d%",
+	}}
+	if err := hook(context.Background(), odd); err != nil {
+		t.Fatalf("hook: %v", err)
+	}
+	if got, want := odd.Env["OTEL_RESOURCE_ATTRIBUTES"], "heph.node_id=a%2Cb=c%20d%25"; got != want {
+		t.Errorf("expected the node ID to be percent-encoded as %q, got %q", want, got)
+	}
+
+	db := &docker.ContainerConfig{Name: "db", Labels: map[string]string{docker.LabelServiceType: string(model.ServiceTypePostgreSQL)}}
+	if err := hook(context.Background(), db); err != nil {
+		t.Fatalf("hook: %v", err)
+	}
+	if db.Env != nil || db.ExtraHosts != nil {
+		t.Errorf("expected other services untouched, got %+v", db)
+	}
+}
@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"encoding/hex"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/otlp"
+	"github.com/stwalsh4118/hephaestus/backend/internal/tsdb"
+)
+
+// Ingest records the spans of an OTLP export and returns how many were
+// rejected.
+//
+// Spans are filed under the deployment in their resource's
+// heph.deployment_id attribute, or the running deployment in status when
+// it has none. Their node is the resource's heph.node_id, or the node of
+// status whose container name is the resource's service.name; a client
+// span's target node is the node named by its peer.service. Spans with
+// malformed IDs, and spans of no deployment, are rejected.
+func (s *Store) Ingest(req otlp.ExportTraceServiceRequest, status deploy.Status) int {
+	current := ""
+	if status.State == deploy.StateDeployed {
+		current = tsdb.DeploymentID(status)
+	}
+	nodeByName := make(map[string]string, len(status.Nodes))
+	for _, n := range status.Nodes {
+		nodeByName[n.Name] = n.NodeID
+	}
+
+	rejected := 0
+	for _, rs := range req.ResourceSpans {
+		resource := otlp.Attributes(rs.Resource.Attributes)
+		deploymentID := resource[otlp.AttrDeploymentID]
+		if deploymentID == "" {
+			deploymentID = current
+		}
+		// Names only identify nodes of the running deployment.
+		names := nodeByName
+		if deploymentID != current {
+			names = nil
+		}
+		nodeID := resource[otlp.AttrNodeID]
+		if nodeID == "" {
+			nodeID = names[resource[otlp.AttrServiceName]]
+		}
+
+		var spans []Span
+		for _, ss := range rs.ScopeSpans {
+			for _, in := range ss.Spans {
+				if deploymentID == "" || !validID(in.TraceID, 16) || !validID(in.SpanID, 8) ||
+					(in.ParentSpanID != "" && !validID(in.ParentSpanID, 8)) {
+					rejected++
+					continue
+				}
+				spans = append(spans, convert(in, resource[otlp.AttrServiceName], nodeID, names))
+			}
+		}
+		if len(spans) > 0 {
+			rejected += s.Add(deploymentID, spans)
+		}
+	}
+	return rejected
+}
+
+// convert builds a Span from an exported one.
+func convert(in otlp.Span, service, nodeID string, nodeByName map[string]string) Span {
+	attrs := otlp.Attributes(in.Attributes)
+	start, end := in.Start(), in.End()
+	if end.Before(start) {
+		end = start
+	}
+	sp := Span{
+		TraceID:      in.TraceID,
+		SpanID:       in.SpanID,
+		ParentSpanID: in.ParentSpanID,
+		Name:         in.Name,
+		Kind:         in.Kind.String(),
+		Service:      service,
+		NodeID:       nodeID,
+		Start:        start.UTC(),
+		End:          end.UTC(),
+		DurationMs:   float64(end.Sub(start)) / 1e6,
+		Error:        in.Status.Code == otlp.StatusError,
+		Message:      in.Status.Message,
+		Attributes:   attrs,
+	}
+	if in.Kind == otlp.SpanKindClient {
+		sp.TargetNodeID = nodeByName[attrs[otlp.AttrPeerService]]
+	}
+	return sp
+}
+
+// validID reports whether id is n bytes of hex, not all zero.
+func validID(id string, n int) bool {
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) != n {
+		return false
+	}
+	for _, c := range b {
+		if c != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/otlp"
+)
+
+const (
+	traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	rootID  = "00f067aa0ba902b7"
+	childID = "00f067aa0ba902b8"
+)
+
+func export(resource []otlp.KeyValue, spans ...otlp.Span) otlp.ExportTraceServiceRequest {
+	return otlp.ExportTraceServiceRequest{ResourceSpans: []otlp.ResourceSpans{{
+		Resource:   otlp.Resource{Attributes: resource},
+		ScopeSpans: []otlp.ScopeSpans{{Spans: spans}},
+	}}}
+}
+
+func exported(id, parent string, kind otlp.SpanKind, attrs ...otlp.KeyValue) otlp.Span {
+	return otlp.Span{
+		TraceID:           traceID,
+		SpanID:            id,
+		ParentSpanID:      parent,
+		Name:              "GET /orders",
+		Kind:              kind,
+		StartTimeUnixNano: otlp.Int64(t0.UnixNano()),
+		EndTimeUnixNano:   otlp.Int64(t0.Add(15 * time.Millisecond).UnixNano()),
+		Attributes:        attrs,
+	}
+}
+
+var deployed = deploy.Status{
+	State:        deploy.StateDeployed,
+	DiagramID:    "diagram",
+	DeploymentID: "dep-1",
+	Nodes: []deploy.NodeStatus{
+		{NodeID: "n-orders", Name: "orders"},
+		{NodeID: "n-users", Name: "users"},
+	},
+}
+
+func TestIngest_ResolvesNodesByName(t *testing.T) {
+	s := NewStore()
+	rejected := s.Ingest(export([]otlp.KeyValue{otlp.String(otlp.AttrServiceName, "orders")},
+		exported(rootID, "", otlp.SpanKindServer),
+		exported(childID, rootID, otlp.SpanKindClient, otlp.String(otlp.AttrPeerService, "users")),
+	), deployed)
+	if rejected != 0 {
+		t.Fatalf("expected no rejected spans, got %d", rejected)
+	}
+
+	w, err := s.Trace("dep-1", traceID)
+	if err != nil {
+		t.Fatalf("Trace: %v", err)
+	}
+	if len(w.Spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(w.Spans))
+	}
+	root, client := w.Spans[0], w.Spans[1]
+	if root.NodeID != "n-orders" || root.Service != "orders" || root.Kind != "server" || root.DurationMs != 15 {
+		t.Errorf("unexpected root span %+v", root)
+	}
+	if client.NodeID != "n-orders" || client.TargetNodeID != "n-users" {
+		t.Errorf("expected the client span to resolve its target, got %+v", client)
+	}
+}
+
+func TestIngest_PrefersResourceIDs(t *testing.T) {
+	s := NewStore()
+	s.Ingest(export([]otlp.KeyValue{
+		otlp.String(otlp.AttrServiceName, "orders"),
+		otlp.String(otlp.AttrNodeID, "n-old"),
+		otlp.String(otlp.AttrDeploymentID, "dep-0"),
+	}, exported(rootID, "", otlp.SpanKindClient, otlp.String(otlp.AttrPeerService, "users"))), deployed)
+
+	w, err := s.Trace("dep-0", traceID)
+	if err != nil {
+		t.Fatalf("expected the span under its own deployment: %v", err)
+	}
+	// Names of the running deployment do not apply to a past one.
+	if sp := w.Spans[0]; sp.NodeID != "n-old" || sp.TargetNodeID != "" {
+		t.Errorf("unexpected span %+v", sp)
+	}
+}
+
+func TestIngest_RejectsUnfileableSpans(t *testing.T) {
+	s := NewStore()
+	bad := exported("xyz", "", otlp.SpanKindServer)
+	zero := exported("0000000000000000", "", otlp.SpanKindServer)
+	badParent := exported(childID, "1234", otlp.SpanKindServer)
+	if got := s.Ingest(export(nil, bad, zero, badParent, exported(rootID, "", otlp.SpanKindServer)), deployed); got != 3 {
+		t.Errorf("expected 3 malformed spans rejected, got %d", got)
+	}
+	if got := s.Ingest(export(nil, exported(rootID, "", otlp.SpanKindServer)), deploy.Status{State: deploy.StateIdle}); got != 1 {
+		t.Errorf("expected a span of no deployment rejected, got %d", got)
+	}
+}
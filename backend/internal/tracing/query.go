@@ -0,0 +1,282 @@
+package tracing
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// DefaultTraceLimit is how many traces a listing returns by default.
+const DefaultTraceLimit = 50
+
+// TraceSummary describes a trace in a listing.
+type TraceSummary struct {
+	TraceID string `json:"traceId"`
+	// Root is the name of the earliest span without a received parent.
+	Root       string    `json:"root"`
+	RootNodeID string    `json:"rootNodeId,omitempty"`
+	Start      time.Time `json:"start"`
+	DurationMs float64   `json:"durationMs"`
+	Spans      int       `json:"spans"`
+	Errors     int       `json:"errors"`
+	// Nodes are the nodes with spans in the trace, sorted.
+	Nodes []string `json:"nodes"`
+}
+
+// TraceFilter selects traces in a listing.
+type TraceFilter struct {
+	// Node keeps traces with a span of this node.
+	Node string
+	// MinDuration keeps traces lasting at least this long.
+	MinDuration time.Duration
+	// ErrorsOnly keeps traces with a failed span.
+	ErrorsOnly bool
+	// Limit bounds the result; 0 means DefaultTraceLimit.
+	Limit int
+}
+
+// WaterfallSpan is a span placed in its trace's waterfall.
+type WaterfallSpan struct {
+	Span
+	// Depth is the number of ancestors of the span in the trace.
+	Depth int `json:"depth"`
+	// OffsetMs is the time from the start of the trace to the span's start.
+	OffsetMs float64 `json:"offsetMs"`
+}
+
+// Waterfall is a trace with its spans in call order: each span follows its
+// parent, and siblings are ordered by start time.
+type Waterfall struct {
+	TraceID    string          `json:"traceId"`
+	Start      time.Time       `json:"start"`
+	DurationMs float64         `json:"durationMs"`
+	Spans      []WaterfallSpan `json:"spans"`
+}
+
+// LatencyStats summarises durations, in milliseconds.
+type LatencyStats struct {
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// EdgeStats aggregates the calls made from one node to another, as seen by
+// the caller's client spans.
+type EdgeStats struct {
+	Source    string       `json:"source"`
+	Target    string       `json:"target"`
+	Calls     int          `json:"calls"`
+	Errors    int          `json:"errors"`
+	ErrorRate float64      `json:"errorRate"`
+	Latency   LatencyStats `json:"latencyMs"`
+}
+
+// Traces returns the traces of deploymentID matching f, most recently
+// started first.
+func (s *Store) Traces(deploymentID string, f TraceFilter) []TraceSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []TraceSummary{}
+	d, ok := s.deployments[deploymentID]
+	if !ok {
+		return result
+	}
+	for id, t := range d.traces {
+		sum := summarise(id, t.spans)
+		if f.Node != "" && !slices.Contains(sum.Nodes, f.Node) {
+			continue
+		}
+		if sum.DurationMs < float64(f.MinDuration)/1e6 || (f.ErrorsOnly && sum.Errors == 0) {
+			continue
+		}
+		result = append(result, sum)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Start.Equal(result[j].Start) {
+			return result[i].Start.After(result[j].Start)
+		}
+		return result[i].TraceID < result[j].TraceID
+	})
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultTraceLimit
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// Trace returns the waterfall of a trace of deploymentID.
+func (s *Store) Trace(deploymentID, traceID string) (Waterfall, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	d, ok := s.deployments[deploymentID]
+	if !ok {
+		return Waterfall{}, ErrNotFound
+	}
+	t, ok := d.traces[traceID]
+	if !ok {
+		return Waterfall{}, ErrNotFound
+	}
+
+	start, end := bounds(t.spans)
+	w := Waterfall{
+		TraceID:    traceID,
+		Start:      start,
+		DurationMs: ms(end.Sub(start)),
+		Spans:      make([]WaterfallSpan, 0, len(t.spans)),
+	}
+	roots, children := tree(t.spans)
+	var visit func(sp Span, depth int)
+	visit = func(sp Span, depth int) {
+		w.Spans = append(w.Spans, WaterfallSpan{Span: sp, Depth: depth, OffsetMs: ms(sp.Start.Sub(start))})
+		for _, c := range children[sp.SpanID] {
+			visit(c, depth+1)
+		}
+	}
+	for _, r := range roots {
+		visit(r, 0)
+	}
+	return w, nil
+}
+
+// Edges aggregates the client spans of deploymentID that started at or
+// after since, per calling and called node, sorted by source then target.
+// Calls whose caller or target is unknown are left out.
+func (s *Store) Edges(deploymentID string, since time.Time) []EdgeStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []EdgeStats{}
+	d, ok := s.deployments[deploymentID]
+	if !ok {
+		return result
+	}
+	type edge struct{ source, target string }
+	durations := make(map[edge][]float64)
+	errors := make(map[edge]int)
+	for _, t := range d.traces {
+		for _, sp := range t.spans {
+			if sp.NodeID == "" || sp.TargetNodeID == "" || sp.Start.Before(since) {
+				continue
+			}
+			e := edge{sp.NodeID, sp.TargetNodeID}
+			durations[e] = append(durations[e], sp.DurationMs)
+			if sp.Error {
+				errors[e]++
+			}
+		}
+	}
+
+	for e, ds := range durations {
+		result = append(result, EdgeStats{
+			Source:    e.source,
+			Target:    e.target,
+			Calls:     len(ds),
+			Errors:    errors[e],
+			ErrorRate: float64(errors[e]) / float64(len(ds)),
+			Latency:   latency(ds),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Source != result[j].Source {
+			return result[i].Source < result[j].Source
+		}
+		return result[i].Target < result[j].Target
+	})
+	return result
+}
+
+// summarise builds the summary of a trace.
+func summarise(id string, spans []Span) TraceSummary {
+	start, end := bounds(spans)
+	sum := TraceSummary{TraceID: id, Start: start, DurationMs: ms(end.Sub(start)), Spans: len(spans), Nodes: []string{}}
+	if roots, _ := tree(spans); len(roots) > 0 {
+		sum.Root, sum.RootNodeID = roots[0].Name, roots[0].NodeID
+	}
+	for _, sp := range spans {
+		if sp.Error {
+			sum.Errors++
+		}
+		if sp.NodeID != "" && !slices.Contains(sum.Nodes, sp.NodeID) {
+			sum.Nodes = append(sum.Nodes, sp.NodeID)
+		}
+	}
+	sort.Strings(sum.Nodes)
+	return sum
+}
+
+// tree returns the spans without a received parent and the children of
+// every span, each ordered by start time.
+func tree(spans []Span) ([]Span, map[string][]Span) {
+	ids := make(map[string]bool, len(spans))
+	for _, sp := range spans {
+		ids[sp.SpanID] = true
+	}
+	var roots []Span
+	children := make(map[string][]Span)
+	for _, sp := range spans {
+		if sp.ParentSpanID != "" && ids[sp.ParentSpanID] && sp.ParentSpanID != sp.SpanID {
+			children[sp.ParentSpanID] = append(children[sp.ParentSpanID], sp)
+			continue
+		}
+		roots = append(roots, sp)
+	}
+	byStart := func(s []Span) {
+		sort.SliceStable(s, func(i, j int) bool { return s[i].Start.Before(s[j].Start) })
+	}
+	byStart(roots)
+	for _, c := range children {
+		byStart(c)
+	}
+	return roots, children
+}
+
+// bounds returns the earliest start and latest end of spans.
+func bounds(spans []Span) (time.Time, time.Time) {
+	var start, end time.Time
+	for i, sp := range spans {
+		if i == 0 || sp.Start.Before(start) {
+			start = sp.Start
+		}
+		if i == 0 || sp.End.After(end) {
+			end = sp.End
+		}
+	}
+	return start, end
+}
+
+// latency summarises durations in milliseconds.
+func latency(ds []float64) LatencyStats {
+	sort.Float64s(ds)
+	var sum float64
+	for _, d := range ds {
+		sum += d
+	}
+	return LatencyStats{
+		Avg: sum / float64(len(ds)),
+		P50: percentile(ds, 0.5),
+		P95: percentile(ds, 0.95),
+		P99: percentile(ds, 0.99),
+		Max: ds[len(ds)-1],
+	}
+}
+
+// percentile returns the q-quantile of sorted values by the nearest-rank
+// method.
+func percentile(sorted []float64, q float64) float64 {
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// ms converts d to fractional milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
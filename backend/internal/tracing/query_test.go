@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStore_TraceWaterfall(t *testing.T) {
+	s := NewStore()
+	// Spans arrive out of order, as separate services export them.
+	s.Add("d1", []Span{
+		span("t1", "db", "call", 30*time.Millisecond, 10*time.Millisecond),
+		span("t1", "root", "", 0, 100*time.Millisecond),
+		span("t1", "late", "root", 60*time.Millisecond, 20*time.Millisecond),
+		span("t1", "call", "root", 10*time.Millisecond, 40*time.Millisecond),
+	})
+
+	w, err := s.Trace("d1", "t1")
+	if err != nil {
+		t.Fatalf("Trace: %v", err)
+	}
+	if !w.Start.Equal(t0) || w.DurationMs != 100 {
+		t.Errorf("unexpected bounds %v %v", w.Start, w.DurationMs)
+	}
+	want := []struct {
+		id     string
+		depth  int
+		offset float64
+	}{{"root", 0, 0}, {"call", 1, 10}, {"db", 2, 30}, {"late", 1, 60}}
+	if len(w.Spans) != len(want) {
+		t.Fatalf("expected %d spans, got %d", len(want), len(w.Spans))
+	}
+	for i, sp := range w.Spans {
+		if sp.SpanID != want[i].id || sp.Depth != want[i].depth || sp.OffsetMs != want[i].offset {
+			t.Errorf("span %d: got %s depth %d offset %v, want %+v", i, sp.SpanID, sp.Depth, sp.OffsetMs, want[i])
+		}
+	}
+
+	if _, err := s.Trace("d1", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_TraceWithMissingParent(t *testing.T) {
+	s := NewStore()
+	// The caller's span was not received: its child becomes a root.
+	s.Add("d1", []Span{span("t1", "orphan", "gone", 0, time.Millisecond)})
+
+	sum := s.Traces("d1", TraceFilter{})
+	if len(sum) != 1 || sum[0].Root != "orphan" {
+		t.Fatalf("expected the orphan as root, got %+v", sum)
+	}
+}
+
+func TestStore_TracesFilters(t *testing.T) {
+	s := NewStore()
+	fast := span("fast", "a", "", 0, 5*time.Millisecond)
+	fast.NodeID = "gw"
+	slow := span("slow", "a", "", time.Second, 500*time.Millisecond)
+	slow.NodeID = "api"
+	failed := span("failed", "a", "", 2*time.Second, 10*time.Millisecond)
+	failed.NodeID, failed.Error = "api", true
+	s.Add("d1", []Span{fast, slow, failed})
+
+	ids := func(f TraceFilter) []string {
+		var out []string
+		for _, sum := range s.Traces("d1", f) {
+			out = append(out, sum.TraceID)
+		}
+		return out
+	}
+	check := func(name string, got []string, want ...string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+			return
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: got %v, want %v", name, got, want)
+				return
+			}
+		}
+	}
+
+	check("all, newest first", ids(TraceFilter{}), "failed", "slow", "fast")
+	check("node", ids(TraceFilter{Node: "api"}), "failed", "slow")
+	check("minDuration", ids(TraceFilter{MinDuration: 100 * time.Millisecond}), "slow")
+	check("errors", ids(TraceFilter{ErrorsOnly: true}), "failed")
+	check("limit", ids(TraceFilter{Limit: 1}), "failed")
+
+	if got := s.Traces("other", TraceFilter{}); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty listing for an unknown deployment, got %v", got)
+	}
+}
+
+func TestStore_Edges(t *testing.T) {
+	s := NewStore()
+	var spans []Span
+	for i, d := range []time.Duration{10, 20, 30, 40} {
+		sp := span("t1", string(rune('a'+i)), "", time.Duration(i)*time.Second, d*time.Millisecond)
+		sp.Kind, sp.NodeID, sp.TargetNodeID = "client", "gw", "api"
+		sp.Error = i == 3
+		spans = append(spans, sp)
+	}
+	db := span("t2", "db", "", 0, 5*time.Millisecond)
+	db.Kind, db.NodeID, db.TargetNodeID = "client", "api", "db"
+	unknown := span("t2", "ext", "", 0, 5*time.Millisecond)
+	unknown.Kind, unknown.NodeID = "client", "api"
+	s.Add("d1", append(spans, db, unknown))
+
+	edges := s.Edges("d1", time.Time{})
+	if len(edges) != 2 {
+		t.Fatalf("expected 2 edges, got %+v", edges)
+	}
+	if e := edges[0]; e.Source != "api" || e.Target != "db" || e.Calls != 1 {
+		t.Errorf("unexpected first edge %+v", e)
+	}
+	e := edges[1]
+	if e.Source != "gw" || e.Target != "api" || e.Calls != 4 || e.Errors != 1 || e.ErrorRate != 0.25 {
+		t.Errorf("unexpected counts %+v", e)
+	}
+	if want := (LatencyStats{Avg: 25, P50: 20, P95: 40, P99: 40, Max: 40}); e.Latency != want {
+		t.Errorf("latency: got %+v, want %+v", e.Latency, want)
+	}
+
+	recent := s.Edges("d1", t0.Add(2*time.Second))
+	if len(recent) != 1 || recent[0].Calls != 2 {
+		t.Errorf("expected only calls since the cut-off, got %+v", recent)
+	}
+}
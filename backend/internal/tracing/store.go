@@ -0,0 +1,138 @@
+// Package tracing assembles the spans that deployed services export over
+// OTLP into traces, per deployment, and answers waterfall and per-edge
+// latency queries over them. Spans are kept in memory with fixed limits.
+package tracing
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Default limits of a Store.
+const (
+	// DefaultMaxDeployments is how many deployments' traces are kept; the
+	// traces of the deployment written to least recently are dropped first.
+	DefaultMaxDeployments = 5
+	// DefaultMaxTraces is how many traces are kept per deployment; the
+	// oldest are dropped first.
+	DefaultMaxTraces = 1000
+	// MaxSpansPerTrace bounds a trace; further spans are rejected.
+	MaxSpansPerTrace = 1000
+)
+
+// ErrNotFound is returned for a trace the store does not hold.
+var ErrNotFound = errors.New("trace not found")
+
+// Span is a received span.
+type Span struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId,omitempty"`
+	Name         string `json:"name"`
+	// Kind is "server", "client", "internal", "producer", "consumer" or
+	// "unspecified".
+	Kind string `json:"kind"`
+	// Service is the service.name of the process that recorded the span.
+	Service string `json:"service,omitempty"`
+	// NodeID is the diagram node that recorded the span, if known.
+	NodeID string `json:"nodeId,omitempty"`
+	// TargetNodeID is the node a client span called, if known.
+	TargetNodeID string            `json:"targetNodeId,omitempty"`
+	Start        time.Time         `json:"start"`
+	End          time.Time         `json:"end"`
+	DurationMs   float64           `json:"durationMs"`
+	Error        bool              `json:"error,omitempty"`
+	Message      string            `json:"message,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+}
+
+// trace holds the spans of one trace in arrival order.
+type trace struct {
+	spans []Span
+	ids   map[string]bool
+}
+
+// deployment holds the traces of one deployment.
+type deployment struct {
+	traces map[string]*trace
+	// order holds trace IDs oldest first.
+	order   []string
+	touched time.Time
+}
+
+// Store keeps traces in memory. It is safe for concurrent use.
+type Store struct {
+	maxDeployments int
+	maxTraces      int
+	now            func() time.Time
+
+	mu          sync.RWMutex
+	deployments map[string]*deployment
+}
+
+// NewStore creates an empty Store with the default limits.
+func NewStore() *Store {
+	return &Store{
+		maxDeployments: DefaultMaxDeployments,
+		maxTraces:      DefaultMaxTraces,
+		now:            time.Now,
+		deployments:    make(map[string]*deployment),
+	}
+}
+
+// Add records spans of deploymentID and returns how many were rejected
+// because their trace is full. Spans already recorded, such as ones sent
+// again by a retried export, are ignored.
+func (s *Store) Add(deploymentID string, spans []Span) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.deployments[deploymentID]
+	if !ok {
+		s.evict()
+		d = &deployment{traces: make(map[string]*trace)}
+		s.deployments[deploymentID] = d
+	}
+	d.touched = s.now()
+
+	rejected := 0
+	for _, sp := range spans {
+		t, ok := d.traces[sp.TraceID]
+		if !ok {
+			t = &trace{ids: make(map[string]bool)}
+			d.traces[sp.TraceID] = t
+			d.order = append(d.order, sp.TraceID)
+			if len(d.order) > s.maxTraces {
+				delete(d.traces, d.order[0])
+				d.order = d.order[1:]
+			}
+		}
+		if t.ids[sp.SpanID] {
+			continue
+		}
+		if len(t.spans) >= MaxSpansPerTrace {
+			rejected++
+			continue
+		}
+		t.spans = append(t.spans, sp)
+		t.ids[sp.SpanID] = true
+	}
+	return rejected
+}
+
+// evict drops the least recently written deployments until there is room
+// for a new one. s.mu must be held.
+func (s *Store) evict() {
+	for len(s.deployments) >= s.maxDeployments {
+		var oldest string
+		var oldestAt time.Time
+		first := true
+		for id, d := range s.deployments {
+			if first || d.touched.Before(oldestAt) {
+				oldest, oldestAt, first = id, d.touched, false
+			}
+		}
+		delete(s.deployments, oldest)
+	}
+}
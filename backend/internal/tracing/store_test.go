@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// span builds a span of trace starting at offset from t0 and lasting d.
+func span(trace, id, parent string, offset, d time.Duration) Span {
+	start := t0.Add(offset)
+	return Span{
+		TraceID:      trace,
+		SpanID:       id,
+		ParentSpanID: parent,
+		Name:         id,
+		Kind:         "server",
+		Start:        start,
+		End:          start.Add(d),
+		DurationMs:   ms(d),
+	}
+}
+
+func TestStore_IgnoresDuplicateSpans(t *testing.T) {
+	s := NewStore()
+	s.Add("d1", []Span{span("t1", "a", "", 0, time.Millisecond)})
+	s.Add("d1", []Span{span("t1", "a", "", 0, time.Millisecond), span("t1", "b", "a", 0, time.Millisecond)})
+
+	w, err := s.Trace("d1", "t1")
+	if err != nil {
+		t.Fatalf("Trace: %v", err)
+	}
+	if len(w.Spans) != 2 {
+		t.Errorf("expected 2 spans, got %d", len(w.Spans))
+	}
+}
+
+func TestStore_RejectsSpansBeyondTraceLimit(t *testing.T) {
+	s := NewStore()
+	spans := make([]Span, MaxSpansPerTrace+3)
+	for i := range spans {
+		spans[i] = span("t1", fmt.Sprintf("s%d", i), "", 0, time.Millisecond)
+	}
+	if rejected := s.Add("d1", spans); rejected != 3 {
+		t.Errorf("expected 3 spans rejected, got %d", rejected)
+	}
+}
+
+func TestStore_DropsOldestTraces(t *testing.T) {
+	s := NewStore()
+	s.maxTraces = 2
+	for i := range 3 {
+		s.Add("d1", []Span{span(fmt.Sprintf("t%d", i), "a", "", time.Duration(i)*time.Second, time.Millisecond)})
+	}
+	if _, err := s.Trace("d1", "t0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the oldest trace to be dropped, got %v", err)
+	}
+	if got := len(s.Traces("d1", TraceFilter{})); got != 2 {
+		t.Errorf("expected 2 traces kept, got %d", got)
+	}
+}
+
+func TestStore_DropsLeastRecentlyWrittenDeployment(t *testing.T) {
+	s := NewStore()
+	s.maxDeployments = 2
+	now := t0
+	s.now = func() time.Time { return now }
+
+	for _, id := range []string{"d1", "d2", "d1", "d3"} {
+		now = now.Add(time.Second)
+		s.Add(id, []Span{span("t-"+id, "a", "", 0, time.Millisecond)})
+	}
+	if _, err := s.Trace("d2", "t-d2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected d2 to be dropped, got %v", err)
+	}
+	for _, id := range []string{"d1", "d3"} {
+		if _, err := s.Trace(id, "t-"+id); err != nil {
+			t.Errorf("expected %s to be kept, got %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package traffic
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RunState is the lifecycle state of a traffic run.
+type RunState string
+
+const (
+	RunRunning   RunState = "running"
+	RunCompleted RunState = "completed"
+	RunStopped   RunState = "stopped"
+	RunFailed    RunState = "failed"
+)
+
+// Run is a snapshot of a traffic run.
+type Run struct {
+	ID        string     `json:"id"`
+	Generator string     `json:"generator"`
+	State     RunState   `json:"state"`
+	Options   Options    `json:"options"`
+	Endpoints []Endpoint `json:"endpoints"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Stats     Stats      `json:"stats"`
+}
+
+// Notifier receives a run snapshot whenever its stats or state change.
+type Notifier func(Run)
+
+// Controller runs one traffic run at a time on a Generator and keeps the
+// last run for status queries.
+type Controller struct {
+	gen    Generator
+	notify Notifier
+
+	mu      sync.Mutex
+	run     *Run
+	cancel  context.CancelFunc
+	done    chan struct{}
+	stopped bool // Stop was called on the active run
+}
+
+// NewController creates a Controller for gen. notify may be nil.
+func NewController(gen Generator, notify Notifier) *Controller {
+	if notify == nil {
+		notify = func(Run) {}
+	}
+	return &Controller{gen: gen, notify: notify}
+}
+
+// Start begins a run against endpoints in the background and returns its
+// first snapshot. It returns ErrRunning if a run is active.
+func (c *Controller) Start(endpoints []Endpoint, opts Options) (Run, error) {
+	if err := opts.Validate(); err != nil {
+		return Run{}, err
+	}
+	opts = opts.withDefaults()
+
+	c.mu.Lock()
+	if c.done != nil {
+		c.mu.Unlock()
+		return Run{}, ErrRunning
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.run = &Run{
+		ID:        uuid.New().String(),
+		Generator: c.gen.Name(),
+		State:     RunRunning,
+		Options:   opts,
+		Endpoints: endpoints,
+		StartedAt: time.Now().UTC(),
+		Stats:     Stats{Endpoints: []EndpointStats{}},
+	}
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	c.stopped = false
+	snapshot := c.run.clone()
+	done := c.done
+	c.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		stats, err := c.gen.Run(ctx, endpoints, opts, func(s Stats) {
+			c.update(func(r *Run) { r.Stats = s })
+		})
+		c.finish(stats, err)
+	}()
+
+	c.notify(snapshot)
+	return snapshot, nil
+}
+
+// Stop ends the active run, waits for the generator to report its final
+// stats and returns the finished run. It returns ErrNotRunning if no run is
+// active.
+func (c *Controller) Stop() (Run, error) {
+	c.mu.Lock()
+	if c.done == nil {
+		c.mu.Unlock()
+		return Run{}, ErrNotRunning
+	}
+	c.stopped = true
+	c.cancel()
+	done := c.done
+	c.mu.Unlock()
+
+	<-done
+	run, _ := c.Status()
+	return run, nil
+}
+
+// Status returns the active or last run, and false if none has started.
+func (c *Controller) Status() (Run, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.run == nil {
+		return Run{}, false
+	}
+	return c.run.clone(), true
+}
+
+// finish records the outcome of the generator.
+func (c *Controller) finish(stats Stats, err error) {
+	now := time.Now().UTC()
+	c.update(func(r *Run) {
+		r.EndedAt = &now
+		switch {
+		case err != nil:
+			r.State = RunFailed
+			r.Error = err.Error()
+		case c.stopped:
+			r.State = RunStopped
+		default:
+			r.State = RunCompleted
+		}
+		if err == nil {
+			r.Stats = stats
+		}
+		c.cancel()
+		c.done = nil
+	})
+}
+
+// update applies fn to the run under lock, then notifies with a snapshot.
+func (c *Controller) update(fn func(*Run)) {
+	c.mu.Lock()
+	fn(c.run)
+	snapshot := c.run.clone()
+	c.mu.Unlock()
+
+	c.notify(snapshot)
+}
+
+// clone returns a deep copy of r safe to hand to other goroutines.
+func (r *Run) clone() Run {
+	cp := *r
+	cp.Endpoints = append([]Endpoint(nil), r.Endpoints...)
+	cp.Stats.Endpoints = append([]EndpointStats{}, r.Stats.Endpoints...)
+	if r.EndedAt != nil {
+		t := *r.EndedAt
+		cp.EndedAt = &t
+	}
+	return cp
+}
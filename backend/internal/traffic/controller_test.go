@@ -0,0 +1,102 @@
+package traffic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeGenerator reports one sample, then blocks until ctx is cancelled or
+// release is closed.
+type fakeGenerator struct {
+	release chan struct{}
+	err     error
+}
+
+func (g *fakeGenerator) Name() string { return "fake" }
+
+func (g *fakeGenerator) Run(ctx context.Context, endpoints []Endpoint, _ Options, report func(Stats)) (Stats, error) {
+	report(Stats{Total: EndpointStats{Requests: 1}})
+	select {
+	case <-ctx.Done():
+	case <-g.release:
+	}
+	return Stats{Total: EndpointStats{Requests: 2}}, g.err
+}
+
+// waitFor polls the controller until the run reaches state.
+func waitFor(t *testing.T, c *Controller, state RunState) Run {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if run, ok := c.Status(); ok && run.State == state {
+			return run
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for run state %q", state)
+	return Run{}
+}
+
+func TestController_StartAndStop(t *testing.T) {
+	gen := &fakeGenerator{release: make(chan struct{})}
+	notified := make(chan Run, 10)
+	c := NewController(gen, func(r Run) { notified <- r })
+
+	if _, ok := c.Status(); ok {
+		t.Fatal("expected no run before Start")
+	}
+	if _, err := c.Stop(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+
+	run, err := c.Start(testEndpoints, Options{})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if run.State != RunRunning || run.Generator != "fake" || run.Options.Duration != DefaultDuration || run.Options.VUs != DefaultVUs {
+		t.Errorf("unexpected run %+v", run)
+	}
+	if _, err := c.Start(testEndpoints, Options{}); !errors.Is(err, ErrRunning) {
+		t.Errorf("expected ErrRunning, got %v", err)
+	}
+
+	stopped, err := c.Stop()
+	if err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if stopped.State != RunStopped || stopped.EndedAt == nil || stopped.Stats.Total.Requests != 2 {
+		t.Errorf("unexpected stopped run %+v", stopped)
+	}
+	if len(notified) < 3 {
+		t.Errorf("expected start, report and stop notifications, got %d", len(notified))
+	}
+
+	if _, err := c.Start(testEndpoints, Options{}); err != nil {
+		t.Errorf("expected a new run to start after Stop, got %v", err)
+	}
+	close(gen.release)
+	waitFor(t, c, RunCompleted)
+}
+
+func TestController_GeneratorFailure(t *testing.T) {
+	gen := &fakeGenerator{release: make(chan struct{}), err: errors.New("boom")}
+	close(gen.release)
+	c := NewController(gen, nil)
+
+	if _, err := c.Start(testEndpoints, Options{}); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	run := waitFor(t, c, RunFailed)
+	if run.Error != "boom" || run.Stats.Total.Requests != 1 {
+		t.Errorf("expected failure to keep the last reported stats, got %+v", run)
+	}
+}
+
+func TestController_RejectsInvalidOptions(t *testing.T) {
+	c := NewController(&fakeGenerator{}, nil)
+	if _, err := c.Start(testEndpoints, Options{VUs: MaxVUs + 1}); err == nil {
+		t.Error("expected error for too many VUs")
+	}
+}
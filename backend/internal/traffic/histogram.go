@@ -0,0 +1,118 @@
+package traffic
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Histogram layout. Values are recorded in microseconds. Below
+// histSubBuckets every value has its own bucket; above it each power of two
+// is split into histSubBuckets/2 linear buckets. Reporting the middle of a
+// bucket keeps every value within 1/128 (under 1%) of the true one, as an
+// HDR histogram with two significant digits would. Values above histMax are
+// clamped.
+const (
+	histSubBits    = 7
+	histSubBuckets = 1 << histSubBits
+	histHalf       = histSubBuckets / 2
+	histMax        = int64(time.Hour / time.Microsecond)
+)
+
+// histBuckets is the number of buckets needed to reach histMax.
+var histBuckets = histIndex(histMax) + 1
+
+// Histogram counts latencies in logarithmic buckets with fixed relative
+// precision, so memory stays constant however many requests are recorded.
+// It is not safe for concurrent use.
+type Histogram struct {
+	counts []int64
+	total  int64
+	sum    time.Duration
+	max    time.Duration
+}
+
+// NewHistogram creates an empty histogram.
+func NewHistogram() *Histogram {
+	return &Histogram{counts: make([]int64, histBuckets)}
+}
+
+// Record adds one latency. Negative values count as zero.
+func (h *Histogram) Record(d time.Duration) {
+	d = max(d, 0)
+	us := min(int64(d/time.Microsecond), histMax)
+	h.counts[histIndex(us)]++
+	h.total++
+	h.sum += d
+	h.max = max(h.max, d)
+}
+
+// Merge adds every value recorded in o.
+func (h *Histogram) Merge(o *Histogram) {
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	h.total += o.total
+	h.sum += o.sum
+	h.max = max(h.max, o.max)
+}
+
+// Count returns the number of recorded values.
+func (h *Histogram) Count() int64 {
+	return h.total
+}
+
+// Mean returns the exact mean of the recorded values, or 0 if empty.
+func (h *Histogram) Mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.total)
+}
+
+// Max returns the exact largest recorded value.
+func (h *Histogram) Max() time.Duration {
+	return h.max
+}
+
+// Percentile returns the nearest-rank p-th percentile, 0 < p <= 100, as the
+// middle of the bucket it falls in, capped at Max. The top rank is Max
+// itself. It returns 0 if empty.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := max(int64(math.Ceil(p/100*float64(h.total))), 1)
+	if rank >= h.total {
+		return h.max
+	}
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			lo, width := histBucket(i)
+			return min(time.Duration(lo+width/2)*time.Microsecond, h.max)
+		}
+	}
+	return h.max
+}
+
+// histIndex returns the bucket holding a value in microseconds.
+func histIndex(us int64) int {
+	if us < histSubBuckets {
+		return int(us)
+	}
+	shift := bits.Len64(uint64(us)) - histSubBits
+	return histSubBuckets + (shift-1)*histHalf + int(us>>shift) - histHalf
+}
+
+// histBucket returns the lowest value and width of bucket i, in
+// microseconds.
+func histBucket(i int) (lo, width int64) {
+	if i < histSubBuckets {
+		return int64(i), 1
+	}
+	shift := (i-histSubBuckets)/histHalf + 1
+	sub := int64((i-histSubBuckets)%histHalf + histHalf)
+	return sub << shift, 1 << shift
+}
@@ -0,0 +1,53 @@
+package traffic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram_BucketsCoverEveryValue(t *testing.T) {
+	prev := -1
+	for us := int64(0); us <= 1<<20; us++ {
+		i := histIndex(us)
+		if i < prev || i > prev+1 {
+			t.Fatalf("index jumped from %d to %d at %dus", prev, i, us)
+		}
+		lo, width := histBucket(i)
+		if us < lo || us >= lo+width {
+			t.Fatalf("%dus is outside bucket %d [%d, %d)", us, i, lo, lo+width)
+		}
+		prev = i
+	}
+	if histIndex(histMax) != histBuckets-1 {
+		t.Errorf("expected histMax in the last bucket")
+	}
+}
+
+func TestHistogram_Percentiles(t *testing.T) {
+	h := NewHistogram()
+	if h.Percentile(50) != 0 || h.Mean() != 0 {
+		t.Error("expected zero values for an empty histogram")
+	}
+	for i := 1; i <= 1000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+	h.Record(2 * time.Hour) // clamped into the last bucket
+
+	for _, p := range []float64{50, 90, 99} {
+		want := time.Duration(p*10) * time.Millisecond
+		got := h.Percentile(p)
+		if diff := got - want; diff < -want/100 || diff > want/100 {
+			t.Errorf("p%v: got %v, want %v within 1%%", p, got, want)
+		}
+	}
+	if h.Count() != 1001 || h.Max() != 2*time.Hour || h.Percentile(100) != 2*time.Hour {
+		t.Errorf("unexpected count %d, max %v, p100 %v", h.Count(), h.Max(), h.Percentile(100))
+	}
+
+	other := NewHistogram()
+	other.Record(time.Millisecond)
+	h.Merge(other)
+	if h.Count() != 1002 {
+		t.Errorf("expected merged count 1002, got %d", h.Count())
+	}
+}
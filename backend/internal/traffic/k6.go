@@ -0,0 +1,307 @@
+package traffic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// K6Image is the image the k6 generator runs.
+const K6Image = "grafana/k6:latest"
+
+// k6 container layout. The name contains an underscore, which node
+// hostnames never do, so it cannot clash with a node's container.
+const (
+	k6ContainerName = "traffic_k6"
+	k6MountPath     = "/heph"
+	k6ScriptFile    = "script.js"
+	k6SummaryFile   = "summary.json"
+	k6MetricsFile   = "metrics.json"
+)
+
+// k6 timeouts: a run is abandoned k6StopGrace after its duration, and the
+// container gets k6CleanupTimeout to stop and be removed.
+const (
+	k6StopGrace      = 30 * time.Second
+	k6CleanupTimeout = 30 * time.Second
+)
+
+// k6TrendStats are the latency statistics k6 writes to its summary.
+var k6TrendStats = []string{"avg", "min", "med", "max", "p(90)", "p(95)", "p(99)"}
+
+// K6Generator runs a generated k6 script in a container on the shared
+// network. Results stream back through k6's JSON output, which is tailed
+// every ReportInterval, and the final stats come from k6's end-of-test
+// summary.
+type K6Generator struct {
+	orch docker.Orchestrator
+	// dir is the host directory run files are written under.
+	dir      string
+	interval time.Duration
+}
+
+// NewK6Generator creates a K6Generator that runs k6 through orch.
+func NewK6Generator(orch docker.Orchestrator) *K6Generator {
+	return &K6Generator{
+		orch:     orch,
+		dir:      filepath.Join(os.TempDir(), "heph-traffic"),
+		interval: ReportInterval,
+	}
+}
+
+// Name returns "k6".
+func (g *K6Generator) Name() string {
+	return "k6"
+}
+
+// Run writes the script, starts k6 and reports its streamed results until
+// k6 exits, the duration plus k6StopGrace has passed, or ctx is cancelled.
+// The container is then stopped, which makes k6 write its summary, and
+// removed. If the summary is missing the streamed stats are returned.
+func (g *K6Generator) Run(ctx context.Context, endpoints []Endpoint, opts Options, report func(Stats)) (Stats, error) {
+	opts = opts.withDefaults()
+	script, err := K6Script(endpoints, opts)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	dir, err := g.writeRunDir(script)
+	if err != nil {
+		return Stats{}, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	id, err := g.orch.CreateContainer(ctx, docker.ContainerConfig{
+		Image: K6Image,
+		Name:  k6ContainerName,
+		Cmd: []string{
+			"run", "--quiet",
+			"--summary-export", k6MountPath + "/" + k6SummaryFile,
+			"--out", "json=" + k6MountPath + "/" + k6MetricsFile,
+			k6MountPath + "/" + k6ScriptFile,
+		},
+		Volumes:     map[string]string{dir: k6MountPath},
+		NetworkName: docker.NetworkName,
+	})
+	if err != nil {
+		return Stats{}, fmt.Errorf("create k6 container: %w", err)
+	}
+	cleanupCtx, cancelCleanup := context.WithTimeout(context.WithoutCancel(ctx), k6CleanupTimeout)
+	defer cancelCleanup()
+	defer func() {
+		if err := g.orch.RemoveContainer(cleanupCtx, id); err != nil {
+			log.Printf("traffic: remove k6 container: %v", err)
+		}
+	}()
+
+	if err := g.orch.StartContainer(ctx, id); err != nil {
+		return Stats{}, fmt.Errorf("start k6 container: %w", err)
+	}
+
+	start := time.Now()
+	stream := newK6Stream(filepath.Join(dir, k6MetricsFile), endpoints)
+	g.follow(ctx, id, opts.Duration+k6StopGrace, func() {
+		stream.read()
+		report(stream.stats(time.Since(start)))
+	})
+
+	if err := g.orch.StopContainer(cleanupCtx, id); err != nil {
+		log.Printf("traffic: stop k6 container: %v", err)
+	}
+	stream.read()
+
+	if summary, err := os.ReadFile(filepath.Join(dir, k6SummaryFile)); err == nil {
+		stats, err := ParseK6Summary(summary, endpoints)
+		if err == nil {
+			return stats, nil
+		}
+		log.Printf("traffic: %v; using streamed results", err)
+	}
+	return stream.stats(time.Since(start)), nil
+}
+
+// writeRunDir creates a fresh run directory holding the script. k6 runs as
+// an unprivileged user, so the directory is world-writable for its results.
+func (g *K6Generator) writeRunDir(script []byte) (string, error) {
+	if err := os.MkdirAll(g.dir, 0o755); err != nil {
+		return "", fmt.Errorf("create traffic directory %q: %w", g.dir, err)
+	}
+	dir, err := os.MkdirTemp(g.dir, "run-*")
+	if err != nil {
+		return "", fmt.Errorf("create run directory: %w", err)
+	}
+	if err := os.Chmod(dir, 0o777); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("make run directory writable: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, k6ScriptFile), script, 0o644); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("write k6 script: %w", err)
+	}
+	return dir, nil
+}
+
+// follow calls tick every interval until the container has exited, limit
+// has passed or ctx is cancelled.
+func (g *K6Generator) follow(ctx context.Context, id string, limit time.Duration, tick func()) {
+	ctx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tick()
+			status, err := g.orch.HealthCheck(ctx, id)
+			if err == nil && (status == docker.StatusStopped || status == docker.StatusError) {
+				return
+			}
+		}
+	}
+}
+
+// k6Endpoint is an endpoint as the generated script sees it.
+type k6Endpoint struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// k6ScriptBody picks a random endpoint per iteration in proportion to its
+// weight, then pauses for the think time. Requests are tagged with the
+// endpoint's index so results can be attributed.
+const k6ScriptBody = `
+const totalWeight = endpoints.reduce((sum, ep) => sum + ep.weight, 0);
+
+function pick() {
+  let r = Math.random() * totalWeight;
+  return endpoints.find((ep) => (r -= ep.weight) < 0) || endpoints[endpoints.length - 1];
+}
+
+export default function () {
+  const ep = pick();
+  const body = ['GET', 'HEAD', 'DELETE', 'OPTIONS'].includes(ep.method) ? null : '{}';
+  http.request(ep.method, ep.url, body, {
+    headers: { 'Content-Type': 'application/json' },
+    tags: { ep: ep.id, name: ep.name },
+  });
+  if (thinkTime > 0) {
+    sleep(thinkTime);
+  }
+}
+`
+
+// K6Script generates the k6 script for endpoints. Every endpoint gets
+// no-op thresholds so that k6 includes its tagged metrics in the summary.
+// All generated values are JSON-encoded, so names and URLs from the
+// diagram cannot break out of the script's literals.
+func K6Script(endpoints []Endpoint, opts Options) ([]byte, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEntryPoints
+	}
+	opts = opts.withDefaults()
+	duration := strconv.Itoa(int(opts.Duration.Seconds())) + "s"
+
+	eps := make([]k6Endpoint, len(endpoints))
+	thresholds := make(map[string][]string, 3*len(endpoints))
+	for i, ep := range endpoints {
+		id := strconv.Itoa(i)
+		eps[i] = k6Endpoint{ID: id, Name: ep.NodeID + " " + ep.Method + " " + ep.Path, Method: ep.Method, URL: ep.URL, Weight: max(ep.Weight, 1)}
+		thresholds[k6MetricReqs+k6Selector(i)] = []string{"count>=0"}
+		thresholds[k6MetricDuration+k6Selector(i)] = []string{"max>=0"}
+		thresholds[k6MetricFailed+k6Selector(i)] = []string{"rate>=0"}
+	}
+
+	var thinkTime float64 // seconds; arrival-rate iterations never pause
+	options := map[string]any{
+		"summaryTrendStats": k6TrendStats,
+		"thresholds":        thresholds,
+	}
+	switch {
+	case len(opts.Stages) > 0 && opts.Rate > 0:
+		options["scenarios"] = map[string]any{
+			"traffic": map[string]any{
+				"executor":        "ramping-arrival-rate",
+				"startRate":       0,
+				"timeUnit":        "1s",
+				"stages":          k6Stages(opts.Stages),
+				"preAllocatedVUs": opts.VUs,
+				"maxVUs":          MaxVUs,
+			},
+		}
+	case len(opts.Stages) > 0:
+		options["scenarios"] = map[string]any{
+			"traffic": map[string]any{
+				"executor":         "ramping-vus",
+				"startVUs":         0,
+				"stages":           k6Stages(opts.Stages),
+				"gracefulRampDown": "0s",
+			},
+		}
+		thinkTime = opts.ThinkTime.Seconds()
+	case opts.Rate > 0:
+		options["scenarios"] = map[string]any{
+			"traffic": map[string]any{
+				"executor":        "constant-arrival-rate",
+				"rate":            opts.Rate,
+				"timeUnit":        "1s",
+				"duration":        duration,
+				"preAllocatedVUs": opts.VUs,
+				"maxVUs":          MaxVUs,
+			},
+		}
+	default:
+		options["vus"] = opts.VUs
+		options["duration"] = duration
+		thinkTime = opts.ThinkTime.Seconds()
+	}
+
+	optionsJSON, err := json.MarshalIndent(options, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode k6 options: %w", err)
+	}
+	endpointsJSON, err := json.MarshalIndent(eps, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode k6 endpoints: %w", err)
+	}
+
+	script := "import http from 'k6/http';\nimport { sleep } from 'k6';\n\n" +
+		"export const options = " + string(optionsJSON) + ";\n\n" +
+		"const endpoints = " + string(endpointsJSON) + ";\n" +
+		"const thinkTime = " + strconv.FormatFloat(thinkTime, 'f', -1, 64) + ";\n" +
+		k6ScriptBody
+	return []byte(script), nil
+}
+
+// k6Stages converts load stages to k6 ramping stages, which are always
+// linear. A step becomes a zero-length stage to the target followed by a
+// stage that holds it.
+func k6Stages(stages []model.LoadStage) []map[string]any {
+	out := make([]map[string]any, 0, len(stages))
+	for _, st := range stages {
+		if st.Ramp == model.RampStep {
+			out = append(out, map[string]any{"duration": "0s", "target": st.Target})
+		}
+		out = append(out, map[string]any{"duration": strconv.Itoa(st.DurationSeconds) + "s", "target": st.Target})
+	}
+	return out
+}
+
+// k6Selector is the tag selector that restricts a metric to the requests
+// of endpoint i.
+func k6Selector(i int) string {
+	return "{ep:" + strconv.Itoa(i) + "}"
+}
@@ -0,0 +1,156 @@
+package traffic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// k6 metric names read back from results.
+const (
+	k6MetricReqs     = "http_reqs"
+	k6MetricDuration = "http_req_duration"
+	k6MetricFailed   = "http_req_failed"
+	k6MetricDropped  = "dropped_iterations"
+)
+
+// k6Summary is the subset of k6's --summary-export file that is read.
+// Metric values are numbers except for the thresholds object, which is
+// skipped.
+type k6Summary struct {
+	Metrics map[string]map[string]json.RawMessage `json:"metrics"`
+}
+
+// ParseK6Summary converts a k6 --summary-export file into Stats. The
+// per-endpoint figures come from the submetrics tagged by the generated
+// script.
+func ParseK6Summary(data []byte, endpoints []Endpoint) (Stats, error) {
+	var summary k6Summary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return Stats{}, fmt.Errorf("parse k6 summary: %w", err)
+	}
+	if _, ok := summary.Metrics[k6MetricReqs]; !ok {
+		return Stats{}, fmt.Errorf("parse k6 summary: missing %s", k6MetricReqs)
+	}
+
+	stats := Stats{
+		Total:     summary.endpoint(Endpoint{}, ""),
+		Endpoints: make([]EndpointStats, len(endpoints)),
+		Dropped:   int64(value(summary.Metrics[k6MetricDropped], "count")),
+	}
+	for i, ep := range endpoints {
+		stats.Endpoints[i] = summary.endpoint(ep, k6Selector(i))
+	}
+	return stats, nil
+}
+
+// endpoint reads the stats of the metrics with the given tag selector.
+func (s k6Summary) endpoint(ep Endpoint, selector string) EndpointStats {
+	reqs := s.Metrics[k6MetricReqs+selector]
+	dur := s.Metrics[k6MetricDuration+selector]
+	return EndpointStats{
+		Endpoint:  ep,
+		Requests:  int64(value(reqs, "count")),
+		RPS:       value(reqs, "rate"),
+		ErrorRate: value(s.Metrics[k6MetricFailed+selector], "value"),
+		Latency: LatencyStats{
+			Avg: value(dur, "avg"),
+			P50: value(dur, "med"),
+			P90: value(dur, "p(90)"),
+			P95: value(dur, "p(95)"),
+			P99: value(dur, "p(99)"),
+			Max: value(dur, "max"),
+		},
+	}
+}
+
+// value returns the numeric field key of a summary metric, or 0.
+func value(metric map[string]json.RawMessage, key string) float64 {
+	var v float64
+	if raw, ok := metric[key]; ok {
+		_ = json.Unmarshal(raw, &v)
+	}
+	return v
+}
+
+// k6Point is one line of k6's JSON output. Lines of other types, such as
+// metric declarations, are skipped.
+type k6Point struct {
+	Type   string `json:"type"`
+	Metric string `json:"metric"`
+	Data   struct {
+		Value float64           `json:"value"`
+		Tags  map[string]string `json:"tags"`
+	} `json:"data"`
+}
+
+// k6Stream follows the JSON output file k6 writes while it runs.
+type k6Stream struct {
+	path    string
+	offset  int64
+	partial []byte // incomplete trailing line from the last read
+	rec     *recorder
+}
+
+// newK6Stream creates a stream over the output file at path.
+func newK6Stream(path string, endpoints []Endpoint) *k6Stream {
+	return &k6Stream{path: path, rec: newRecorder(endpoints)}
+}
+
+// read consumes the lines appended since the last read. A missing file,
+// which k6 has not created yet, is not an error.
+func (s *k6Stream) read() {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Seek(s.offset, io.SeekStart); err != nil {
+		return
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return
+	}
+	s.offset += int64(len(data))
+
+	data = append(s.partial, data...)
+	end := bytes.LastIndexByte(data, '\n')
+	s.partial = append([]byte(nil), data[end+1:]...)
+	for _, line := range bytes.Split(data[:end+1], []byte("\n")) {
+		s.consume(line)
+	}
+}
+
+// consume records one output line.
+func (s *k6Stream) consume(line []byte) {
+	if !bytes.Contains(line, []byte(`"`+k6MetricDuration+`"`)) && !bytes.Contains(line, []byte(`"`+k6MetricFailed+`"`)) {
+		return
+	}
+	var p k6Point
+	if err := json.Unmarshal(line, &p); err != nil || p.Type != "Point" {
+		return
+	}
+	i, err := strconv.Atoi(p.Data.Tags["ep"])
+	if err != nil {
+		return
+	}
+	switch p.Metric {
+	case k6MetricDuration:
+		s.rec.observe(i, time.Duration(p.Data.Value*float64(time.Millisecond)))
+	case k6MetricFailed:
+		if p.Data.Value != 0 {
+			s.rec.fail(i)
+		}
+	}
+}
+
+// stats summarises the points read so far.
+func (s *k6Stream) stats(elapsed time.Duration) Stats {
+	return s.rec.stats(elapsed)
+}
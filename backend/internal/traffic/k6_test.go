@@ -0,0 +1,204 @@
+package traffic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+var testEndpoints = []Endpoint{
+	{NodeID: "users", Method: "GET", Path: "/users/{id}", URL: "http://users:4010/users/1"},
+	{NodeID: "edge", Method: "GET", Path: "/", URL: "http://edge:80/"},
+}
+
+const testSummary = `{
+  "metrics": {
+    "http_reqs": {"count": 300, "rate": 10},
+    "http_req_duration": {"avg": 12, "min": 1, "med": 10, "max": 90, "p(90)": 20, "p(95)": 30, "p(99)": 80},
+    "http_req_failed": {"passes": 3, "fails": 297, "value": 0.01},
+    "http_reqs{ep:0}": {"count": 200, "rate": 6.67},
+    "http_req_duration{ep:0}": {"avg": 15, "med": 12, "max": 90, "p(90)": 25, "p(95)": 35, "p(99)": 85, "thresholds": {"max>=0": false}},
+    "http_req_failed{ep:0}": {"value": 0.015},
+    "http_reqs{ep:1}": {"count": 100, "rate": 3.33},
+    "dropped_iterations": {"count": 4, "rate": 0.13}
+  }
+}`
+
+func TestK6Script_ClosedModel(t *testing.T) {
+	script, err := K6Script(testEndpoints, Options{Duration: time.Minute, VUs: 5, ThinkTime: 1500 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("K6Script: %v", err)
+	}
+	s := string(script)
+	for _, want := range []string{
+		`"vus": 5`,
+		`"duration": "60s"`,
+		`"http_req_duration{ep:1}"`,
+		`"url": "http://users:4010/users/1"`,
+		`"name": "users GET /users/{id}"`,
+		`"weight": 1`,
+		"const thinkTime = 1.5;",
+		"export default function",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected script to contain %s:\n%s", want, s)
+		}
+	}
+	if strings.Contains(s, "constant-arrival-rate") {
+		t.Error("expected no arrival-rate scenario without a rate")
+	}
+}
+
+func TestK6Script_OpenModel(t *testing.T) {
+	script, err := K6Script(testEndpoints, Options{Rate: 200})
+	if err != nil {
+		t.Fatalf("K6Script: %v", err)
+	}
+	s := string(script)
+	if !strings.Contains(s, `"executor": "constant-arrival-rate"`) || !strings.Contains(s, `"rate": 200`) || !strings.Contains(s, `"duration": "30s"`) {
+		t.Errorf("expected an arrival-rate scenario with defaults:\n%s", s)
+	}
+	if !strings.Contains(s, "const thinkTime = 0;") {
+		t.Errorf("expected no think time in the open model:\n%s", s)
+	}
+}
+
+func TestK6Script_Stages(t *testing.T) {
+	stages := []model.LoadStage{{DurationSeconds: 10, Target: 50}, {DurationSeconds: 20, Target: 100, Ramp: model.RampStep}}
+
+	script, err := K6Script(testEndpoints, Options{Rate: 100, Stages: stages})
+	if err != nil {
+		t.Fatalf("K6Script: %v", err)
+	}
+	s := string(script)
+	for _, want := range []string{
+		`"executor": "ramping-arrival-rate"`,
+		`"duration": "10s"`,
+		`"duration": "0s"`,
+		`"duration": "20s"`,
+		`"target": 100`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected script to contain %s:\n%s", want, s)
+		}
+	}
+
+	script, err = K6Script(testEndpoints, Options{VUs: 100, Stages: stages, ThinkTime: time.Second})
+	if err != nil {
+		t.Fatalf("K6Script: %v", err)
+	}
+	if s := string(script); !strings.Contains(s, `"executor": "ramping-vus"`) || !strings.Contains(s, "const thinkTime = 1;") || strings.Contains(s, `"vus": 100`) {
+		t.Errorf("expected a ramping-vus scenario with think time:\n%s", s)
+	}
+}
+
+func TestParseK6Summary(t *testing.T) {
+	stats, err := ParseK6Summary([]byte(testSummary), testEndpoints)
+	if err != nil {
+		t.Fatalf("ParseK6Summary: %v", err)
+	}
+	if stats.Total.Requests != 300 || stats.Total.RPS != 10 || stats.Total.ErrorRate != 0.01 || stats.Total.Latency.P99 != 80 || stats.Dropped != 4 {
+		t.Errorf("unexpected total %+v", stats.Total)
+	}
+	users := stats.Endpoints[0]
+	if users.NodeID != "users" || users.Requests != 200 || users.ErrorRate != 0.015 || users.Latency.P50 != 12 || users.Latency.Max != 90 {
+		t.Errorf("unexpected endpoint stats %+v", users)
+	}
+	if edge := stats.Endpoints[1]; edge.Requests != 100 || edge.ErrorRate != 0 {
+		t.Errorf("unexpected endpoint stats %+v", edge)
+	}
+
+	if _, err := ParseK6Summary([]byte(`{"metrics": {}}`), testEndpoints); err == nil {
+		t.Error("expected error for a summary without http_reqs")
+	}
+}
+
+func TestK6Stream_ReadsAppendedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), k6MetricsFile)
+	stream := newK6Stream(path, testEndpoints)
+	stream.read() // file not created yet
+
+	appendFile(t, path, `{"type":"Metric","metric":"http_req_duration","data":{"type":"trend"}}
+{"type":"Point","metric":"http_req_duration","data":{"value":10,"tags":{"ep":"0"}}}
+{"type":"Point","metric":"http_req_failed","data":{"value":1,"tags":{"ep":"0"}}}
+{"type":"Point","metric":"http_req_duration","data":{"value":`)
+	stream.read()
+	if s := stream.stats(time.Second); s.Total.Requests != 1 {
+		t.Fatalf("expected the partial line to be held back, got %+v", s.Total)
+	}
+
+	appendFile(t, path, `30,"tags":{"ep":"1"}}}
+{"type":"Point","metric":"http_req_waiting","data":{"value":5,"tags":{"ep":"1"}}}
+`)
+	stream.read()
+	s := stream.stats(time.Second)
+	if s.Total.Requests != 2 || s.Endpoints[0].ErrorRate != 1 || s.Endpoints[1].Latency.Max != 30 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestK6Generator_Run(t *testing.T) {
+	// k6 "exits" once the simulated start delay has passed.
+	sim := docker.NewSimulatedOrchestrator(docker.SimulationConfig{
+		StartDelay: 20 * time.Millisecond,
+		Failures:   map[string]docker.SimFailure{k6ContainerName: docker.SimFailCrash},
+	})
+	ctx := context.Background()
+	if err := sim.CreateNetwork(ctx); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+
+	var script string
+	sim.Events().OnPostCreate(func(_ context.Context, _ string, cfg docker.ContainerConfig) {
+		for dir := range cfg.Volumes {
+			data, _ := os.ReadFile(filepath.Join(dir, k6ScriptFile))
+			script = string(data)
+			appendFile(t, filepath.Join(dir, k6MetricsFile), `{"type":"Point","metric":"http_req_duration","data":{"value":10,"tags":{"ep":"0"}}}`+"\n")
+			appendFile(t, filepath.Join(dir, k6SummaryFile), testSummary)
+		}
+	})
+
+	g := NewK6Generator(sim)
+	g.dir = t.TempDir()
+	g.interval = 5 * time.Millisecond
+
+	var reports []Stats
+	stats, err := g.Run(ctx, testEndpoints, Options{Duration: time.Minute}, func(s Stats) { reports = append(reports, s) })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if !strings.Contains(script, "http://edge:80/") {
+		t.Errorf("expected the script to be mounted into the container, got %q", script)
+	}
+	if len(reports) == 0 || reports[0].Total.Requests != 1 {
+		t.Errorf("expected streamed reports, got %+v", reports)
+	}
+	if stats.Total.Requests != 300 {
+		t.Errorf("expected final stats from the summary, got %+v", stats.Total)
+	}
+	if infos, _ := sim.ListContainers(ctx); len(infos) != 0 {
+		t.Errorf("expected the k6 container to be removed, got %+v", infos)
+	}
+	if entries, _ := os.ReadDir(g.dir); len(entries) != 0 {
+		t.Errorf("expected the run directory to be removed, got %d entries", len(entries))
+	}
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,252 @@
+package traffic
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Native generator tuning. Requests time out after nativeRequestTimeout,
+// the open model schedules arrivals in batches every nativeTick, and idle
+// virtual users check whether a stage needs them every nativeIdlePoll.
+const (
+	nativeRequestTimeout = 10 * time.Second
+	nativeTick           = 5 * time.Millisecond
+	nativeIdlePoll       = 50 * time.Millisecond
+)
+
+// NativeGenerator sends traffic from the backend process itself. It
+// requests each endpoint's HostURL, the port published on the host, and
+// falls back to URL for endpoints without one. The generator itself runs
+// no container, but the services it requests must be real ones, so it
+// cannot run against a simulated deployment.
+type NativeGenerator struct {
+	client   *http.Client
+	interval time.Duration
+}
+
+// NewNativeGenerator creates a NativeGenerator. Its connection pool keeps
+// up to MaxVUs connections per host alive, so sustained load does not pay
+// for a new connection per request.
+func NewNativeGenerator() *NativeGenerator {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = MaxVUs
+	transport.MaxIdleConnsPerHost = MaxVUs
+	return &NativeGenerator{
+		client:   &http.Client{Transport: transport, Timeout: nativeRequestTimeout},
+		interval: ReportInterval,
+	}
+}
+
+// Name returns "native".
+func (g *NativeGenerator) Name() string {
+	return "native"
+}
+
+// Run sends requests until the duration has passed or ctx is cancelled,
+// reporting every interval. Requests cut short by the end of the run are
+// not counted.
+func (g *NativeGenerator) Run(ctx context.Context, endpoints []Endpoint, opts Options, report func(Stats)) (Stats, error) {
+	if len(endpoints) == 0 {
+		return Stats{}, ErrNoEntryPoints
+	}
+	opts = opts.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	l := newNativeLoad(g.client, endpoints)
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if opts.Rate > 0 {
+			l.open(ctx, opts)
+		} else {
+			l.closed(ctx, opts)
+		}
+	}()
+
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return l.stats(time.Since(start)), nil
+		case <-ticker.C:
+			report(l.stats(time.Since(start)))
+		}
+	}
+}
+
+// nativeLoad is the state of one native run.
+type nativeLoad struct {
+	client    *http.Client
+	endpoints []Endpoint
+	// cumulative holds the running total of endpoint weights, for picking
+	// endpoints in proportion to them.
+	cumulative []int
+
+	mu      sync.Mutex
+	rec     *recorder
+	dropped int64
+}
+
+// newNativeLoad prepares a run against endpoints.
+func newNativeLoad(client *http.Client, endpoints []Endpoint) *nativeLoad {
+	l := &nativeLoad{
+		client:     client,
+		endpoints:  endpoints,
+		cumulative: make([]int, len(endpoints)),
+		rec:        newRecorder(endpoints),
+	}
+	total := 0
+	for i, ep := range endpoints {
+		total += max(ep.Weight, 1)
+		l.cumulative[i] = total
+	}
+	return l
+}
+
+// closed runs opts.VUs virtual users that each send a request, wait the
+// think time and repeat until ctx is done. With stages, user i only sends
+// while the stage's level is above i.
+func (l *nativeLoad) closed(ctx context.Context, opts Options) {
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range opts.VUs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if float64(i) >= opts.level(time.Since(start)) {
+					sleep(ctx, nativeIdlePoll)
+					continue
+				}
+				l.send(ctx, l.pick())
+				if opts.ThinkTime > 0 {
+					sleep(ctx, opts.ThinkTime)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// open starts requests at the options' rate until ctx is done, whatever the
+// response times. At most MaxVUs requests are in flight; arrivals beyond
+// that are dropped and counted, as k6's arrival-rate executor does.
+func (l *nativeLoad) open(ctx context.Context, opts Options) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	slots := make(chan struct{}, MaxVUs)
+	ticker := time.NewTicker(nativeTick)
+	defer ticker.Stop()
+
+	start := time.Now()
+	last := start
+	var due float64 // arrivals owed since start
+	var started int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			due += opts.level(now.Sub(start)) * now.Sub(last).Seconds()
+			last = now
+		}
+		for ; started < int64(due); started++ {
+			select {
+			case slots <- struct{}{}:
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-slots }()
+					l.send(ctx, l.pick())
+				}()
+			default:
+				l.mu.Lock()
+				l.dropped++
+				l.mu.Unlock()
+			}
+		}
+	}
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+// pick returns the index of a random endpoint, weighted.
+func (l *nativeLoad) pick() int {
+	n := rand.IntN(l.cumulative[len(l.cumulative)-1])
+	return sort.SearchInts(l.cumulative, n+1)
+}
+
+// send makes one request to endpoint i and records it. Transport errors
+// and statuses of 400 and above are failures.
+func (l *nativeLoad) send(ctx context.Context, i int) {
+	ep := l.endpoints[i]
+	url := ep.HostURL
+	if url == "" {
+		url = ep.URL
+	}
+	var body io.Reader
+	switch ep.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+	default:
+		body = strings.NewReader("{}")
+	}
+	req, err := http.NewRequestWithContext(ctx, ep.Method, url, body)
+	if err != nil {
+		l.record(i, 0, false)
+		return
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := l.client.Do(req)
+	if err == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+	elapsed := time.Since(start)
+	if ctx.Err() != nil {
+		return
+	}
+	l.record(i, elapsed, err == nil && resp.StatusCode < http.StatusBadRequest)
+}
+
+// record adds one request outcome.
+func (l *nativeLoad) record(i int, d time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rec.observe(i, d)
+	if !ok {
+		l.rec.fail(i)
+	}
+}
+
+// stats summarises the run so far.
+func (l *nativeLoad) stats(elapsed time.Duration) Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := l.rec.stats(elapsed)
+	s.Dropped = l.dropped
+	return s
+}
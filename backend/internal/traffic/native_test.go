@@ -0,0 +1,151 @@
+package traffic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// countingServer serves /ok, fails /fail with a 500 and counts requests by
+// method and path.
+func countingServer(t *testing.T) (*httptest.Server, func(string) int) {
+	t.Helper()
+	var mu sync.Mutex
+	counts := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counts[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func(key string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[key]
+	}
+}
+
+func TestNativeGenerator_ClosedModel(t *testing.T) {
+	srv, count := countingServer(t)
+	endpoints := []Endpoint{
+		{NodeID: "a", Method: "GET", Path: "/ok", URL: "http://a:80/ok", HostURL: srv.URL + "/ok", Weight: 3},
+		{NodeID: "b", Method: "POST", Path: "/fail", HostURL: srv.URL + "/fail"},
+	}
+
+	g := NewNativeGenerator()
+	g.interval = 50 * time.Millisecond
+	var reports int
+	stats, err := g.Run(context.Background(), endpoints, Options{Duration: 500 * time.Millisecond, VUs: 4}, func(Stats) { reports++ })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if reports == 0 {
+		t.Error("expected stats to be reported while running")
+	}
+
+	ok, fail := stats.Endpoints[0], stats.Endpoints[1]
+	if ok.Requests == 0 || fail.Requests == 0 || stats.Total.Requests != ok.Requests+fail.Requests {
+		t.Fatalf("unexpected counts: ok %d, fail %d, total %d", ok.Requests, fail.Requests, stats.Total.Requests)
+	}
+	if ratio := float64(ok.Requests) / float64(fail.Requests); ratio < 2 || ratio > 4.5 {
+		t.Errorf("expected about 3 requests to /ok per /fail, got %.2f", ratio)
+	}
+	if ok.ErrorRate != 0 || fail.ErrorRate != 1 {
+		t.Errorf("unexpected error rates: ok %v, fail %v", ok.ErrorRate, fail.ErrorRate)
+	}
+	if got := count("POST /fail"); int64(got) < fail.Requests {
+		t.Errorf("server saw %d POST /fail, generator recorded %d", got, fail.Requests)
+	}
+}
+
+func TestNativeGenerator_ThinkTime(t *testing.T) {
+	srv, _ := countingServer(t)
+	endpoints := []Endpoint{{NodeID: "a", Method: "GET", HostURL: srv.URL + "/ok"}}
+
+	stats, err := NewNativeGenerator().Run(context.Background(), endpoints, Options{Duration: 300 * time.Millisecond, VUs: 2, ThinkTime: 100 * time.Millisecond}, func(Stats) {})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n := stats.Total.Requests; n < 2 || n > 8 {
+		t.Errorf("expected 2 VUs pausing 100ms to send 2-8 requests in 300ms, got %d", n)
+	}
+}
+
+func TestNativeGenerator_OpenModelSustains1000RPS(t *testing.T) {
+	srv, count := countingServer(t)
+	endpoints := []Endpoint{{NodeID: "a", Method: "GET", HostURL: srv.URL + "/ok"}}
+
+	stats, err := NewNativeGenerator().Run(context.Background(), endpoints, Options{Duration: 2 * time.Second, Rate: 1000}, func(Stats) {})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n := stats.Total.Requests; n < 1600 || n > 2100 {
+		t.Errorf("expected about 2000 requests at 1000 RPS for 2s, got %d (%.0f RPS, %d dropped)", n, stats.Total.RPS, stats.Dropped)
+	}
+	if stats.Total.ErrorRate != 0 {
+		t.Errorf("expected no errors, got rate %v", stats.Total.ErrorRate)
+	}
+	if got := count("GET /ok"); int64(got) < stats.Total.Requests {
+		t.Errorf("server saw %d requests, generator recorded %d", got, stats.Total.Requests)
+	}
+}
+
+func TestNativeGenerator_FollowsStages(t *testing.T) {
+	srv, _ := countingServer(t)
+	endpoints := []Endpoint{{NodeID: "a", Method: "GET", HostURL: srv.URL + "/ok"}}
+	opts := ProfileOptions(model.LoadProfile{
+		Model: model.LoadModelOpen,
+		Stages: []model.LoadStage{
+			{DurationSeconds: 1, Target: 0, Ramp: model.RampStep},
+			{DurationSeconds: 1, Target: 200, Ramp: model.RampStep},
+		},
+	})
+
+	g := NewNativeGenerator()
+	g.interval = 500 * time.Millisecond
+	var first Stats
+	stats, err := g.Run(context.Background(), endpoints, opts, func(s Stats) {
+		if first.Endpoints == nil {
+			first = s
+		}
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if first.Total.Requests != 0 {
+		t.Errorf("expected no requests in the idle first stage, got %d", first.Total.Requests)
+	}
+	if n := stats.Total.Requests; n < 150 || n > 210 {
+		t.Errorf("expected about 200 requests in the second stage, got %d", n)
+	}
+}
+
+func TestNativeGenerator_StopsOnCancel(t *testing.T) {
+	srv, _ := countingServer(t)
+	endpoints := []Endpoint{{NodeID: "a", Method: "GET", HostURL: srv.URL + "/ok"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	if _, err := NewNativeGenerator().Run(ctx, endpoints, Options{Duration: time.Minute, Rate: 100}, func(Stats) {}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected Run to return soon after cancel, took %v", elapsed)
+	}
+}
+
+func TestNativeGenerator_NoEndpoints(t *testing.T) {
+	if _, err := NewNativeGenerator().Run(context.Background(), nil, Options{}, func(Stats) {}); !errors.Is(err, ErrNoEntryPoints) {
+		t.Errorf("expected ErrNoEntryPoints, got %v", err)
+	}
+}
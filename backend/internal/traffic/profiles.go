@@ -0,0 +1,221 @@
+package traffic
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+// DefaultProfileID is the preset that is active until another profile is
+// chosen. It sends the same traffic as the default Options.
+const DefaultProfileID = "constant"
+
+// ErrPresetReadOnly is returned when changing or deleting a preset.
+var ErrPresetReadOnly = errors.New("preset profiles cannot be changed")
+
+// presets are the built-in load profiles, in display order.
+var presets = []model.LoadProfile{
+	{
+		ID:          DefaultProfileID,
+		Name:        "Constant",
+		Description: "10 virtual users for 30 seconds.",
+		Model:       model.LoadModelClosed,
+		Stages:      []model.LoadStage{{DurationSeconds: 30, Target: DefaultVUs, Ramp: model.RampStep}},
+	},
+	{
+		ID:          "ramp-up",
+		Name:        "Ramp-up",
+		Description: "Ramp from 0 to 100 requests per second over a minute, then hold for a minute.",
+		Model:       model.LoadModelOpen,
+		Stages: []model.LoadStage{
+			{DurationSeconds: 60, Target: 100},
+			{DurationSeconds: 60, Target: 100},
+		},
+	},
+	{
+		ID:          "spike",
+		Name:        "Spike",
+		Description: "A 20 requests per second baseline with a 10-second burst to 200.",
+		Model:       model.LoadModelOpen,
+		Stages: []model.LoadStage{
+			{DurationSeconds: 30, Target: 20, Ramp: model.RampStep},
+			{DurationSeconds: 10, Target: 200, Ramp: model.RampStep},
+			{DurationSeconds: 30, Target: 20, Ramp: model.RampStep},
+		},
+	},
+	{
+		ID:          "step",
+		Name:        "Step",
+		Description: "Step up by 25 requests per second every 30 seconds, to 100.",
+		Model:       model.LoadModelOpen,
+		Stages: []model.LoadStage{
+			{DurationSeconds: 30, Target: 25, Ramp: model.RampStep},
+			{DurationSeconds: 30, Target: 50, Ramp: model.RampStep},
+			{DurationSeconds: 30, Target: 75, Ramp: model.RampStep},
+			{DurationSeconds: 30, Target: 100, Ramp: model.RampStep},
+		},
+	},
+	{
+		ID:          "soak",
+		Name:        "Soak",
+		Description: "20 virtual users with a second of think time for 30 minutes, ramping up and down over a minute each.",
+		Model:       model.LoadModelClosed,
+		ThinkTimeMs: 1000,
+		Stages: []model.LoadStage{
+			{DurationSeconds: 60, Target: 20},
+			{DurationSeconds: 1800, Target: 20},
+			{DurationSeconds: 60, Target: 0},
+		},
+	},
+}
+
+// Presets returns the built-in load profiles.
+func Presets() []model.LoadProfile {
+	out := make([]model.LoadProfile, len(presets))
+	for i, p := range presets {
+		p.Preset = true
+		p.Stages = append([]model.LoadStage(nil), p.Stages...)
+		out[i] = p
+	}
+	return out
+}
+
+// preset returns the built-in profile with the given ID.
+func preset(id string) (model.LoadProfile, bool) {
+	for _, p := range Presets() {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return model.LoadProfile{}, false
+}
+
+// ProfileOptions returns the Options that run p. The open model sets Rate,
+// and the closed model VUs, to the peak target; the stages then shape the
+// load over time.
+func ProfileOptions(p model.LoadProfile) Options {
+	opts := Options{
+		Duration:  p.Duration(),
+		ThinkTime: time.Duration(p.ThinkTimeMs) * time.Millisecond,
+		Stages:    append([]model.LoadStage(nil), p.Stages...),
+		Profile:   p.ID,
+	}
+	if p.Model == model.LoadModelOpen {
+		opts.Rate = p.Peak()
+		opts.ThinkTime = 0
+	} else {
+		opts.VUs = p.Peak()
+	}
+	return opts
+}
+
+// ValidateProfile checks that p is well formed and that the options it
+// runs with are within the generators' limits.
+func ValidateProfile(p *model.LoadProfile) error {
+	if err := model.ValidateLoadProfile(p); err != nil {
+		return err
+	}
+	if err := ProfileOptions(*p).Validate(); err != nil {
+		return &model.ValidationError{Errors: []string{err.Error()}}
+	}
+	return nil
+}
+
+// Profiles combines the presets with the stored load profiles and tracks
+// which one is active. The active profile is kept in memory and reverts to
+// DefaultProfileID on restart.
+type Profiles struct {
+	store storage.ProfileStore
+
+	mu     sync.Mutex
+	active string
+}
+
+// NewProfiles creates Profiles backed by store.
+func NewProfiles(store storage.ProfileStore) *Profiles {
+	return &Profiles{store: store, active: DefaultProfileID}
+}
+
+// List returns the presets followed by the stored profiles.
+func (p *Profiles) List() ([]model.LoadProfile, error) {
+	stored, err := p.store.List()
+	if err != nil {
+		return nil, err
+	}
+	return append(Presets(), stored...), nil
+}
+
+// Get returns the preset or stored profile with the given ID. It returns
+// storage.ErrProfileNotFound if there is none.
+func (p *Profiles) Get(id string) (model.LoadProfile, error) {
+	if lp, ok := preset(id); ok {
+		return lp, nil
+	}
+	lp, err := p.store.Get(id)
+	if err != nil {
+		return model.LoadProfile{}, err
+	}
+	return *lp, nil
+}
+
+// Create stores a new profile. It must already be valid.
+func (p *Profiles) Create(lp *model.LoadProfile) (*model.LoadProfile, error) {
+	return p.store.Create(lp)
+}
+
+// Update replaces a stored profile. It returns ErrPresetReadOnly for a
+// preset.
+func (p *Profiles) Update(id string, lp *model.LoadProfile) (*model.LoadProfile, error) {
+	if _, ok := preset(id); ok {
+		return nil, ErrPresetReadOnly
+	}
+	return p.store.Update(id, lp)
+}
+
+// Delete removes a stored profile, making DefaultProfileID active if it
+// was. It returns ErrPresetReadOnly for a preset.
+func (p *Profiles) Delete(id string) error {
+	if _, ok := preset(id); ok {
+		return ErrPresetReadOnly
+	}
+	if err := p.store.Delete(id); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == id {
+		p.active = DefaultProfileID
+	}
+	return nil
+}
+
+// Active returns the active profile. If it has disappeared from the store,
+// DefaultProfileID is active again.
+func (p *Profiles) Active() (model.LoadProfile, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lp, err := p.Get(p.active)
+	if errors.Is(err, storage.ErrProfileNotFound) {
+		p.active = DefaultProfileID
+		return p.Get(p.active)
+	}
+	return lp, err
+}
+
+// SetActive makes the profile with the given ID active and returns it.
+func (p *Profiles) SetActive(id string) (model.LoadProfile, error) {
+	lp, err := p.Get(id)
+	if err != nil {
+		return model.LoadProfile{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active = id
+	return lp, nil
+}
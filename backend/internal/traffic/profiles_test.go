@@ -0,0 +1,140 @@
+package traffic
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+	"github.com/stwalsh4118/hephaestus/backend/internal/storage"
+)
+
+func TestPresets_AreValid(t *testing.T) {
+	want := []string{"constant", "ramp-up", "spike", "step", "soak"}
+	got := Presets()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d presets, got %d", len(want), len(got))
+	}
+	for i, p := range got {
+		if p.ID != want[i] || !p.Preset {
+			t.Errorf("preset %d: got %q (preset %v), want %q", i, p.ID, p.Preset, want[i])
+		}
+		if err := ValidateProfile(&p); err != nil {
+			t.Errorf("preset %q: %v", p.ID, err)
+		}
+	}
+
+	got[0].Stages[0].Target = 999
+	if Presets()[0].Stages[0].Target == 999 {
+		t.Error("expected Presets to return copies")
+	}
+}
+
+func TestProfileOptions(t *testing.T) {
+	open := ProfileOptions(model.LoadProfile{
+		ID:          "p",
+		Model:       model.LoadModelOpen,
+		ThinkTimeMs: 500,
+		Stages:      []model.LoadStage{{DurationSeconds: 10, Target: 50}, {DurationSeconds: 20, Target: 20}},
+	})
+	if open.Rate != 50 || open.VUs != 0 || open.Duration != 30*time.Second || open.ThinkTime != 0 || open.Profile != "p" || len(open.Stages) != 2 {
+		t.Errorf("unexpected open options %+v", open)
+	}
+
+	closed := ProfileOptions(model.LoadProfile{
+		Model:       model.LoadModelClosed,
+		ThinkTimeMs: 500,
+		Stages:      []model.LoadStage{{DurationSeconds: 10, Target: 5}},
+	})
+	if closed.VUs != 5 || closed.Rate != 0 || closed.ThinkTime != 500*time.Millisecond {
+		t.Errorf("unexpected closed options %+v", closed)
+	}
+}
+
+func TestValidateProfile_Limits(t *testing.T) {
+	p := &model.LoadProfile{
+		Name:   "too much",
+		Model:  model.LoadModelOpen,
+		Stages: []model.LoadStage{{DurationSeconds: 10, Target: MaxRate + 1}},
+	}
+	var ve *model.ValidationError
+	if err := ValidateProfile(p); !errors.As(err, &ve) {
+		t.Errorf("expected a validation error for a rate over the limit, got %v", err)
+	}
+
+	p.Stages = []model.LoadStage{{DurationSeconds: 3601, Target: 10}}
+	if err := ValidateProfile(p); !errors.As(err, &ve) {
+		t.Errorf("expected a validation error for a profile over an hour, got %v", err)
+	}
+}
+
+func TestOptions_Level(t *testing.T) {
+	opts := Options{Rate: 100, Stages: []model.LoadStage{
+		{DurationSeconds: 10, Target: 100},
+		{DurationSeconds: 10, Target: 40, Ramp: model.RampStep},
+		{DurationSeconds: 10, Target: 0},
+	}}
+	for _, tc := range []struct {
+		at   time.Duration
+		want float64
+	}{
+		{0, 0},
+		{5 * time.Second, 50},
+		{10 * time.Second, 40},
+		{19 * time.Second, 40},
+		{25 * time.Second, 20},
+		{time.Minute, 0},
+	} {
+		if got := opts.level(tc.at); got != tc.want {
+			t.Errorf("level at %v: got %v, want %v", tc.at, got, tc.want)
+		}
+	}
+
+	if got := (Options{VUs: 7}).level(time.Hour); got != 7 {
+		t.Errorf("expected constant VUs without stages, got %v", got)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	store, err := storage.NewFileProfileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileProfileStore: %v", err)
+	}
+	profiles := NewProfiles(store)
+
+	if active, err := profiles.Active(); err != nil || active.ID != DefaultProfileID {
+		t.Fatalf("expected %q active by default, got %q (%v)", DefaultProfileID, active.ID, err)
+	}
+
+	created, err := profiles.Create(&model.LoadProfile{Name: "mine", Model: model.LoadModelClosed, Stages: []model.LoadStage{{DurationSeconds: 5, Target: 2}}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	list, err := profiles.List()
+	if err != nil || len(list) != len(presets)+1 || list[len(list)-1].ID != created.ID {
+		t.Errorf("expected presets then the stored profile, got %d profiles (%v)", len(list), err)
+	}
+
+	if _, err := profiles.SetActive(created.ID); err != nil {
+		t.Fatalf("SetActive: %v", err)
+	}
+	if active, _ := profiles.Active(); active.ID != created.ID {
+		t.Errorf("expected %q active, got %q", created.ID, active.ID)
+	}
+	if err := profiles.Delete(created.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if active, _ := profiles.Active(); active.ID != DefaultProfileID {
+		t.Errorf("expected the default active after deleting the active profile, got %q", active.ID)
+	}
+
+	if _, err := profiles.Update("spike", &model.LoadProfile{}); !errors.Is(err, ErrPresetReadOnly) {
+		t.Errorf("Update preset: expected ErrPresetReadOnly, got %v", err)
+	}
+	if err := profiles.Delete("spike"); !errors.Is(err, ErrPresetReadOnly) {
+		t.Errorf("Delete preset: expected ErrPresetReadOnly, got %v", err)
+	}
+	if _, err := profiles.SetActive("missing"); !errors.Is(err, storage.ErrProfileNotFound) {
+		t.Errorf("SetActive: expected ErrProfileNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package traffic
+
+import (
+	"math"
+	"time"
+)
+
+// recorder accumulates request outcomes per endpoint in histograms. It is
+// not safe for concurrent use.
+type recorder struct {
+	endpoints []Endpoint
+	latency   []*Histogram // per endpoint
+	failures  []int64      // per endpoint
+}
+
+// newRecorder creates a recorder for the given endpoints.
+func newRecorder(endpoints []Endpoint) *recorder {
+	r := &recorder{
+		endpoints: endpoints,
+		latency:   make([]*Histogram, len(endpoints)),
+		failures:  make([]int64, len(endpoints)),
+	}
+	for i := range r.latency {
+		r.latency[i] = NewHistogram()
+	}
+	return r
+}
+
+// observe records a completed request to endpoint i. Out-of-range indexes
+// are ignored.
+func (r *recorder) observe(i int, d time.Duration) {
+	if i >= 0 && i < len(r.latency) {
+		r.latency[i].Record(d)
+	}
+}
+
+// fail records a failed request to endpoint i. Failures are counted
+// separately from observe so generators that report them as separate
+// samples, such as k6, need not pair them up.
+func (r *recorder) fail(i int) {
+	if i >= 0 && i < len(r.failures) {
+		r.failures[i]++
+	}
+}
+
+// stats summarises everything recorded over elapsed.
+func (r *recorder) stats(elapsed time.Duration) Stats {
+	s := Stats{Endpoints: make([]EndpointStats, len(r.endpoints))}
+	all := NewHistogram()
+	var failures int64
+	for i, ep := range r.endpoints {
+		s.Endpoints[i] = summarise(ep, r.latency[i], r.failures[i], elapsed)
+		all.Merge(r.latency[i])
+		failures += r.failures[i]
+	}
+	s.Total = summarise(Endpoint{}, all, failures, elapsed)
+	return s
+}
+
+// summarise builds the stats for one histogram.
+func summarise(ep Endpoint, h *Histogram, failures int64, elapsed time.Duration) EndpointStats {
+	st := EndpointStats{Endpoint: ep, Requests: h.Count()}
+	if h.Count() == 0 {
+		return st
+	}
+	if elapsed > 0 {
+		st.RPS = float64(h.Count()) / elapsed.Seconds()
+	}
+	st.ErrorRate = math.Min(float64(failures)/float64(h.Count()), 1)
+	st.Latency = LatencyStats{
+		Avg: ms(h.Mean()),
+		P50: ms(h.Percentile(50)),
+		P90: ms(h.Percentile(90)),
+		P95: ms(h.Percentile(95)),
+		P99: ms(h.Percentile(99)),
+		Max: ms(h.Max()),
+	}
+	return st
+}
+
+// ms converts d to fractional milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,45 @@
+package traffic
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// near reports whether got is within the histogram's 1% precision of want.
+func near(got, want float64) bool {
+	return math.Abs(got-want) <= want/100
+}
+
+func TestRecorder_Stats(t *testing.T) {
+	eps := []Endpoint{{NodeID: "a"}, {NodeID: "b"}}
+	r := newRecorder(eps)
+	for i := 1; i <= 100; i++ {
+		r.observe(0, time.Duration(i)*time.Millisecond)
+	}
+	r.observe(1, 500*time.Millisecond)
+	r.fail(1)
+	r.observe(7, time.Millisecond) // unknown endpoints are ignored
+
+	s := r.stats(10 * time.Second)
+	a := s.Endpoints[0]
+	if a.Requests != 100 || a.RPS != 10 || a.ErrorRate != 0 {
+		t.Errorf("unexpected counts %+v", a)
+	}
+	if !near(a.Latency.P50, 50) || !near(a.Latency.P90, 90) || !near(a.Latency.P99, 99) || a.Latency.Max != 100 || a.Latency.Avg != 50.5 {
+		t.Errorf("unexpected latency %+v", a.Latency)
+	}
+	if b := s.Endpoints[1]; b.ErrorRate != 1 || !near(b.Latency.P50, 500) {
+		t.Errorf("unexpected stats %+v", b)
+	}
+	if s.Total.Requests != 101 || s.Total.Latency.Max != 500 || s.Total.Endpoint != (Endpoint{}) {
+		t.Errorf("unexpected total %+v", s.Total)
+	}
+}
+
+func TestRecorder_NoSamples(t *testing.T) {
+	s := newRecorder([]Endpoint{{NodeID: "a"}}).stats(time.Second)
+	if s.Total.Requests != 0 || s.Total.Latency != (LatencyStats{}) {
+		t.Errorf("expected empty stats, got %+v", s.Total)
+	}
+}
@@ -0,0 +1,118 @@
+package traffic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// pathParam matches an OpenAPI path template parameter such as {id}.
+var pathParam = regexp.MustCompile(`\{[^/{}]*\}`)
+
+// pathParamValue is substituted for every path parameter. The mock servers
+// accept any value, and a number satisfies both string and integer params.
+const pathParamValue = "1"
+
+// Target is where a deployed node can be reached.
+type Target struct {
+	// Hostname is the node's hostname on the shared network.
+	Hostname string
+	// Ports maps published host ports to container ports.
+	Ports map[string]string
+}
+
+// EntryPoints returns the endpoints traffic is sent to: every endpoint of
+// api-service nodes no other node calls, and the root of every nginx node.
+// targets maps the deployed node IDs to where they can be reached; nodes
+// without one are not running and are skipped. Endpoints are ordered by node
+// ID, then in definition order. ErrNoEntryPoints is returned when nothing is
+// left.
+func EntryPoints(d model.Diagram, targets map[string]Target) ([]Endpoint, error) {
+	inbound := make(map[string]bool, len(d.Edges))
+	for _, e := range d.Edges {
+		inbound[e.Target] = true
+	}
+
+	nodes := make([]model.DiagramNode, len(d.Nodes))
+	copy(nodes, d.Nodes)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	var endpoints []Endpoint
+	for _, n := range nodes {
+		target, ok := targets[n.ID]
+		if !ok {
+			continue
+		}
+		switch n.Type {
+		case model.ServiceTypeNginx:
+			endpoints = append(endpoints, newEndpoint(n.ID, target, templates.PortNginx, model.Endpoint{Method: "GET", Path: "/"}))
+		case model.ServiceTypeAPIService:
+			if inbound[n.ID] {
+				continue
+			}
+			defs, err := apiEndpoints(n)
+			if err != nil {
+				return nil, err
+			}
+			for _, def := range defs {
+				endpoints = append(endpoints, newEndpoint(n.ID, target, templates.PortAPIService, def))
+			}
+		}
+	}
+
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%w: deploy an nginx node or an api-service with endpoints that nothing else calls", ErrNoEntryPoints)
+	}
+	return endpoints, nil
+}
+
+// apiEndpoints returns the endpoint definitions of an api-service node.
+func apiEndpoints(n model.DiagramNode) ([]model.Endpoint, error) {
+	if len(n.Config) == 0 {
+		return nil, nil
+	}
+	var cfg model.ApiServiceConfig
+	if err := json.Unmarshal(n.Config, &cfg); err != nil {
+		return nil, fmt.Errorf("parse api-service config for node %q: %w", n.ID, err)
+	}
+	return cfg.Endpoints, nil
+}
+
+// newEndpoint builds the Endpoint for def on the target's container port.
+// HostURL is set when that port is published.
+func newEndpoint(nodeID string, target Target, port string, def model.Endpoint) Endpoint {
+	path := pathParam.ReplaceAllString(def.Path, pathParamValue)
+	ep := Endpoint{
+		NodeID: nodeID,
+		Method: strings.ToUpper(def.Method),
+		Path:   def.Path,
+		URL:    "http://" + net.JoinHostPort(target.Hostname, port) + path,
+		Weight: max(def.Weight, 1),
+	}
+	if hostPort := publishedPort(target.Ports, port); hostPort != "" {
+		ep.HostURL = "http://" + net.JoinHostPort("127.0.0.1", hostPort) + path
+	}
+	return ep
+}
+
+// publishedPort returns a host port mapped to containerPort, the same one
+// every call, or "" if it is not published.
+func publishedPort(ports map[string]string, containerPort string) string {
+	var found []string
+	for host, cp := range ports {
+		if cp == containerPort {
+			found = append(found, host)
+		}
+	}
+	if len(found) == 0 {
+		return ""
+	}
+	sort.Strings(found)
+	return found[0]
+}
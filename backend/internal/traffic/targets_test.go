@@ -0,0 +1,69 @@
+package traffic
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// apiNode returns an api-service node with the given endpoints.
+func apiNode(t *testing.T, id string, endpoints ...model.Endpoint) model.DiagramNode {
+	t.Helper()
+	cfg, err := json.Marshal(model.ApiServiceConfig{Type: model.ServiceTypeAPIService, Endpoints: endpoints})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	return model.DiagramNode{ID: id, Type: model.ServiceTypeAPIService, Name: id, Config: cfg}
+}
+
+func TestEntryPoints(t *testing.T) {
+	d := model.Diagram{
+		Nodes: []model.DiagramNode{
+			apiNode(t, "users", model.Endpoint{Method: "get", Path: "/users/{id}", Weight: 3}, model.Endpoint{Method: "POST", Path: "/users"}),
+			apiNode(t, "orders", model.Endpoint{Method: "GET", Path: "/orders"}),
+			{ID: "edge", Type: model.ServiceTypeNginx, Name: "edge"},
+			{ID: "db", Type: model.ServiceTypePostgreSQL, Name: "db"},
+			{ID: "idle", Type: model.ServiceTypeNginx, Name: "idle"},
+		},
+		Edges: []model.DiagramEdge{
+			{ID: "e1", Source: "edge", Target: "orders"},
+			{ID: "e2", Source: "users", Target: "db"},
+		},
+	}
+	targets := map[string]Target{
+		"users":  {Hostname: "users", Ports: map[string]string{"9000": "4010"}},
+		"orders": {Hostname: "orders"},
+		"edge":   {Hostname: "edge"},
+		"db":     {Hostname: "db"},
+	}
+
+	got, err := EntryPoints(d, targets)
+	if err != nil {
+		t.Fatalf("EntryPoints: %v", err)
+	}
+	want := []Endpoint{
+		{NodeID: "edge", Method: "GET", Path: "/", URL: "http://edge:80/", Weight: 1},
+		{NodeID: "users", Method: "GET", Path: "/users/{id}", URL: "http://users:4010/users/1", HostURL: "http://127.0.0.1:9000/users/1", Weight: 3},
+		{NodeID: "users", Method: "POST", Path: "/users", URL: "http://users:4010/users", HostURL: "http://127.0.0.1:9000/users", Weight: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d endpoints, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("endpoint %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestEntryPoints_NothingToTarget(t *testing.T) {
+	d := model.Diagram{Nodes: []model.DiagramNode{
+		apiNode(t, "empty"),
+		{ID: "db", Type: model.ServiceTypePostgreSQL, Name: "db"},
+	}}
+	if _, err := EntryPoints(d, map[string]Target{"empty": {Hostname: "empty"}, "db": {Hostname: "db"}}); !errors.Is(err, ErrNoEntryPoints) {
+		t.Errorf("expected ErrNoEntryPoints, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Package traffic generates load against a deployed diagram and reports
+// per-endpoint throughput, latency and error rates.
+package traffic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/model"
+)
+
+// Option limits and defaults.
+const (
+	DefaultDuration = 30 * time.Second
+	DefaultVUs      = 10
+	MaxDuration     = time.Hour
+	MaxVUs          = 1000
+	MaxRate         = 10000
+	MaxThinkTime    = time.Minute
+)
+
+// ReportInterval is how often a running generator reports its stats.
+const ReportInterval = time.Second
+
+var (
+	// ErrRunning is returned when starting traffic while a run is active.
+	ErrRunning = errors.New("traffic is already running")
+	// ErrNotRunning is returned when stopping traffic while no run is active.
+	ErrNotRunning = errors.New("traffic is not running")
+	// ErrNoEntryPoints is returned when a diagram has nothing to send
+	// traffic to.
+	ErrNoEntryPoints = errors.New("no entry points")
+)
+
+// Endpoint is one request a generator sends. Fields are omitted from JSON
+// when empty, as they are in the run total.
+type Endpoint struct {
+	NodeID string `json:"nodeId,omitempty"`
+	Method string `json:"method,omitempty"`
+	// Path is the path as defined on the node, e.g. /users/{id}.
+	Path string `json:"path,omitempty"`
+	// URL is the concrete URL requested on the shared network, with path
+	// parameters filled in.
+	URL string `json:"url,omitempty"`
+	// HostURL is the same request through the port published on the host,
+	// for generators running outside the network. Empty if not published.
+	HostURL string `json:"hostUrl,omitempty"`
+	// Weight is the endpoint's relative share of requests.
+	Weight int `json:"weight,omitempty"`
+}
+
+// Options controls a traffic run. With Rate set, requests arrive at that
+// many per second whatever the response times (open model); otherwise VUs
+// virtual users each send a request, wait ThinkTime and repeat (closed
+// model).
+type Options struct {
+	Duration time.Duration `json:"duration"`
+	VUs      int           `json:"vus"`
+	// Rate is the target request rate in requests per second; 0 runs the
+	// closed model.
+	Rate int `json:"rate,omitempty"`
+	// ThinkTime is the pause between a virtual user's requests in the
+	// closed model.
+	ThinkTime time.Duration `json:"thinkTime,omitempty"`
+	// Stages shape the load over Duration, their targets scaling Rate in
+	// the open model and VUs in the closed one. Without stages the load is
+	// constant. See ProfileOptions.
+	Stages []model.LoadStage `json:"stages,omitempty"`
+	// Profile is the ID of the load profile the options came from, if any.
+	Profile string `json:"profile,omitempty"`
+}
+
+// withDefaults fills in zero values.
+func (o Options) withDefaults() Options {
+	if o.Duration == 0 {
+		o.Duration = DefaultDuration
+	}
+	if o.VUs == 0 {
+		o.VUs = DefaultVUs
+	}
+	return o
+}
+
+// Validate checks that o is within limits. Zero values are allowed and
+// replaced by defaults when the run starts.
+func (o Options) Validate() error {
+	if o.Duration != 0 && (o.Duration < time.Second || o.Duration > MaxDuration) {
+		return fmt.Errorf("duration must be between 1s and %s", MaxDuration)
+	}
+	if o.VUs < 0 || o.VUs > MaxVUs {
+		return fmt.Errorf("vus must be between 0 and %d", MaxVUs)
+	}
+	if o.Rate < 0 || o.Rate > MaxRate {
+		return fmt.Errorf("rate must be between 0 and %d", MaxRate)
+	}
+	if o.ThinkTime < 0 || o.ThinkTime > MaxThinkTime {
+		return fmt.Errorf("think time must be between 0s and %s", MaxThinkTime)
+	}
+	return nil
+}
+
+// level returns the target request rate (open model) or number of virtual
+// users (closed model) elapsed into the run. Each stage starts from the
+// previous stage's target, or 0 for the first, and the last target holds
+// once the stages are over.
+func (o Options) level(elapsed time.Duration) float64 {
+	if len(o.Stages) == 0 {
+		if o.Rate > 0 {
+			return float64(o.Rate)
+		}
+		return float64(o.VUs)
+	}
+	prev := 0.0
+	for _, st := range o.Stages {
+		d := time.Duration(st.DurationSeconds) * time.Second
+		target := float64(st.Target)
+		if elapsed < d {
+			if st.Ramp == model.RampStep {
+				return target
+			}
+			return prev + (target-prev)*float64(elapsed)/float64(d)
+		}
+		elapsed -= d
+		prev = target
+	}
+	return prev
+}
+
+// LatencyStats summarises response times in milliseconds.
+type LatencyStats struct {
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// EndpointStats is the measured traffic for one endpoint, or for the whole
+// run when Endpoint is empty.
+type EndpointStats struct {
+	Endpoint
+	Requests int64   `json:"requests"`
+	RPS      float64 `json:"rps"`
+	// ErrorRate is the fraction of requests that failed, from 0 to 1.
+	ErrorRate float64      `json:"errorRate"`
+	Latency   LatencyStats `json:"latencyMs"`
+}
+
+// Stats is a generator's measurements so far.
+type Stats struct {
+	Total     EndpointStats   `json:"total"`
+	Endpoints []EndpointStats `json:"endpoints"`
+	// Dropped counts arrivals the open model skipped because every virtual
+	// user was busy.
+	Dropped int64 `json:"dropped,omitempty"`
+}
+
+// Generator sends traffic to endpoints until the options' duration has
+// passed or ctx is cancelled, calling report with the stats so far every
+// ReportInterval. It returns the final stats.
+type Generator interface {
+	// Name identifies the generator in run reports, e.g. "k6".
+	Name() string
+	Run(ctx context.Context, endpoints []Endpoint, opts Options, report func(Stats)) (Stats, error)
+}
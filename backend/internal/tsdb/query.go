@@ -0,0 +1,217 @@
+package tsdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrInvalid is returned for a query that cannot be answered as asked.
+var ErrInvalid = errors.New("invalid query")
+
+// MaxPoints bounds the points of one series in a query result.
+const MaxPoints = 11000
+
+// Aggregation is how the samples in each step of a query are combined.
+type Aggregation string
+
+// Aggregation values.
+const (
+	AggLast Aggregation = "last"
+	AggAvg  Aggregation = "avg"
+	AggMin  Aggregation = "min"
+	AggMax  Aggregation = "max"
+	// AggRate is the per-second increase of a counter over each step,
+	// treating a decrease as a counter reset.
+	AggRate Aggregation = "rate"
+	AggP50  Aggregation = "p50"
+	AggP95  Aggregation = "p95"
+	AggP99  Aggregation = "p99"
+)
+
+// Aggregations are the supported aggregations.
+var Aggregations = []Aggregation{AggLast, AggAvg, AggMin, AggMax, AggRate, AggP50, AggP95, AggP99}
+
+// percentiles maps the percentile aggregations to their quantile.
+var percentiles = map[Aggregation]float64{AggP50: 0.5, AggP95: 0.95, AggP99: 0.99}
+
+// Query selects series of a deployment and how to aggregate them.
+type Query struct {
+	Deployment string
+	// Node limits the query to one node; empty matches every node.
+	Node   string
+	Metric string
+	Start  time.Time
+	End    time.Time
+	// Step is the spacing of the result points. Each point aggregates the
+	// samples in the step ending at its time.
+	Step        time.Duration
+	Aggregation Aggregation
+}
+
+// Validate checks that q can be answered.
+func (q Query) Validate() error {
+	if q.Metric == "" {
+		return fmt.Errorf("%w: metric is required", ErrInvalid)
+	}
+	if !q.End.After(q.Start) {
+		return fmt.Errorf("%w: end must be after start", ErrInvalid)
+	}
+	if q.Step <= 0 {
+		return fmt.Errorf("%w: step must be positive", ErrInvalid)
+	}
+	if n := q.End.Sub(q.Start) / q.Step; n >= MaxPoints {
+		return fmt.Errorf("%w: %d points exceed the limit of %d; use a larger step", ErrInvalid, n+1, MaxPoints)
+	}
+	for _, a := range Aggregations {
+		if q.Aggregation == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown aggregation %q", ErrInvalid, q.Aggregation)
+}
+
+// Point is one value of a query result.
+type Point struct {
+	T time.Time `json:"t"`
+	V float64   `json:"v"`
+}
+
+// Series is a series of a query result. Steps without samples have no
+// point.
+type Series struct {
+	Key
+	Points []Point `json:"points"`
+}
+
+// Query returns the series matching q, sorted by node, aggregated into a
+// point every q.Step from q.Start to q.End. Each series is read at the
+// finest resolution still holding q.Start, so older ranges come from the
+// downsampled tiers; percentiles over those use the bucket averages.
+func (s *Store) Query(q Query) ([]Series, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []Series{}
+	d, ok := s.deployments[q.Deployment]
+	if !ok {
+		return result, nil
+	}
+	for key, ser := range d.series {
+		if key.Metric != q.Metric || (q.Node != "" && key.Node != q.Node) {
+			continue
+		}
+		result = append(result, Series{Key: key, Points: ser.query(q)})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Node < result[j].Node })
+	return result, nil
+}
+
+// query aggregates the points of s for q.
+func (s *series) query(q Query) []Point {
+	r := s.rings[len(s.rings)-1]
+	for _, candidate := range s.rings {
+		if candidate.len() > 0 && (!candidate.full() || !candidate.at(0).t.After(q.Start)) {
+			r = candidate
+			break
+		}
+	}
+
+	out := []Point{}
+	// i is the first point after the previous step.
+	i := sort.Search(r.len(), func(i int) bool { return r.at(i).t.After(q.Start.Add(-q.Step)) })
+	for t := q.Start; !t.After(q.End); t = t.Add(q.Step) {
+		j := i
+		for j < r.len() && !r.at(j).t.After(t) {
+			j++
+		}
+		if j > i {
+			var prev *point
+			if i > 0 {
+				p := r.at(i - 1)
+				prev = &p
+			}
+			if v, ok := aggregate(q.Aggregation, r, i, j, prev, q.Step); ok {
+				out = append(out, Point{T: t, V: v})
+			}
+		}
+		i = j
+	}
+	return out
+}
+
+// aggregate combines the points [i, j) of r. prev is the point before i,
+// if any, which a rate counts the increase from.
+func aggregate(agg Aggregation, r *ring, i, j int, prev *point, step time.Duration) (float64, bool) {
+	switch agg {
+	case AggLast:
+		return r.at(j - 1).last, true
+	case AggAvg:
+		var sum float64
+		var count int
+		for k := i; k < j; k++ {
+			p := r.at(k)
+			sum += p.sum
+			count += p.count
+		}
+		return sum / float64(count), true
+	case AggMin:
+		v := math.Inf(1)
+		for k := i; k < j; k++ {
+			v = min(v, r.at(k).min)
+		}
+		return v, true
+	case AggMax:
+		v := math.Inf(-1)
+		for k := i; k < j; k++ {
+			v = max(v, r.at(k).max)
+		}
+		return v, true
+	case AggRate:
+		if prev == nil && j-i == 1 && r.at(i).count == 1 {
+			return 0, false
+		}
+		var increase float64
+		last := math.NaN()
+		if prev != nil {
+			last = prev.last
+		}
+		for k := i; k < j; k++ {
+			p := r.at(k)
+			if !math.IsNaN(last) {
+				increase += counterIncrease(last, p.first)
+			}
+			increase += counterIncrease(p.first, p.last)
+			last = p.last
+		}
+		return increase / step.Seconds(), true
+	default:
+		values := make([]float64, 0, j-i)
+		for k := i; k < j; k++ {
+			values = append(values, r.at(k).avg())
+		}
+		return percentile(values, percentiles[agg]), true
+	}
+}
+
+// counterIncrease returns how much a counter grew from a to b; a counter
+// that went down was reset and grew by b since.
+func counterIncrease(a, b float64) float64 {
+	if b < a {
+		return b
+	}
+	return b - a
+}
+
+// percentile returns the q-quantile of values by the nearest-rank method.
+func percentile(values []float64, q float64) float64 {
+	sort.Float64s(values)
+	rank := int(math.Ceil(q*float64(len(values)))) - 1
+	return values[max(rank, 0)]
+}
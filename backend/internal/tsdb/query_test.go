@@ -0,0 +1,152 @@
+package tsdb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func values(points []Point) []float64 {
+	out := make([]float64, len(points))
+	for i, p := range points {
+		out[i] = p.V
+	}
+	return out
+}
+
+func equal(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQuery_Aggregations(t *testing.T) {
+	s := NewStore()
+	k := key("api", "latency")
+	// Two steps of 10s: samples 1..4 at 2.5s spacing in each.
+	for i, v := range []float64{1, 2, 3, 4, 10, 20, 30, 40} {
+		s.Append(k, t0.Add(time.Duration(i+1)*2500*time.Millisecond), v)
+	}
+	s.Append(key("db", "latency"), t0.Add(time.Second), 7)
+	s.Append(key("api", "other"), t0.Add(time.Second), 7)
+
+	tests := []struct {
+		agg  Aggregation
+		want []float64
+	}{
+		{AggLast, []float64{4, 40}},
+		{AggAvg, []float64{2.5, 25}},
+		{AggMin, []float64{1, 10}},
+		{AggMax, []float64{4, 40}},
+		{AggP50, []float64{2, 20}},
+		{AggP99, []float64{4, 40}},
+	}
+	for _, tt := range tests {
+		got, err := s.Query(Query{Deployment: "dep", Node: "api", Metric: "latency",
+			Start: t0.Add(10 * time.Second), End: t0.Add(20 * time.Second), Step: 10 * time.Second, Aggregation: tt.agg})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.agg, err)
+		}
+		if len(got) != 1 || got[0].Node != "api" {
+			t.Fatalf("%s: expected the api series only, got %+v", tt.agg, got)
+		}
+		if v := values(got[0].Points); !equal(v, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.agg, v, tt.want)
+		}
+		if !got[0].Points[0].T.Equal(t0.Add(10 * time.Second)) {
+			t.Errorf("%s: expected the first point at the start, got %v", tt.agg, got[0].Points[0].T)
+		}
+	}
+
+	all, err := s.Query(Query{Deployment: "dep", Metric: "latency",
+		Start: t0, End: t0.Add(20 * time.Second), Step: 5 * time.Second, Aggregation: AggLast})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(all) != 2 || all[0].Node != "api" || all[1].Node != "db" {
+		t.Fatalf("expected both nodes sorted, got %+v", all)
+	}
+	if v := values(all[1].Points); !equal(v, []float64{7}) {
+		t.Errorf("expected steps without samples to be omitted, got %v", v)
+	}
+}
+
+func TestQuery_RateHandlesResets(t *testing.T) {
+	s := NewStore()
+	k := key("api", MetricMockRequests)
+	// A counter growing by 10 every 5s, reset to 0 at 25s.
+	for i, v := range []float64{0, 10, 20, 30, 40, 5, 15} {
+		s.Append(k, t0.Add(time.Duration(i)*5*time.Second), v)
+	}
+
+	got, err := s.Query(Query{Deployment: "dep", Metric: MetricMockRequests,
+		Start: t0, End: t0.Add(30 * time.Second), Step: 10 * time.Second, Aggregation: AggRate})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	// The first step holds a single sample and no earlier one to compare to.
+	want := []float64{2, 2, 1.5}
+	if v := values(got[0].Points); !equal(v, want) {
+		t.Errorf("got %v, want %v", v, want)
+	}
+}
+
+func TestQuery_UsesDownsampledTierForOldRanges(t *testing.T) {
+	s := NewStore()
+	k := key("api", MetricCPUPercent)
+	// One hour of 5s samples, more than the raw tier holds; each minute's
+	// samples are all equal to the minute.
+	for i := range 720 {
+		s.Append(k, t0.Add(time.Duration(i)*5*time.Second), float64(i/12))
+	}
+
+	got, err := s.Query(Query{Deployment: "dep", Metric: MetricCPUPercent,
+		Start: t0.Add(time.Minute), End: t0.Add(3 * time.Minute), Step: time.Minute, Aggregation: AggAvg})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	// Buckets are keyed by their start, so the step ending at 1m holds the
+	// bucket starting at 1m.
+	if v := values(got[0].Points); !equal(v, []float64{1, 2, 3}) {
+		t.Errorf("got %v, want [1 2 3]", v)
+	}
+
+	recent, _ := s.Query(Query{Deployment: "dep", Metric: MetricCPUPercent,
+		Start: t0.Add(59 * time.Minute), End: t0.Add(59*time.Minute + 55*time.Second), Step: 5 * time.Second, Aggregation: AggLast})
+	if n := len(recent[0].Points); n != 12 {
+		t.Errorf("expected raw samples for the last minute, got %d points", n)
+	}
+}
+
+func TestQuery_Validate(t *testing.T) {
+	valid := Query{Metric: "m", Start: t0, End: t0.Add(time.Minute), Step: time.Second, Aggregation: AggAvg}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected a valid query, got %v", err)
+	}
+
+	tests := map[string]func(q *Query){
+		"no metric":       func(q *Query) { q.Metric = "" },
+		"end before":      func(q *Query) { q.End = q.Start },
+		"no step":         func(q *Query) { q.Step = 0 },
+		"too many points": func(q *Query) { q.End = q.Start.Add(MaxPoints * time.Second) },
+		"unknown agg":     func(q *Query) { q.Aggregation = "median" },
+	}
+	for name, mutate := range tests {
+		q := valid
+		mutate(&q)
+		if _, err := NewStore().Query(q); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", name, err)
+		}
+	}
+
+	got, err := NewStore().Query(valid)
+	if err != nil || got == nil || len(got) != 0 {
+		t.Errorf("expected no series for an unknown deployment, got %v (%v)", got, err)
+	}
+}
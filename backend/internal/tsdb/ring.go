@@ -0,0 +1,84 @@
+package tsdb
+
+import "time"
+
+// point is a sample, or the summary of the samples merged into one bucket
+// of a downsampled tier.
+type point struct {
+	// t is the sample time, or the start of the bucket.
+	t     time.Time
+	count int
+	sum   float64
+	min   float64
+	max   float64
+	// first and last are the earliest and latest samples, which rates of
+	// counters are computed from.
+	first float64
+	last  float64
+}
+
+// newPoint returns a point holding the single sample v at t.
+func newPoint(t time.Time, v float64) point {
+	return point{t: t, count: 1, sum: v, min: v, max: v, first: v, last: v}
+}
+
+// add merges the sample v, taken after every sample in p, into p.
+func (p *point) add(v float64) {
+	p.count++
+	p.sum += v
+	p.min = min(p.min, v)
+	p.max = max(p.max, v)
+	p.last = v
+}
+
+// avg returns the mean of the samples in p.
+func (p point) avg() float64 {
+	return p.sum / float64(p.count)
+}
+
+// ring holds the newest points up to a fixed capacity, overwriting the
+// oldest once full. Its buffer grows as points arrive, so short-lived
+// series stay small.
+type ring struct {
+	buf  []point
+	cap  int
+	head int // index of the oldest point once buf is full
+}
+
+func newRing(capacity int) *ring {
+	return &ring{cap: capacity}
+}
+
+// len returns the number of points held.
+func (r *ring) len() int {
+	return len(r.buf)
+}
+
+// full reports whether points have started being overwritten.
+func (r *ring) full() bool {
+	return len(r.buf) == r.cap
+}
+
+// at returns the i-th oldest point.
+func (r *ring) at(i int) point {
+	return r.buf[(r.head+i)%len(r.buf)]
+}
+
+// newest returns a pointer to the newest point, which may be updated in
+// place, or nil when the ring is empty.
+func (r *ring) newest() *point {
+	if len(r.buf) == 0 {
+		return nil
+	}
+	return &r.buf[(r.head+len(r.buf)-1)%len(r.buf)]
+}
+
+// push appends p, overwriting the oldest point when full.
+func (r *ring) push(p point) {
+	if len(r.buf) < r.cap {
+		r.buf = append(r.buf, p)
+		return
+	}
+	r.buf[r.head] = p
+	r.head = (r.head + 1) % r.cap
+}
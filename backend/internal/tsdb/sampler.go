@@ -0,0 +1,282 @@
+package tsdb
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/traffic"
+)
+
+// DefaultSampleInterval is how often a Sampler collects container stats
+// and mock-server counters.
+const DefaultSampleInterval = 5 * time.Second
+
+// scrapeTimeout bounds one container stats call or mock-server scrape.
+const scrapeTimeout = 3 * time.Second
+
+// Container metrics, sampled from the orchestrator.
+const (
+	MetricCPUPercent     = "cpu_percent"
+	MetricMemoryBytes    = "memory_bytes"
+	MetricNetworkRxBytes = "network_rx_bytes"
+	MetricNetworkTxBytes = "network_tx_bytes"
+)
+
+// Mock-server metrics, scraped from the metrics port of API services. They
+// are counters summed over routes and targets.
+const (
+	MetricMockRequests        = "mock_requests_total"
+	MetricMockErrors          = "mock_errors_total"
+	MetricMockDownstreamCalls = "mock_downstream_calls_total"
+	MetricMockDownstreamErrs  = "mock_downstream_errors_total"
+)
+
+// Load-generator metrics, recorded per target node from traffic run stats.
+// Requests and errors are counters that restart with each run; latency
+// percentiles are the request-weighted mean over the node's endpoints.
+const (
+	MetricTrafficRequests   = "traffic_requests_total"
+	MetricTrafficErrors     = "traffic_errors_total"
+	MetricTrafficLatencyP50 = "traffic_latency_p50_ms"
+	MetricTrafficLatencyP95 = "traffic_latency_p95_ms"
+	MetricTrafficLatencyP99 = "traffic_latency_p99_ms"
+)
+
+// Deployment reports the running deployment. *deploy.Manager satisfies it.
+type Deployment interface {
+	Status() deploy.Status
+}
+
+// DeploymentID returns the ID the series of the deployment in status are
+// stored under: its history record, or its diagram when history is not
+// kept.
+func DeploymentID(status deploy.Status) string {
+	if status.DeploymentID != "" {
+		return status.DeploymentID
+	}
+	return status.DiagramID
+}
+
+// Sampler feeds a Store with the metrics of the running deployment.
+type Sampler struct {
+	store      *Store
+	orch       docker.Orchestrator
+	deployment Deployment
+	client     *http.Client
+	now        func() time.Time
+}
+
+// NewSampler creates a Sampler writing the metrics of deployment, whose
+// containers run on orch, to store.
+func NewSampler(store *Store, orch docker.Orchestrator, deployment Deployment) *Sampler {
+	return &Sampler{
+		store:      store,
+		orch:       orch,
+		deployment: deployment,
+		client:     &http.Client{Timeout: scrapeTimeout},
+		now:        time.Now,
+	}
+}
+
+// Run samples every interval until ctx is cancelled.
+func (s *Sampler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sample(ctx)
+		}
+	}
+}
+
+// Sample records the stats of every container of the running deployment
+// and the counters of its mock servers, all at the same time. Containers
+// that cannot be read, e.g. because they are being replaced, are skipped
+// until the next round.
+func (s *Sampler) Sample(ctx context.Context) {
+	status := s.deployment.Status()
+	if status.State != deploy.StateDeployed {
+		return
+	}
+	id := DeploymentID(status)
+	now := s.now()
+
+	var wg sync.WaitGroup
+	for _, n := range status.Nodes {
+		if n.ContainerID == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.sampleContainer(ctx, id, n, now)
+			if port, ok := publishedPort(n, templates.PortAPIServiceMetrics); ok {
+				s.scrapeMockServer(ctx, id, n.NodeID, port, now)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// sampleContainer records the resource usage of n's container.
+func (s *Sampler) sampleContainer(ctx context.Context, deploymentID string, n deploy.NodeStatus, now time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, scrapeTimeout)
+	defer cancel()
+	stats, err := s.orch.ContainerStats(ctx, n.ContainerID)
+	if err != nil {
+		return
+	}
+	s.append(deploymentID, n.NodeID, now, map[string]float64{
+		MetricCPUPercent:     stats.CPUPercent,
+		MetricMemoryBytes:    float64(stats.MemoryBytes),
+		MetricNetworkRxBytes: float64(stats.NetworkRxBytes),
+		MetricNetworkTxBytes: float64(stats.NetworkTxBytes),
+	})
+}
+
+// scrapeMockServer records the counters exposed by a mock server on the
+// host port.
+func (s *Sampler) scrapeMockServer(ctx context.Context, deploymentID, nodeID, port string, now time.Time) {
+	url := "http://" + net.JoinHostPort("127.0.0.1", port) + "/metrics"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return
+	}
+	s.append(deploymentID, nodeID, now, mockCounters(families))
+}
+
+// mockCounters sums the mock-server counters in families.
+func mockCounters(families map[string]*dto.MetricFamily) map[string]float64 {
+	values := map[string]float64{
+		MetricMockRequests:        0,
+		MetricMockErrors:          0,
+		MetricMockDownstreamCalls: 0,
+		MetricMockDownstreamErrs:  0,
+	}
+	if f, ok := families["mockserver_requests_total"]; ok {
+		for _, m := range f.GetMetric() {
+			v := m.GetCounter().GetValue()
+			values[MetricMockRequests] += v
+			if code, err := strconv.Atoi(label(m, "code")); err == nil && code >= 500 {
+				values[MetricMockErrors] += v
+			}
+		}
+	}
+	if f, ok := families["mockserver_downstream_calls_total"]; ok {
+		for _, m := range f.GetMetric() {
+			v := m.GetCounter().GetValue()
+			values[MetricMockDownstreamCalls] += v
+			if label(m, "outcome") == "error" {
+				values[MetricMockDownstreamErrs] += v
+			}
+		}
+	}
+	return values
+}
+
+// label returns the value of m's label name, or "".
+func label(m *dto.Metric, name string) string {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
+
+// ObserveTraffic records the stats of a traffic run against the running
+// deployment, per target node. It is meant to be called with every run
+// snapshot a traffic.Controller publishes.
+func (s *Sampler) ObserveTraffic(run traffic.Run) {
+	status := s.deployment.Status()
+	if status.State != deploy.StateDeployed {
+		return
+	}
+	id := DeploymentID(status)
+	now := s.now()
+
+	type nodeTotals struct {
+		requests, errors float64
+		p50, p95, p99    float64 // weighted by requests
+	}
+	nodes := make(map[string]*nodeTotals)
+	for _, ep := range run.Stats.Endpoints {
+		if ep.NodeID == "" {
+			continue
+		}
+		t, ok := nodes[ep.NodeID]
+		if !ok {
+			t = &nodeTotals{}
+			nodes[ep.NodeID] = t
+		}
+		r := float64(ep.Requests)
+		t.requests += r
+		t.errors += r * ep.ErrorRate
+		t.p50 += r * ep.Latency.P50
+		t.p95 += r * ep.Latency.P95
+		t.p99 += r * ep.Latency.P99
+	}
+
+	for nodeID, t := range nodes {
+		values := map[string]float64{
+			MetricTrafficRequests: t.requests,
+			MetricTrafficErrors:   t.errors,
+		}
+		if t.requests > 0 {
+			values[MetricTrafficLatencyP50] = t.p50 / t.requests
+			values[MetricTrafficLatencyP95] = t.p95 / t.requests
+			values[MetricTrafficLatencyP99] = t.p99 / t.requests
+		}
+		s.append(id, nodeID, now, values)
+	}
+}
+
+// append records values, keyed by metric, for one node at t.
+func (s *Sampler) append(deploymentID, nodeID string, t time.Time, values map[string]float64) {
+	for metric, v := range values {
+		s.store.Append(Key{Deployment: deploymentID, Node: nodeID, Metric: metric}, t, v)
+	}
+}
+
+// publishedPort returns the host port n publishes containerPort on.
+func publishedPort(n deploy.NodeStatus, containerPort string) (string, bool) {
+	var published []string
+	for host, ctr := range n.Ports {
+		if ctr == containerPort {
+			published = append(published, host)
+		}
+	}
+	if len(published) == 0 {
+		return "", false
+	}
+	sort.Strings(published)
+	return published[0], true
+}
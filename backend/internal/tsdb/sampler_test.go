@@ -0,0 +1,143 @@
+package tsdb
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker/templates"
+	"github.com/stwalsh4118/hephaestus/backend/internal/traffic"
+)
+
+// fakeDeployment reports a fixed status.
+type fakeDeployment struct {
+	status deploy.Status
+}
+
+func (d *fakeDeployment) Status() deploy.Status { return d.status }
+
+const mockMetrics = `# TYPE mockserver_requests_total counter
+mockserver_requests_total{code="200",method="GET",route="/users/{id}"} 7
+mockserver_requests_total{code="502",method="POST",route="/orders"} 2
+mockserver_requests_total{code="404",method="GET",route="unmatched"} 1
+# TYPE mockserver_downstream_calls_total counter
+mockserver_downstream_calls_total{outcome="ok",target="db"} 5
+mockserver_downstream_calls_total{outcome="error",target="cache"} 3
+`
+
+func lastValue(t *testing.T, s *Store, node, metric string) float64 {
+	t.Helper()
+	got, err := s.Query(Query{Deployment: "dep", Node: node, Metric: metric,
+		Start: t0, End: t0.Add(time.Second), Step: time.Second, Aggregation: AggLast})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(got) != 1 || len(got[0].Points) != 1 {
+		t.Fatalf("expected one point of %s/%s, got %+v", node, metric, got)
+	}
+	return got[0].Points[0].V
+}
+
+func TestSampler_Sample(t *testing.T) {
+	mock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(mockMetrics))
+	}))
+	defer mock.Close()
+	u, _ := url.Parse(mock.URL)
+	_, port, _ := net.SplitHostPort(u.Host)
+
+	ctx := context.Background()
+	orch := docker.NewSimulatedOrchestrator(docker.SimulationConfig{})
+	if err := orch.CreateNetwork(ctx); err != nil {
+		t.Fatalf("CreateNetwork: %v", err)
+	}
+	api, _ := orch.CreateContainer(ctx, docker.ContainerConfig{Name: "api", Image: "x"})
+	if err := orch.StartContainer(ctx, api); err != nil {
+		t.Fatalf("StartContainer: %v", err)
+	}
+
+	dep := &fakeDeployment{status: deploy.Status{State: deploy.StateDeployed, DeploymentID: "dep", Nodes: []deploy.NodeStatus{
+		{NodeID: "api", Name: "api", ContainerID: api, Ports: map[string]string{port: templates.PortAPIServiceMetrics}},
+		{NodeID: "gone", Name: "gone", ContainerID: "missing"},
+		{NodeID: "pending", Name: "pending"},
+	}}}
+	store := NewStore()
+	sampler := NewSampler(store, orch, dep)
+	sampler.now = func() time.Time { return t0.Add(time.Second) }
+	sampler.Sample(ctx)
+
+	if v := lastValue(t, store, "api", MetricMemoryBytes); v == 0 {
+		t.Error("expected the container's memory to be sampled")
+	}
+	for metric, want := range map[string]float64{
+		MetricMockRequests:        10,
+		MetricMockErrors:          2,
+		MetricMockDownstreamCalls: 8,
+		MetricMockDownstreamErrs:  3,
+	} {
+		if v := lastValue(t, store, "api", metric); v != want {
+			t.Errorf("%s: got %v, want %v", metric, v, want)
+		}
+	}
+	for _, k := range store.Keys("dep") {
+		if k.Node != "api" {
+			t.Errorf("expected only the api node to be sampled, got %+v", k)
+		}
+	}
+
+	dep.status.State = deploy.StateIdle
+	sampler.now = func() time.Time { return t0.Add(2 * time.Second) }
+	sampler.Sample(ctx)
+	got, _ := store.Query(Query{Deployment: "dep", Metric: MetricMemoryBytes,
+		Start: t0.Add(2 * time.Second), End: t0.Add(3 * time.Second), Step: time.Second, Aggregation: AggLast})
+	if len(got[0].Points) != 0 {
+		t.Errorf("expected no samples without a deployment, got %+v", got[0].Points)
+	}
+}
+
+func TestSampler_ObserveTraffic(t *testing.T) {
+	dep := &fakeDeployment{status: deploy.Status{State: deploy.StateDeployed, DiagramID: "dep"}}
+	store := NewStore()
+	sampler := NewSampler(store, docker.NewSimulatedOrchestrator(docker.SimulationConfig{}), dep)
+	sampler.now = func() time.Time { return t0.Add(time.Second) }
+
+	sampler.ObserveTraffic(traffic.Run{Stats: traffic.Stats{
+		Total: traffic.EndpointStats{Requests: 40},
+		Endpoints: []traffic.EndpointStats{
+			{Endpoint: traffic.Endpoint{NodeID: "gw", Path: "/a"}, Requests: 10, ErrorRate: 0.5,
+				Latency: traffic.LatencyStats{P50: 10, P95: 20, P99: 30}},
+			{Endpoint: traffic.Endpoint{NodeID: "gw", Path: "/b"}, Requests: 30,
+				Latency: traffic.LatencyStats{P50: 50, P95: 60, P99: 70}},
+			{Endpoint: traffic.Endpoint{NodeID: "idle"}},
+		},
+	}})
+
+	for metric, want := range map[string]float64{
+		MetricTrafficRequests:   40,
+		MetricTrafficErrors:     5,
+		MetricTrafficLatencyP50: 40,
+		MetricTrafficLatencyP95: 50,
+		MetricTrafficLatencyP99: 60,
+	} {
+		if v := lastValue(t, store, "gw", metric); v != want {
+			t.Errorf("%s: got %v, want %v", metric, v, want)
+		}
+	}
+	if v := lastValue(t, store, "idle", MetricTrafficRequests); v != 0 {
+		t.Errorf("expected an idle node to report 0 requests, got %v", v)
+	}
+	if got, _ := store.Query(Query{Deployment: "dep", Node: "idle", Metric: MetricTrafficLatencyP50,
+		Start: t0, End: t0.Add(time.Second), Step: time.Second, Aggregation: AggLast}); len(got) != 0 {
+		t.Errorf("expected no latency without requests, got %+v", got)
+	}
+}
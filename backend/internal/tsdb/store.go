@@ -0,0 +1,169 @@
+// Package tsdb is a small in-process time-series store for the metrics of
+// deployments: container stats, load-generator results and mock-server
+// counters. Each series keeps its recent samples at full resolution and
+// older history downsampled into coarser buckets, all in fixed-size rings,
+// so memory stays bounded however long a deployment runs.
+package tsdb
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default limits of a Store.
+const (
+	// DefaultMaxDeployments is how many deployments' series are kept; the
+	// series of the deployment written to least recently are dropped first.
+	DefaultMaxDeployments = 5
+	// DefaultMaxSeries bounds the series of one deployment, so a mock
+	// server with many routes cannot grow the store without limit.
+	DefaultMaxSeries = 2000
+)
+
+// tier is one resolution a series is kept at.
+type tier struct {
+	// resolution is the bucket width; zero keeps every sample.
+	resolution time.Duration
+	capacity   int
+}
+
+// tiers are the resolutions of every series, finest first: 30 minutes of
+// raw samples at the default 5s sampling interval, 6 hours of 1 minute
+// buckets and 2 days of 10 minute buckets.
+var tiers = []tier{
+	{resolution: 0, capacity: 360},
+	{resolution: time.Minute, capacity: 360},
+	{resolution: 10 * time.Minute, capacity: 288},
+}
+
+// Key identifies a series.
+type Key struct {
+	Deployment string `json:"deploymentId"`
+	Node       string `json:"nodeId"`
+	Metric     string `json:"metric"`
+}
+
+// series holds the samples of one key at every tier.
+type series struct {
+	rings []*ring
+}
+
+func newSeries() *series {
+	s := &series{rings: make([]*ring, len(tiers))}
+	for i, t := range tiers {
+		s.rings[i] = newRing(t.capacity)
+	}
+	return s
+}
+
+// append adds the sample v at t to every tier. Samples not after the
+// newest raw sample are dropped so every tier stays in time order.
+func (s *series) append(t time.Time, v float64) bool {
+	if last := s.rings[0].newest(); last != nil && !t.After(last.t) {
+		return false
+	}
+	for i, tr := range tiers {
+		r := s.rings[i]
+		if tr.resolution == 0 {
+			r.push(newPoint(t, v))
+			continue
+		}
+		bucket := t.Truncate(tr.resolution)
+		if last := r.newest(); last != nil && last.t.Equal(bucket) {
+			last.add(v)
+			continue
+		}
+		r.push(newPoint(bucket, v))
+	}
+	return true
+}
+
+// deployment holds the series of one deployment.
+type deployment struct {
+	series  map[Key]*series
+	touched time.Time
+}
+
+// Store keeps series in memory. It is safe for concurrent use.
+type Store struct {
+	maxDeployments int
+	maxSeries      int
+
+	mu          sync.RWMutex
+	deployments map[string]*deployment
+}
+
+// NewStore creates an empty Store with the default limits.
+func NewStore() *Store {
+	return &Store{
+		maxDeployments: DefaultMaxDeployments,
+		maxSeries:      DefaultMaxSeries,
+		deployments:    make(map[string]*deployment),
+	}
+}
+
+// Append records the sample v of key at t. It reports whether the sample
+// was kept: samples not newer than the series' latest one, and samples of
+// new series once the deployment has DefaultMaxSeries, are dropped.
+func (s *Store) Append(key Key, t time.Time, v float64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.deployments[key.Deployment]
+	if !ok {
+		s.evict()
+		d = &deployment{series: make(map[Key]*series)}
+		s.deployments[key.Deployment] = d
+	}
+	d.touched = t
+
+	ser, ok := d.series[key]
+	if !ok {
+		if len(d.series) >= s.maxSeries {
+			return false
+		}
+		ser = newSeries()
+		d.series[key] = ser
+	}
+	return ser.append(t, v)
+}
+
+// evict drops the least recently written deployments until there is room
+// for a new one. s.mu must be held.
+func (s *Store) evict() {
+	for len(s.deployments) >= s.maxDeployments {
+		var oldest string
+		var oldestAt time.Time
+		first := true
+		for id, d := range s.deployments {
+			if first || d.touched.Before(oldestAt) {
+				oldest, oldestAt, first = id, d.touched, false
+			}
+		}
+		delete(s.deployments, oldest)
+	}
+}
+
+// Keys returns the keys of the series of deploymentID, sorted by node and
+// metric.
+func (s *Store) Keys(deploymentID string) []Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	d, ok := s.deployments[deploymentID]
+	if !ok {
+		return []Key{}
+	}
+	keys := make([]Key, 0, len(d.series))
+	for k := range d.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Node != keys[j].Node {
+			return keys[i].Node < keys[j].Node
+		}
+		return keys[i].Metric < keys[j].Metric
+	})
+	return keys
+}
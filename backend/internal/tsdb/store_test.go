@@ -0,0 +1,96 @@
+package tsdb
+
+import (
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func key(node, metric string) Key {
+	return Key{Deployment: "dep", Node: node, Metric: metric}
+}
+
+func TestRing_OverwritesOldest(t *testing.T) {
+	r := newRing(3)
+	if r.newest() != nil {
+		t.Fatal("expected an empty ring to have no newest point")
+	}
+	for i := range 5 {
+		r.push(newPoint(t0.Add(time.Duration(i)*time.Second), float64(i)))
+	}
+	if !r.full() || r.len() != 3 {
+		t.Fatalf("expected a full ring of 3, got %d", r.len())
+	}
+	for i, want := range []float64{2, 3, 4} {
+		if got := r.at(i).last; got != want {
+			t.Errorf("point %d: got %v, want %v", i, got, want)
+		}
+	}
+	if r.newest().last != 4 {
+		t.Errorf("expected newest 4, got %v", r.newest().last)
+	}
+}
+
+func TestStore_AppendDownsamples(t *testing.T) {
+	s := NewStore()
+	k := key("api", MetricCPUPercent)
+	for i := range 24 {
+		if !s.Append(k, t0.Add(time.Duration(i)*5*time.Second), float64(i)) {
+			t.Fatalf("sample %d dropped", i)
+		}
+	}
+	if s.Append(k, t0, 100) {
+		t.Error("expected an out-of-order sample to be dropped")
+	}
+
+	ser := s.deployments["dep"].series[k]
+	if n := ser.rings[0].len(); n != 24 {
+		t.Errorf("expected 24 raw samples, got %d", n)
+	}
+	minutes := ser.rings[1]
+	if minutes.len() != 2 {
+		t.Fatalf("expected 2 one-minute buckets, got %d", minutes.len())
+	}
+	b := minutes.at(0)
+	if !b.t.Equal(t0) || b.count != 12 || b.min != 0 || b.max != 11 || b.first != 0 || b.last != 11 || b.avg() != 5.5 {
+		t.Errorf("unexpected first bucket %+v", b)
+	}
+	if n := ser.rings[2].len(); n != 1 {
+		t.Errorf("expected 1 ten-minute bucket, got %d", n)
+	}
+}
+
+func TestStore_EvictsOldestDeployment(t *testing.T) {
+	s := NewStore()
+	s.maxDeployments = 2
+	s.Append(Key{Deployment: "a", Metric: "m"}, t0, 1)
+	s.Append(Key{Deployment: "b", Metric: "m"}, t0.Add(time.Second), 1)
+	s.Append(Key{Deployment: "a", Metric: "m"}, t0.Add(2*time.Second), 1)
+	s.Append(Key{Deployment: "c", Metric: "m"}, t0.Add(3*time.Second), 1)
+
+	if len(s.Keys("b")) != 0 {
+		t.Error("expected the least recently written deployment to be evicted")
+	}
+	if len(s.Keys("a")) != 1 || len(s.Keys("c")) != 1 {
+		t.Error("expected the other deployments to be kept")
+	}
+}
+
+func TestStore_LimitsSeries(t *testing.T) {
+	s := NewStore()
+	s.maxSeries = 2
+	s.Append(key("a", "m"), t0, 1)
+	s.Append(key("b", "m"), t0, 1)
+	if s.Append(key("c", "m"), t0, 1) {
+		t.Error("expected a series beyond the limit to be dropped")
+	}
+	if !s.Append(key("a", "m"), t0.Add(time.Second), 2) {
+		t.Error("expected existing series to keep accepting samples")
+	}
+
+	keys := s.Keys("dep")
+	if len(keys) != 2 || keys[0].Node != "a" || keys[1].Node != "b" {
+		t.Errorf("unexpected keys %+v", keys)
+	}
+}
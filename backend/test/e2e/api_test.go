@@ -8,8 +8,12 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/stwalsh4118/hephaestus/backend/internal/deploy"
+	"github.com/stwalsh4118/hephaestus/backend/internal/docker"
+	"github.com/stwalsh4118/hephaestus/backend/internal/export"
 	"github.com/stwalsh4118/hephaestus/backend/internal/handler"
 	"github.com/stwalsh4118/hephaestus/backend/internal/middleware"
 	"github.com/stwalsh4118/hephaestus/backend/internal/model"
@@ -25,20 +29,48 @@ func buildServer(t *testing.T, storageDir string) *httptest.Server {
 		t.Fatalf("NewFileStore: %v", err)
 	}
 
+	deploymentStore, err := storage.NewFileDeploymentStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileDeploymentStore: %v", err)
+	}
+
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
+		_, _ = w.Write([]byte(`{"status":"ok","orchestrator":"simulated"}`))
 	})
 
 	diagramHandler := handler.NewDiagramHandler(store)
 	diagramHandler.RegisterRoutes(mux)
 
+	exportHandler := handler.NewExportHandler(store, export.Options{})
+	exportHandler.RegisterRoutes(mux)
+
+	importHandler := handler.NewImportHandler("")
+	importHandler.RegisterRoutes(mux)
+
 	wsHandler := handler.NewWebSocketHandler()
 	wsHandler.RegisterRoutes(mux)
 
+	orchestrator := docker.NewSimulatedOrchestrator(docker.SimulationConfig{})
+	manager := deploy.NewManager(orchestrator, deploymentStore, docker.ModeSimulated, func(s deploy.Status) {
+		wsHandler.Broadcast(handler.WSMessageDeploymentStatus, s)
+	})
+	wsHandler.SendOnConnect(handler.WSMessageDeploymentStatus, func() any { return manager.Status() })
+	deployHandler := handler.NewDeployHandler(store, manager)
+	deployHandler.RegisterRoutes(mux)
+
+	planHandler := handler.NewPlanHandler(store, manager)
+	planHandler.RegisterRoutes(mux)
+
+	deploymentHandler := handler.NewDeploymentHandler(deploymentStore)
+	deploymentHandler.RegisterRoutes(mux)
+
+	systemHandler := handler.NewSystemHandler(manager)
+	systemHandler.RegisterRoutes(mux)
+
 	corsHandler := middleware.CORS()(mux)
 
 	return httptest.NewServer(corsHandler)
@@ -320,6 +352,77 @@ func TestAC6_WebSocketPingPong(t *testing.T) {
 	}
 }
 
+// --- Deploy: simulated deployment streams status over /ws/status ---
+
+func TestDeploy_SimulatedStreamsStatus(t *testing.T) {
+	dir := t.TempDir()
+	server := buildServer(t, dir)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/status"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	body, _ := json.Marshal(validDiagramPayload())
+	resp, err := http.Post(server.URL+"/api/deploy", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /api/deploy: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(resp.Body)
+		t.Fatalf("status: got %d, want %d (body %s)", resp.StatusCode, http.StatusOK, raw)
+	}
+
+	// The deploy may have started before the client was registered, so read
+	// until the final deployed status arrives.
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var msg struct {
+			Type string        `json:"type"`
+			Data deploy.Status `json:"data"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if msg.Type != handler.WSMessageDeploymentStatus {
+			t.Fatalf("type: got %q, want %q", msg.Type, handler.WSMessageDeploymentStatus)
+		}
+		if msg.Data.State == deploy.StateDeployed {
+			if len(msg.Data.Nodes) != 2 {
+				t.Errorf("nodes: got %d, want 2", len(msg.Data.Nodes))
+			}
+			break
+		}
+	}
+
+	histResp, err := http.Get(server.URL + "/api/deployments")
+	if err != nil {
+		t.Fatalf("GET /api/deployments: %v", err)
+	}
+	var history []model.Deployment
+	if err := json.NewDecoder(histResp.Body).Decode(&history); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	_ = histResp.Body.Close()
+	if len(history) != 1 || history[0].Status != model.DeploymentDeployed {
+		t.Errorf("history: got %+v, want one deployed record", history)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/api/deploy", nil)
+	delResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE /api/deploy: %v", err)
+	}
+	_ = delResp.Body.Close()
+	if delResp.StatusCode != http.StatusOK {
+		t.Errorf("status: got %d, want %d", delResp.StatusCode, http.StatusOK)
+	}
+}
+
 // --- AC7: CORS configured to allow frontend origin ---
 
 func TestAC7_CORSHeaders(t *testing.T) {
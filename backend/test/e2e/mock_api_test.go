@@ -215,7 +215,7 @@ func TestPBI8_AC3_PrismContainerServesEndpoints(t *testing.T) {
 	}
 
 	hostPort := "14010"
-	cfg, err := tmpl.Build(node, hostPort)
+	cfg, err := tmpl.Build(node, "e2e-api", hostPort)
 	if err != nil {
 		t.Fatalf("Build: %v", err)
 	}
@@ -312,7 +312,7 @@ func TestPBI8_AC4_ResponseSchemaMatching(t *testing.T) {
 	}
 
 	hostPort := "14011"
-	cfg, err := tmpl.Build(node, hostPort)
+	cfg, err := tmpl.Build(node, "schema-api", hostPort)
 	if err != nil {
 		t.Fatalf("Build: %v", err)
 	}
@@ -390,7 +390,7 @@ func TestPBI8_AC5_CrossServiceDNSResolution(t *testing.T) {
 		}`),
 	}
 
-	apiCfg, err := tmpl.Build(apiNode, "14012")
+	apiCfg, err := tmpl.Build(apiNode, "user-service", "14012")
 	if err != nil {
 		t.Fatalf("Build API: %v", err)
 	}
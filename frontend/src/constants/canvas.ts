@@ -1,5 +1,6 @@
 import {
   ST_API_SERVICE,
+  ST_CUSTOM_CONTAINER,
   ST_NGINX,
   ST_POSTGRESQL,
   ST_RABBITMQ,
@@ -28,6 +29,7 @@ export const SERVICE_COLORS: Record<ServiceType, string> = {
   [ST_REDIS]: "#dc2626",
   [ST_NGINX]: "#009639",
   [ST_RABBITMQ]: "#ff6600",
+  [ST_CUSTOM_CONTAINER]: "#64748b",
 };
 
 export const SERVICE_ICONS: Record<ServiceType, string> = {
@@ -36,6 +38,7 @@ export const SERVICE_ICONS: Record<ServiceType, string> = {
   [ST_REDIS]: "RD",
   [ST_NGINX]: "NX",
   [ST_RABBITMQ]: "MQ",
+  [ST_CUSTOM_CONTAINER]: "CC",
 };
 
 export const SERVICE_LABELS: Record<ServiceType, string> = {
@@ -44,6 +47,7 @@ export const SERVICE_LABELS: Record<ServiceType, string> = {
   [ST_REDIS]: "Redis",
   [ST_NGINX]: "Nginx",
   [ST_RABBITMQ]: "RabbitMQ",
+  [ST_CUSTOM_CONTAINER]: "Custom Container",
 };
 
 export const PALETTE_ITEMS: PaletteItem[] = [
@@ -6,6 +6,7 @@ import type {
   CanvasEdge,
   CanvasNode,
   CanvasNodeData,
+  Resources,
   ServiceConfig,
   ServiceType,
 } from "@/types/canvas";
@@ -19,6 +20,7 @@ export interface DiagramJsonNode {
   description: string;
   position: { x: number; y: number };
   config?: ServiceConfig;
+  resources?: Resources;
 }
 
 export interface DiagramJsonEdge {
@@ -28,11 +30,17 @@ export interface DiagramJsonEdge {
   label: string;
 }
 
+export interface DiagramMonitoring {
+  prometheus: boolean;
+}
+
 export interface DiagramJson {
   id: string;
   name: string;
+  revision?: number;
   nodes: DiagramJsonNode[];
   edges: DiagramJsonEdge[];
+  monitoring?: DiagramMonitoring;
 }
 
 export function exportDiagram(
@@ -49,6 +57,7 @@ export function exportDiagram(
       description: node.data.description,
       position: { x: node.position.x, y: node.position.y },
       ...(node.data.config ? { config: node.data.config } : {}),
+      ...(node.data.resources ? { resources: node.data.resources } : {}),
     })),
     edges: edges.map((edge) => ({
       id: edge.id,
@@ -138,6 +147,7 @@ export function importDiagram(json: unknown): {
       type: node.type as ServiceType,
       description: node.description ?? "",
       ...(node.config ? { config: node.config } : {}),
+      ...(node.resources ? { resources: node.resources } : {}),
     } as CanvasNodeData,
   }));
 
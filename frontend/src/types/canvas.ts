@@ -5,13 +5,15 @@ export const ST_POSTGRESQL = "postgresql" as const;
 export const ST_REDIS = "redis" as const;
 export const ST_NGINX = "nginx" as const;
 export const ST_RABBITMQ = "rabbitmq" as const;
+export const ST_CUSTOM_CONTAINER = "custom-container" as const;
 
 export type ServiceType =
   | typeof ST_API_SERVICE
   | typeof ST_POSTGRESQL
   | typeof ST_REDIS
   | typeof ST_NGINX
-  | typeof ST_RABBITMQ;
+  | typeof ST_RABBITMQ
+  | typeof ST_CUSTOM_CONTAINER;
 
 export type HttpMethod = "GET" | "POST" | "PUT" | "DELETE" | "PATCH";
 
@@ -19,6 +21,35 @@ export interface Endpoint {
   method: HttpMethod;
   path: string;
   responseSchema: string;
+  weight?: number;
+  downstream?: DownstreamCall[];
+  latency?: Latency;
+  errors?: ErrorInjection;
+  timeoutRate?: number;
+}
+
+export type LatencyDistribution = "fixed" | "uniform" | "normal" | "lognormal";
+
+export interface Latency {
+  distribution: LatencyDistribution;
+  valueMs?: number;
+  minMs?: number;
+  maxMs?: number;
+  meanMs?: number;
+  stdDevMs?: number;
+  p50Ms?: number;
+  p99Ms?: number;
+}
+
+export interface ErrorInjection {
+  rate: number;
+  statusCodes?: number[];
+}
+
+export interface DownstreamCall {
+  target: string;
+  method?: HttpMethod;
+  path?: string;
 }
 
 export interface ApiServiceConfig {
@@ -49,12 +80,38 @@ export interface RabbitMQConfig {
   vhost: string;
 }
 
+export interface Healthcheck {
+  test: string[];
+  intervalSeconds?: number;
+  timeoutSeconds?: number;
+  retries?: number;
+  startPeriodSeconds?: number;
+}
+
+export interface CustomContainerConfig {
+  type: typeof ST_CUSTOM_CONTAINER;
+  image: string;
+  command?: string[];
+  entrypoint?: string[];
+  env?: Record<string, string>;
+  ports?: number[];
+  healthcheck?: Healthcheck;
+}
+
 export type ServiceConfig =
   | ApiServiceConfig
   | PostgresqlConfig
   | RedisConfig
   | NginxConfig
-  | RabbitMQConfig;
+  | RabbitMQConfig
+  | CustomContainerConfig;
+
+export interface Resources {
+  replicas?: number;
+  cpus?: number;
+  memoryMb?: number;
+  storageGb?: number;
+}
 
 export interface PaletteItem {
   id: ServiceType;
@@ -68,6 +125,7 @@ export interface CanvasNodeData extends Record<string, unknown> {
   type: ServiceType;
   description: string;
   config?: ServiceConfig;
+  resources?: Resources;
 }
 
 export interface CanvasEdgeData extends Record<string, unknown> {